@@ -0,0 +1,14 @@
+//go:build headless
+
+// sapliyd is the headless build of the Sapliy CLI. Build it with
+//
+//	CGO_ENABLED=0 go build -tags headless -trimpath -ldflags "-s -w" ./cmd/sapliyd
+package main
+
+import (
+	"github.com/sapliy/sapliy-cli/pkg/cmd"
+)
+
+func main() {
+	cmd.Execute()
+}
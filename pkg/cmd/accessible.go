@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Accessible mode (--accessible, or accessible: true in the config) makes
+// output plain text for screen readers and log collectors: status symbols
+// become words, emoji and box drawing are dropped, progress is reported in
+// whole lines instead of redrawn in place, and list and inspect commands
+// print labeled key-value lines instead of aligned tables.
+//
+// Commands print symbols straight to stdout, so the symbols are rewritten
+// on their way out: the command is run again in a child process whose
+// stdout and stderr pass through plainWriter. A child, unlike a pipe read
+// in this process, keeps what is printed right before os.Exit.
+
+// accessibleFilteredEnv is set in the child, whose output is already being
+// rewritten.
+const accessibleFilteredEnv = "SAPLIY_ACCESSIBLE_FILTERED"
+
+// accessibleTerminalFd is where the child finds the real stdout, for
+// programs such as $EDITOR that need the terminal.
+const accessibleTerminalFd = 3
+
+func accessibleMode() bool {
+	return viper.GetBool("accessible")
+}
+
+// terminalStdout is the stdout to hand to full-screen programs: the real
+// one, even when accessible mode rewrites this process's output.
+func terminalStdout() *os.File {
+	if os.Getenv(accessibleFilteredEnv) != "" {
+		if f := os.NewFile(accessibleTerminalFd, "terminal"); f != nil {
+			return f
+		}
+	}
+	return os.Stdout
+}
+
+// runAccessible runs this command in a child with its output rewritten by
+// plainWriter, and exits with the child's status. It returns without doing
+// anything when accessible mode is off, output is JSON or YAML (which is
+// left exactly as it is), or this already is the child.
+func runAccessible() {
+	if !accessibleMode() || structuredOutput() || os.Getenv(accessibleFilteredEnv) != "" {
+		return
+	}
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	child := exec.Command(self, os.Args[1:]...)
+	child.Env = append(os.Environ(), accessibleFilteredEnv+"=1")
+	child.Stdin = os.Stdin
+	child.Stdout = &plainWriter{w: os.Stdout}
+	child.Stderr = &plainWriter{w: os.Stderr}
+	if runtime.GOOS != "windows" {
+		child.ExtraFiles = []*os.File{os.Stdout}
+	}
+	// A process the child leaves running, such as a browser, must not
+	// keep this one waiting for the output pipes to close.
+	child.WaitDelay = time.Second
+
+	// Ctrl+C reaches the child directly, as it is in the same process
+	// group; SIGTERM is passed on.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	if err := child.Start(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	go func() {
+		for s := range sig {
+			if s != os.Interrupt {
+				child.Process.Signal(s)
+			}
+		}
+	}()
+	err = child.Wait()
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit):
+		os.Exit(exit.ExitCode())
+	case err != nil:
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// plainSymbols are the symbols output uses, as words or ASCII. Other
+// symbols are dropped.
+var plainSymbols = strings.NewReplacer(
+	"✅", "OK:",
+	"❌", "Error:",
+	"⚠️", "Warning:",
+	"⚠", "Warning:",
+	"✓", "ok",
+	"✗", "failed",
+	"→", "->",
+	"➡", "->",
+	"←", "<-",
+	"•", "-",
+	"›", ">",
+	"—", "-",
+	"…", "...",
+	"├", "-",
+	"└", "-",
+	"│", "",
+	"─", "",
+)
+
+// doubledLabel matches a label followed by a word that says the same, as in
+// "❌ Error creating zone".
+var doubledLabel = regexp.MustCompile(`(?:Error|Warning|OK): ((?:Error|Failed|Warning|OK)\b)`)
+
+// plainLine rewrites one line of output.
+func plainLine(s string) string {
+	s = plainSymbols.Replace(s)
+	var b strings.Builder
+	skipSpace := false
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r), r == '\ufe0f', r == '\u200d':
+			skipSpace = true
+			continue
+		case skipSpace && r == ' ':
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return doubledLabel.ReplaceAllString(b.String(), "$1")
+}
+
+// plainWriter rewrites output with plainLine as it is written, so prompts
+// and streamed lines appear at once. Lines that were only decoration, such
+// as box-drawn rules, are left out.
+type plainWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	partial []byte // a rune cut off at the end of the last write
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data := append(p.partial, b...)
+	p.partial = nil
+	if i := lastRuneStart(data); i >= 0 && !utf8.FullRune(data[i:]) {
+		p.partial = append([]byte(nil), data[i:]...)
+		data = data[:i]
+	}
+
+	var out strings.Builder
+	lines := strings.SplitAfter(string(data), "\n")
+	for _, line := range lines {
+		plain := plainLine(line)
+		if strings.HasSuffix(line, "\n") && strings.TrimSpace(line) != "" && strings.TrimSpace(plain) == "" {
+			continue
+		}
+		out.WriteString(plain)
+	}
+	if _, err := io.WriteString(p.w, out.String()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// lastRuneStart returns the index of the first byte of the last rune in b,
+// or -1.
+func lastRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// printLabeled prints v, as it would be encoded for --output json, as one
+// "label: value" line per field, in field order. Lists print each item under
+// a numbered heading, and nested fields are labeled with their path.
+func printLabeled(v interface{}) {
+	// JSON is YAML, and a yaml.Node keeps field order and numbers as written.
+	raw, err := json.Marshal(v)
+	var doc yaml.Node
+	if err == nil {
+		err = yaml.Unmarshal(raw, &doc)
+	}
+	if err != nil || len(doc.Content) == 0 {
+		fmt.Fprintf(os.Stderr, "Error encoding output: %v\n", err)
+		os.Exit(1)
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		printFields("", root)
+		return
+	}
+	if len(root.Content) == 0 {
+		fmt.Println("No items.")
+		return
+	}
+	for i, item := range root.Content {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Item %d of %d\n", i+1, len(root.Content))
+		printFields("", item)
+	}
+}
+
+func printFields(label string, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if label != "" {
+				key = label + "." + key
+			}
+			printFields(key, n.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			fmt.Printf("%s: none\n", label)
+		}
+		for i, item := range n.Content {
+			printFields(fmt.Sprintf("%s %d", label, i+1), item)
+		}
+	default:
+		value := n.Value
+		if n.Tag == "!!null" {
+			value = "none"
+		}
+		fmt.Printf("%s: %s\n", firstNonEmpty(label, "Value"), value)
+	}
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// An organization groups the accounts (merchants) an agency or platform
+// manages. Requests are scoped to the active account via the account_id
+// config key.
+type organization struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type account struct {
+	ID     string   `json:"id"`
+	OrgID  string   `json:"orgId"`
+	Name   string   `json:"name"`
+	Mode   string   `json:"mode"`
+	Labels []string `json:"labels"`
+}
+
+// contextStatusLine describes the active org, account and zone, e.g.
+// "org_acme › acct_shop1 · zone_test". Empty parts are left out.
+func contextStatusLine() string {
+	var b strings.Builder
+	if org := viper.GetString("org_id"); org != "" {
+		b.WriteString(org)
+	}
+	if acct := viper.GetString("account_id"); acct != "" {
+		if b.Len() > 0 {
+			b.WriteString(" › ")
+		}
+		b.WriteString(acct)
+	}
+	if zone := viper.GetString("current_zone"); zone != "" {
+		if b.Len() > 0 {
+			b.WriteString(" · ")
+		}
+		b.WriteString(zone)
+	}
+	return b.String()
+}
+
+func listAccounts(ctx context.Context, orgID string) ([]account, error) {
+	var accounts []account
+	err := apiRequest(ctx, http.MethodGet, "/v1/orgs/"+url.PathEscape(orgID)+"/accounts", nil, &accounts)
+	return accounts, err
+}
+
+var orgsCmd = &cobra.Command{
+	Use:   "orgs",
+	Short: "List and switch organizations",
+}
+
+var orgsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the organizations you belong to",
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var orgs []organization
+		if err := apiRequest(context.Background(), http.MethodGet, "/v1/orgs", nil, &orgs); err != nil {
+			fmt.Printf("Error listing organizations: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(orgs, func() {
+			if len(orgs) == 0 {
+				fmt.Println("No organizations found.")
+				return
+			}
+
+			active := viper.GetString("org_id")
+			fmt.Printf("  %-24s %-30s %-10s\n", "ID", "NAME", "ROLE")
+			fmt.Println(strings.Repeat("─", 68))
+			for _, o := range orgs {
+				marker := " "
+				if o.ID == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %-24s %-30s %-10s\n", marker, o.ID, truncate(o.Name, 30), o.Role)
+			}
+		})
+	},
+}
+
+var orgsUseCmd = &cobra.Command{
+	Use:   "use [org_id]",
+	Short: "Switch the active organization",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changed := []string{"org_id"}
+		if args[0] != viper.GetString("org_id") {
+			viper.Set("account_id", "")
+			viper.Set("current_zone", "")
+			changed = append(changed, "account_id", "current_zone")
+		}
+		viper.Set("org_id", args[0])
+		if err := saveConfig(changed...); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+		fmt.Printf("Switched to organization: %s\n", args[0])
+		fmt.Println("Pick an account with 'sapliy accounts list' and 'sapliy accounts use <id>'.")
+	},
+}
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "List and switch accounts within an organization",
+}
+
+var accountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the accounts in the active organization",
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		orgID, _ := cmd.Flags().GetString("org")
+		if orgID == "" {
+			orgID = viper.GetString("org_id")
+		}
+		if orgID == "" {
+			fmt.Println("Error: No organization selected. Use 'sapliy orgs use <id>' or --org.")
+			os.Exit(1)
+		}
+
+		accounts, err := listAccounts(context.Background(), orgID)
+		if err != nil {
+			fmt.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(accounts, func() {
+			if len(accounts) == 0 {
+				fmt.Printf("No accounts in %s.\n", orgID)
+				return
+			}
+
+			active := viper.GetString("account_id")
+			fmt.Printf("  %-24s %-30s %-6s %s\n", "ID", "NAME", "MODE", "LABELS")
+			fmt.Println(strings.Repeat("─", 80))
+			for _, a := range accounts {
+				marker := " "
+				if a.ID == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %-24s %-30s %-6s %s\n", marker, a.ID, truncate(a.Name, 30), a.Mode, strings.Join(a.Labels, ","))
+			}
+		})
+	},
+}
+
+var accountsUseCmd = &cobra.Command{
+	Use:   "use [account_id]",
+	Short: "Switch the active account",
+	Long: `Make an account the target of all following commands. The account's
+organization becomes active too, and the current zone is cleared since zones
+belong to an account.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var acct account
+		if err := apiRequest(context.Background(), http.MethodGet, "/v1/accounts/"+url.PathEscape(args[0]), nil, &acct); err != nil {
+			fmt.Printf("Error: cannot use account %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+
+		changed := []string{"account_id"}
+		if acct.ID != viper.GetString("account_id") && viper.GetString("current_zone") != "" {
+			fmt.Printf("Cleared current zone %s (it belongs to the previous account).\n", viper.GetString("current_zone"))
+			viper.Set("current_zone", "")
+			changed = append(changed, "current_zone")
+		}
+		viper.Set("account_id", acct.ID)
+		if acct.OrgID != "" {
+			viper.Set("org_id", acct.OrgID)
+			changed = append(changed, "org_id")
+		}
+		if err := saveConfig(changed...); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Switched to account %s (%s)\n", acct.ID, acct.Name)
+		fmt.Printf("   %s\n", contextStatusLine())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orgsCmd)
+	orgsCmd.AddCommand(orgsListCmd)
+	orgsCmd.AddCommand(orgsUseCmd)
+
+	rootCmd.AddCommand(accountsCmd)
+	accountsCmd.AddCommand(accountsListCmd)
+	accountsCmd.AddCommand(accountsUseCmd)
+
+	accountsListCmd.Flags().String("org", "", "Organization to list (default the active one)")
+}
@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// agentUpstreamHeader tells the agent which API host a request is for.
+const agentUpstreamHeader = "Sapliy-Agent-Upstream"
+
+// agentErrorHeader is set on 502 responses when the agent could not reach
+// the API, as opposed to the API answering 502 itself.
+const agentErrorHeader = "Sapliy-Agent-Error"
+
+// agentWarmInterval is how often the agent touches each API host so idle
+// connections are not closed by the server or a proxy.
+const agentWarmInterval = 25 * time.Second
+
+type agentStatus struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"startedAt"`
+	Hosts     []string  `json:"hosts"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"`
+}
+
+func defaultAgentSocket() string {
+	dir, err := sapliyDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "sapliy-agent.sock")
+	}
+	return filepath.Join(dir, "agent.sock")
+}
+
+// unixSocketClient returns an HTTP client that sends every request to the
+// server on a Unix socket, whatever the URL's host.
+func unixSocketClient(socket string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+}
+
+// agentTransport sends API requests through a running agent, which holds
+// warm connections to the API, and straight to the API otherwise. Requests
+// the agent cannot take (another API host, agent gone) fall back to base;
+// so do requests the agent could not deliver, if they are safe to repeat.
+type agentTransport struct {
+	base http.RoundTripper
+}
+
+// agentClient is the connection to the local agent, or nil if none is
+// running. It is looked up once per process.
+var agentClient = sync.OnceValue(func() *http.Client {
+	if os.Getenv("SAPLIY_AGENT") == "off" {
+		return nil
+	}
+	socket := defaultAgentSocket()
+	if !controlSocketAlive(socket) {
+		return nil
+	}
+	return unixSocketClient(socket, 0)
+})
+
+func (t *agentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	client := agentClient()
+	if client == nil || (req.Body != nil && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set(agentUpstreamHeader, req.URL.Scheme+"://"+req.URL.Host)
+	r.URL = &url.URL{Scheme: "http", Host: "sapliy-agent", Path: req.URL.Path, RawPath: req.URL.RawPath, RawQuery: req.URL.RawQuery}
+	r.Host = ""
+	if req.GetBody != nil {
+		var err error
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := client.Transport.RoundTrip(r)
+	var opErr *net.OpError
+	switch {
+	case err != nil && errors.As(err, &opErr) && opErr.Op == "dial":
+		// The agent went away; nothing was sent.
+	case err != nil:
+		if !idempotent(req) {
+			return nil, err
+		}
+	case resp.StatusCode == http.StatusMisdirectedRequest:
+		// Not an API host the agent serves; nothing was sent.
+		resp.Body.Close()
+	case resp.Header.Get(agentErrorHeader) != "" && idempotent(req):
+		// Repeat it directly so the error, and failover, are the same as
+		// without the agent.
+		resp.Body.Close()
+	default:
+		return resp, nil
+	}
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// runAgent serves API requests from other sapliy processes on socket,
+// forwarding them over the shared connection pool, until ctx is done or a
+// client asks it to stop.
+func runAgent(ctx context.Context, socket string) error {
+	if controlSocketAlive(socket) {
+		return fmt.Errorf("an agent is already running on %s", socket)
+	}
+	os.Remove(socket)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("agent socket %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+	os.Chmod(socket, 0600)
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	status := agentStatus{PID: os.Getpid(), StartedAt: time.Now(), Hosts: apiHosts()}
+	var requests, failures atomic.Int64
+
+	proxy := &httputil.ReverseProxy{
+		Transport: apiTransport(),
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			upstream, _ := url.Parse(pr.In.Header.Get(agentUpstreamHeader))
+			pr.Out.Header.Del(agentUpstreamHeader)
+			pr.SetURL(upstream)
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			failures.Add(1)
+			w.Header().Set(agentErrorHeader, err.Error())
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_agent/status", func(w http.ResponseWriter, r *http.Request) {
+		s := status
+		s.Requests, s.Errors = requests.Load(), failures.Load()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("POST /_agent/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+		stop()
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		upstream := r.Header.Get(agentUpstreamHeader)
+		if upstream == "" || matchAPIHost(status.Hosts, upstream) == "" {
+			http.Error(w, "not an API host this agent serves", http.StatusMisdirectedRequest)
+			return
+		}
+		requests.Add(1)
+		proxy.ServeHTTP(w, r)
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	go keepAPIWarm(ctx, status.Hosts)
+
+	<-ctx.Done()
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdown)
+}
+
+// keepAPIWarm opens a connection to each API host and touches it every
+// agentWarmInterval so it stays in the pool.
+func keepAPIWarm(ctx context.Context, hosts []string) {
+	client := &http.Client{Transport: apiTransport(), Timeout: 10 * time.Second}
+	ticker := time.NewTicker(agentWarmInterval)
+	defer ticker.Stop()
+	for {
+		for _, host := range hosts {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, host, nil)
+			if err != nil {
+				continue
+			}
+			if resp, err := client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func fetchAgentStatus(socket string) (*agentStatus, error) {
+	resp, err := unixSocketClient(socket, 5*time.Second).Get("http://sapliy-agent/_agent/status")
+	if err != nil {
+		return nil, fmt.Errorf("no agent running on %s (start one with 'sapliy agent start')", socket)
+	}
+	defer resp.Body.Close()
+	var s agentStatus
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Keep HTTP connections to the API warm for fast repeated commands",
+	Long: `Run a background agent that holds warm HTTP connections to the API. While it
+runs, every sapliy command sends its API requests through the agent over a
+local socket instead of opening a new connection (DNS, TCP and TLS) each
+time, which takes most of the latency out of short commands.
+
+Only HTTP API requests go through the agent. Event streams (debug listen,
+webhooks listen, connect, ...) are not shared or kept warm; each opens its
+own WebSocket connection as it does without the agent.
+
+Commands work the same with or without the agent: requests for API hosts the
+agent does not serve, or that it cannot deliver, go straight to the API.
+Set SAPLIY_AGENT=off to bypass it.`,
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the agent in the background",
+	Example: `  sapliy agent start
+  sapliy agent start --foreground   # e.g. under a process supervisor`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		socket, _ := cmd.Flags().GetString("socket")
+		foreground, _ := cmd.Flags().GetBool("foreground")
+		if socket == "" {
+			socket = defaultAgentSocket()
+		}
+		if s, err := fetchAgentStatus(socket); err == nil {
+			fmt.Printf("Agent already running (pid %d) on %s\n", s.PID, socket)
+			return
+		}
+
+		if foreground {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			fmt.Printf("🔌 Agent listening on %s for %s\n", socket, strings.Join(apiHosts(), ", "))
+			if err := runAgent(ctx, socket); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		dir, err := sapliyDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		logPath := filepath.Join(dir, "agent.log")
+		logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer logFile.Close()
+
+		childArgs := []string{"agent", "start", "--foreground", "--socket", socket}
+		if configFile := cmd.Flags().Lookup("config"); configFile != nil && configFile.Changed {
+			childArgs = append([]string{"--config", configFile.Value.String()}, childArgs...)
+		}
+		if profile := cmd.Flags().Lookup("profile"); profile != nil && profile.Changed {
+			childArgs = append([]string{"--profile", profile.Value.String()}, childArgs...)
+		}
+		child := exec.Command(self, childArgs...)
+		child.Stdout, child.Stderr = logFile, logFile
+		detachProcess(child)
+		if err := child.Start(); err != nil {
+			fmt.Printf("Error starting agent: %v\n", err)
+			os.Exit(1)
+		}
+		child.Process.Release()
+
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if s, err := fetchAgentStatus(socket); err == nil {
+				fmt.Printf("🔌 Agent started (pid %d) on %s\n", s.PID, socket)
+				return
+			}
+		}
+		fmt.Printf("❌ The agent did not start; see %s\n", logPath)
+		os.Exit(1)
+	},
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		socket, _ := cmd.Flags().GetString("socket")
+		if socket == "" {
+			socket = defaultAgentSocket()
+		}
+		resp, err := unixSocketClient(socket, 5*time.Second).Post("http://sapliy-agent/_agent/stop", "application/json", nil)
+		if err != nil {
+			fmt.Printf("No agent running on %s\n", socket)
+			return
+		}
+		resp.Body.Close()
+		fmt.Println("🔌 Agent stopped")
+	},
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the agent is running and what it has done",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		socket, _ := cmd.Flags().GetString("socket")
+		if socket == "" {
+			socket = defaultAgentSocket()
+		}
+		s, err := fetchAgentStatus(socket)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(s, func() {
+			fmt.Printf("Agent:     running (pid %d)\n", s.PID)
+			fmt.Printf("Socket:    %s\n", socket)
+			fmt.Printf("API hosts: %s\n", strings.Join(s.Hosts, ", "))
+			fmt.Printf("Uptime:    %s\n", time.Since(s.StartedAt).Round(time.Second))
+			fmt.Printf("Requests:  %d (%d could not be delivered)\n", s.Requests, s.Errors)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentStartCmd)
+	agentCmd.AddCommand(agentStopCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+
+	agentCmd.PersistentFlags().String("socket", "", "Agent socket (default ~/.sapliy/agent.sock)")
+	agentStartCmd.Flags().Bool("foreground", false, "Run in the foreground instead of detaching")
+}
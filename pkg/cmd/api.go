@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// apiError is a non-2xx response from the REST API.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// apiPage is one page of a cursor-paginated list endpoint. NextCursor is
+// empty on the last page.
+type apiPage[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// listPages fetches the page of path starting at cursor, or with all set
+// that page and every one after it. It returns the items and the cursor of
+// the next page, empty when there are no more.
+func listPages[T any](ctx context.Context, path string, q url.Values, cursor string, all bool) ([]T, string, error) {
+	items := []T{}
+	for {
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		var page apiPage[T]
+		if err := apiRequest(ctx, http.MethodGet, path+"?"+q.Encode(), nil, &page); err != nil {
+			return nil, "", err
+		}
+		items = append(items, page.Data...)
+		cursor = page.NextCursor
+		if !all || cursor == "" {
+			return items, cursor, nil
+		}
+	}
+}
+
+// apiRequest calls a REST endpoint the SDK does not cover yet. body, if not
+// nil, is sent as JSON, and a JSON response is decoded into out if it is not
+// nil. Authentication headers match the ones the SDK sends, plus the active
+// account when one is selected.
+func apiRequest(ctx context.Context, method, path string, body, out interface{}) error {
+	return apiCall(ctx, method, path, body, out, 0)
+}
+
+// apiGetTrimmed is apiRequest for the GETs of list and inspect commands:
+// event and step payloads in the response are cut to --max-payload-bytes
+// while it is decoded (see decodeTrimmed). Responses that are sent back to
+// the API or written to files must use apiRequest.
+func apiGetTrimmed(ctx context.Context, path string, out interface{}) error {
+	return apiCall(ctx, http.MethodGet, path, nil, out, maxPayloadBytes())
+}
+
+// apiCall is apiRequest with payloads cut to payloadLimit bytes, if not 0.
+func apiCall(ctx context.Context, method, path string, body, out interface{}, payloadLimit int) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiBaseURL()+path, reader)
+	if err != nil {
+		return err
+	}
+	setAuthHeaders(req)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := apiHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &apiError{StatusCode: resp.StatusCode, Message: apiErrorMessage(resp.Body)}
+	}
+
+	// A request held for dual control is answered 202 with the approval
+	// it waits for; other 202s carry the object asked for.
+	var respBody io.Reader = resp.Body
+	if resp.StatusCode == http.StatusAccepted {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		var a approval
+		if json.Unmarshal(raw, &a) == nil && a.Object == "approval" {
+			return &approvalPendingError{approval: &a}
+		}
+		respBody = bytes.NewReader(raw)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if payloadLimit > 0 {
+		err = decodeTrimmed(respBody, out, payloadLimit)
+	} else {
+		err = json.NewDecoder(respBody).Decode(out)
+	}
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// setAuthHeaders adds the headers the SDK authenticates with, plus the
+// active account when one is selected.
+func setAuthHeaders(req *http.Request) {
+	apiKey := viper.GetString("api_key")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("X-API-Key", apiKey)
+	if account := viper.GetString("account_id"); account != "" {
+		req.Header.Set("Sapliy-Account", account)
+	}
+}
+
+// uploadedFile is a file stored by the API, referenced by its ID from other
+// objects such as dispute evidence.
+type uploadedFile struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	Type     string `json:"type"`
+	Purpose  string `json:"purpose"`
+}
+
+// apiUpload sends a local file to the files endpoint as multipart form data
+// and returns the stored file. purpose tells the API what the file is for,
+// e.g. dispute_evidence, and decides which file types it accepts.
+func apiUpload(ctx context.Context, purpose, path string) (*uploadedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(head[:n])
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Stream the form through a pipe so large files are not held in memory.
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		err := form.WriteField("purpose", purpose)
+		if err == nil {
+			h := textproto.MIMEHeader{}
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filepath.Base(path)))
+			h.Set("Content-Type", contentType)
+			var part io.Writer
+			if part, err = form.CreatePart(h); err == nil {
+				_, err = io.Copy(part, f)
+			}
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiBaseURL()+"/v1/files", pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	setAuthHeaders(req)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := transferHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &apiError{StatusCode: resp.StatusCode, Message: apiErrorMessage(resp.Body)}
+	}
+
+	var uploaded uploadedFile
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &uploaded, nil
+}
+
+// apiErrorMessage extracts a readable message from an error response body.
+func apiErrorMessage(r io.Reader) string {
+	raw, _ := io.ReadAll(io.LimitReader(r, 4096))
+	var body struct {
+		Error   interface{} `json:"error"`
+		Message string      `json:"message"`
+	}
+	if json.Unmarshal(raw, &body) == nil {
+		switch e := body.Error.(type) {
+		case string:
+			return e
+		case map[string]interface{}:
+			if msg, ok := e["message"].(string); ok {
+				return msg
+			}
+		}
+		if body.Message != "" {
+			return body.Message
+		}
+	}
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// approvalStatuses are the statuses approvals list --status accepts.
+var approvalStatuses = []string{"pending", "approved", "rejected", "expired", "canceled"}
+
+// approval is an operation, such as a large refund or a payout, that the
+// account's dual control rules hold until someone other than the requester
+// approves it.
+type approval struct {
+	ID          string          `json:"id"`
+	Object      string          `json:"object"`
+	Action      string          `json:"action"` // e.g. refund.create, payout.create
+	Status      string          `json:"status"`
+	Summary     string          `json:"summary,omitempty"`
+	Amount      int64           `json:"amount,omitempty"`
+	Currency    string          `json:"currency,omitempty"`
+	Resource    string          `json:"resource,omitempty"` // the object acted on
+	Result      string          `json:"result,omitempty"`   // the object created once approved
+	RequestedBy string          `json:"requestedBy,omitempty"`
+	Note        string          `json:"note,omitempty"`
+	CreatedAt   *time.Time      `json:"createdAt,omitempty"`
+	ExpiresAt   *time.Time      `json:"expiresAt,omitempty"`
+	Trail       []approvalEntry `json:"trail,omitempty"`
+}
+
+// approvalEntry is one step of an approval's audit trail.
+type approvalEntry struct {
+	Action string    `json:"action"` // requested, approved, rejected, expired or canceled
+	Actor  string    `json:"actor,omitempty"`
+	Note   string    `json:"note,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+func approvalPath(approvalID, suffix string) string {
+	return "/v1/approvals/" + url.PathEscape(approvalID) + suffix
+}
+
+// title names what an approval is for, for tables and messages.
+func (a *approval) title() string {
+	if a.Summary != "" {
+		return a.Summary
+	}
+	if a.Resource != "" {
+		return a.Action + " on " + a.Resource
+	}
+	return a.Action
+}
+
+func (a *approval) amount() string {
+	if a.Currency == "" {
+		return ""
+	}
+	return formatAmount(a.Amount, a.Currency) + " " + strings.ToUpper(a.Currency)
+}
+
+// approvalPendingError is returned by apiRequest when the API accepted a
+// request but holds it for a second approver, answering 202 with the
+// approval instead of the object asked for.
+type approvalPendingError struct {
+	approval *approval
+}
+
+func (e *approvalPendingError) Error() string {
+	return fmt.Sprintf("waiting for a second approver (approval %s; see 'sapliy approvals list')", e.approval.ID)
+}
+
+// reportPendingApproval prints the approval a mutating command's request is
+// waiting for, and reports whether err was one. Commands call it before
+// treating err as a failure, as the request itself went through.
+func reportPendingApproval(err error) bool {
+	var pending *approvalPendingError
+	if !errors.As(err, &pending) {
+		return false
+	}
+	a := pending.approval
+	printOutput(a, func() {
+		fmt.Printf("⏳ %s needs a second approver; approval %s is pending.\n", a.title(), a.ID)
+		if a.ExpiresAt != nil {
+			fmt.Printf("   It expires %s unless approved.\n", a.ExpiresAt.Local().Format(time.RFC1123))
+		}
+		fmt.Printf("   Someone else on the account can approve it with: sapliy approvals approve %s\n", a.ID)
+	})
+	return true
+}
+
+func printApproval(a *approval) {
+	fmt.Printf("📝 Approval %s — %s\n", a.ID, a.Status)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Action:       %s\n", a.title())
+	if amount := a.amount(); amount != "" {
+		fmt.Printf("Amount:       %s\n", amount)
+	}
+	if a.Resource != "" {
+		fmt.Printf("On:           %s\n", a.Resource)
+	}
+	if a.RequestedBy != "" {
+		fmt.Printf("Requested by: %s\n", a.RequestedBy)
+	}
+	if a.Note != "" {
+		fmt.Printf("Note:         %s\n", a.Note)
+	}
+	if a.ExpiresAt != nil && a.Status == "pending" {
+		fmt.Printf("Expires:      %s\n", a.ExpiresAt.Local().Format(time.RFC1123))
+	}
+	if a.Result != "" {
+		fmt.Printf("Result:       %s\n", a.Result)
+	}
+	if len(a.Trail) > 0 {
+		fmt.Println("\nAudit trail:")
+		for _, e := range a.Trail {
+			line := fmt.Sprintf("  %s  %-9s  %s", e.At.Local().Format("2006-01-02 15:04:05"), e.Action, e.Actor)
+			if e.Note != "" {
+				line += fmt.Sprintf("  %q", e.Note)
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// fetchPendingApproval loads an approval for approve or reject, exiting if
+// it has already been decided.
+func fetchPendingApproval(ctx context.Context, approvalID string) *approval {
+	var a approval
+	if err := apiRequest(ctx, http.MethodGet, approvalPath(approvalID, ""), nil, &a); err != nil {
+		fmt.Printf("Error fetching approval: %v\n", err)
+		os.Exit(1)
+	}
+	if a.Status != "pending" {
+		fmt.Printf("Error: approval %s is already %s.\n", a.ID, a.Status)
+		os.Exit(1)
+	}
+	return &a
+}
+
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Approve or reject operations held for dual control",
+	Long: `Operations the account's dual control rules cover, such as large refunds and
+payouts, are not carried out when requested: the API holds them as a pending
+approval until someone other than the requester approves or rejects them.
+Commands that request one print the approval's ID instead of a result.
+
+Every request and decision, with who made it and their note, is kept in the
+approval's audit trail and in the account's audit log.`,
+}
+
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List approvals, pending ones by default",
+	Example: `  sapliy approvals list
+  sapliy approvals list --status approved --action refund.create`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		action, _ := cmd.Flags().GetString("action")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "all" && !slices.Contains(approvalStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s or all)\n", status, strings.Join(approvalStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		if status != "all" {
+			q.Set("status", status)
+		}
+		if action != "" {
+			q.Set("action", action)
+		}
+		approvals, cursor, err := listPages[approval](context.Background(), "/v1/approvals", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing approvals: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(approvals, func() {
+			if len(approvals) == 0 {
+				fmt.Println("No approvals found.")
+				return
+			}
+
+			fmt.Printf("%-20s %-36s %-16s %-24s %-9s %s\n", "ID", "ACTION", "AMOUNT", "REQUESTED BY", "STATUS", "CREATED")
+			fmt.Println(strings.Repeat("─", 120))
+			for _, a := range approvals {
+				created := ""
+				if a.CreatedAt != nil {
+					created = a.CreatedAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Printf("%-20s %-36s %-16s %-24s %-9s %s\n", a.ID, truncate(a.title(), 36), a.amount(), truncate(a.RequestedBy, 24), a.Status, created)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore approvals available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var approvalsGetCmd = &cobra.Command{
+	Use:   "get [approval_id]",
+	Short: "Show an approval and its audit trail",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var a approval
+		if err := apiRequest(context.Background(), http.MethodGet, approvalPath(args[0], ""), nil, &a); err != nil {
+			fmt.Printf("Error fetching approval: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(a, func() { printApproval(&a) })
+	},
+}
+
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve [approval_id]",
+	Short: "Approve an operation, which is then carried out",
+	Long: `Approve a pending operation as the second approver. It is carried out at
+once; the approval then names what it created, such as the refund. The API
+refuses approvals from whoever requested the operation.`,
+	Example: `  sapliy approvals approve apr_123
+  sapliy approvals approve apr_123 --note "Checked with the customer" --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		a := fetchPendingApproval(ctx, args[0])
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			printApproval(a)
+			fmt.Println()
+			if !confirm(fmt.Sprintf("Approve %s? It will be carried out at once. [y/N]: ", a.title())) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		req := map[string]interface{}{}
+		if note, _ := cmd.Flags().GetString("note"); note != "" {
+			req["note"] = note
+		}
+		if err := apiRequest(ctx, http.MethodPost, approvalPath(a.ID, "/approve"), req, a); err != nil {
+			fmt.Printf("❌ Failed to approve: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(a, func() {
+			fmt.Printf("✅ Approved %s: %s\n", a.ID, a.title())
+			if a.Result != "" {
+				fmt.Printf("   Carried out as %s\n", a.Result)
+			}
+		})
+	},
+}
+
+var approvalsRejectCmd = &cobra.Command{
+	Use:     "reject [approval_id]",
+	Short:   "Reject an operation, which is then not carried out",
+	Example: `  sapliy approvals reject apr_123 --reason "Refund already issued by bank transfer"`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		reason, _ := cmd.Flags().GetString("reason")
+		if strings.TrimSpace(reason) == "" {
+			fmt.Println("Error: --reason is required; it is kept in the audit trail.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		a := fetchPendingApproval(ctx, args[0])
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Reject %s? [y/N]: ", a.title())) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(ctx, http.MethodPost, approvalPath(a.ID, "/reject"), map[string]interface{}{"reason": reason}, a); err != nil {
+			fmt.Printf("❌ Failed to reject: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(a, func() {
+			fmt.Printf("🚫 Rejected %s: %s\n", a.ID, a.title())
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+	approvalsCmd.AddCommand(approvalsListCmd)
+	approvalsListCmd.Flags().IntP("limit", "l", 20, "Number of approvals per page")
+	approvalsListCmd.Flags().StringP("status", "s", "pending", "Filter by status ("+strings.Join(approvalStatuses, ", ")+", or all)")
+	approvalsListCmd.Flags().String("action", "", "Filter by action (e.g. refund.create, payout.create)")
+	approvalsListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	approvalsListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	approvalsCmd.AddCommand(approvalsGetCmd)
+
+	approvalsCmd.AddCommand(approvalsApproveCmd)
+	approvalsApproveCmd.Flags().String("note", "", "Note kept in the audit trail")
+	approvalsApproveCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	approvalsCmd.AddCommand(approvalsRejectCmd)
+	approvalsRejectCmd.Flags().String("reason", "", "Why the operation is rejected, kept in the audit trail (required)")
+	approvalsRejectCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
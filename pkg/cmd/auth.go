@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,28 +18,99 @@ var authCmd = &cobra.Command{
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
-	Short: "Log in with your API key",
+	Short: "Log in through the browser or with an API key",
+	Long: `Log in through the browser: the CLI shows a one-time code and a URL,
+opens the URL, and waits while you approve the request in the dashboard. The
+resulting token is stored in the config file and refreshed automatically.
+
+Use --with-api-key to paste an API key instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var apiKey string
-		fmt.Print("Enter API Key: ")
-		fmt.Scanln(&apiKey)
+		withAPIKey, _ := cmd.Flags().GetBool("with-api-key")
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+
+		if withAPIKey {
+			apiKey, err := (&lineReader{mask: true}).readLine("Enter API Key: ")
+			if err != nil {
+				fmt.Println("Cancelled.")
+				return
+			}
+			apiKey = strings.TrimSpace(apiKey)
+			if apiKey == "" {
+				// An empty key would silently replace the stored one.
+				fmt.Println("❌ No API key entered.")
+				os.Exit(1)
+			}
+
+			err = setCredential("api_key", apiKey)
+			if err == nil {
+				err = setCredential("refresh_token", "")
+			}
+			if err != nil {
+				fmt.Printf("Error storing API key: %v\n", err)
+				return
+			}
+			viper.Set("token_expiry", "")
+			if err := saveConfig("token_expiry"); err != nil {
+				fmt.Printf("Error saving config: %v\n", err)
+				return
+			}
+
+			fmt.Println("Successfully authenticated!")
+			return
+		}
 
-		viper.Set("api_key", apiKey)
-		err := viper.WriteConfig()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		da, err := requestDeviceCode(ctx)
 		if err != nil {
-			err = viper.SafeWriteConfig()
+			fmt.Printf("❌ Could not start browser login: %v\n", err)
+			fmt.Println("   Use 'sapliy auth login --with-api-key' to log in with an API key.")
+			os.Exit(1)
 		}
 
+		link := da.VerificationURIComplete
+		if link == "" {
+			link = da.VerificationURI
+		}
+		fmt.Printf("Your one-time code is: %s\n", da.UserCode)
+		fmt.Printf("Approve it at: %s\n", da.VerificationURI)
+		if !noBrowser {
+			if err := openBrowser(link); err == nil {
+				fmt.Println("🌐 Opened your browser. Waiting for approval... (Ctrl+C to cancel)")
+			} else {
+				fmt.Println("Open the URL above in a browser. Waiting for approval... (Ctrl+C to cancel)")
+			}
+		} else {
+			fmt.Println("Waiting for approval... (Ctrl+C to cancel)")
+		}
+
+		tok, err := pollDeviceToken(ctx, da)
 		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Println("\nLogin cancelled.")
+				os.Exit(1)
+			}
+			fmt.Printf("❌ Login failed: %v\n", err)
+			os.Exit(1)
+		}
+		if !strings.EqualFold(tok.TokenType, "bearer") && tok.TokenType != "" {
+			fmt.Printf("❌ Login failed: unsupported token type %q\n", tok.TokenType)
+			os.Exit(1)
+		}
+
+		if err := storeToken(tok); err != nil {
 			fmt.Printf("Error saving config: %v\n", err)
 			return
 		}
-
-		fmt.Println("Successfully authenticated!")
+		fmt.Println("✅ Successfully authenticated!")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().Bool("with-api-key", false, "Paste an API key instead of logging in through the browser")
+	loginCmd.Flags().Bool("no-browser", false, "Print the login URL without opening a browser")
 }
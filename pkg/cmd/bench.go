@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// benchEndpoint is an API call 'sapliy bench' can measure. body builds the
+// request body for the zone being benchmarked; nil for reads.
+type benchEndpoint struct {
+	method string
+	path   string
+	body   func(zone string) interface{}
+}
+
+var benchEndpoints = map[string]benchEndpoint{
+	"payments.create": {http.MethodPost, "/v1/payments", func(zone string) interface{} {
+		return map[string]interface{}{
+			"amount": 100, "currency": "usd", "zoneId": zone, "description": "sapliy bench",
+			"metadata": map[string]string{"sapliy_bench": "true"},
+		}
+	}},
+	"payments.list":    {http.MethodGet, "/v1/payments?limit=1", nil},
+	"customers.list":   {http.MethodGet, "/v1/customers?limit=1", nil},
+	"event-types.list": {http.MethodGet, "/v1/event-types?limit=1", nil},
+}
+
+// benchSample is one timed request. Connection phases are zero when an
+// idle connection was reused.
+type benchSample struct {
+	total, dns, connect, tls, server time.Duration
+	err                              error
+}
+
+// benchStats summarizes the durations of one phase.
+type benchStats struct {
+	Count int     `json:"count"`
+	P50Ms float64 `json:"p50Ms"`
+	P95Ms float64 `json:"p95Ms"`
+	P99Ms float64 `json:"p99Ms"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+type benchReport struct {
+	Endpoint    string                `json:"endpoint"`
+	Method      string                `json:"method"`
+	URL         string                `json:"url"`
+	Requests    int                   `json:"requests"`
+	Concurrency int                   `json:"concurrency"`
+	Errors      int                   `json:"errors"`
+	ErrorRate   float64               `json:"errorRate"`
+	ErrorKinds  map[string]int        `json:"errorKinds,omitempty"`
+	Throughput  float64               `json:"requestsPerSecond"`
+	Latency     map[string]benchStats `json:"latency"`
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted)) + 0.999999)
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func summarizeDurations(ds []time.Duration) benchStats {
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	s := benchStats{Count: len(sorted)}
+	if len(sorted) > 0 {
+		s.P50Ms, s.P95Ms, s.P99Ms = ms(percentile(sorted, 50)), ms(percentile(sorted, 95)), ms(percentile(sorted, 99))
+		s.MaxMs = ms(sorted[len(sorted)-1])
+	}
+	return s
+}
+
+// benchErrorKind groups failures for the summary: by status code for API
+// errors, by kind for network errors.
+func benchErrorKind(err error) string {
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "network"
+}
+
+// timedRequest makes one API call and records how long each phase took.
+func timedRequest(ep benchEndpoint, body interface{}, timeout time.Duration) benchSample {
+	var s benchSample
+	var dnsStart, connectStart, tlsStart, wroteAt time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { s.dns = time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { s.connect = time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { s.tls = time.Since(tlsStart) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteAt = time.Now() },
+		GotFirstResponseByte: func() { s.server = time.Since(wroteAt) },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ctx = httptrace.WithClientTrace(ctx, trace)
+
+	start := time.Now()
+	s.err = apiRequest(ctx, ep.method, ep.path, body, nil)
+	s.total = time.Since(start)
+	return s
+}
+
+func benchEndpointNames() []string {
+	names := make([]string, 0, len(benchEndpoints))
+	for name := range benchEndpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure API latency from this machine",
+	Long: `Call an API endpoint --n times, --concurrency at a time, and report p50, p95
+and p99 latencies and the error rate as seen from this machine's network.
+Each request is split into DNS lookup, TCP connect, TLS handshake and server
+time (request sent to first response byte), so a slow network path can be
+told apart from a slow API; connection phases only count requests that
+opened a new connection.
+
+Endpoints: ` + strings.Join(benchEndpointNames(), ", ") + `.
+payments.create creates real test payments (metadata.sapliy_bench=true), so
+bench refuses to run with a live API key.`,
+	Example: `  sapliy bench --endpoint payments.create --n 200 --concurrency 10
+  sapliy bench --endpoint payments.list -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+		if strings.HasPrefix(apiKey, "sk_live_") {
+			fmt.Println("Error: bench only runs in test mode; use a test API key (sk_test_...).")
+			os.Exit(1)
+		}
+
+		name, _ := cmd.Flags().GetString("endpoint")
+		n, _ := cmd.Flags().GetInt("n")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		ep, ok := benchEndpoints[name]
+		if !ok {
+			fmt.Printf("Error: unknown endpoint %q (use %s)\n", name, strings.Join(benchEndpointNames(), ", "))
+			os.Exit(1)
+		}
+		if n < 1 {
+			fmt.Println("Error: --n must be at least 1.")
+			os.Exit(1)
+		}
+		concurrency = min(max(concurrency, 1), n)
+
+		var body interface{}
+		if ep.body != nil {
+			body = ep.body(requireZone(cmd))
+		}
+
+		report := benchReport{Endpoint: name, Method: ep.method, URL: apiBaseURL() + ep.path, Requests: n, Concurrency: concurrency}
+		if !structuredOutput() {
+			fmt.Printf("⏱️  %s %s, %d request(s), %d at a time\n", ep.method, report.URL, n, concurrency)
+		}
+
+		samples := make([]benchSample, n)
+		progress := newProgressBar(n)
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		start := time.Now()
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					samples[i] = timedRequest(ep, body, timeout)
+					progress.step()
+				}
+			}()
+		}
+		for i := range samples {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		elapsed := time.Since(start)
+
+		var total, dns, connect, handshake, server []time.Duration
+		for _, s := range samples {
+			if s.err != nil {
+				report.Errors++
+				if report.ErrorKinds == nil {
+					report.ErrorKinds = map[string]int{}
+				}
+				report.ErrorKinds[benchErrorKind(s.err)]++
+				continue
+			}
+			total = append(total, s.total)
+			server = append(server, s.server)
+			if s.dns > 0 {
+				dns = append(dns, s.dns)
+			}
+			if s.connect > 0 {
+				connect = append(connect, s.connect)
+			}
+			if s.tls > 0 {
+				handshake = append(handshake, s.tls)
+			}
+		}
+		report.ErrorRate = float64(report.Errors) / float64(n)
+		report.Throughput = float64(n) / elapsed.Seconds()
+		report.Latency = map[string]benchStats{
+			"total":   summarizeDurations(total),
+			"dns":     summarizeDurations(dns),
+			"connect": summarizeDurations(connect),
+			"tls":     summarizeDurations(handshake),
+			"server":  summarizeDurations(server),
+		}
+
+		printOutput(report, func() {
+			fmt.Printf("\n%-10s %6s %9s %9s %9s %9s\n", "PHASE", "COUNT", "P50", "P95", "P99", "MAX")
+			fmt.Println(strings.Repeat("─", 58))
+			for _, phase := range []string{"total", "dns", "connect", "tls", "server"} {
+				s := report.Latency[phase]
+				if s.Count == 0 {
+					continue
+				}
+				fmt.Printf("%-10s %6d %7.1fms %7.1fms %7.1fms %7.1fms\n", phase, s.Count, s.P50Ms, s.P95Ms, s.P99Ms, s.MaxMs)
+			}
+			fmt.Println(strings.Repeat("─", 58))
+			fmt.Printf("Errors:     %d of %d (%.1f%%)", report.Errors, n, 100*report.ErrorRate)
+			if len(report.ErrorKinds) > 0 {
+				kinds := make([]string, 0, len(report.ErrorKinds))
+				for k, c := range report.ErrorKinds {
+					kinds = append(kinds, fmt.Sprintf("%s ×%d", k, c))
+				}
+				sort.Strings(kinds)
+				fmt.Printf(" — %s", strings.Join(kinds, ", "))
+			}
+			fmt.Println()
+			fmt.Printf("Throughput: %.1f req/s over %s\n", report.Throughput, elapsed.Round(time.Millisecond))
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().String("endpoint", "payments.list", "Endpoint to call ("+strings.Join(benchEndpointNames(), ", ")+")")
+	benchCmd.Flags().Int("n", 200, "Number of requests")
+	benchCmd.Flags().IntP("concurrency", "c", 10, "Requests in flight at a time")
+	benchCmd.Flags().Duration("timeout", 30*time.Second, "Timeout for each request")
+	benchCmd.Flags().StringP("zone", "z", "", "Zone to create payments in (default: current zone)")
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// A flowDeployment is one version of a flow going live. With a canary
+// percentage, that share of matching events runs the new version and the
+// rest keeps running the current one until the canary is promoted or
+// aborted.
+type flowDeployment struct {
+	ID              string     `json:"id,omitempty"`
+	FlowID          string     `json:"flowId"`
+	Version         int        `json:"version,omitempty"`
+	PreviousVersion int        `json:"previousVersion,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	CanaryPercent   int        `json:"canaryPercent,omitempty"`
+	CreatedAt       *time.Time `json:"createdAt,omitempty"`
+
+	// Filled in by the API for active canaries.
+	Stats *canaryStats `json:"stats,omitempty"`
+}
+
+// canaryStats compares the two versions while a canary runs.
+type canaryStats struct {
+	CanaryRuns     int `json:"canaryRuns"`
+	CanaryFailures int `json:"canaryFailures"`
+	StableRuns     int `json:"stableRuns"`
+	StableFailures int `json:"stableFailures"`
+}
+
+func failureRate(failures, runs int) string {
+	if runs == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(failures)/float64(runs))
+}
+
+// parsePercent parses "10%" or "10" as a canary share, which must leave
+// some traffic on each version.
+func parsePercent(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if err != nil || n < 1 || n > 99 {
+		return 0, fmt.Errorf("invalid canary percentage %q (use 1%%-99%%)", s)
+	}
+	return n, nil
+}
+
+func flowPath(zone, flowID, suffix string) string {
+	return zonePath(zone, "/flows/"+url.PathEscape(flowID)+suffix)
+}
+
+func printDeployment(d *flowDeployment) {
+	fmt.Printf("🚀 Flow %s", d.FlowID)
+	if d.Version > 0 {
+		fmt.Printf(" v%d", d.Version)
+	}
+	fmt.Printf(" — %s\n", d.Status)
+	if d.CanaryPercent > 0 && d.Status == "canary" {
+		fmt.Printf("   %d%% of matching events run v%d, %d%% stay on v%d\n", d.CanaryPercent, d.Version, 100-d.CanaryPercent, d.PreviousVersion)
+	}
+	if s := d.Stats; s != nil {
+		fmt.Printf("   %-8s %6s %9s %8s\n", "", "RUNS", "FAILURES", "RATE")
+		fmt.Printf("   %-8s %6d %9d %8s\n", "canary", s.CanaryRuns, s.CanaryFailures, failureRate(s.CanaryFailures, s.CanaryRuns))
+		fmt.Printf("   %-8s %6d %9d %8s\n", "stable", s.StableRuns, s.StableFailures, failureRate(s.StableFailures, s.StableRuns))
+	}
+}
+
+var flowsDeployCmd = &cobra.Command{
+	Use:   "deploy [flow.json]",
+	Short: "Deploy a flow, optionally as a canary",
+	Long: `Deploy a new version of a flow from its *.flow.json file. The file's "id"
+says which flow to update.
+
+With --canary, only that share of matching events runs the new version and
+the rest keeps running the current one. Watch it with 'sapliy flows canary
+status', then roll it out with 'promote' or roll it back with 'abort'.`,
+	Example: `  sapliy flows deploy checkout.flow.json
+  sapliy flows deploy checkout.flow.json --canary 10%
+  sapliy flows canary status flow_checkout
+  sapliy flows canary promote flow_checkout`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("Error reading flow: %v\n", err)
+			os.Exit(1)
+		}
+		var definition map[string]interface{}
+		if err := json.Unmarshal(raw, &definition); err != nil {
+			fmt.Printf("Error: %s is not valid JSON: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			// The edited copy is deployed; the file on disk is left as is.
+			edited, err := editJSON("flow", raw, func(def map[string]interface{}) error {
+				if stepString(def, "id") == "" {
+					return fmt.Errorf("the flow has no \"id\"; it is needed to know which flow to deploy")
+				}
+				return nil
+			})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if edited == nil {
+				fmt.Println("Cancelled.")
+				return
+			}
+			definition = edited
+		}
+		flowID := stepString(definition, "id")
+		if flowID == "" {
+			fmt.Printf("Error: %s has no \"id\"; it is needed to know which flow to deploy.\n", args[0])
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{"definition": definition}
+		if canary, _ := cmd.Flags().GetString("canary"); canary != "" {
+			percent, err := parsePercent(canary)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			req["canaryPercent"] = percent
+		}
+
+		zone := requireZone(cmd)
+		var d flowDeployment
+		if err := apiRequest(context.Background(), http.MethodPost, flowPath(zone, flowID, "/deployments"), req, &d); err != nil {
+			fmt.Printf("❌ Deploy failed: %v\n", err)
+			os.Exit(1)
+		}
+		if d.FlowID == "" {
+			d.FlowID = flowID
+		}
+
+		printOutput(d, func() {
+			printDeployment(&d)
+			if d.Status == "canary" {
+				fmt.Printf("\nPromote with 'sapliy flows canary promote %s' or roll back with 'sapliy flows canary abort %s'.\n", flowID, flowID)
+			}
+		})
+	},
+}
+
+var flowsCanaryCmd = &cobra.Command{
+	Use:   "canary",
+	Short: "Watch, promote or abort a canary deployment",
+}
+
+var flowsCanaryStatusCmd = &cobra.Command{
+	Use:   "status [flow_id]",
+	Short: "Show how a canary compares with the current version",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var d flowDeployment
+		if err := apiRequest(context.Background(), http.MethodGet, flowPath(requireZone(cmd), args[0], "/canary"), nil, &d); err != nil {
+			if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == http.StatusNotFound {
+				fmt.Printf("No canary is running for %s.\n", args[0])
+				os.Exit(1)
+			}
+			fmt.Printf("❌ Failed to fetch canary: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(d, func() { printDeployment(&d) })
+	},
+}
+
+// finishCanary builds the promote and abort commands, which end a canary by
+// moving all traffic to the new or the current version.
+func finishCanary(action, done string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var d flowDeployment
+		if err := apiRequest(context.Background(), http.MethodPost, flowPath(requireZone(cmd), args[0], "/canary/"+action), nil, &d); err != nil {
+			fmt.Printf("❌ Failed to %s canary: %v\n", action, err)
+			os.Exit(1)
+		}
+		if d.FlowID == "" {
+			d.FlowID = args[0]
+		}
+		printOutput(d, func() {
+			version := d.Version
+			if action == "abort" {
+				version = d.PreviousVersion
+			}
+			fmt.Printf("✅ %s: all matching events now run v%d of %s\n", done, version, d.FlowID)
+		})
+	}
+}
+
+var flowsCanaryPromoteCmd = &cobra.Command{
+	Use:   "promote [flow_id]",
+	Short: "Send all traffic to the canary version",
+	Args:  cobra.ExactArgs(1),
+	Run:   finishCanary("promote", "Promoted"),
+}
+
+var flowsCanaryAbortCmd = &cobra.Command{
+	Use:   "abort [flow_id]",
+	Short: "Roll back to the current version",
+	Args:  cobra.ExactArgs(1),
+	Run:   finishCanary("abort", "Aborted"),
+}
+
+func init() {
+	flowsCmd.AddCommand(flowsDeployCmd)
+	flowsCmd.AddCommand(flowsCanaryCmd)
+	flowsCanaryCmd.AddCommand(flowsCanaryStatusCmd)
+	flowsCanaryCmd.AddCommand(flowsCanaryPromoteCmd)
+	flowsCanaryCmd.AddCommand(flowsCanaryAbortCmd)
+
+	flowsDeployCmd.Flags().String("canary", "", "Run the new version for only this share of events (e.g. 10%)")
+	flowsDeployCmd.Flags().Bool("edit", false, "Edit the flow in $EDITOR before deploying (the file is not changed)")
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	fintech "github.com/sapliy/fintech-sdk-go"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultRequestTimeout is how long an API request may take, retries
+	// included, unless --timeout says otherwise.
+	defaultRequestTimeout = 30 * time.Second
+
+	// defaultRetries is how many times a failed request is repeated.
+	defaultRetries = 3
+
+	// maxRetryWait is the longest a retry waits, including for a
+	// Retry-After; a response asking for longer is returned as it is.
+	maxRetryWait = 20 * time.Second
+)
+
+// newClient returns an SDK client for the configured API key and host that
+// sends its requests through apiHTTPClient, so it shares the timeouts,
+// retries and failover of every other API call.
+func newClient() *fintech.Client {
+	return fintech.NewClient(viper.GetString("api_key"), fintech.WithBaseURL(apiBaseURL()), fintech.WithHTTPClient(apiHTTPClient()))
+}
+
+// requestTimeout returns the --timeout for API requests, 0 meaning none.
+func requestTimeout() time.Duration {
+	return max(viper.GetDuration("timeout"), 0)
+}
+
+// retryTransport repeats API requests that fail with a network error, 429
+// or a 5xx, waiting longer before each attempt (or as long as Retry-After
+// asks). Only requests that are safe to repeat are retried once they may
+// have reached the server; others only when the connection could not be
+// made at all.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := viper.GetInt("retries")
+	if !viper.IsSet("retries") {
+		retries = defaultRetries
+	}
+	if retries <= 0 || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		if attempt == retries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !hostUnreachable(err, false) || (!idempotent(req) && !hostUnreachable(err, true)) {
+				return nil, err
+			}
+			wait = retryBackoff(attempt)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			if !idempotent(req) || resp.StatusCode == http.StatusNotImplemented {
+				return resp, nil
+			}
+			var ok bool
+			if wait, ok = retryAfter(resp); !ok {
+				wait = retryBackoff(attempt)
+			}
+			if wait > maxRetryWait {
+				return resp, nil
+			}
+		default:
+			return resp, nil
+		}
+
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			// The retry could not finish in time; report this failure
+			// rather than a timeout.
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if viper.GetBool("verbose") {
+			reason := fmt.Sprint(err)
+			if err == nil {
+				reason = resp.Status
+			}
+			fmt.Fprintf(os.Stderr, "↻ %s %s: %s; retrying in %s (%d/%d)\n", req.Method, req.URL.Path, reason, wait.Round(10*time.Millisecond), attempt+1, retries)
+		}
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryBackoff returns how long to wait before retry attempt+1: 250ms
+// doubling with each attempt, with jitter so clients that failed together
+// do not retry together.
+func retryBackoff(attempt int) time.Duration {
+	d := min(250*time.Millisecond<<min(attempt, 6), 8*time.Second)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as a date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, or returns the context's error if it is done
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transferHTTPClient returns the client for file uploads and downloads,
+// which get at least five minutes whatever --timeout is.
+func transferHTTPClient() *http.Client {
+	client := apiHTTPClient()
+	if client.Timeout != 0 {
+		client.Timeout = max(client.Timeout, 5*time.Minute)
+	}
+	return client
+}
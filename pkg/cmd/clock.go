@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var clockSkewWarning sync.Once
+
+// strictClockSkew makes API calls and event streams fail, rather than warn,
+// when the local clock is off by more than the signature tolerance.
+// Commands whose result depends on signature timestamps turn it on.
+var strictClockSkew bool
+
+// clockSkewError is how strict mode fails.
+type clockSkewError struct {
+	skew time.Duration
+}
+
+func (e *clockSkewError) Error() string {
+	return fmt.Sprintf("local clock is %s (tolerance %s); fix your system time before verifying signatures", formatSkew(e.skew), signatureTolerance)
+}
+
+// skewTransport compares the server Date header of every API response with
+// the local clock. Skew beyond the webhook signature tolerance is the most
+// common reason signatures fail to verify, so it is surfaced as early as
+// possible. In strict mode the request fails instead of only warning.
+type skewTransport struct {
+	base   http.RoundTripper
+	strict bool
+}
+
+func (t *skewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if skew, ok := responseClockSkew(resp); ok && exceedsTolerance(skew) {
+		if t.strict {
+			resp.Body.Close()
+			return nil, &clockSkewError{skew: skew}
+		}
+		warnClockSkew(skew)
+	}
+	return resp, nil
+}
+
+// apiHTTPClient returns the HTTP client used for authenticated API calls.
+// All clients share one connection pool (see apiTransport), or the agent's
+// when one runs (see agentTransport), and fail over between the configured
+// API hosts (see failoverTransport). Requests are limited to --timeout and
+// retried when they fail in a way worth retrying (see retryTransport).
+func apiHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   requestTimeout(),
+		Transport: &skewTransport{strict: strictClockSkew, base: &retryTransport{base: &tlsHintTransport{base: &failoverTransport{base: &residencyTransport{base: &agentTransport{base: apiTransport()}}}}}},
+	}
+}
+
+// responseClockSkew returns how far the server clock is ahead of the local
+// clock (negative when behind), based on the response Date header.
+func responseClockSkew(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return 0, false
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return 0, false
+	}
+	return serverTime.Sub(time.Now()).Round(time.Second), true
+}
+
+// checkStreamClockSkew does for an event stream what skewTransport does for
+// API calls, with the response to the WebSocket handshake.
+func checkStreamClockSkew(resp *http.Response) error {
+	skew, ok := responseClockSkew(resp)
+	if !ok || !exceedsTolerance(skew) {
+		return nil
+	}
+	if strictClockSkew {
+		return &clockSkewError{skew: skew}
+	}
+	warnClockSkew(skew)
+	return nil
+}
+
+// requireSignatureClock turns on strict mode and checks the local clock
+// against the API's, exiting when signature timestamps would be judged
+// wrongly. Commands that work offline still run when the API cannot be
+// reached; the clock just goes unchecked.
+func requireSignatureClock() {
+	strictClockSkew = true
+	req, err := http.NewRequest(http.MethodHead, apiBaseURL(), nil)
+	if err != nil {
+		return
+	}
+	// No retries or failover: this is a quick check, not an API call.
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &skewTransport{strict: true, base: &agentTransport{base: apiTransport()}}}
+	resp, err := client.Do(req)
+	var skewErr *clockSkewError
+	switch {
+	case errors.As(err, &skewErr):
+		fmt.Printf("❌ %v\n", skewErr)
+		os.Exit(1)
+	case err != nil:
+		if viper.GetBool("verbose") {
+			fmt.Fprintf(os.Stderr, "Clock not checked against the API: %v\n", err)
+		}
+	default:
+		resp.Body.Close()
+	}
+}
+
+func exceedsTolerance(skew time.Duration) bool {
+	return skew > signatureTolerance || skew < -signatureTolerance
+}
+
+func warnClockSkew(skew time.Duration) {
+	clockSkewWarning.Do(func() {
+		fmt.Fprintf(os.Stderr, "⚠️  Local clock is %s (webhook tolerance is %s). Signature verification may fail; run 'sapliy doctor'.\n", formatSkew(skew), signatureTolerance)
+	})
+}
+
+func formatSkew(skew time.Duration) string {
+	if skew < 0 {
+		return fmt.Sprintf("%s ahead of the server", -skew)
+	}
+	return fmt.Sprintf("%s behind the server", skew)
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	fintech "github.com/sapliy/fintech-sdk-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// remapKinds are the reference kinds --remap understands. Every kind
+// replaces string values that equal the old value, except url, which
+// replaces a prefix so that paths under a host move along with it.
+var remapKinds = []string{"endpoint", "secret", "connector", "url", "value"}
+
+// A zoneRemap rewrites an environment-specific reference while cloning,
+// e.g. endpoint:we_ep_prod=we_ep_stage.
+type zoneRemap struct {
+	Kind string
+	From string
+	To   string
+	hits int
+}
+
+func parseZoneRemap(spec string) (*zoneRemap, error) {
+	kind, pair, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid remap %q (expected kind:old=new)", spec)
+	}
+	from, to, ok := strings.Cut(pair, "=")
+	if !ok || from == "" {
+		return nil, fmt.Errorf("invalid remap %q (expected kind:old=new)", spec)
+	}
+	for _, k := range remapKinds {
+		if k == kind {
+			return &zoneRemap{Kind: kind, From: from, To: to}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown remap kind %q (use %s)", kind, strings.Join(remapKinds, ", "))
+}
+
+func (r *zoneRemap) apply(s string) (string, bool) {
+	if r.Kind == "url" {
+		if strings.HasPrefix(s, r.From) {
+			return r.To + strings.TrimPrefix(s, r.From), true
+		}
+		return s, false
+	}
+	if s == r.From {
+		return r.To, true
+	}
+	return s, false
+}
+
+// rewriteRefs walks a decoded JSON document and applies the remaps to every
+// string value. Object keys are left alone.
+func rewriteRefs(v interface{}, remaps []*zoneRemap) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = rewriteRefs(child, remaps)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = rewriteRefs(child, remaps)
+		}
+		return val
+	case string:
+		for _, r := range remaps {
+			if out, ok := r.apply(val); ok {
+				r.hits++
+				return out
+			}
+		}
+		return val
+	}
+	return v
+}
+
+// serverFields are set by the API and must not be sent when recreating a
+// resource.
+var serverFields = []string{"id", "zoneId", "createdAt", "updatedAt"}
+
+func stripServerFields(resource map[string]interface{}) {
+	for _, f := range serverFields {
+		delete(resource, f)
+	}
+}
+
+func zonePath(zoneID, suffix string) string {
+	return "/v1/zones/" + url.PathEscape(zoneID) + suffix
+}
+
+func resourceName(resource map[string]interface{}) string {
+	for _, key := range []string{"name", "event", "type", "id"} {
+		if s, ok := resource[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return "(unnamed)"
+}
+
+// createZoneResources posts flows and then triggers to a zone. Flows go first
+// so that references to their old IDs (sourceFlowIDs, by position) can be
+// rewired to the IDs the API assigns. It returns how many resources were
+// created before any error.
+func createZoneResources(ctx context.Context, zoneID string, flows []map[string]interface{}, sourceFlowIDs []string, triggers []map[string]interface{}, rewire []*zoneRemap) (int, error) {
+	created := 0
+	for i, f := range flows {
+		rewriteRefs(f, rewire)
+		var out struct {
+			ID string `json:"id"`
+		}
+		if err := apiRequest(ctx, http.MethodPost, zonePath(zoneID, "/flows"), f, &out); err != nil {
+			return created, fmt.Errorf("failed to create flow %s: %w", resourceName(f), err)
+		}
+		if old := sourceFlowIDs[i]; old != "" && out.ID != "" {
+			rewire = append(rewire, &zoneRemap{Kind: "value", From: old, To: out.ID})
+		}
+		created++
+	}
+	for _, t := range triggers {
+		rewriteRefs(t, rewire)
+		if err := apiRequest(ctx, http.MethodPost, zonePath(zoneID, "/triggers"), t, nil); err != nil {
+			return created, fmt.Errorf("failed to create trigger %s: %w", resourceName(t), err)
+		}
+		created++
+	}
+	return created, nil
+}
+
+var cloneZoneCmd = &cobra.Command{
+	Use:   "clone [source_zone_id]",
+	Short: "Duplicate a zone's flows and triggers into a new zone",
+	Long: `Create a new zone with copies of the source zone's flows and triggers,
+for example to give every developer their own sandbox.
+
+References that only make sense in the source environment are rewritten with
+--remap kind:old=new. endpoint, secret, connector and value remaps replace
+exact matches; url remaps replace a prefix. References to the source zone and
+between the copied flows are rewired automatically.`,
+	Example: `  sapliy zones clone zone_src --name staging-copy --remap endpoint:we_ep_prod=we_ep_stage
+  sapliy zones clone zone_src --name alice-sandbox --remap url:https://api.shop.com=http://localhost:4242 --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		orgID := viper.GetString("org_id")
+		if apiKey == "" || orgID == "" {
+			fmt.Println("Error: Not authenticated or org_id not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		srcID := args[0]
+		name, _ := cmd.Flags().GetString("name")
+		mode, _ := cmd.Flags().GetString("mode")
+		specs, _ := cmd.Flags().GetStringArray("remap")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var remaps []*zoneRemap
+		for _, spec := range specs {
+			r, err := parseZoneRemap(spec)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			remaps = append(remaps, r)
+		}
+
+		ctx := context.Background()
+		var src zoneView
+		if err := apiRequest(ctx, http.MethodGet, zonePath(srcID, ""), nil, &src); err != nil {
+			fmt.Printf("❌ Failed to fetch zone %s: %v\n", srcID, err)
+			os.Exit(1)
+		}
+		if mode == "" {
+			mode = src.Mode
+		}
+
+		var flows, triggers []map[string]interface{}
+		if err := apiRequest(ctx, http.MethodGet, zonePath(srcID, "/flows"), nil, &flows); err != nil {
+			fmt.Printf("❌ Failed to fetch flows: %v\n", err)
+			os.Exit(1)
+		}
+		if err := apiRequest(ctx, http.MethodGet, zonePath(srcID, "/triggers"), nil, &triggers); err != nil {
+			fmt.Printf("❌ Failed to fetch triggers: %v\n", err)
+			os.Exit(1)
+		}
+
+		sourceFlowIDs := make([]string, len(flows))
+		for i, f := range flows {
+			sourceFlowIDs[i], _ = f["id"].(string)
+			stripServerFields(f)
+			rewriteRefs(f, remaps)
+		}
+		for _, t := range triggers {
+			stripServerFields(t)
+			rewriteRefs(t, remaps)
+		}
+
+		fmt.Printf("🧬 Cloning %s (%s) → %q [%s]\n", srcID, src.Name, name, mode)
+		fmt.Printf("   %d flow(s), %d trigger(s)\n", len(flows), len(triggers))
+		for _, r := range remaps {
+			if r.hits == 0 {
+				fmt.Printf("⚠️  Remap %s:%s matched nothing\n", r.Kind, r.From)
+			} else {
+				fmt.Printf("   %s: %s → %s (%d reference(s))\n", r.Kind, r.From, r.To, r.hits)
+			}
+		}
+
+		if dryRun {
+			fmt.Println("\nDry run: no zone was created.")
+			return
+		}
+
+		client := newClient()
+		z, err := client.Zones.Create(ctx, &fintech.CreateZoneRequest{
+			OrgID: orgID,
+			Name:  name,
+			Mode:  mode,
+		})
+		if err != nil {
+			fmt.Printf("❌ Error creating zone: %v\n", err)
+			os.Exit(1)
+		}
+
+		rewire := []*zoneRemap{{Kind: "value", From: srcID, To: z.ID}}
+		if created, err := createZoneResources(ctx, z.ID, flows, sourceFlowIDs, triggers, rewire); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			fmt.Printf("   Zone %s was left with %d of %d resource(s); delete it or finish by hand.\n", z.ID, created, len(flows)+len(triggers))
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Zone %s created with %d flow(s) and %d trigger(s)\n", z.ID, len(flows), len(triggers))
+		fmt.Printf("   Switch to it with 'sapliy zones use %s'\n", z.ID)
+	},
+}
+
+func init() {
+	zonesCmd.AddCommand(cloneZoneCmd)
+	cloneZoneCmd.Flags().StringP("name", "n", "", "Name of the new zone")
+	cloneZoneCmd.Flags().StringP("mode", "m", "", "Mode of the new zone (default the source zone's mode)")
+	cloneZoneCmd.Flags().StringArray("remap", nil, "Rewrite a reference, as kind:old=new (repeatable)")
+	cloneZoneCmd.Flags().Bool("dry-run", false, "Show what would be cloned without creating anything")
+	cloneZoneCmd.MarkFlagRequired("name")
+}
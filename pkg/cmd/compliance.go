@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// holdReasons are the reasons compliance hold create --reason accepts.
+var holdReasons = []string{"litigation", "regulatory", "investigation", "audit", "other"}
+
+// legalHold keeps a customer's records, or a single payment, from being
+// redacted or deleted until it is released.
+type legalHold struct {
+	ID         string     `json:"id"`
+	Customer   string     `json:"customer,omitempty"`
+	Payment    string     `json:"payment,omitempty"`
+	Reason     string     `json:"reason"`
+	Note       string     `json:"note,omitempty"`
+	Status     string     `json:"status"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	ReleasedBy string     `json:"releasedBy,omitempty"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+func legalHoldPath(holdID, suffix string) string {
+	return "/v1/compliance/holds/" + url.PathEscape(holdID) + suffix
+}
+
+// subject is what the hold covers, for tables and messages.
+func (h *legalHold) subject() string {
+	if h.Payment != "" {
+		return h.Payment
+	}
+	return h.Customer
+}
+
+// explainHold rewrites the API's 423 Locked answer to a deletion or
+// redaction of a record under legal hold into a pointer to the hold.
+func explainHold(err error, customerID string) error {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusLocked {
+		return err
+	}
+	return fmt.Errorf("%s is under legal hold and cannot be deleted or redacted until the hold is released "+
+		"(see 'sapliy compliance hold list --customer %s')", customerID, customerID)
+}
+
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Compliance controls such as legal holds",
+}
+
+var complianceHoldCmd = &cobra.Command{
+	Use:   "hold",
+	Short: "Manage legal holds",
+	Long: `A legal hold stops a customer's records, or a single payment, from being
+redacted or deleted, including by retention policies, until it is released.
+Holds and releases are recorded in the account's audit log.`,
+}
+
+var complianceHoldCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Place records under legal hold",
+	Example: `  sapliy compliance hold create --customer cus_123 --reason litigation --note "Case 24-cv-0193"
+  sapliy compliance hold create --payment pay_456 --reason investigation`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		customerID, _ := cmd.Flags().GetString("customer")
+		paymentID, _ := cmd.Flags().GetString("payment")
+		reason, _ := cmd.Flags().GetString("reason")
+		note, _ := cmd.Flags().GetString("note")
+		if (customerID == "") == (paymentID == "") {
+			fmt.Println("Error: pass exactly one of --customer or --payment.")
+			os.Exit(1)
+		}
+		if !slices.Contains(holdReasons, reason) {
+			fmt.Printf("Error: invalid --reason %q (use %s)\n", reason, strings.Join(holdReasons, ", "))
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{"reason": reason}
+		if customerID != "" {
+			req["customer"] = customerID
+		} else {
+			req["payment"] = paymentID
+		}
+		if note != "" {
+			req["note"] = note
+		}
+		var h legalHold
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/compliance/holds", req, &h); err != nil {
+			fmt.Printf("❌ Failed to create legal hold: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(h, func() {
+			fmt.Printf("🔒 Legal hold %s placed on %s (%s)\n", h.ID, h.subject(), h.Reason)
+			fmt.Println("   Its records cannot be redacted or deleted until the hold is released.")
+		})
+	},
+}
+
+var complianceHoldListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List legal holds",
+	Example: `  sapliy compliance hold list
+  sapliy compliance hold list --customer cus_123 --status released`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		customerID, _ := cmd.Flags().GetString("customer")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "" && status != "active" && status != "released" {
+			fmt.Printf("Error: invalid --status %q (use active or released)\n", status)
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		if status != "" {
+			q.Set("status", status)
+		}
+		if customerID != "" {
+			q.Set("customer", customerID)
+		}
+		holds, cursor, err := listPages[legalHold](context.Background(), "/v1/compliance/holds", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing legal holds: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(holds, func() {
+			if len(holds) == 0 {
+				fmt.Println("No legal holds found.")
+				return
+			}
+
+			fmt.Printf("%-20s %-24s %-14s %-9s %-12s %s\n", "ID", "ON", "REASON", "STATUS", "CREATED", "NOTE")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, h := range holds {
+				created := ""
+				if h.CreatedAt != nil {
+					created = h.CreatedAt.Local().Format("2006-01-02")
+				}
+				fmt.Printf("%-20s %-24s %-14s %-9s %-12s %s\n", h.ID, h.subject(), h.Reason, h.Status, created, truncate(h.Note, 30))
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore legal holds available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var complianceHoldReleaseCmd = &cobra.Command{
+	Use:   "release [hold_id]",
+	Short: "Release a legal hold",
+	Long: `Release a legal hold. Retention policies and redaction requests apply to the
+records again once no other hold covers them.`,
+	Example: `  sapliy compliance hold release lh_123 --note "Case settled"`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Release legal hold %s? Its records can be redacted or deleted again. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		req := map[string]interface{}{}
+		if note, _ := cmd.Flags().GetString("note"); note != "" {
+			req["note"] = note
+		}
+		var h legalHold
+		if err := apiRequest(context.Background(), http.MethodPost, legalHoldPath(args[0], "/release"), req, &h); err != nil {
+			fmt.Printf("❌ Failed to release legal hold: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(h, func() {
+			fmt.Printf("🔓 Released legal hold %s on %s\n", h.ID, h.subject())
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(complianceCmd)
+	complianceCmd.AddCommand(complianceHoldCmd)
+	complianceHoldCmd.AddCommand(complianceHoldCreateCmd)
+	complianceHoldCmd.AddCommand(complianceHoldListCmd)
+	complianceHoldCmd.AddCommand(complianceHoldReleaseCmd)
+
+	complianceHoldCreateCmd.Flags().String("customer", "", "Hold every record of this customer (cus_...)")
+	complianceHoldCreateCmd.Flags().String("payment", "", "Hold a single payment (pay_...)")
+	complianceHoldCreateCmd.Flags().String("reason", "", "Why the records are held ("+strings.Join(holdReasons, ", ")+")")
+	complianceHoldCreateCmd.Flags().String("note", "", "Free-text reference, e.g. a case number")
+	complianceHoldCreateCmd.MarkFlagRequired("reason")
+
+	complianceHoldListCmd.Flags().IntP("limit", "l", 20, "Number of holds per page")
+	complianceHoldListCmd.Flags().StringP("status", "s", "", "Filter by status (active, released)")
+	complianceHoldListCmd.Flags().String("customer", "", "Filter by customer ID")
+	complianceHoldListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	complianceHoldListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	complianceHoldReleaseCmd.Flags().String("note", "", "Why the hold is released")
+	complianceHoldReleaseCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
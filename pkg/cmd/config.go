@@ -0,0 +1,778 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// currentConfigVersion is the schema version written by this CLI. Config
+// files without a config_version key are version 1.
+const currentConfigVersion = 2
+
+type configKind int
+
+const (
+	kindString configKind = iota
+	kindBool
+	kindInt
+	kindStringList
+	// kindSection is a nested table whose keys are part of the schema.
+	kindSection
+	// kindMap is a nested table with free-form keys.
+	kindMap
+)
+
+func (k configKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "integer"
+	case kindStringList:
+		return "list of strings"
+	default:
+		return "table"
+	}
+}
+
+type configField struct {
+	Key         string
+	Kind        configKind
+	Description string
+}
+
+// configSchema lists every key the CLI reads from the config file. Keys
+// inside a section are written with a dot (listen.filter).
+var configSchema = []configField{
+	{"config_version", kindInt, "Schema version of this file"},
+	{"api_key", kindString, "API key or OAuth access token used for all requests"},
+	{"refresh_token", kindString, "OAuth refresh token from 'auth login'"},
+	{"token_expiry", kindString, "When the OAuth access token expires (RFC 3339)"},
+	{"credential_store", kindString, "Where secrets are kept: auto, keychain or file"},
+	{"active_profile", kindString, "Profile used when --profile is not given"},
+	{"profiles", kindMap, "Named profiles with their own API URL, zone and account"},
+	{"api_url", kindString, "API endpoint (default http://localhost:8080)"},
+	{"api_urls", kindStringList, "API hosts to fail over between, e.g. one per region"},
+	{"region", kindString, "Region whose API host is tried first (see api_urls)"},
+	{"residency", kindString, "Refuse to connect to API hosts outside this region, e.g. eu"},
+	{"current_zone", kindString, "Zone used when --zone is not given"},
+	{"org_id", kindString, "Organization for zone management"},
+	{"account_id", kindString, "Active account within the organization"},
+	{"verbose", kindBool, "Enable verbose output"},
+	{"max_payload_bytes", kindInt, "Cut event and step payloads larger than this in list and inspect commands (0 for no limit)"},
+	{"accessible", kindBool, "Plain text output for screen readers and logs (see --accessible)"},
+	{"plugins", kindStringList, "WebAssembly plugins to load"},
+	{"listen", kindSection, "Settings for streaming commands"},
+	{"listen.filter", kindString, "Event type filter for debug listen"},
+	{"listen.sinks", kindStringList, "Sinks that receive streamed events"},
+	{"proxy", kindString, "Proxy for API and event stream connections (http, https or socks5 URL)"},
+	{"ca_cert", kindString, "PEM file of extra root CAs to trust, e.g. a TLS-inspecting proxy's"},
+	{"timeout", kindString, "Time limit for each API request, retries included, e.g. 30s"},
+	{"retries", kindInt, "How many times a failed API request is retried (default 3, 0 to disable)"},
+	{"transport", kindSection, "HTTP connection pool settings for API requests"},
+	{"transport.profile", kindString, "Pool preset: default or bulk"},
+	{"transport.max_idle_conns", kindInt, "Idle connections kept open in total"},
+	{"transport.max_idle_conns_per_host", kindInt, "Idle connections kept open to the API"},
+	{"transport.max_conns_per_host", kindInt, "Open connections allowed to the API (0 for no limit)"},
+	{"transport.idle_timeout", kindString, "How long an idle connection is kept, e.g. 90s"},
+	{"transport.keep_alive", kindString, "TCP keep-alive interval, e.g. 30s"},
+	{"studio", kindSection, "Settings for the Studio assets"},
+	{"studio.url", kindString, "Where 'studio install' downloads from; %s stands for the version"},
+	{"defaults", kindMap, "Default flag values, keyed by command path and flag"},
+}
+
+func lookupConfigField(key string) (configField, bool) {
+	for _, f := range configSchema {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return configField{}, false
+}
+
+// configProblem is a single finding from validateConfig.
+type configProblem struct {
+	Key     string
+	Message string
+}
+
+func (p configProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// validateConfig checks raw config file settings against configSchema and
+// returns unknown keys (with a suggestion for likely typos) and type errors.
+func validateConfig(settings map[string]interface{}) []configProblem {
+	var problems []configProblem
+	validateSection(settings, "", &problems)
+	problems = append(problems, validateProfiles(settings)...)
+
+	if v, ok := settings["config_version"]; ok {
+		if n, err := cast.ToIntE(v); err == nil && n > currentConfigVersion {
+			problems = append(problems, configProblem{"config_version", fmt.Sprintf(
+				"version %d is newer than this CLI supports (%d); upgrade sapliy", n, currentConfigVersion)})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems
+}
+
+func validateSection(settings map[string]interface{}, prefix string, problems *[]configProblem) {
+	for key, value := range settings {
+		full := prefix + key
+		field, ok := lookupConfigField(full)
+		if !ok {
+			msg := "unknown key"
+			if s := suggestConfigKey(full); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			*problems = append(*problems, configProblem{full, msg})
+			continue
+		}
+
+		if !configValueHasKind(value, field.Kind) {
+			*problems = append(*problems, configProblem{full, fmt.Sprintf("expected %s, got %T", field.Kind, value)})
+			continue
+		}
+		if field.Kind == kindSection {
+			validateSection(value.(map[string]interface{}), full+".", problems)
+		}
+	}
+}
+
+func configValueHasKind(value interface{}, kind configKind) bool {
+	switch kind {
+	case kindString:
+		_, ok := value.(string)
+		return ok
+	case kindBool:
+		_, ok := value.(bool)
+		return ok
+	case kindInt:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			_, err := cast.ToIntE(value)
+			return err == nil
+		}
+		return false
+	case kindStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			_, ok = value.([]string)
+			return ok
+		}
+		for _, e := range list {
+			if _, ok := e.(string); !ok {
+				return false
+			}
+		}
+		return true
+	case kindSection, kindMap:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+// suggestConfigKey returns the schema key closest to key, if it is close
+// enough to be a typo.
+func suggestConfigKey(key string) string {
+	normalized := strings.NewReplacer("-", "_", " ", "_").Replace(key)
+	best, bestDist := "", 3
+	for _, f := range configSchema {
+		if f.Key == normalized {
+			return f.Key
+		}
+		if d := editDistance(normalized, f.Key); d < bestDist {
+			best, bestDist = f.Key, d
+		}
+		if strings.ReplaceAll(f.Key, "_", "") == strings.ReplaceAll(normalized, "_", "") {
+			return f.Key
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// configMigration upgrades settings from one schema version to the next and
+// returns a description of each change it made.
+type configMigration struct {
+	From  int
+	Apply func(settings map[string]interface{}) []string
+}
+
+var configMigrations = []configMigration{
+	{From: 1, Apply: migrateConfigV1},
+}
+
+// migrateConfigV1 renames the keys older CLI versions and docs used.
+func migrateConfigV1(settings map[string]interface{}) []string {
+	renames := []struct{ from, to string }{
+		{"apikey", "api_key"},
+		{"api-key", "api_key"},
+		{"apiurl", "api_url"},
+		{"api-url", "api_url"},
+		{"zone", "current_zone"},
+		{"zone_id", "current_zone"},
+		{"org", "org_id"},
+	}
+
+	var changes []string
+	for _, r := range renames {
+		value, ok := settings[r.from]
+		if !ok {
+			continue
+		}
+		delete(settings, r.from)
+		if _, exists := settings[r.to]; exists {
+			changes = append(changes, fmt.Sprintf("removed %s (%s is already set)", r.from, r.to))
+			continue
+		}
+		settings[r.to] = value
+		changes = append(changes, fmt.Sprintf("renamed %s → %s", r.from, r.to))
+	}
+	return changes
+}
+
+func configVersion(settings map[string]interface{}) int {
+	v, ok := settings["config_version"]
+	if !ok {
+		return 1
+	}
+	return cast.ToInt(v)
+}
+
+// migrateConfig runs every migration newer than the version of settings,
+// updating it in place, and returns the changes made.
+func migrateConfig(settings map[string]interface{}) []string {
+	var changes []string
+	from := configVersion(settings)
+	version := from
+	for _, m := range configMigrations {
+		if m.From < version {
+			continue
+		}
+		for _, c := range m.Apply(settings) {
+			changes = append(changes, fmt.Sprintf("v%d→v%d: %s", m.From, m.From+1, c))
+		}
+		version = m.From + 1
+	}
+	if version != from {
+		settings["config_version"] = version
+	}
+	return changes
+}
+
+// readConfigFile loads the config file on its own, without environment
+// variables or flags, so that it can be validated and rewritten as is.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+func writeConfigFile(path string, settings map[string]interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	return v.WriteConfigAs(path)
+}
+
+// projectConfigName is the file name of a project config, as written by
+// 'sapliy init'.
+const projectConfigName = ".sapliy.yaml"
+
+// projectConfigFile is the project config merged over the user config, if
+// the CLI runs inside a project, and projectSettings what it sets. They are
+// kept apart from the user config: saveConfig only writes the keys a command
+// changed.
+var (
+	projectConfigFile string
+	projectSettings   map[string]interface{}
+)
+
+// findProjectConfig returns the nearest .sapliy.yaml in the working
+// directory or a parent of it. The one in the home directory is the user
+// config, so the search stops there.
+func findProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for dir != home {
+		path := filepath.Join(dir, projectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// loadProjectConfig merges the project config, if any, over the user config.
+// Environment variables and flags still win over both.
+func loadProjectConfig() {
+	path := findProjectConfig()
+	if path == "" || path == viper.ConfigFileUsed() {
+		return
+	}
+	settings, err := readConfigFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  project config %s: %v\n", path, err)
+		return
+	}
+	if err := viper.MergeConfigMap(settings); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  project config %s: %v\n", path, err)
+		return
+	}
+	projectConfigFile = path
+	projectSettings = settings
+	if viper.GetBool("verbose") {
+		fmt.Println("Using project config:", path)
+	}
+}
+
+// warnProjectOverrides points out saved keys the project config also sets,
+// since its value still wins inside the project.
+func warnProjectOverrides(keys []string) {
+	for _, key := range keys {
+		if _, ok := projectSettings[key]; ok {
+			fmt.Fprintf(os.Stderr, "⚠️  %s is saved to your config, but %s sets it too and wins in this project\n", key, projectConfigFile)
+		}
+	}
+}
+
+// checkLoadedConfig validates the config files viper read and applies
+// pending migrations in memory, reporting problems on stderr. Commands
+// annotated with skipConfigCheck do their own reporting.
+func checkLoadedConfig(cmd *cobra.Command, args []string) {
+	if cmd.Annotations[skipConfigCheck] != "" {
+		return
+	}
+	if projectConfigFile != "" {
+		checkProjectConfig(projectConfigFile)
+	}
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return
+	}
+	settings, err := readConfigFile(path)
+	if err != nil {
+		return
+	}
+
+	if configVersion(settings) < currentConfigVersion {
+		if changes := migrateConfig(settings); len(changes) > 0 {
+			// Renamed keys act as defaults so env vars and flags still win.
+			for key, value := range settings {
+				if !viper.InConfig(key) {
+					viper.SetDefault(key, value)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  %s uses an old config format; run 'sapliy config migrate' to update it.\n", path)
+		}
+	}
+
+	for _, p := range validateConfig(settings) {
+		fmt.Fprintf(os.Stderr, "⚠️  config %s: %s\n", path, p)
+	}
+}
+
+// checkProjectConfig reports problems in a project config. Projects are
+// usually committed, so secrets in one are reported too.
+func checkProjectConfig(path string) {
+	settings, err := readConfigFile(path)
+	if err != nil {
+		return
+	}
+	for _, p := range validateConfig(settings) {
+		fmt.Fprintf(os.Stderr, "⚠️  config %s: %s\n", path, p)
+	}
+	for _, key := range secretConfigKeys {
+		if _, ok := settings[key]; ok {
+			fmt.Fprintf(os.Stderr, "⚠️  config %s: %s is a secret; keep it in ~/.sapliy.yaml or SAPLIY_%s, not in the project\n", path, key, strings.ToUpper(key))
+		}
+	}
+}
+
+const skipConfigCheck = "skipConfigCheck"
+
+// flagDefaults returns the "defaults" table of the config flattened to
+// dotted keys, so that nested and dotted spellings are equivalent:
+//
+//	defaults:
+//	  webhooks.list.limit: 100
+//	  output: json
+func flagDefaults(settings map[string]interface{}) map[string]string {
+	out := make(map[string]string)
+	if table, ok := settings["defaults"].(map[string]interface{}); ok {
+		flattenDefaults(table, "", out)
+	}
+	return out
+}
+
+func flattenDefaults(table map[string]interface{}, prefix string, out map[string]string) {
+	for key, value := range table {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenDefaults(v, prefix+key+".", out)
+		case []interface{}:
+			out[prefix+key] = strings.Join(cast.ToStringSlice(v), ",")
+		default:
+			out[prefix+key] = cast.ToString(v)
+		}
+	}
+}
+
+// commandPath is the dotted path of cmd below the root (webhooks.list).
+func commandPath(cmd *cobra.Command) string {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	return strings.Join(strings.Fields(path), ".")
+}
+
+// applyFlagDefaults fills flags the user did not pass from the defaults
+// table. A command-specific default (webhooks.list.limit) wins over a global
+// one (limit).
+func applyFlagDefaults(cmd *cobra.Command) {
+	defaults := flagDefaults(viper.AllSettings())
+	if len(defaults) == 0 {
+		return
+	}
+
+	prefix := commandPath(cmd)
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		value, ok := defaults[prefix+"."+f.Name]
+		if !ok {
+			value, ok = defaults[f.Name]
+		}
+		if !ok {
+			return
+		}
+		if err := cmd.Flags().Set(f.Name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  config default for --%s: %v\n", f.Name, err)
+		}
+	})
+}
+
+// resolveDefaultPath finds the commands and flag a defaults key refers to:
+// one command for a command path, or for a bare flag name every command
+// that has the flag, as applyFlagDefaults applies it to all of them.
+func resolveDefaultPath(path string) ([]*cobra.Command, string, error) {
+	parts := strings.Split(path, ".")
+	flagName := parts[len(parts)-1]
+
+	if len(parts) == 1 {
+		var cmds []*cobra.Command
+		var visit func(c *cobra.Command)
+		visit = func(c *cobra.Command) {
+			if c.LocalFlags().Lookup(flagName) != nil {
+				cmds = append(cmds, c)
+			}
+			for _, child := range c.Commands() {
+				visit(child)
+			}
+		}
+		visit(rootCmd)
+		if len(cmds) == 0 {
+			return nil, "", fmt.Errorf("no command has a --%s flag", flagName)
+		}
+		return cmds, flagName, nil
+	}
+
+	cmd, rest, err := rootCmd.Find(parts[:len(parts)-1])
+	if err != nil || len(rest) > 0 {
+		return nil, "", fmt.Errorf("no command %q", strings.Join(parts[:len(parts)-1], " "))
+	}
+	if cmd.Flags().Lookup(flagName) == nil && cmd.InheritedFlags().Lookup(flagName) == nil {
+		return nil, "", fmt.Errorf("%s has no --%s flag", cmd.CommandPath(), flagName)
+	}
+	return []*cobra.Command{cmd}, flagName, nil
+}
+
+// configFilePath is the file config commands write to, ~/.sapliy.yaml when
+// none exists yet.
+func configFilePath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sapliy.yaml"), nil
+}
+
+// updateDefaults applies change to the defaults table of the config file.
+func updateDefaults(change func(defaults map[string]string)) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	settings, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		settings = map[string]interface{}{"config_version": currentConfigVersion}
+	} else if err != nil {
+		return err
+	}
+
+	defaults := flagDefaults(settings)
+	change(defaults)
+
+	// Written as nested tables; viper cannot write keys containing dots.
+	table := make(map[string]interface{})
+	for k, v := range defaults {
+		parts := strings.Split(k, ".")
+		node := table
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := node[p].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[p] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = v
+	}
+	if len(table) == 0 {
+		delete(settings, "defaults")
+	} else {
+		settings["defaults"] = table
+	}
+	return writeConfigFile(path, settings)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate and migrate the config file and manage flag defaults",
+}
+
+var configDefaultsCmd = &cobra.Command{
+	Use:   "defaults",
+	Short: "Manage default flag values",
+	Long: `Set organization-wide default flag values in the config file instead of
+wrapping the CLI in shell aliases. Keys are the command path followed by the
+flag name (webhooks.list.limit), or just the flag name to apply it to every
+command that has that flag. Flags passed on the command line always win.`,
+	Example: `  sapliy config defaults set webhooks.listen.dedup-window 1h
+  sapliy config defaults set zone zone_sandbox
+  sapliy config defaults list`,
+}
+
+var configDefaultsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show configured flag defaults",
+	Run: func(cmd *cobra.Command, args []string) {
+		defaults := flagDefaults(viper.AllSettings())
+		printOutput(defaults, func() {
+			if len(defaults) == 0 {
+				fmt.Println("No flag defaults configured.")
+				return
+			}
+
+			keys := make([]string, 0, len(defaults))
+			for k := range defaults {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			fmt.Printf("%-40s %s\n", "KEY", "VALUE")
+			fmt.Println(strings.Repeat("─", 60))
+			for _, k := range keys {
+				fmt.Printf("%-40s %s\n", k, defaults[k])
+			}
+		})
+	},
+}
+
+var configDefaultsSetCmd = &cobra.Command{
+	Use:   "set [path] [value]",
+	Short: "Set the default value of a flag",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, value := strings.ToLower(args[0]), args[1]
+
+		targets, flagName, err := resolveDefaultPath(path)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		for _, target := range targets {
+			if err := target.Flags().Set(flagName, value); err != nil {
+				fmt.Printf("❌ Invalid value for --%s of %s: %v\n", flagName, target.CommandPath(), err)
+				os.Exit(1)
+			}
+		}
+
+		if err := updateDefaults(func(d map[string]string) { d[path] = value }); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s = %s\n", path, value)
+	},
+}
+
+var configDefaultsUnsetCmd = &cobra.Command{
+	Use:   "unset [path]",
+	Short: "Remove a flag default",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := strings.ToLower(args[0])
+		if err := updateDefaults(func(d map[string]string) { delete(d, path) }); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s unset\n", path)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:         "validate",
+	Annotations: map[string]string{skipConfigCheck: "true"},
+	Short:       "Check the config file for unknown keys and wrong types",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := viper.ConfigFileUsed()
+		if path == "" {
+			fmt.Println("No config file found.")
+			return
+		}
+
+		settings, err := readConfigFile(path)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", path, err)
+			os.Exit(1)
+		}
+
+		if configVersion(settings) < currentConfigVersion {
+			migrateConfig(settings)
+		}
+		problems := validateConfig(settings)
+		if len(problems) == 0 {
+			fmt.Printf("✅ %s is valid (version %d)\n", path, currentConfigVersion)
+			return
+		}
+
+		fmt.Printf("❌ %s has %d problem(s):\n", path, len(problems))
+		for _, p := range problems {
+			fmt.Printf("  • %s\n", p)
+		}
+		os.Exit(1)
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:         "migrate",
+	Annotations: map[string]string{skipConfigCheck: "true"},
+	Short:       "Upgrade the config file to the current schema version",
+	Long: `Rewrite the config file in the current schema version, renaming keys
+used by older versions of the CLI. The original is kept next to it with a
+.bak suffix. Use --dry-run to see the changes without writing anything.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		path := viper.ConfigFileUsed()
+		if path == "" {
+			fmt.Println("No config file found.")
+			return
+		}
+
+		settings, err := readConfigFile(path)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", path, err)
+			os.Exit(1)
+		}
+
+		from := configVersion(settings)
+		if from >= currentConfigVersion {
+			fmt.Printf("✅ %s is already at version %d\n", path, from)
+			return
+		}
+
+		changes := migrateConfig(settings)
+		fmt.Printf("Migrating %s from version %d to %d\n", path, from, currentConfigVersion)
+		fmt.Println(strings.Repeat("─", 60))
+		for _, c := range changes {
+			fmt.Printf("  • %s\n", c)
+		}
+		fmt.Printf("  • set config_version: %d\n", currentConfigVersion)
+
+		for _, p := range validateConfig(settings) {
+			fmt.Printf("  ⚠️  %s (fix by hand)\n", p)
+		}
+
+		if dryRun {
+			fmt.Println("\nDry run: nothing was written.")
+			return
+		}
+
+		original, err := os.ReadFile(path)
+		if err == nil {
+			err = os.WriteFile(path+".bak", original, 0600)
+		}
+		if err != nil {
+			fmt.Printf("❌ Could not back up %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		if err := writeConfigFile(path, settings); err != nil {
+			fmt.Printf("❌ Could not write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ Migrated (backup at %s.bak)\n", path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configMigrateCmd.Flags().Bool("dry-run", false, "Show the changes without writing the file")
+
+	configCmd.AddCommand(configDefaultsCmd)
+	configDefaultsCmd.AddCommand(configDefaultsListCmd)
+	configDefaultsCmd.AddCommand(configDefaultsSetCmd)
+	configDefaultsCmd.AddCommand(configDefaultsUnsetCmd)
+}
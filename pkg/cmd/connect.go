@@ -5,9 +5,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"os"
-	"os/signal"
-	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
@@ -26,6 +23,13 @@ var connectCmd = &cobra.Command{
 
 		apiKey, _ := cmd.Flags().GetString("key")
 		trigger, _ := cmd.Flags().GetString("trigger")
+		sinkSpecs, _ := cmd.Flags().GetStringArray("sink")
+
+		sinks, err := newSinkSet(sinkSpecs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer sinks.close()
 
 		u, err := url.Parse(serverURL)
 		if err != nil {
@@ -39,7 +43,10 @@ var connectCmd = &cobra.Command{
 			header.Set("Authorization", "Bearer "+apiKey)
 		}
 
-		c, _, err := websocket.DefaultDialer.Dial(u.String(), header)
+		ctx, stop := interruptContext()
+		defer stop()
+
+		c, _, err := dialEventStream(ctx, u.String(), header)
 		if err != nil {
 			log.Fatal("Connection failed:", err)
 		}
@@ -47,51 +54,26 @@ var connectCmd = &cobra.Command{
 
 		fmt.Println("✅ Connected! Listening for events...")
 
-		done := make(chan struct{})
-
-		// Reader loop
-		go func() {
-			defer close(done)
-			for {
-				_, message, err := c.ReadMessage()
-				if err != nil {
-					log.Println("read-error:", err)
-					return
-				}
-				fmt.Printf("< %s\n", message)
-			}
-		}()
-
-		// Trigger logic
 		if trigger != "" {
 			fmt.Printf("> Triggering event: %s\n", trigger)
-			err := c.WriteMessage(websocket.TextMessage, []byte(trigger))
-			if err != nil {
+			if err := c.WriteMessage(websocket.TextMessage, []byte(trigger)); err != nil {
 				log.Println("write-error:", err)
+				return
 			}
 		}
 
-		interrupt := make(chan os.Signal, 1)
-		signal.Notify(interrupt, os.Interrupt)
-
-		for {
-			select {
-			case <-done:
-				return
-			case <-interrupt:
-				fmt.Println("\nDisconnecting...")
-				// Cleanly close the connection by sending a close message
-				err := c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-				if err != nil {
-					log.Println("write-close:", err)
-					return
-				}
-				select {
-				case <-done:
-				case <-time.After(time.Second):
-				}
-				return
-			}
+		err = readStream(ctx, c, func(message []byte) error {
+			fmt.Printf("< %s\n", message)
+			sinks.send(message)
+			return nil
+		})
+		switch {
+		case interrupted(ctx):
+			fmt.Println("\nDisconnecting...")
+		case err == errServerClosed:
+			fmt.Println("Server closed connection")
+		case err != nil:
+			fmt.Printf("❌ %v\n", err)
 		}
 	},
 }
@@ -100,4 +82,5 @@ func init() {
 	rootCmd.AddCommand(connectCmd)
 	connectCmd.Flags().StringP("key", "k", "", "API Key for authentication")
 	connectCmd.Flags().StringP("trigger", "t", "", "Send a JSON event payload immediately after connecting")
+	connectCmd.Flags().StringArray("sink", nil, "Also stream received messages to a local socket (unix:///path or fifo:///path, repeatable)")
 }
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+// secretConfigKeys are kept in the credential store rather than the config
+// file. They are still read through viper; SAPLIY_API_KEY and friends keep
+// overriding them.
+var secretConfigKeys = []string{"api_key", "refresh_token"}
+
+const keyringService = "sapliy-cli"
+
+var errCredentialNotFound = errors.New("credential not found")
+
+// credentialStore keeps secrets outside the config file.
+type credentialStore interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// keychainStore uses the OS credential manager: the macOS keychain, the
+// Secret Service (libsecret) on Linux or the Windows Credential Manager.
+type keychainStore struct{}
+
+func (keychainStore) Name() string { return "OS keychain" }
+
+func (keychainStore) Get(key string) (string, error) {
+	v, err := keyring.Get(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", errCredentialNotFound
+	}
+	return v, err
+}
+
+func (keychainStore) Set(key, value string) error {
+	return keyring.Set(keyringService, key, value)
+}
+
+func (keychainStore) Delete(key string) error {
+	err := keyring.Delete(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// fileStore is the fallback when no keychain is available, e.g. on headless
+// Linux. Secrets are sealed with AES-GCM in ~/.sapliy/credentials under a
+// random key kept in a separate owner-only file, so they do not end up in
+// shared or committed config files.
+type fileStore struct {
+	dir string
+}
+
+func (s fileStore) Name() string { return "encrypted file " + filepath.Join(s.dir, "credentials") }
+
+func (s fileStore) key() ([]byte, error) {
+	path := filepath.Join(s.dir, "credentials.key")
+	key, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return key, os.WriteFile(path, key, 0600)
+	}
+	if err == nil && len(key) != 32 {
+		err = fmt.Errorf("%s is corrupt", path)
+	}
+	return key, err
+}
+
+func (s fileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	sealed, err := os.ReadFile(filepath.Join(s.dir, "credentials"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("credentials file is corrupt")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt credentials file: %w", err)
+	}
+	return secrets, json.Unmarshal(plain, &secrets)
+}
+
+func (s fileStore) save(secrets map[string]string) error {
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, "credentials"), gcm.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+func (s fileStore) cipher() (cipher.AEAD, error) {
+	key, err := s.key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s fileStore) Get(key string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := secrets[key]
+	if !ok {
+		return "", errCredentialNotFound
+	}
+	return v, nil
+}
+
+func (s fileStore) Set(key, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s fileStore) Delete(key string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+var (
+	credentialsOnce  sync.Once
+	credentials      credentialStore
+	credentialsError error
+)
+
+// credentialStoreInUse picks the store named by the credential_store config
+// key: "keychain", "file", or "auto" (the default), which uses the keychain
+// when one is reachable and the encrypted file otherwise.
+func credentialStoreInUse() (credentialStore, error) {
+	credentialsOnce.Do(func() {
+		mode := viper.GetString("credential_store")
+		if mode == "" || mode == "auto" || mode == "keychain" {
+			_, err := keychainStore{}.Get("probe")
+			if err == nil || errors.Is(err, errCredentialNotFound) {
+				credentials = keychainStore{}
+				return
+			}
+			if mode == "keychain" {
+				credentialsError = fmt.Errorf("OS keychain unavailable: %w", err)
+				return
+			}
+		} else if mode != "file" {
+			credentialsError = fmt.Errorf("invalid credential_store %q (use auto, keychain or file)", mode)
+			return
+		}
+
+		dir, err := sapliyDir()
+		if err != nil {
+			credentialsError = err
+			return
+		}
+		credentials = fileStore{dir: dir}
+	})
+	return credentials, credentialsError
+}
+
+func isSecretConfigKey(key string) bool {
+	for _, k := range secretConfigKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setCredential stores a secret for the active profile and makes it visible
+// to this process.
+func setCredential(key, value string) error {
+	store, err := credentialStoreInUse()
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		err = store.Delete(credentialKey(key))
+	} else {
+		err = store.Set(credentialKey(key), value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", store.Name(), err)
+	}
+	viper.Set(key, value)
+	return nil
+}
+
+// copyCredentials copies the active profile's secrets to another profile.
+func copyCredentials(profile string) error {
+	store, err := credentialStoreInUse()
+	if err != nil {
+		return err
+	}
+	for _, key := range secretConfigKeys {
+		if v := viper.GetString(key); v != "" {
+			if err := store.Set(profile+"/"+key, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadCredentials makes the active profile's stored secrets available
+// through viper, unless an environment variable overrides them. Secrets
+// still in the config file from older versions are moved to the store and
+// removed from the file.
+func loadCredentials() {
+	store, err := credentialStoreInUse()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		return
+	}
+
+	path := viper.ConfigFileUsed()
+	var moved []string
+	if path != "" {
+		file, err := readConfigFile(path)
+		if err == nil {
+			for _, key := range secretConfigKeys {
+				value, _ := file[key].(string)
+				if value == "" {
+					continue
+				}
+				if err := store.Set(key, value); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Could not move %s to the %s: %v\n", key, store.Name(), err)
+					continue
+				}
+				moved = append(moved, key)
+			}
+		}
+	}
+
+	for _, key := range secretConfigKeys {
+		if envOverrides(key) {
+			continue
+		}
+		value, err := store.Get(credentialKey(key))
+		switch {
+		case err == nil:
+			viper.Set(key, value)
+		case !errors.Is(err, errCredentialNotFound):
+			fmt.Fprintf(os.Stderr, "⚠️  Could not read %s from the %s: %v\n", key, store.Name(), err)
+		case activeProfile() != defaultProfile:
+			// Do not fall back to the default profile's credentials.
+			viper.Set(key, "")
+		}
+	}
+
+	if len(moved) == 0 {
+		return
+	}
+	settings, err := readConfigFile(path)
+	if err == nil {
+		for _, key := range moved {
+			delete(settings, key)
+		}
+		err = writeConfigFile(path, settings)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Copied secrets to the %s but could not remove them from %s: %v\n", store.Name(), path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🔐 Moved %v from %s to the %s\n", moved, path, store.Name())
+}
+
+// saveConfig writes keys, as currently set, to the config file and leaves
+// the rest of the file as it is, so flag defaults, environment variables and
+// the project config never end up in it. Profile settings are written to the
+// active profile's section. Secrets live in the credential store and are
+// never written.
+func saveConfig(keys ...string) error {
+	path, file, err := readConfigOrEmpty()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if isSecretConfigKey(key) {
+			continue
+		}
+		if name := activeProfile(); name != defaultProfile && slices.Contains(profileKeys, key) {
+			setProfileSetting(file, name, key, viper.Get(key))
+			continue
+		}
+		file[key] = viper.Get(key)
+	}
+	if err := writeConfigFile(path, file); err != nil {
+		return err
+	}
+	warnProjectOverrides(keys)
+	return nil
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+// cursorStore persists, per named stream, the ID of the last event that was
+// successfully forwarded, so a restarted forwarder resumes where it left off
+// instead of losing or re-sending events.
+type cursorStore struct {
+	db *sql.DB
+}
+
+type streamCursor struct {
+	Name      string    `json:"name"`
+	EventID   string    `json:"eventId"`
+	Acked     int64     `json:"acked"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func openCursorStore() (*cursorStore, error) {
+	dir, err := sapliyDir()
+	if err != nil {
+		return nil, err
+	}
+	return openCursorStoreAt(filepath.Join(dir, "state.db"))
+}
+
+func openCursorStoreAt(path string) (*cursorStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS cursors (
+		name       TEXT PRIMARY KEY,
+		event_id   TEXT NOT NULL,
+		acked      INTEGER NOT NULL DEFAULT 0,
+		updated_at TEXT NOT NULL
+	)`)
+	if err == nil {
+		_, err = db.Exec(`CREATE TABLE IF NOT EXISTS deliveries (
+			stream       TEXT NOT NULL,
+			event_hash   TEXT NOT NULL,
+			status       TEXT NOT NULL,
+			delivered_at TEXT NOT NULL,
+			PRIMARY KEY (stream, event_hash)
+		)`)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize cursor store %s: %w", path, err)
+	}
+
+	return &cursorStore{db: db}, nil
+}
+
+func (s *cursorStore) Close() error {
+	return s.db.Close()
+}
+
+// get returns the cursor for name, or nil if there is none.
+func (s *cursorStore) get(name string) (*streamCursor, error) {
+	c := &streamCursor{Name: name}
+	var updated string
+	err := s.db.QueryRow(`SELECT event_id, acked, updated_at FROM cursors WHERE name = ?`, name).
+		Scan(&c.EventID, &c.Acked, &updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updated)
+	return c, nil
+}
+
+// ack moves the cursor for name to eventID.
+func (s *cursorStore) ack(name, eventID string) error {
+	_, err := s.db.Exec(`INSERT INTO cursors (name, event_id, acked, updated_at) VALUES (?, ?, 1, ?)
+		ON CONFLICT(name) DO UPDATE SET event_id = excluded.event_id, acked = acked + 1, updated_at = excluded.updated_at`,
+		name, eventID, time.Now().UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+// reset deletes the cursor for name along with its dedup window.
+func (s *cursorStore) reset(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM cursors WHERE name = ?`, name); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM deliveries WHERE stream = ?`, name)
+	return err
+}
+
+// Delivery states in the dedup window. An event is marked pending before it
+// is sent and delivered once the target accepted it; a pending entry found
+// on startup means the previous run crashed mid-forward.
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+)
+
+func eventHash(eventID string) string {
+	sum := sha256.Sum256([]byte(eventID))
+	return hex.EncodeToString(sum[:])
+}
+
+// deliveryStatus returns the dedup-window state of an event for stream, or
+// "" if it has not been seen.
+func (s *cursorStore) deliveryStatus(stream, hash string) (string, error) {
+	var status string
+	err := s.db.QueryRow(`SELECT status FROM deliveries WHERE stream = ? AND event_hash = ?`, stream, hash).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
+func (s *cursorStore) markDelivery(stream, hash, status string) error {
+	_, err := s.db.Exec(`INSERT INTO deliveries (stream, event_hash, status, delivered_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(stream, event_hash) DO UPDATE SET status = excluded.status, delivered_at = excluded.delivered_at`,
+		stream, hash, status, time.Now().UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *cursorStore) forgetDelivery(stream, hash string) error {
+	_, err := s.db.Exec(`DELETE FROM deliveries WHERE stream = ? AND event_hash = ?`, stream, hash)
+	return err
+}
+
+// pruneDeliveries drops dedup entries older than window and returns how many
+// remain for stream.
+func (s *cursorStore) pruneDeliveries(stream string, window time.Duration) (int, error) {
+	cutoff := time.Now().Add(-window).UTC().Format(time.RFC3339Nano)
+	if _, err := s.db.Exec(`DELETE FROM deliveries WHERE delivered_at < ?`, cutoff); err != nil {
+		return 0, err
+	}
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM deliveries WHERE stream = ?`, stream).Scan(&n)
+	return n, err
+}
+
+func (s *cursorStore) list() ([]streamCursor, error) {
+	rows, err := s.db.Query(`SELECT name, event_id, acked, updated_at FROM cursors ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cursors []streamCursor
+	for rows.Next() {
+		var c streamCursor
+		var updated string
+		if err := rows.Scan(&c.Name, &c.EventID, &c.Acked, &updated); err != nil {
+			return nil, err
+		}
+		c.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updated)
+		cursors = append(cursors, c)
+	}
+	return cursors, rows.Err()
+}
+
+var cursorCmd = &cobra.Command{
+	Use:   "cursor",
+	Short: "Inspect and reset forwarding cursors",
+	Long: `Forwarders remember the last event they delivered to your local target
+so that a restart resumes from that point. Cursors are stored in
+~/.sapliy/state.db and named after the zone and target they belong to.`,
+}
+
+var cursorShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show stored cursors",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openCursorStore()
+		if err != nil {
+			fmt.Printf("Error opening cursor store: %v\n", err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		cursors, err := store.list()
+		if err != nil {
+			fmt.Printf("Error reading cursors: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) > 0 {
+			var filtered []streamCursor
+			for _, c := range cursors {
+				if c.Name == args[0] {
+					filtered = append(filtered, c)
+				}
+			}
+			cursors = filtered
+		}
+
+		printOutput(cursors, func() {
+			if len(cursors) == 0 {
+				fmt.Println("No cursors stored.")
+				return
+			}
+
+			fmt.Printf("%-50s %-24s %8s  %s\n", "NAME", "LAST EVENT", "ACKED", "UPDATED")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, c := range cursors {
+				fmt.Printf("%-50s %-24s %8d  %s\n", truncate(c.Name, 50), c.EventID, c.Acked, c.UpdatedAt.Local().Format("Jan 02 15:04:05"))
+			}
+		})
+	},
+}
+
+var cursorResetCmd = &cobra.Command{
+	Use:   "reset [name]",
+	Short: "Delete a stored cursor so the next run starts from the live stream",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := openCursorStore()
+		if err != nil {
+			fmt.Printf("Error opening cursor store: %v\n", err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		if err := store.reset(args[0]); err != nil {
+			fmt.Printf("Error resetting cursor: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Cursor %s reset\n", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cursorCmd)
+	cursorCmd.AddCommand(cursorShowCmd)
+	cursorCmd.AddCommand(cursorResetCmd)
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// customer is a cus_ record that payments, subscriptions and invoices
+// refer to.
+type customer struct {
+	ID        string            `json:"id,omitempty"`
+	Email     string            `json:"email,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Phone     string            `json:"phone,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt *time.Time        `json:"createdAt,omitempty"`
+}
+
+func customerPath(customerID string) string {
+	return "/v1/customers/" + url.PathEscape(customerID)
+}
+
+func printCustomer(c *customer) {
+	fmt.Printf("👤 Customer %s\n", c.ID)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Email:     %s\n", c.Email)
+	if c.Name != "" {
+		fmt.Printf("Name:      %s\n", c.Name)
+	}
+	if c.Phone != "" {
+		fmt.Printf("Phone:     %s\n", c.Phone)
+	}
+	if c.CreatedAt != nil {
+		fmt.Printf("Created:   %s\n", c.CreatedAt.Local().Format(time.RFC1123))
+	}
+	if len(c.Metadata) > 0 {
+		keys := make([]string, 0, len(c.Metadata))
+		for k := range c.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Println("Metadata:")
+		for _, k := range keys {
+			fmt.Printf("  %s = %s\n", k, c.Metadata[k])
+		}
+	}
+}
+
+var customersCmd = &cobra.Command{
+	Use:   "customers",
+	Short: "Manage customers",
+}
+
+var customersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List customers",
+	Example: `  sapliy customers list --email jane@example.com
+  sapliy customers list --all -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		email, _ := cmd.Flags().GetString("email")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		if email != "" {
+			q.Set("email", email)
+		}
+		customers, cursor, err := listPages[customer](context.Background(), "/v1/customers", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing customers: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(customers, func() {
+			if len(customers) == 0 {
+				fmt.Println("No customers found.")
+				return
+			}
+
+			fmt.Printf("%-24s %-32s %-24s %s\n", "ID", "EMAIL", "NAME", "CREATED")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, c := range customers {
+				created := ""
+				if c.CreatedAt != nil {
+					created = c.CreatedAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Printf("%-24s %-32s %-24s %s\n", c.ID, truncate(c.Email, 32), truncate(c.Name, 24), created)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore customers available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var customersGetCmd = &cobra.Command{
+	Use:   "get [customer_id]",
+	Short: "Show a customer",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var c customer
+		if err := apiRequest(context.Background(), http.MethodGet, customerPath(args[0]), nil, &c); err != nil {
+			fmt.Printf("❌ Failed to fetch customer: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(c, func() { printCustomer(&c) })
+	},
+}
+
+var customersCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a customer",
+	Example: `  sapliy customers create --email jane@example.com --name "Jane Doe"
+  sapliy customers create --email ops@example.com --metadata plan=pro --metadata crm_id=4821`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var c customer
+		c.Email, _ = cmd.Flags().GetString("email")
+		c.Name, _ = cmd.Flags().GetString("name")
+		c.Phone, _ = cmd.Flags().GetString("phone")
+		c.Metadata, _ = cmd.Flags().GetStringToString("metadata")
+		if !strings.Contains(c.Email, "@") {
+			fmt.Printf("Error: invalid --email %q\n", c.Email)
+			os.Exit(1)
+		}
+
+		var created customer
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/customers", &c, &created); err != nil {
+			fmt.Printf("❌ Failed to create customer: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(created, func() {
+			printCustomer(&created)
+			fmt.Println("\n✅ Customer created!")
+		})
+	},
+}
+
+var customersUpdateCmd = &cobra.Command{
+	Use:   "update [customer_id]",
+	Short: "Change a customer's email, name, phone or metadata",
+	Long: `Change a customer. Only the flags you pass are changed. --metadata sets
+keys and leaves the others alone; give a key an empty value (--metadata
+plan=) to remove it.`,
+	Example: `  sapliy customers update cus_123 --email jane.doe@example.com
+  sapliy customers update cus_123 --metadata plan=enterprise --metadata trial=`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		changes := map[string]interface{}{}
+		for _, name := range []string{"email", "name", "phone"} {
+			if flags.Changed(name) {
+				changes[name], _ = flags.GetString(name)
+			}
+		}
+		if email, ok := changes["email"].(string); ok && !strings.Contains(email, "@") {
+			fmt.Printf("Error: invalid --email %q\n", email)
+			os.Exit(1)
+		}
+		if flags.Changed("metadata") {
+			set, _ := flags.GetStringToString("metadata")
+			metadata := map[string]interface{}{}
+			for k, v := range set {
+				if v == "" {
+					metadata[k] = nil
+				} else {
+					metadata[k] = v
+				}
+			}
+			changes["metadata"] = metadata
+		}
+		if len(changes) == 0 {
+			fmt.Println("Nothing to update. Pass --email, --name, --phone or --metadata.")
+			return
+		}
+
+		var updated customer
+		if err := apiRequest(context.Background(), http.MethodPatch, customerPath(args[0]), changes, &updated); err != nil {
+			fmt.Printf("❌ Failed to update customer: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(updated, func() {
+			printCustomer(&updated)
+			fmt.Println("\n✅ Customer updated!")
+		})
+	},
+}
+
+var customersDeleteCmd = &cobra.Command{
+	Use:   "delete [customer_id]",
+	Short: "Delete a customer",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Delete customer %s? Their saved payment methods are removed too. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, customerPath(args[0]), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete customer: %v\n", explainHold(err, args[0]))
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted customer %s\n", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(customersCmd)
+	customersCmd.AddCommand(customersListCmd)
+	customersCmd.AddCommand(customersGetCmd)
+	customersCmd.AddCommand(customersCreateCmd)
+	customersCmd.AddCommand(customersUpdateCmd)
+	customersCmd.AddCommand(customersDeleteCmd)
+
+	customersListCmd.Flags().IntP("limit", "l", 20, "Number of customers per page")
+	customersListCmd.Flags().String("email", "", "Only customers with this email")
+	customersListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	customersListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	for _, c := range []*cobra.Command{customersCreateCmd, customersUpdateCmd} {
+		c.Flags().String("email", "", "Email address")
+		c.Flags().String("name", "", "Full name or company name")
+		c.Flags().String("phone", "", "Phone number")
+		c.Flags().StringToString("metadata", nil, "Metadata as key=value (repeatable)")
+	}
+	customersCreateCmd.MarkFlagRequired("email")
+	customersDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
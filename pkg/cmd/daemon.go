@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// listenState is the part of a streaming command that can be changed while
+// it runs, through the local control API.
+type listenState struct {
+	mu        sync.Mutex
+	command   string
+	zone      string
+	filter    string
+	paused    bool
+	startedAt time.Time
+
+	received  int64
+	delivered int64
+	filtered  int64
+	skipped   int64
+}
+
+type daemonStatus struct {
+	Command   string    `json:"command"`
+	PID       int       `json:"pid"`
+	Zone      string    `json:"zone"`
+	Filter    string    `json:"filter"`
+	Paused    bool      `json:"paused"`
+	StartedAt time.Time `json:"startedAt"`
+	Received  int64     `json:"received"`
+	Delivered int64     `json:"delivered"`
+	Filtered  int64     `json:"filtered"`
+	Skipped   int64     `json:"skippedWhilePaused"`
+}
+
+func newListenState(command, zone, filter string) *listenState {
+	return &listenState{command: command, zone: zone, filter: filter, startedAt: time.Now()}
+}
+
+// admit records an incoming event and reports whether it should be emitted,
+// given the current pause state and type filter (substring match).
+func (s *listenState) admit(eventType string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.received++
+	if s.paused {
+		s.skipped++
+		return false
+	}
+	if s.filter != "" && !strings.Contains(eventType, s.filter) {
+		s.filtered++
+		return false
+	}
+	s.delivered++
+	return true
+}
+
+func (s *listenState) setPaused(paused bool) {
+	s.mu.Lock()
+	s.paused = paused
+	s.mu.Unlock()
+}
+
+func (s *listenState) setFilter(filter string) {
+	s.mu.Lock()
+	s.filter = filter
+	s.mu.Unlock()
+}
+
+func (s *listenState) setZone(zone string) {
+	s.mu.Lock()
+	s.zone = zone
+	s.mu.Unlock()
+}
+
+func (s *listenState) status() daemonStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return daemonStatus{
+		Command:   s.command,
+		PID:       os.Getpid(),
+		Zone:      s.zone,
+		Filter:    s.filter,
+		Paused:    s.paused,
+		StartedAt: s.startedAt,
+		Received:  s.received,
+		Delivered: s.delivered,
+		Filtered:  s.filtered,
+		Skipped:   s.skipped,
+	}
+}
+
+func defaultControlSocket() string {
+	dir, err := sapliyDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "sapliy-daemon.sock")
+	}
+	return filepath.Join(dir, "daemon.sock")
+}
+
+// startControlServer serves the JSON control API for state on a Unix socket
+// and returns a function that shuts it down.
+func startControlServer(path string, state *listenState) (func(), error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if controlSocketAlive(path) {
+			return nil, fmt.Errorf("another daemon is already listening on %s", path)
+		}
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("control socket %s: %w", path, err)
+	}
+
+	writeStatus := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state.status())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w)
+	})
+	mux.HandleFunc("POST /v1/pause", func(w http.ResponseWriter, r *http.Request) {
+		state.setPaused(true)
+		writeStatus(w)
+	})
+	mux.HandleFunc("POST /v1/resume", func(w http.ResponseWriter, r *http.Request) {
+		state.setPaused(false)
+		writeStatus(w)
+	})
+	mux.HandleFunc("POST /v1/filter", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Filter string `json:"filter"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		state.setFilter(body.Filter)
+		writeStatus(w)
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+		os.Remove(path)
+	}, nil
+}
+
+func controlSocketAlive(path string) bool {
+	conn, err := net.DialTimeout("unix", path, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// controlRequest calls the control API of a running daemon.
+func controlRequest(socket, method, path string, body interface{}) (*daemonStatus, error) {
+	client := unixSocketClient(socket, 5*time.Second)
+
+	var reader *strings.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		reader = strings.NewReader(string(b))
+	} else {
+		reader = strings.NewReader("")
+	}
+
+	req, err := http.NewRequest(method, "http://daemon"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("no daemon running on %s (start one with 'sapliy debug listen --daemon' or 'sapliy webhooks listen --daemon')", socket)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("daemon returned status %d", resp.StatusCode)
+	}
+
+	var status daemonStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func printDaemonStatus(s *daemonStatus) {
+	state := "running"
+	if s.Paused {
+		state = "paused"
+	}
+	filter := s.Filter
+	if filter == "" {
+		filter = "(none)"
+	}
+	zone := s.Zone
+	if zone == "" {
+		zone = "(all)"
+	}
+
+	fmt.Printf("Command:     %s (pid %d)\n", s.Command, s.PID)
+	fmt.Printf("State:       %s\n", state)
+	fmt.Printf("Zone:        %s\n", zone)
+	fmt.Printf("Filter:      %s\n", filter)
+	fmt.Printf("Uptime:      %s\n", time.Since(s.StartedAt).Round(time.Second))
+	fmt.Printf("Received:    %d\n", s.Received)
+	fmt.Printf("Delivered:   %d\n", s.Delivered)
+	fmt.Printf("Filtered:    %d\n", s.Filtered)
+	fmt.Printf("Skipped:     %d (while paused)\n", s.Skipped)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Control a running streaming daemon",
+	Long: `Manage a long-running listener started with 'sapliy debug listen --daemon'
+or 'sapliy webhooks listen --daemon' without restarting it: inspect stats,
+pause and resume delivery, or change the event filter on the fly.`,
+}
+
+func runDaemonCommand(method, path string, body interface{}, message string) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		socket, _ := cmd.Flags().GetString("socket")
+		if socket == "" {
+			socket = defaultControlSocket()
+		}
+
+		payload := body
+		if payload == nil && len(args) > 0 {
+			payload = map[string]string{"filter": args[0]}
+		}
+
+		status, err := controlRequest(socket, method, path, payload)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		if message != "" {
+			fmt.Println(message)
+			fmt.Println(strings.Repeat("─", 40))
+		}
+		printDaemonStatus(status)
+	}
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show state and delivery stats of the running daemon",
+	Run:   runDaemonCommand(http.MethodGet, "/v1/status", nil, ""),
+}
+
+var daemonPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause event delivery (the connection stays open)",
+	Run:   runDaemonCommand(http.MethodPost, "/v1/pause", nil, "⏸️  Paused"),
+}
+
+var daemonResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume event delivery",
+	Run:   runDaemonCommand(http.MethodPost, "/v1/resume", nil, "▶️  Resumed"),
+}
+
+var daemonFilterCmd = &cobra.Command{
+	Use:   "filter [type]",
+	Short: "Change the event type filter (empty string clears it)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runDaemonCommand(http.MethodPost, "/v1/filter", nil, "🔎 Filter updated"),
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonPauseCmd)
+	daemonCmd.AddCommand(daemonResumeCmd)
+	daemonCmd.AddCommand(daemonFilterCmd)
+
+	daemonCmd.PersistentFlags().String("socket", "", "Control socket of the daemon (default ~/.sapliy/daemon.sock)")
+}
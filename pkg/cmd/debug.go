@@ -1,15 +1,17 @@
 package cmd
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
-	"os/signal"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -25,7 +27,29 @@ var debugListenCmd = &cobra.Command{
 	Use:   "listen",
 	Short: "Listen to real-time event stream via WebSocket",
 	Long: `Connect to Sapliy API and stream events in real-time.
-This is useful for debugging flows and watching events as they happen.`,
+This is useful for debugging flows and watching events as they happen.
+
+--zone can be repeated or given a comma-separated list to follow several
+zones at once; their events are interleaved into one stream with a zone
+column.
+
+The filter, sinks and zone can be changed while listening by editing the
+config file (listen.filter, listen.sinks, current_zone) or sending SIGHUP.
+Filter and sink changes apply without dropping the connection; a zone change
+re-subscribes (unless --zone was given).
+
+If the connection drops, it is re-established with exponential backoff
+(1s doubling up to 30s, jittered) and the stream resumes after the last
+event received, so nothing is missed. Use --reconnect=false to exit instead.
+A zone whose stream fails for good, e.g. because the API key is rejected,
+ends the whole command.
+
+With --format ndjson each event is written to stdout as one line of raw JSON,
+with connection messages on stderr, for piping into jq or saving to a file.`,
+	Example: `  sapliy debug listen --filter payment
+  sapliy debug listen --zone zone_tenant_a,zone_tenant_b
+  sapliy debug listen --format ndjson | jq -r 'select(.type == "payment.failed") | .data.id'
+  sapliy debug listen --format ndjson >> events.ndjson`,
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey := viper.GetString("api_key")
 		if apiKey == "" {
@@ -33,73 +57,139 @@ This is useful for debugging flows and watching events as they happen.`,
 			os.Exit(1)
 		}
 
-		zone := viper.GetString("current_zone")
-		if zone == "" {
-			zone, _ = cmd.Flags().GetString("zone")
-		}
-
-		// Determine WS URL (default to localhost:8089 for dev)
-		apiURL := viper.GetString("api_url")
-		wsURL := "ws://localhost:8089/v1/events/stream"
-		if apiURL != "" && !strings.Contains(apiURL, "localhost") {
-			// Production logic would replace https:// with wss://
-			wsURL = strings.Replace(apiURL, "https://", "wss://", 1) + "/v1/events/stream"
+		var zones []string
+		flagZones, _ := cmd.Flags().GetStringSlice("zone")
+		for _, z := range flagZones {
+			if z = strings.TrimSpace(z); z != "" && !slices.Contains(zones, z) {
+				zones = append(zones, z)
+			}
 		}
-
-		// Append query params
-		wsURL += fmt.Sprintf("?api_key=%s", apiKey)
-		if zone != "" {
-			wsURL += fmt.Sprintf("&zone=%s", zone)
+		followConfig := len(zones) == 0
+		if followConfig {
+			zones = []string{viper.GetString("current_zone")}
 		}
 
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		filterType, _ := cmd.Flags().GetString("filter")
+		sinkSpecs, _ := cmd.Flags().GetStringArray("sink")
+		daemon, _ := cmd.Flags().GetBool("daemon")
+		controlSocket, _ := cmd.Flags().GetString("control-socket")
+		reconnect, _ := cmd.Flags().GetBool("reconnect")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "ndjson" {
+			fmt.Printf("Error: invalid --format %q (use text or ndjson)\n", format)
+			os.Exit(1)
+		}
+		// With ndjson, stdout carries only events; everything else goes to
+		// stderr.
+		ndjson := format == "ndjson"
+		var status io.Writer = os.Stdout
+		if ndjson {
+			status = os.Stderr
+		}
 
-		fmt.Printf("🔌 Connecting to %s...\n", wsURL)
+		state := newListenState("debug listen", strings.Join(zones, ","), filterType)
+		if daemon {
+			if controlSocket == "" {
+				controlSocket = defaultControlSocket()
+			}
+			stop, err := startControlServer(controlSocket, state)
+			if err != nil {
+				fmt.Fprintf(status, "❌ %v\n", err)
+				return
+			}
+			defer stop()
+			fmt.Fprintf(status, "🎛️  Control API on %s (manage with 'sapliy daemon status|pause|resume')\n", controlSocket)
+		}
 
-		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		sinks, err := newSinkSet(sinkSpecs)
 		if err != nil {
-			fmt.Printf("❌ Failed to connect: %v\n", err)
+			fmt.Fprintf(status, "❌ %v\n", err)
 			return
 		}
-		defer conn.Close()
-
-		fmt.Println("✅ Connected! Streaming events... (Ctrl+C to stop)")
-		fmt.Println(strings.Repeat("─", 60))
-
-		// Handle graceful shutdown
-		interrupt := make(chan os.Signal, 1)
-		signal.Notify(interrupt, os.Interrupt)
-
-		done := make(chan struct{})
+		defer sinks.close()
+		for _, spec := range sinkSpecs {
+			fmt.Fprintf(status, "📤 Streaming events to %s\n", spec)
+		}
 
-		go func() {
-			defer close(done)
-			for {
-				_, message, err := conn.ReadMessage()
-				if err != nil {
-					// Check if normal close
-					if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
-						fmt.Printf("❌ connection error: %v\n", err)
-					}
-					return
+		plugins, err := loadPlugins()
+		if err != nil {
+			fmt.Fprintf(status, "❌ %v\n", err)
+			return
+		}
+		defer plugins.close()
+
+		reload := watchConfigReload()
+		ctx, stop := interruptContext()
+		defer stop()
+
+		// Each zone has its own connection; frames from all of them arrive
+		// on frames and are printed here, so output lines never interleave.
+		// A zone change replaces the whole group of connections.
+		frames := make(chan zoneFrame)
+		var streams *streamGroup
+		subscribe := func() {
+			streams = newStreamGroup(ctx)
+			for _, zone := range zones {
+				s := &zoneStream{apiKey: apiKey, zone: zone, reconnect: reconnect, status: status}
+				if len(zones) > 1 {
+					s.label = "[" + zone + "] "
 				}
+				streams.Go(func(ctx context.Context) error { return s.run(ctx, frames) })
+			}
+		}
+		subscribe()
 
+		for {
+			select {
+			case <-streams.Done():
+				if interrupted(ctx) {
+					fmt.Fprintln(status, "\n👋 Disconnecting...")
+				}
+				if err := streams.Wait(); err != nil {
+					fmt.Fprintf(status, "❌ %v\n", err)
+				}
+				return
+			case <-reload:
+				newZone := applyListenReload(status, state, sinks, zones[0])
+				if followConfig && newZone != zones[0] {
+					fmt.Fprintf(status, "🔁 Zone changed to %q, re-subscribing...\n", newZone)
+					streams.stop()
+					zones = []string{newZone}
+					state.setZone(newZone)
+					subscribe()
+				}
+			case f := <-frames:
 				var event map[string]interface{}
-				if err := json.Unmarshal(message, &event); err != nil {
+				if err := json.Unmarshal(f.message, &event); err != nil {
 					continue
 				}
-
 				eventType, _ := event["type"].(string)
 
-				// Apply filter if specified
-				if filterType != "" && !strings.Contains(eventType, filterType) {
+				// Apply pause state and filter, which may change while running
+				if !state.admit(eventType) || !plugins.keep(f.message) {
+					continue
+				}
+
+				sinks.send(f.message)
+
+				if ndjson {
+					var line bytes.Buffer
+					if err := json.Compact(&line, f.message); err == nil {
+						line.WriteByte('\n')
+						os.Stdout.Write(line.Bytes())
+					}
 					continue
 				}
 
 				timestamp := time.Now().Format("15:04:05")
+				if len(zones) > 1 {
+					timestamp += fmt.Sprintf("] [%-*s", zoneColumnWidth(zones), f.zone)
+				}
 
-				if verbose {
+				if out, ok := plugins.render(f.message); ok {
+					fmt.Printf("[%s] %s\n", timestamp, out)
+				} else if verbose {
 					prettyJSON, _ := json.MarshalIndent(event, "", "  ")
 					fmt.Printf("[%s] %s\n%s\n\n", timestamp, eventType, string(prettyJSON))
 				} else {
@@ -113,121 +203,154 @@ This is useful for debugging flows and watching events as they happen.`,
 					fmt.Printf("[%s] %-30s  %s\n", timestamp, eventType, id)
 				}
 			}
-		}()
-
-		select {
-		case <-interrupt:
-			fmt.Println("\n👋 Disconnecting...")
-			err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-			if err != nil {
-				return
-			}
-			select {
-			case <-done:
-			case <-time.After(time.Second):
-			}
-		case <-done:
-			fmt.Println("Server closed connection")
 		}
 	},
 }
 
-// pollEvents fetches events from the API
+// zoneColumnWidth is the width of the zone column when listening to zones.
+func zoneColumnWidth(zones []string) int {
+	width := 0
+	for _, z := range zones {
+		width = max(width, len(z))
+	}
+	return width
+}
 
-var debugInspectCmd = &cobra.Command{
-	Use:   "inspect [flow_id]",
-	Short: "Inspect a specific flow execution",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKey := viper.GetString("api_key")
-		if apiKey == "" {
-			fmt.Println("Error: API key not set.")
-			os.Exit(1)
-		}
+// eventStreamURL builds the WebSocket URL of the event stream for a zone.
+func eventStreamURL(apiKey, zone string) string {
+	// Determine WS URL (default to localhost:8089 for dev)
+	apiURL := apiBaseURL()
+	wsURL := "ws://localhost:8089/v1/events/stream"
+	if !strings.Contains(apiURL, "localhost") {
+		// Production logic would replace https:// with wss://; plain http://
+		// hosts (such as 'sapliy dev' in a container) get ws://.
+		wsURL = strings.Replace(apiURL, "https://", "wss://", 1)
+		wsURL = strings.Replace(wsURL, "http://", "ws://", 1) + "/v1/events/stream"
+	}
+
+	// Append query params
+	wsURL += fmt.Sprintf("?api_key=%s", apiKey)
+	if zone != "" {
+		wsURL += fmt.Sprintf("&zone=%s", zone)
+	}
+	return wsURL
+}
 
-		flowID := args[0]
-		fmt.Printf("🔍 Inspecting flow: %s\n", flowID)
-		fmt.Println(strings.Repeat("─", 60))
+// zoneFrame is an event received on one zone's stream.
+type zoneFrame struct {
+	zone    string
+	message []byte
+}
 
-		// TODO: Implement API call to get flow details
-		fmt.Println("Flow inspection coming soon...")
-	},
+// zoneStream is the connection debug listen keeps to one zone's event
+// stream, reconnecting with backoff and resuming after the last event when
+// it drops.
+type zoneStream struct {
+	apiKey    string
+	zone      string
+	reconnect bool
+	status    io.Writer
+	// label prefixes status messages when several zones are followed.
+	label string
 }
 
-var debugReplCmd = &cobra.Command{
-	Use:   "repl",
-	Short: "Interactive REPL for testing events",
-	Long: `Start an interactive REPL to test events and flows.
-Type event types and JSON data to trigger events interactively.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		apiKey := viper.GetString("api_key")
-		if apiKey == "" {
-			fmt.Println("Error: API key not set.")
-			os.Exit(1)
+// run streams events to frames until ctx is done (returning nil) or the
+// stream ends for good.
+func (s *zoneStream) run(ctx context.Context, frames chan<- zoneFrame) error {
+	var backoff streamBackoff
+	lastEventID := ""
+	for {
+		wsURL := eventStreamURL(s.apiKey, s.zone)
+		if lastEventID != "" {
+			wsURL += "&after=" + url.QueryEscape(lastEventID)
+		}
+		fmt.Fprintf(s.status, "%s🔌 Connecting to %s...\n", s.label, wsURL)
+
+		conn, resp, err := dialEventStream(ctx, wsURL, nil)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			if !s.reconnect || !retryableDialError(resp, err) {
+				return fmt.Errorf("%sFailed to connect: %v", s.label, err)
+			}
+			if !s.wait(ctx, &backoff, fmt.Sprintf("Failed to connect: %v", err)) {
+				return nil
+			}
+			continue
 		}
+		connectedAt := time.Now()
 
-		zone := viper.GetString("current_zone")
+		if err := checkStreamClockSkew(resp); err != nil {
+			conn.Close()
+			return fmt.Errorf("%s%v", s.label, err)
+		}
 
-		fmt.Println("🎮 Sapliy Debug REPL")
-		fmt.Println("Type 'help' for commands, 'exit' to quit")
-		fmt.Printf("Current zone: %s\n", zone)
-		fmt.Println(strings.Repeat("─", 60))
+		fmt.Fprintf(s.status, "%s✅ Connected (%s)! Streaming events... (Ctrl+C to stop)\n", s.label, streamFeatures(conn, resp))
+		if s.label == "" {
+			fmt.Fprintln(s.status, strings.Repeat("─", 60))
+		}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		for {
-			fmt.Print("sapliy> ")
-			if !scanner.Scan() {
-				break
+		err = readStream(ctx, conn, func(message []byte) error {
+			var event struct {
+				ID string `json:"id"`
 			}
-
-			input := strings.TrimSpace(scanner.Text())
-			if input == "" {
-				continue
+			if json.Unmarshal(message, &event) == nil && event.ID != "" {
+				lastEventID = event.ID
 			}
-
-			switch input {
-			case "exit", "quit":
-				fmt.Println("👋 Goodbye!")
-				return
-			case "help":
-				fmt.Println(`Commands:
-  emit <type> [json]  - Emit an event (e.g., emit payment.created {"amount":100})
-  zone <id>           - Switch to a different zone
-  status              - Show current configuration
-  exit                - Exit the REPL`)
-			case "status":
-				fmt.Printf("API Key: %s...%s\n", apiKey[:8], apiKey[len(apiKey)-4:])
-				fmt.Printf("Zone: %s\n", zone)
-				fmt.Printf("API URL: %s\n", viper.GetString("api_url"))
-			default:
-				if strings.HasPrefix(input, "emit ") {
-					parts := strings.SplitN(input[5:], " ", 2)
-					eventType := parts[0]
-					data := "{}"
-					if len(parts) > 1 {
-						data = parts[1]
-					}
-					fmt.Printf("➡️  Emitting %s: %s\n", eventType, data)
-					// TODO: Actually emit the event via SDK
-				} else if strings.HasPrefix(input, "zone ") {
-					zone = strings.TrimSpace(input[5:])
-					viper.Set("current_zone", zone)
-					fmt.Printf("✅ Switched to zone: %s\n", zone)
-				} else {
-					fmt.Printf("Unknown command: %s\n", input)
-				}
+			select {
+			case frames <- zoneFrame{zone: s.zone, message: message}:
+			case <-ctx.Done():
 			}
+			return nil
+		})
+		conn.Close()
+		if err != nil && err != errServerClosed {
+			fmt.Fprintf(s.status, "%s❌ %v\n", s.label, err)
 		}
-	},
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !s.reconnect {
+			return fmt.Errorf("%sServer closed connection", s.label)
+		}
+		// Only a connection that stayed up a while counts as recovered; one
+		// dropped straight away keeps backing off.
+		if time.Since(connectedAt) > time.Minute {
+			backoff.reset()
+		}
+		if !s.wait(ctx, &backoff, "Connection lost") {
+			return nil
+		}
+	}
+}
+
+// wait sleeps before the next reconnect attempt. It returns false if ctx is
+// done first.
+func (s *zoneStream) wait(ctx context.Context, backoff *streamBackoff, reason string) bool {
+	wait := backoff.next()
+	fmt.Fprintf(s.status, "%s🔁 %s; reconnecting in %s... (Ctrl+C to stop)\n", s.label, reason, wait.Round(100*time.Millisecond))
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
 }
 
+// pollEvents fetches events from the API
+
 func init() {
 	rootCmd.AddCommand(debugCmd)
 	debugCmd.AddCommand(debugListenCmd)
-	debugCmd.AddCommand(debugInspectCmd)
-	debugCmd.AddCommand(debugReplCmd)
 
-	debugListenCmd.Flags().StringP("zone", "z", "", "Zone ID to filter events")
+	debugListenCmd.Flags().StringSliceP("zone", "z", nil, "Zone ID to listen to (repeatable or comma-separated; default: current zone)")
 	debugListenCmd.Flags().BoolP("verbose", "v", false, "Show full event payloads")
 	debugListenCmd.Flags().StringP("filter", "f", "", "Filter events by type (substring match)")
+	debugListenCmd.Flags().Bool("daemon", false, "Expose a local control API to pause, resume and re-filter the stream")
+	debugListenCmd.Flags().String("control-socket", "", "Control API socket path (default ~/.sapliy/daemon.sock)")
+	debugListenCmd.Flags().String("format", "text", "Event output: text, or ndjson for one raw JSON event per line")
+	debugListenCmd.Flags().Bool("reconnect", true, "Reconnect with backoff when the connection drops, resuming after the last event")
+	debugListenCmd.Flags().StringArray("sink", nil, "Also stream matching events to a local socket (unix:///path or fifo:///path, repeatable)")
 }
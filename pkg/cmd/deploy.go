@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// deployResource is a zone or flow file being deployed, and what deploying
+// it did (or would do, with --dry-run): created, updated or unchanged.
+type deployResource struct {
+	File   string `json:"file"`
+	Kind   string `json:"kind"` // zone or flow
+	ID     string `json:"id"`
+	Zone   string `json:"zone,omitempty"`
+	Action string `json:"action,omitempty"`
+	doc    map[string]interface{}
+}
+
+// resourceExts are the extensions of zone and flow files: JSON as generated
+// by default, or YAML.
+var resourceExts = []string{".json", ".yaml", ".yml"}
+
+// fileKind tells zone files from flow files by name: *.zone.json,
+// *.flow.yaml and so on. It returns "" for other names.
+func fileKind(path string) string {
+	for _, ext := range resourceExts {
+		for _, kind := range []string{"zone", "flow"} {
+			if strings.HasSuffix(path, "."+kind+ext) {
+				return kind
+			}
+		}
+	}
+	return ""
+}
+
+func isYAMLFile(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
+
+// deployFiles lists the files to deploy: files named with -f, and the zone
+// and flow files (*.zone.json, *.flow.yaml, ...) under directories named
+// with -f.
+func deployFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (path == root || fileKind(path) != "") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// resourceKind tells zone files from flow files by name, or else by
+// content.
+func resourceKind(path string, doc map[string]interface{}) string {
+	if kind := fileKind(path); kind != "" {
+		return kind
+	}
+	switch {
+	case doc["steps"] != nil:
+		return "flow"
+	case doc["triggers"] != nil, doc["actions"] != nil:
+		return "zone"
+	}
+	return ""
+}
+
+// decodeResource parses the content of a zone or flow file into out. YAML
+// files are converted to JSON first, so their values decode exactly as they
+// would from the same JSON file.
+func decodeResource(file string, raw []byte, out interface{}) error {
+	if !isYAMLFile(file) {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("not valid JSON: %w", err)
+		}
+		return nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("not valid YAML: %w", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("not valid as JSON: %w", err)
+	}
+	return json.Unmarshal(converted, out)
+}
+
+// readResource reads a zone or flow file.
+func readResource(file string) (*deployResource, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := decodeResource(file, raw, &doc); err != nil {
+		return nil, err
+	}
+	r := &deployResource{File: file, Kind: resourceKind(file, doc), ID: stepString(doc, "id"), doc: doc}
+	if r.Kind == "" {
+		return nil, fmt.Errorf("cannot tell whether this is a zone or a flow; name it *.zone.json or *.flow.json (or .yaml)")
+	}
+	return r, nil
+}
+
+// validateResource returns what is wrong with a resource; warnings do not
+// stop a deploy.
+func validateResource(r *deployResource) (problems, warnings []string) {
+	if r.ID == "" {
+		problems = append(problems, `missing "id"; it says which resource to create or update`)
+	}
+	switch r.Kind {
+	case "zone":
+		if stepString(r.doc, "name") == "" {
+			problems = append(problems, `missing "name"`)
+		}
+	case "flow":
+		if len(stepList(r.doc["steps"])) == 0 {
+			problems = append(problems, `no steps (each step needs an "id")`)
+		}
+		for _, f := range analyzeFlowLogic(lintedFlow{Source: r.File, Doc: r.doc}) {
+			msg := f.Message
+			if f.Step != "" {
+				msg = "step " + f.Step + ": " + msg
+			}
+			if f.Severity == "error" {
+				problems = append(problems, msg)
+			} else {
+				warnings = append(warnings, msg)
+			}
+		}
+	}
+	return problems, warnings
+}
+
+// flowZone picks the zone a flow file is deployed to: its own "zoneId", the
+// zone file deployed from its directory or the nearest parent, or else
+// fallback (--zone or the current zone).
+func flowZone(flow *deployResource, zones []*deployResource, fallback string) string {
+	if z := stepString(flow.doc, "zoneId"); z != "" {
+		return z
+	}
+	best, depth := "", -1
+	for _, z := range zones {
+		dir := filepath.Dir(z.File)
+		rel, err := filepath.Rel(dir, filepath.Dir(flow.File))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if d := len(strings.Split(filepath.Clean(dir), string(filepath.Separator))); d > depth {
+			best, depth = z.ID, d
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return fallback
+}
+
+// sameFields reports whether remote has every field of local with the same
+// value. Fields only the API sets (version, createdAt, ...) are ignored.
+func sameFields(local, remote map[string]interface{}) bool {
+	for k, v := range local {
+		if !reflect.DeepEqual(v, remote[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchResource gets the deployed copy of a resource, or nil if there is
+// none yet.
+func fetchResource(ctx context.Context, path string) (map[string]interface{}, error) {
+	var remote map[string]interface{}
+	err := apiRequest(ctx, http.MethodGet, path, nil, &remote)
+	if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return remote, err
+}
+
+// resourcePath is the API path of a zone or flow.
+func resourcePath(r *deployResource) string {
+	if r.Kind == "flow" {
+		return flowPath(r.Zone, r.ID, "")
+	}
+	return zonePath(r.ID, "")
+}
+
+// applyResource creates or updates a resource unless it is unchanged. Flow
+// updates are deployed as a new version, as with 'flows deploy'.
+func applyResource(ctx context.Context, r *deployResource, dryRun bool) error {
+	path := resourcePath(r)
+	remote, err := fetchResource(ctx, path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case remote == nil:
+		r.Action = "created"
+	case sameFields(r.doc, remote):
+		r.Action = "unchanged"
+		return nil
+	default:
+		r.Action = "updated"
+	}
+	if dryRun {
+		return nil
+	}
+
+	switch {
+	case r.Kind == "zone" && r.Action == "created":
+		body := map[string]interface{}{"orgId": viper.GetString("org_id")}
+		for k, v := range r.doc {
+			body[k] = v
+		}
+		return apiRequest(ctx, http.MethodPost, "/v1/zones", body, nil)
+	case r.Kind == "zone":
+		return apiRequest(ctx, http.MethodPut, path, r.doc, nil)
+	case r.Action == "created":
+		return apiRequest(ctx, http.MethodPost, zonePath(r.Zone, "/flows"), r.doc, nil)
+	}
+	return apiRequest(ctx, http.MethodPost, flowPath(r.Zone, r.ID, "/deployments"), map[string]interface{}{"definition": r.doc}, nil)
+}
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Create or update zones and flows from local JSON or YAML files",
+	Long: `Deploy the zone (*.zone.json) and flow (*.flow.json) files written by
+'sapliy generate', or the same in YAML (*.zone.yaml, *.flow.yml, ...): each
+one is created if it does not exist yet, updated if it differs from the
+deployed copy, and otherwise left alone. -f takes files and directories,
+which are searched recursively.
+
+Everything is validated before anything is sent: files must be valid JSON
+or YAML with an "id", and flows must pass the error checks of 'sapliy flows lint'.
+Zones are deployed before flows. A flow goes to its "zoneId", or else to the
+zone whose file is in its directory or a parent directory, or else to --zone
+or the current zone.
+
+Use --dry-run to see what would be created and updated.`,
+	Example: `  sapliy deploy -f myzone.zone.json -f checkout.flow.json
+  sapliy deploy -f infra/ --dry-run
+  sapliy deploy -f flows/ --zone zone_123`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+		paths, _ := cmd.Flags().GetStringArray("file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if len(paths) == 0 {
+			fmt.Println("Error: pass the files or directories to deploy with -f.")
+			os.Exit(1)
+		}
+
+		files, err := deployFiles(paths)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			fmt.Println("No zone or flow files (*.zone.json, *.flow.json, or .yaml) found.")
+			return
+		}
+
+		var zones, flows []*deployResource
+		invalid := false
+		seen := map[string]string{}
+		for _, file := range files {
+			r, err := readResource(file)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", file, err)
+				invalid = true
+				continue
+			}
+			problems, warnings := validateResource(r)
+			if other, dup := seen[r.Kind+" "+r.ID]; dup && r.ID != "" {
+				problems = append(problems, fmt.Sprintf("%s %s is also defined in %s", r.Kind, r.ID, other))
+			}
+			seen[r.Kind+" "+r.ID] = file
+			for _, p := range problems {
+				fmt.Printf("❌ %s: %s\n", file, p)
+			}
+			for _, w := range warnings {
+				fmt.Printf("⚠️  %s: %s\n", file, w)
+			}
+			if len(problems) > 0 {
+				invalid = true
+			}
+			if r.Kind == "zone" {
+				zones = append(zones, r)
+			} else {
+				flows = append(flows, r)
+			}
+		}
+		for _, f := range flows {
+			if f.Zone = flowZone(f, zones, resolveZone(cmd)); f.Zone == "" {
+				fmt.Printf("❌ %s: no zone to deploy to; add a zone file, set \"zoneId\", or pass --zone\n", f.File)
+				invalid = true
+			}
+		}
+		if invalid {
+			fmt.Println("Nothing was deployed.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		resources := append(zones, flows...)
+		for i, r := range resources {
+			if err := applyResource(ctx, r, dryRun); err != nil {
+				fmt.Printf("❌ %s %s (%s): %v\n", r.Kind, r.ID, r.File, err)
+				if i > 0 {
+					fmt.Printf("   The %d resource(s) before it were deployed; fix it and run deploy again.\n", i)
+				}
+				os.Exit(1)
+			}
+		}
+
+		printOutput(resources, func() {
+			icons := map[string]string{"created": "➕", "updated": "🔄", "unchanged": "✓ "}
+			counts := map[string]int{}
+			for _, r := range resources {
+				counts[r.Action]++
+				action := r.Action
+				if dryRun && action != "unchanged" {
+					action = "would be " + action
+				}
+				where := r.File
+				if r.Kind == "flow" {
+					where += " → " + r.Zone
+				}
+				fmt.Printf("%s %-4s %-24s %-18s %s\n", icons[r.Action], r.Kind, r.ID, action, where)
+			}
+			fmt.Println(strings.Repeat("─", 60))
+			summary := fmt.Sprintf("%d created, %d updated, %d unchanged", counts["created"], counts["updated"], counts["unchanged"])
+			if dryRun {
+				fmt.Printf("Dry run: %s; nothing was changed.\n", strings.NewReplacer("created", "to create", "updated", "to update").Replace(summary))
+				return
+			}
+			fmt.Println(summary)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.Flags().StringArrayP("file", "f", nil, "Zone or flow file, or a directory of them (repeatable)")
+	deployCmd.Flags().Bool("dry-run", false, "Show what would be created and updated without changing anything")
+	deployCmd.Flags().StringP("zone", "z", "", "Zone for flows without a zone file or \"zoneId\" (default: current zone)")
+}
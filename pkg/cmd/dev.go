@@ -0,0 +1,445 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+)
+
+// loadDevFlows reads every *.flow.json under dir for the local runner.
+func loadDevFlows(dir string) ([]*localFlow, error) {
+	docs, err := loadLocalFlows([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	flows := make([]*localFlow, 0, len(docs))
+	for _, d := range docs {
+		flows = append(flows, newLocalFlow(d.Source, d.Doc))
+	}
+	return flows, nil
+}
+
+// watchManifests calls reload whenever a file under dir changes, once the
+// changes have settled. New subdirectories are watched as they appear.
+func watchManifests(ctx context.Context, dir string, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			err = watcher.Add(path)
+		}
+		return err
+	})
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		var settle <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Has(fsnotify.Create) {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						watcher.Add(ev.Name)
+					}
+				}
+				settle = time.After(200 * time.Millisecond)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "⚠️  watch: %v\n", err)
+			case <-settle:
+				settle = nil
+				reload()
+			}
+		}
+	}()
+	return nil
+}
+
+// devStream is the local event stream 'sapliy debug listen' connects to.
+type devStream struct {
+	upgrader websocket.Upgrader
+	// keep is how many published events are kept for clients that resume
+	// with ?after=; 0 keeps none.
+	keep int
+
+	mu      sync.Mutex
+	clients map[*websocket.Conn]string // connection → zone filter
+	history []*localEvent
+}
+
+func (s *devStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	zone := r.URL.Query().Get("zone")
+	s.mu.Lock()
+	if after := r.URL.Query().Get("after"); after != "" {
+		for i, e := range s.history {
+			if e.ID != after {
+				continue
+			}
+			for _, e := range s.history[i+1:] {
+				if wantsEvent(zone, e) {
+					frame, _ := json.Marshal(e)
+					conn.WriteMessage(websocket.TextMessage, frame)
+				}
+			}
+			break
+		}
+	}
+	s.clients[conn] = zone
+	s.mu.Unlock()
+
+	// Nothing is expected from clients; reading notices when they leave.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	s.mu.Lock()
+	delete(s.clients, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// wantsEvent reports whether a client following zone receives e.
+func wantsEvent(zone string, e *localEvent) bool {
+	return zone == "" || e.Zone == "" || zone == e.Zone
+}
+
+func (s *devStream) publish(e *localEvent) {
+	frame, _ := json.Marshal(e)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keep > 0 {
+		s.history = append(s.history, e)
+		if len(s.history) > s.keep {
+			s.history = s.history[len(s.history)-s.keep:]
+		}
+	}
+	for conn, zone := range s.clients {
+		if !wantsEvent(zone, e) {
+			continue
+		}
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+}
+
+func writeDevJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func devLimit(r *http.Request) int {
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+		return n
+	}
+	return 20
+}
+
+// devLog writes the output of one component of 'sapliy dev' to stdout with
+// each line prefixed by the component's name, so that all of them can share
+// a terminal.
+type devLog struct {
+	mu      *sync.Mutex // shared by every component, so lines never interleave
+	prefix  string
+	partial []byte // the end of the last write, up to its first newline
+}
+
+// devComponentColors are the ANSI colors of each component's prefix.
+var devComponentColors = map[string]string{"api": "36", "flows": "35", "studio": "34", "events": "33"}
+
+func newDevLog(mu *sync.Mutex, name string, color bool) *devLog {
+	prefix := fmt.Sprintf("%-6s │ ", name)
+	if color {
+		prefix = "\x1b[" + devComponentColors[name] + "m" + prefix + "\x1b[0m"
+	}
+	return &devLog{mu: mu, prefix: prefix}
+}
+
+func (l *devLog) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	data := append(l.partial, b...)
+	var out bytes.Buffer
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		out.WriteString(l.prefix)
+		out.Write(data[:i+1])
+		data = data[i+1:]
+	}
+	l.partial = append([]byte(nil), data...)
+	if _, err := os.Stdout.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// devStatusRecorder keeps the status of a response for the Studio's log.
+type devStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *devStatusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// tailDevEvents prints the events on the stream at wsURL until ctx is done,
+// the way 'sapliy debug listen' would.
+func tailDevEvents(ctx context.Context, wsURL string, out io.Writer) error {
+	conn, _, err := dialEventStream(ctx, wsURL, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("event stream: %w", err)
+	}
+	fmt.Fprintf(out, "✅ Listening on %s\n", wsURL)
+	err = readStream(ctx, conn, func(message []byte) error {
+		var event struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+			Data struct {
+				Object struct {
+					ID string `json:"id"`
+				} `json:"object"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(message, &event) != nil {
+			return nil
+		}
+		fmt.Fprintf(out, "[%s] %-30s  %s  %s\n", time.Now().Format("15:04:05"), event.Type, event.ID, event.Data.Object.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("event stream: %w", err)
+	}
+	return nil
+}
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Run a fully local development environment",
+	Long: `Run the mock API, a local flow runner, the Automation Studio and an event
+listener together in one terminal, with nothing sent to Sapliy.
+
+The API is the one 'sapliy mock' serves: payments, events and webhook
+endpoints behave like the real ones, and any API key is accepted. Every
+event is streamed, delivered to the mock's webhook endpoints and run
+through the flows in --manifests (*.flow.json, as written by 'sapliy
+generate flow'). http and webhook steps really send their requests, so
+local handlers can be exercised; other side effects such as email or charge
+are simulated and logged. Flows are reloaded when their files change.
+
+Each line of output is prefixed with the component it comes from: api,
+flows, studio (API calls made from the Studio that change something or
+fail) and events (everything on the event stream). Ctrl+C stops them all.
+
+Point the CLI at it with SAPLIY_API_URL=http://localhost:8080 (the default
+api_url), or use the Studio's /api proxy.`,
+	Example: `  sapliy dev
+  sapliy dev --manifests ./flows --api-port 9090
+
+  # In another terminal
+  sapliy trigger payment.created --zone local --data '{"amount": 5000}'
+  sapliy debug listen --zone local`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("manifests")
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		apiPort, _ := cmd.Flags().GetInt("api-port")
+		streamPort, _ := cmd.Flags().GetInt("stream-port")
+
+		var mu sync.Mutex
+		color := stdoutIsTerminal() && os.Getenv("NO_COLOR") == ""
+		apiLog := newDevLog(&mu, "api", color)
+		flowsLog := newDevLog(&mu, "flows", color)
+		studioLog := newDevLog(&mu, "studio", color)
+		eventsLog := newDevLog(&mu, "events", color)
+
+		runner := &localRunner{client: &http.Client{Timeout: 10 * time.Second}, out: flowsLog}
+		flows, err := loadDevFlows(dir)
+		if err != nil {
+			fmt.Printf("Error reading manifests: %v\n", err)
+			os.Exit(1)
+		}
+		runner.setFlows(flows)
+
+		mock := newMockAPI(apiLog)
+		mock.onEvent = runner.dispatch
+		// Flows, runs and the stream answer without an API key, as the
+		// stream is held open outside mock.mu.
+		api := http.NewServeMux()
+		api.HandleFunc("GET /v1/flows", func(w http.ResponseWriter, r *http.Request) {
+			writeDevJSON(w, http.StatusOK, apiPage[*localFlow]{Data: runner.loadedFlows()})
+		})
+		api.HandleFunc("GET /v1/runs", func(w http.ResponseWriter, r *http.Request) {
+			writeDevJSON(w, http.StatusOK, apiPage[flowRun]{Data: runner.recentRuns(devLimit(r))})
+		})
+		api.Handle("GET /v1/events/stream", mock.stream)
+		api.Handle("/", mock.handler())
+
+		uiFS, uiErr := studioAssets()
+		studio := http.NewServeMux()
+		// The Studio sends no API key of its own, so the proxy adds one.
+		// Successful GETs are not logged, to keep the log to what changed.
+		studio.Handle("/api/", http.StripPrefix("/api", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", "Bearer sk_test_studio")
+			}
+			rec := &devStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+			api.ServeHTTP(rec, r)
+			if r.Method != http.MethodGet || rec.status >= 400 {
+				fmt.Fprintf(studioLog, "[%s] %s %s → %d\n", time.Now().Format("15:04:05"), r.Method, r.URL.Path, rec.status)
+			}
+		})))
+		if uiErr == nil {
+			studio.Handle("/", &SPAHandler{staticFS: uiFS})
+		} else {
+			studio.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, uiErr.Error(), http.StatusNotFound)
+			})
+		}
+
+		servers := []*http.Server{
+			{Addr: net.JoinHostPort(host, strconv.Itoa(apiPort)), Handler: api},
+			{Addr: net.JoinHostPort(host, strconv.Itoa(streamPort)), Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/events/stream" {
+					http.NotFound(w, r)
+					return
+				}
+				mock.stream.ServeHTTP(w, r)
+			})},
+			{Addr: net.JoinHostPort(host, strconv.Itoa(port)), Handler: studio, ErrorLog: log.New(studioLog, "", 0)},
+		}
+		servers[0].ErrorLog = log.New(apiLog, "", 0)
+		servers[1].ErrorLog = log.New(eventsLog, "", 0)
+		var listeners []net.Listener
+		for _, s := range servers {
+			l, err := net.Listen("tcp", s.Addr)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			listeners = append(listeners, l)
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+		group := newStreamGroup(ctx)
+		err = watchManifests(ctx, dir, func() {
+			flows, err := loadDevFlows(dir)
+			if err != nil {
+				fmt.Fprintf(flowsLog, "⚠️  Not reloaded, keeping the previous flows: %v\n", err)
+				return
+			}
+			runner.setFlows(flows)
+			fmt.Fprintf(flowsLog, "🔄 Reloaded %d flow(s) from %s\n", len(flows), dir)
+		})
+		if err != nil {
+			fmt.Printf("Error watching %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+
+		fmt.Println("🧪 Sapliy dev environment (Ctrl+C to stop)")
+		fmt.Printf("   ├── Studio:       http://localhost:%d\n", port)
+		fmt.Printf("   ├── Mock API:     http://localhost:%d\n", apiPort)
+		fmt.Printf("   ├── Event stream: ws://localhost:%d/v1/events/stream\n", streamPort)
+		fmt.Printf("   └── Flows:        %d from %s (reloaded on change)\n", len(flows), dir)
+		for _, f := range flows {
+			fmt.Printf("       • %s ← %s\n", f.Name, strings.Join(f.Events, ", "))
+		}
+		fmt.Println(strings.Repeat("─", 60))
+		if uiErr != nil {
+			fmt.Fprintf(studioLog, "⚠️  %v; only the /api proxy is served\n", uiErr)
+		}
+
+		// Any server failing, or the listener losing the stream, stops
+		// the rest.
+		for i, s := range servers {
+			group.Go(func(ctx context.Context) error {
+				errs := make(chan error, 1)
+				go func() { errs <- s.Serve(listeners[i]) }()
+				select {
+				case err := <-errs:
+					return err
+				case <-ctx.Done():
+				}
+				shutdown, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+				s.Shutdown(shutdown)
+				return nil
+			})
+		}
+		dialHost := host
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			dialHost = "127.0.0.1"
+		}
+		wsURL := fmt.Sprintf("ws://%s/v1/events/stream?api_key=sk_test_dev", net.JoinHostPort(dialHost, strconv.Itoa(streamPort)))
+		group.Go(func(ctx context.Context) error { return tailDevEvents(ctx, wsURL, eventsLog) })
+
+		<-group.Done()
+		if interrupted(ctx) {
+			fmt.Println()
+		}
+		err = group.Wait()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		fmt.Println("👋 Dev environment stopped")
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.Flags().String("manifests", "./manifests", "Directory of *.flow.json files to run")
+	devCmd.Flags().String("host", "127.0.0.1", "Address to listen on (0.0.0.0 in a container)")
+	devCmd.Flags().IntP("port", "p", 3000, "Port to serve the Studio on")
+	devCmd.Flags().Int("api-port", 8080, "Port for the mock API")
+	devCmd.Flags().Int("stream-port", 8089, "Port for the event stream")
+}
@@ -0,0 +1,256 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// devEnvCompose is the docker-compose file written by 'generate dev-env'.
+// %[1]s is the CLI image, %[2]s the manifests directory relative to the
+// compose file and %[3]s the local signing secret.
+const devEnvCompose = `# Local Sapliy stack, generated by 'sapliy generate dev-env'.
+# Start it with 'sapliy dev up'; nothing here talks to Sapliy.
+name: sapliy-dev
+
+services:
+  # Mock API, local flow runner, event stream and Studio ('sapliy dev').
+  api:
+    image: %[1]s
+    command: ["dev", "--host", "0.0.0.0", "--manifests", "/manifests"]
+    ports:
+      - "3000:3000"
+      - "8080:8080"
+      - "8089:8089"
+    volumes:
+      - %[2]s:/manifests:ro
+
+  # Catch-all receiver for flow http/webhook steps: point them at
+  # http://sink:4100/... and browse with
+  # 'sapliy webhooks sink list --db sink-data/sink.db'.
+  sink:
+    image: %[1]s
+    command: ["webhooks", "sink", "--host", "0.0.0.0", "--port", "4100", "--secret", "%[3]s", "--db", "/data/sink.db"]
+    ports:
+      - "4100:4100"
+    volumes:
+      - ./sink-data:/data
+
+  # Delivers every event on the stream to the consumer, signed with the
+  # local secret, as webhook endpoints would be.
+  forwarder:
+    image: %[1]s
+    command: ["webhooks", "listen", "--forward-to", "http://consumer:4242/webhook", "--secret", "%[3]s"]
+    environment:
+      SAPLIY_API_URL: http://api:8080
+      SAPLIY_API_KEY: sk_test_local
+    depends_on:
+      - api
+      - consumer
+    restart: on-failure
+
+  # Sample webhook consumer; replace it with your own service.
+  consumer:
+    image: node:20-alpine
+    command: ["node", "/app/server.js"]
+    environment:
+      SAPLIY_WEBHOOK_SECRET: %[3]s
+    ports:
+      - "4242:4242"
+    volumes:
+      - ./consumer:/app:ro
+`
+
+// devEnvConsumer is the sample consumer: it verifies the Sapliy-Signature
+// header and logs each event.
+const devEnvConsumer = `// Sample Sapliy webhook consumer, generated by 'sapliy generate dev-env'.
+const http = require("http");
+const crypto = require("crypto");
+
+const secret = process.env.SAPLIY_WEBHOOK_SECRET || "";
+
+function verify(header, body) {
+  const parts = Object.fromEntries((header || "").split(",").map((p) => p.split("=")));
+  if (!parts.t || !parts.v1) return false;
+  const expected = crypto.createHmac("sha256", secret).update(parts.t + "." + body).digest("hex");
+  return expected.length === parts.v1.length &&
+    crypto.timingSafeEqual(Buffer.from(expected), Buffer.from(parts.v1));
+}
+
+http.createServer((req, res) => {
+  let body = "";
+  req.on("data", (chunk) => (body += chunk));
+  req.on("end", () => {
+    if (secret && !verify(req.headers["sapliy-signature"], body)) {
+      console.log("rejected: invalid signature");
+      res.writeHead(400).end();
+      return;
+    }
+    const event = JSON.parse(body || "{}");
+    console.log("received", event.type, event.id, JSON.stringify(event.data));
+    res.writeHead(200, { "Content-Type": "application/json" }).end('{"received":true}');
+  });
+}).listen(4242, () => console.log("consumer listening on :4242"));
+`
+
+var devEnvCmd = &cobra.Command{
+	Use:   "dev-env",
+	Short: "Generate a docker-compose stack for local development",
+	Long: `Generate a docker-compose file that wires up a complete local stack: the
+mock API, flow runner and Studio ('sapliy dev'), the local webhook sink, and a
+sample consumer that receives every event signed, as a real endpoint would.
+Start and stop it with 'sapliy dev up' and 'sapliy dev down'.`,
+	Example: `  sapliy generate dev-env
+  sapliy dev up`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		manifests, _ := cmd.Flags().GetString("manifests")
+		image, _ := cmd.Flags().GetString("image")
+		force, _ := cmd.Flags().GetBool("force")
+
+		secret, err := newForwardSecret()
+		if err != nil {
+			fmt.Printf("Error generating signing secret: %v\n", err)
+			os.Exit(1)
+		}
+		absManifests, err := filepath.Abs(manifests)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		rel, err := filepath.Rel(absDir, absManifests)
+		if err != nil {
+			rel = absManifests
+		}
+		if !filepath.IsAbs(rel) && !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+
+		if err := os.MkdirAll(filepath.Join(dir, "consumer"), 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		files := []struct {
+			path string
+			data string
+		}{
+			{filepath.Join(dir, "docker-compose.yml"), fmt.Sprintf(devEnvCompose, image, filepath.ToSlash(rel), secret)},
+			{filepath.Join(dir, "consumer", "server.js"), devEnvConsumer},
+		}
+		for _, f := range files {
+			if err := writeNewFile(f.path, []byte(f.data), force); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ Generated %s\n", f.path)
+		}
+		if _, err := os.Stat(manifests); os.IsNotExist(err) {
+			fmt.Printf("⚠️  %s does not exist yet; add flows with 'sapliy generate flow'.\n", manifests)
+		}
+		fmt.Printf("\nStart the stack with: sapliy dev up --dir %s\n", dir)
+	},
+}
+
+// composeCommand returns the docker compose invocation available: the
+// compose plugin, or the older docker-compose binary.
+func composeCommand() ([]string, error) {
+	if err := exec.Command("docker", "compose", "version").Run(); err == nil {
+		return []string{"docker", "compose"}, nil
+	}
+	if _, err := exec.LookPath("docker-compose"); err == nil {
+		return []string{"docker-compose"}, nil
+	}
+	return nil, fmt.Errorf("docker compose is not installed (see https://docs.docker.com/compose/install/)")
+}
+
+// runCompose runs docker compose against the stack generated in the
+// command's --dir.
+func runCompose(cmd *cobra.Command, args ...string) {
+	dir, _ := cmd.Flags().GetString("dir")
+	if _, err := os.Stat(filepath.Join(dir, "docker-compose.yml")); err != nil {
+		fmt.Printf("Error: no docker-compose.yml in %s. Create one with 'sapliy generate dev-env --dir %s'.\n", dir, dir)
+		os.Exit(1)
+	}
+	compose, err := composeCommand()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	argv := append(compose, args...)
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Dir = dir
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			os.Exit(exit.ExitCode())
+		}
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var devUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Start the generated local stack",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runCompose(cmd, "up", "-d")
+		fmt.Println("\n🧪 Local stack is up")
+		fmt.Println("   ├── Studio:   http://localhost:3000")
+		fmt.Println("   ├── Mock API: http://localhost:8080")
+		fmt.Println("   ├── Sink:     http://localhost:4100")
+		fmt.Println("   └── Consumer: http://localhost:4242")
+		fmt.Println("\nTrigger events with: sapliy trigger payment.created --zone local")
+	},
+}
+
+var devDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop the generated local stack",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runCompose(cmd, "down")
+	},
+}
+
+var devLogsCmd = &cobra.Command{
+	Use:     "logs [service...]",
+	Short:   "Show logs of the generated local stack",
+	Example: `  sapliy dev logs consumer --follow`,
+	Run: func(cmd *cobra.Command, args []string) {
+		composeArgs := []string{"logs"}
+		if follow, _ := cmd.Flags().GetBool("follow"); follow {
+			composeArgs = append(composeArgs, "--follow")
+		}
+		runCompose(cmd, append(composeArgs, args...)...)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(devEnvCmd)
+	devCmd.AddCommand(devUpCmd)
+	devCmd.AddCommand(devDownCmd)
+	devCmd.AddCommand(devLogsCmd)
+
+	devEnvCmd.Flags().String("dir", "sapliy-dev", "Directory to write the stack to")
+	devEnvCmd.Flags().String("manifests", "./manifests", "Flow manifests to mount into the flow runner")
+	devEnvCmd.Flags().String("image", "ghcr.io/sapliy/sapliy-cli:latest", "Sapliy CLI image to run the services with")
+	devEnvCmd.Flags().BoolP("force", "f", false, "Overwrite existing files")
+
+	for _, c := range []*cobra.Command{devUpCmd, devDownCmd, devLogsCmd} {
+		c.Flags().String("dir", "sapliy-dev", "Directory of the generated stack")
+	}
+	devLogsCmd.Flags().BoolP("follow", "f", false, "Keep streaming new log lines")
+}
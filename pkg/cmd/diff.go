@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// fieldChange is one difference between a deployed resource and its local
+// file. Path names the field, with list items that have an "id" written as
+// steps[charge]. Op is add, remove, change or reorder.
+type fieldChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// resourceDiff is what deploying a file would change: Action as for deploy
+// (created, updated or unchanged), and for updates the changes.
+type resourceDiff struct {
+	*deployResource
+	Changes []fieldChange `json:"changes,omitempty"`
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// itemIDs returns the "id" of each item of list, or nil unless every item
+// is an object with one, as steps are.
+func itemIDs(list []interface{}) []string {
+	ids := make([]string, 0, len(list))
+	for _, item := range list {
+		id := ""
+		if m, ok := item.(map[string]interface{}); ok {
+			id = stepString(m, "id")
+		}
+		if id == "" {
+			return nil
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// diffValues appends the changes that turn from into to. Objects are
+// compared field by field and lists of objects with IDs item by item, so a
+// changed step shows as the fields that changed in it; other values that
+// differ are one change.
+func diffValues(path string, from, to interface{}, changes *[]fieldChange) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	fromMap, ok1 := from.(map[string]interface{})
+	toMap, ok2 := to.(map[string]interface{})
+	if ok1 && ok2 {
+		keys := slices.Collect(maps.Keys(fromMap))
+		for k := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			f, inFrom := fromMap[k]
+			t, inTo := toMap[k]
+			switch {
+			case !inFrom:
+				*changes = append(*changes, fieldChange{Path: joinPath(path, k), Op: "add", To: t})
+			case !inTo:
+				*changes = append(*changes, fieldChange{Path: joinPath(path, k), Op: "remove", From: f})
+			default:
+				diffValues(joinPath(path, k), f, t, changes)
+			}
+		}
+		return
+	}
+
+	fromList, ok1 := from.([]interface{})
+	toList, ok2 := to.([]interface{})
+	fromIDs, toIDs := itemIDs(fromList), itemIDs(toList)
+	if !ok1 || !ok2 || fromIDs == nil || toIDs == nil {
+		*changes = append(*changes, fieldChange{Path: path, Op: "change", From: from, To: to})
+		return
+	}
+	var kept, keptBefore []string
+	for i, id := range toIDs {
+		item := path + "[" + id + "]"
+		if j := slices.Index(fromIDs, id); j < 0 {
+			*changes = append(*changes, fieldChange{Path: item, Op: "add", To: toList[i]})
+		} else {
+			kept = append(kept, id)
+			diffValues(item, fromList[j], toList[i], changes)
+		}
+	}
+	for j, id := range fromIDs {
+		if !slices.Contains(toIDs, id) {
+			*changes = append(*changes, fieldChange{Path: path + "[" + id + "]", Op: "remove", From: fromList[j]})
+		} else {
+			keptBefore = append(keptBefore, id)
+		}
+	}
+	if !slices.Equal(kept, keptBefore) {
+		*changes = append(*changes, fieldChange{Path: path, Op: "reorder", From: keptBefore, To: kept})
+	}
+}
+
+// diffResource compares a local file with what is deployed. Fields the API
+// sets are left out, as 'sapliy pull' leaves them out of files.
+func diffResource(ctx context.Context, r *deployResource) (*resourceDiff, error) {
+	remote, err := fetchResource(ctx, resourcePath(r))
+	if err != nil {
+		return nil, err
+	}
+	d := &resourceDiff{deployResource: r}
+	switch {
+	case remote == nil:
+		r.Action = "created"
+		return d, nil
+	case sameFields(r.doc, remote):
+		r.Action = "unchanged"
+		return d, nil
+	}
+	r.Action = "updated"
+	local := maps.Clone(r.doc)
+	for _, field := range pulledFields[r.Kind] {
+		delete(local, field)
+		delete(remote, field)
+	}
+	diffValues("", remote, local, &d.Changes)
+	return d, nil
+}
+
+// diffColors are the ANSI colors for each kind of change.
+var diffColors = map[string]string{"add": "32", "remove": "31", "change": "33", "reorder": "33"}
+
+func diffValue(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return truncate(string(b), 60)
+}
+
+func printChange(c fieldChange, color bool) {
+	var line string
+	switch c.Op {
+	case "add":
+		line = fmt.Sprintf("+ %s: %s", c.Path, diffValue(c.To))
+	case "remove":
+		line = fmt.Sprintf("- %s: %s", c.Path, diffValue(c.From))
+	case "reorder":
+		line = fmt.Sprintf("~ %s: order %s → %s", c.Path, strings.Join(c.From.([]string), ", "), strings.Join(c.To.([]string), ", "))
+	default:
+		line = fmt.Sprintf("~ %s: %s → %s", c.Path, diffValue(c.From), diffValue(c.To))
+	}
+	if color {
+		line = "\x1b[" + diffColors[c.Op] + "m" + line + "\x1b[0m"
+	}
+	fmt.Println("    " + line)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what deploy would change",
+	Long: `Compare local zone and flow files with what is deployed and print what
+'sapliy deploy' with the same arguments would change: new resources, and for
+changed ones every field and step that would be added, removed or changed.
+Steps are matched by "id", so an edited step shows as the fields that
+changed in it rather than as a removed and an added step.
+
+Output is colored on a terminal unless NO_COLOR is set. With --exit-code,
+diff exits 1 when anything would change, as git diff does.`,
+	Example: `  sapliy diff -f checkout.flow.json
+  sapliy diff -f infra/ --exit-code
+  sapliy diff -f checkout.flow.json -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+		paths, _ := cmd.Flags().GetStringArray("file")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+		if len(paths) == 0 {
+			fmt.Println("Error: pass the files or directories to compare with -f.")
+			os.Exit(1)
+		}
+
+		files, err := deployFiles(paths)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		var zones, flows []*deployResource
+		for _, file := range files {
+			r, err := readResource(file)
+			if err == nil && r.ID == "" {
+				err = fmt.Errorf(`missing "id"`)
+			}
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if r.Kind == "zone" {
+				zones = append(zones, r)
+			} else {
+				flows = append(flows, r)
+			}
+		}
+		for _, f := range flows {
+			if f.Zone = flowZone(f, zones, resolveZone(cmd)); f.Zone == "" {
+				fmt.Printf("❌ %s: no zone to compare with; add a zone file, set \"zoneId\", or pass --zone\n", f.File)
+				os.Exit(1)
+			}
+		}
+
+		ctx := context.Background()
+		var diffs []*resourceDiff
+		changed := false
+		for _, r := range append(zones, flows...) {
+			d, err := diffResource(ctx, r)
+			if err != nil {
+				fmt.Printf("❌ %s %s (%s): %v\n", r.Kind, r.ID, r.File, err)
+				os.Exit(1)
+			}
+			diffs = append(diffs, d)
+			changed = changed || d.Action != "unchanged"
+		}
+
+		color := stdoutIsTerminal() && os.Getenv("NO_COLOR") == ""
+		printOutput(diffs, func() {
+			for _, d := range diffs {
+				where := d.File
+				if d.Kind == "flow" {
+					where += " → " + d.Zone
+				}
+				switch d.Action {
+				case "unchanged":
+					fmt.Printf("✓  %s %s (%s): unchanged\n", d.Kind, d.ID, where)
+				case "created":
+					fmt.Printf("➕ %s %s (%s): would be created\n", d.Kind, d.ID, where)
+				default:
+					fmt.Printf("🔄 %s %s (%s): %d change(s)\n", d.Kind, d.ID, where, len(d.Changes))
+					for _, c := range d.Changes {
+						printChange(c, color)
+					}
+				}
+			}
+		})
+		if exitCode && changed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringArrayP("file", "f", nil, "Zone or flow file, or a directory of them (repeatable)")
+	diffCmd.Flags().StringP("zone", "z", "", "Zone for flows without a zone file or \"zoneId\" (default: current zone)")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with status 1 if deploy would change anything")
+}
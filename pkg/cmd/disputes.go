@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// disputeStatuses are the statuses disputes list --status accepts.
+var disputeStatuses = []string{"warning_needs_response", "needs_response", "under_review", "won", "lost"}
+
+// evidenceFileFlags maps submit-evidence file flags to evidence fields. Each
+// file is uploaded first and the field is set to its file ID.
+var evidenceFileFlags = map[string]string{
+	"receipt":                "receipt",
+	"shipping-documentation": "shippingDocumentation",
+	"customer-communication": "customerCommunication",
+	"refund-policy":          "refundPolicy",
+	"service-documentation":  "serviceDocumentation",
+	"uncategorized-file":     "uncategorizedFile",
+}
+
+// evidenceTextFlags maps submit-evidence text flags to evidence fields.
+var evidenceTextFlags = map[string]string{
+	"product-description":      "productDescription",
+	"customer-email":           "customerEmailAddress",
+	"shipping-carrier":         "shippingCarrier",
+	"shipping-tracking-number": "shippingTrackingNumber",
+	"explanation":              "uncategorizedText",
+}
+
+// dispute is a chargeback the customer's bank opened against a payment.
+// Evidence can be changed until it is submitted or EvidenceDueBy passes.
+type dispute struct {
+	ID            string                 `json:"id"`
+	Payment       string                 `json:"payment"`
+	Amount        int64                  `json:"amount"`
+	Currency      string                 `json:"currency"`
+	Reason        string                 `json:"reason"`
+	Status        string                 `json:"status"`
+	EvidenceDueBy *time.Time             `json:"evidenceDueBy,omitempty"`
+	Evidence      map[string]interface{} `json:"evidence,omitempty"`
+	CreatedAt     *time.Time             `json:"createdAt,omitempty"`
+}
+
+func disputePath(disputeID, suffix string) string {
+	return "/v1/disputes/" + url.PathEscape(disputeID) + suffix
+}
+
+// awaitingEvidence reports whether the dispute still accepts evidence.
+func (d *dispute) awaitingEvidence() bool {
+	return d.Status == "needs_response" || d.Status == "warning_needs_response"
+}
+
+func printDispute(d *dispute) {
+	fmt.Printf("⚖️  Dispute %s — %s\n", d.ID, d.Status)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Payment:       %s\n", d.Payment)
+	fmt.Printf("Amount:        %s %s\n", formatAmount(d.Amount, d.Currency), strings.ToUpper(d.Currency))
+	fmt.Printf("Reason:        %s\n", d.Reason)
+	if d.EvidenceDueBy != nil {
+		due := d.EvidenceDueBy.Local().Format(time.RFC1123)
+		if d.awaitingEvidence() {
+			due += fmt.Sprintf(" (in %s)", time.Until(*d.EvidenceDueBy).Round(time.Hour))
+		}
+		fmt.Printf("Evidence due:  %s\n", due)
+	}
+	if d.CreatedAt != nil {
+		fmt.Printf("Opened:        %s\n", d.CreatedAt.Local().Format(time.RFC1123))
+	}
+
+	keys := make([]string, 0, len(d.Evidence))
+	for k, v := range d.Evidence {
+		if v != nil && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		fmt.Println("Evidence:")
+		for _, k := range keys {
+			fmt.Printf("  %-24s %s\n", k, truncate(fmt.Sprint(d.Evidence[k]), 50))
+		}
+	}
+}
+
+var disputesCmd = &cobra.Command{
+	Use:   "disputes",
+	Short: "Review disputes and respond with evidence",
+}
+
+var disputesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List disputes",
+	Example: `  sapliy disputes list --status needs_response
+  sapliy disputes list --payment pi_123 -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		payment, _ := cmd.Flags().GetString("payment")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "" && !slices.Contains(disputeStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(disputeStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		if status != "" {
+			q.Set("status", status)
+		}
+		if payment != "" {
+			q.Set("payment", payment)
+		}
+		disputes, cursor, err := listPages[dispute](context.Background(), "/v1/disputes", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing disputes: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(disputes, func() {
+			if len(disputes) == 0 {
+				fmt.Println("No disputes found.")
+				return
+			}
+
+			fmt.Printf("%-24s %-24s %12s %-4s %-22s %-22s %s\n", "ID", "PAYMENT", "AMOUNT", "", "REASON", "STATUS", "EVIDENCE DUE")
+			fmt.Println(strings.Repeat("─", 120))
+			for _, d := range disputes {
+				due := ""
+				if d.EvidenceDueBy != nil && d.awaitingEvidence() {
+					due = d.EvidenceDueBy.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Printf("%-24s %-24s %12s %-4s %-22s %-22s %s\n",
+					d.ID, d.Payment, formatAmount(d.Amount, d.Currency), strings.ToUpper(d.Currency), truncate(d.Reason, 22), d.Status, due)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore disputes available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var disputesGetCmd = &cobra.Command{
+	Use:   "get [dispute_id]",
+	Short: "Show a dispute and the evidence attached so far",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var d dispute
+		if err := apiRequest(context.Background(), http.MethodGet, disputePath(args[0], ""), nil, &d); err != nil {
+			fmt.Printf("❌ Failed to fetch dispute: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(d, func() {
+			printDispute(&d)
+			printFileRefs(context.Background(), d.Evidence)
+		})
+	},
+}
+
+var disputesSubmitEvidenceCmd = &cobra.Command{
+	Use:   "submit-evidence [dispute_id]",
+	Short: "Attach evidence to a dispute and submit it",
+	Long: `Attach evidence to a dispute and submit it to the bank. Files given with
+--receipt, --shipping-documentation and the other file flags are uploaded
+from local paths first. Evidence can only be submitted once; use --draft to
+save it and keep editing, then run again without --draft to submit.`,
+	Example: `  sapliy disputes submit-evidence dp_123 --receipt ./receipt.pdf --shipping-documentation ./pod.pdf \
+    --shipping-carrier UPS --shipping-tracking-number 1Z999AA10123456784 --draft
+  sapliy disputes submit-evidence dp_123 --explanation @response.txt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		draft, _ := flags.GetBool("draft")
+		evidence := map[string]interface{}{}
+		files := map[string]string{}
+		for flag, field := range evidenceFileFlags {
+			path, _ := flags.GetString(flag)
+			if path == "" {
+				continue
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				fmt.Printf("Error: --%s: %v\n", flag, err)
+				os.Exit(1)
+			}
+			if fi.IsDir() {
+				fmt.Printf("Error: --%s: %s is a directory\n", flag, path)
+				os.Exit(1)
+			}
+			files[field] = path
+		}
+		for flag, field := range evidenceTextFlags {
+			value, _ := flags.GetString(flag)
+			if value == "" {
+				continue
+			}
+			raw, err := readArgValue(value)
+			if err != nil {
+				fmt.Printf("Error: --%s: %v\n", flag, err)
+				os.Exit(1)
+			}
+			evidence[field] = strings.TrimSpace(string(raw))
+		}
+		if len(files) == 0 && len(evidence) == 0 && draft {
+			fmt.Println("Nothing to save. Pass evidence files or text; see --help.")
+			return
+		}
+
+		ctx := context.Background()
+		var d dispute
+		if err := apiRequest(ctx, http.MethodGet, disputePath(args[0], ""), nil, &d); err != nil {
+			fmt.Printf("❌ Failed to fetch dispute: %v\n", err)
+			os.Exit(1)
+		}
+		if !d.awaitingEvidence() {
+			fmt.Printf("Error: dispute %s is %s and no longer accepts evidence.\n", d.ID, d.Status)
+			os.Exit(1)
+		}
+		if !draft {
+			if force, _ := flags.GetBool("force"); !force {
+				prompt := fmt.Sprintf("Submit evidence for dispute %s (%s %s)? It cannot be changed afterwards. [y/N]: ",
+					d.ID, formatAmount(d.Amount, d.Currency), strings.ToUpper(d.Currency))
+				if !confirm(prompt) {
+					fmt.Println("Cancelled. Use --draft to save the evidence without submitting.")
+					return
+				}
+			}
+		}
+
+		fields := make([]string, 0, len(files))
+		for field := range files {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			f, err := apiUpload(ctx, "dispute_evidence", files[field])
+			if err != nil {
+				fmt.Printf("❌ Failed to upload %s: %v\n", files[field], err)
+				os.Exit(1)
+			}
+			if !structuredOutput() {
+				fmt.Printf("📎 Uploaded %s → %s (%d KB)\n", files[field], f.ID, (f.Size+1023)/1024)
+			}
+			evidence[field] = f.ID
+		}
+
+		req := map[string]interface{}{"evidence": evidence, "submit": !draft}
+		var updated dispute
+		if err := apiRequest(ctx, http.MethodPost, disputePath(args[0], "/evidence"), req, &updated); err != nil {
+			fmt.Printf("❌ Failed to save evidence: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(updated, func() {
+			printDispute(&updated)
+			if draft {
+				fmt.Println("\n💾 Evidence saved. Run again without --draft to submit it.")
+			} else {
+				fmt.Println("\n✅ Evidence submitted!")
+			}
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disputesCmd)
+	disputesCmd.AddCommand(disputesListCmd)
+	disputesCmd.AddCommand(disputesGetCmd)
+	disputesCmd.AddCommand(disputesSubmitEvidenceCmd)
+
+	disputesListCmd.Flags().IntP("limit", "l", 20, "Number of disputes per page")
+	disputesListCmd.Flags().StringP("status", "s", "", "Filter by status ("+strings.Join(disputeStatuses, ", ")+")")
+	disputesListCmd.Flags().String("payment", "", "Only disputes of this payment")
+	disputesListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	disputesListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	f := disputesSubmitEvidenceCmd.Flags()
+	f.String("receipt", "", "Receipt or invoice the customer was given (file)")
+	f.String("shipping-documentation", "", "Proof of shipment or delivery (file)")
+	f.String("customer-communication", "", "Emails or chats with the customer (file)")
+	f.String("refund-policy", "", "Refund policy shown to the customer (file)")
+	f.String("service-documentation", "", "Proof the service was provided (file)")
+	f.String("uncategorized-file", "", "Any other supporting document (file)")
+	f.String("product-description", "", "What was sold")
+	f.String("customer-email", "", "Customer's email address")
+	f.String("shipping-carrier", "", "Carrier that delivered the order")
+	f.String("shipping-tracking-number", "", "Tracking number of the shipment")
+	f.String("explanation", "", "Your response to the dispute, or @file")
+	f.Bool("draft", false, "Save the evidence without submitting it")
+	f.BoolP("force", "f", false, "Skip confirmation prompt")
+}
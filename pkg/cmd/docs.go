@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// completionFiles are the completion scripts 'docs completions' writes, by
+// shell, named as each shell looks them up.
+var completionFiles = []struct {
+	shell string
+	name  func(bin string) string
+	gen   func(root *cobra.Command, w io.Writer) error
+}{
+	{"bash", func(bin string) string { return bin + ".bash" }, func(root *cobra.Command, w io.Writer) error { return root.GenBashCompletionV2(w, true) }},
+	{"zsh", func(bin string) string { return "_" + bin }, func(root *cobra.Command, w io.Writer) error { return root.GenZshCompletion(w) }},
+	{"fish", func(bin string) string { return bin + ".fish" }, func(root *cobra.Command, w io.Writer) error { return root.GenFishCompletion(w, true) }},
+	{"powershell", func(bin string) string { return bin + ".ps1" }, func(root *cobra.Command, w io.Writer) error { return root.GenPowerShellCompletionWithDesc(w) }},
+}
+
+// writeCompletions writes every shell's completion script to dir and
+// returns the files written, by shell.
+func writeCompletions(root *cobra.Command, dir string) (map[string]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	written := map[string]string{}
+	for _, c := range completionFiles {
+		var buf bytes.Buffer
+		if err := c.gen(root, &buf); err != nil {
+			return nil, fmt.Errorf("%s completion: %w", c.shell, err)
+		}
+		path := filepath.Join(dir, c.name(root.Name()))
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		written[c.shell] = path
+	}
+	return written, nil
+}
+
+// buildDate is the date man pages carry: $SOURCE_DATE_EPOCH when set, so
+// package builds are reproducible, else today.
+func buildDate() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	return time.Now().UTC()
+}
+
+// documentedCommands lists cmd and its subcommands, leaving out hidden ones
+// and help topics.
+func documentedCommands(cmd *cobra.Command) []*cobra.Command {
+	cmds := []*cobra.Command{cmd}
+	for _, c := range cmd.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		cmds = append(cmds, documentedCommands(c)...)
+	}
+	return cmds
+}
+
+func manName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+var roffEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+var roffLineStart = regexp.MustCompile(`(?m)^([.'])`)
+
+// roff escapes text for a man page: backslashes and hyphens, and a leading
+// dot or quote, which roff would read as a request.
+func roff(text string) string {
+	return roffLineStart.ReplaceAllString(roffEscaper.Replace(text), `\&$1`)
+}
+
+// roffText writes help text as man page paragraphs. Indented blocks, such
+// as lists and config examples in Long texts, are kept as they are.
+func roffText(buf *bytes.Buffer, text string) {
+	for i, block := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			buf.WriteString(".PP\n")
+		}
+		block = roff(strings.TrimRight(block, " \n"))
+		if strings.HasPrefix(block, "  ") {
+			block = ".RS\n.nf\n" + block + "\n.fi\n.RE"
+		}
+		buf.WriteString(block + "\n")
+	}
+}
+
+func roffFlags(buf *bytes.Buffer, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		buf.WriteString(".TP\n")
+		if f.Shorthand != "" {
+			fmt.Fprintf(buf, `\fB\-%s\fP, `, f.Shorthand)
+		}
+		fmt.Fprintf(buf, `\fB\-\-%s\fP`, roff(f.Name))
+		name, usage := pflag.UnquoteUsage(f)
+		if name != "" {
+			fmt.Fprintf(buf, ` \fI%s\fP`, roff(name))
+		}
+		buf.WriteString("\n" + roff(usage))
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "[]" && f.DefValue != "0" {
+			fmt.Fprintf(buf, " (default %s)", roff(f.DefValue))
+		}
+		buf.WriteString("\n")
+	})
+}
+
+// manPage renders the man page of one command, in section 1.
+func manPage(cmd *cobra.Command, date time.Time) []byte {
+	var buf bytes.Buffer
+	version := cmd.Root().Version
+	if version == "" {
+		version = "dev"
+	}
+	fmt.Fprintf(&buf, ".TH %q \"1\" %q %q \"Sapliy Manual\"\n", strings.ToUpper(manName(cmd)), date.Format("Jan 2006"), "Sapliy CLI "+version)
+	fmt.Fprintf(&buf, ".SH NAME\n%s \\- %s\n", roff(manName(cmd)), roff(cmd.Short))
+	fmt.Fprintf(&buf, ".SH SYNOPSIS\n\\fB%s\\fP\n", roff(cmd.UseLine()))
+
+	buf.WriteString(".SH DESCRIPTION\n")
+	if cmd.Long != "" {
+		roffText(&buf, cmd.Long)
+	} else {
+		roffText(&buf, cmd.Short)
+	}
+	if cmd.HasAvailableLocalFlags() {
+		buf.WriteString(".SH OPTIONS\n")
+		roffFlags(&buf, cmd.NonInheritedFlags())
+	}
+	if cmd.HasAvailableInheritedFlags() {
+		buf.WriteString(".SH OPTIONS INHERITED FROM PARENT COMMANDS\n")
+		roffFlags(&buf, cmd.InheritedFlags())
+	}
+	if cmd.Example != "" {
+		buf.WriteString(".SH EXAMPLE\n.PP\n.RS\n.nf\n")
+		buf.WriteString(roff(strings.TrimRight(cmd.Example, "\n")) + "\n")
+		buf.WriteString(".fi\n.RE\n")
+	}
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, manName(cmd.Parent()))
+	}
+	for _, c := range documentedCommands(cmd)[1:] {
+		if c.Parent() == cmd {
+			related = append(related, manName(c))
+		}
+	}
+	if len(related) > 0 {
+		buf.WriteString(".SH SEE ALSO\n")
+		for i, name := range related {
+			sep := ",\n"
+			if i == len(related)-1 {
+				sep = "\n"
+			}
+			fmt.Fprintf(&buf, "\\fB%s\\fP(1)%s", roff(name), sep)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeManPages writes a man page for root and every command under it to
+// dir and returns the files written.
+func writeManPages(root *cobra.Command, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	date := buildDate()
+	var written []string
+	for _, c := range documentedCommands(root) {
+		path := filepath.Join(dir, manName(c)+".1")
+		if err := os.WriteFile(path, manPage(c, date), 0644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate man pages and shell completion scripts",
+	Long: `Generate documentation from the commands and flags of this binary, so it
+always matches what the binary accepts. 'sapliy packaging bundle' writes both
+together with the other files packages install.`,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Write a man page for every command",
+	Long: `Write a section 1 man page for every command, named as man looks them up
+(sapliy-zones-list.1 for 'sapliy zones list'). Pages are dated
+$SOURCE_DATE_EPOCH when it is set, for reproducible builds.`,
+	Example: `  sapliy docs man --dir ./man/man1
+  MANPATH=./man man sapliy-deploy`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		written, err := writeManPages(cmd.Root(), dir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Wrote %d man pages to %s\n", len(written), dir)
+	},
+}
+
+var docsCompletionsCmd = &cobra.Command{
+	Use:   "completions",
+	Short: "Write completion scripts for every supported shell",
+	Long: `Write completion scripts for bash, zsh, fish and PowerShell to one
+directory, named as each shell's completion directory expects them. For a
+single shell, 'sapliy completion <shell>' prints the same script.`,
+	Example: `  sapliy docs completions --dir ./completions`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		written, err := writeCompletions(cmd.Root(), dir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, c := range completionFiles {
+			fmt.Printf("✅ %-10s %s\n", c.shell, written[c.shell])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd)
+	docsCmd.AddCommand(docsCompletionsCmd)
+	docsManCmd.Flags().String("dir", "man", "Directory to write the man pages to")
+	docsCompletionsCmd.Flags().String("dir", "completions", "Directory to write the completion scripts to")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultAPIURL is used when api_url is not configured.
+const defaultAPIURL = "http://localhost:8080"
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check your CLI setup for common problems",
+	Long: `Run a series of checks against your configuration, credentials, API
+connectivity and local clock, and report anything that will cause commands
+or webhook signature verification to fail.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		pass := func(format string, a ...interface{}) { fmt.Printf("✅ "+format+"\n", a...) }
+		warn := func(format string, a ...interface{}) { fmt.Printf("⚠️  "+format+"\n", a...) }
+		fail := func(format string, a ...interface{}) {
+			failed = true
+			fmt.Printf("❌ "+format+"\n", a...)
+		}
+
+		fmt.Println("🩺 Sapliy Doctor")
+		fmt.Println(strings.Repeat("─", 60))
+
+		if f := viper.ConfigFileUsed(); f != "" {
+			pass("Config file: %s", f)
+		} else {
+			warn("No config file found. Run 'sapliy auth login' to create one.")
+		}
+
+		if viper.GetString("api_key") != "" {
+			pass("API key is set")
+		} else {
+			fail("API key not set. Use 'sapliy auth login' or SAPLIY_API_KEY.")
+		}
+
+		if zone := viper.GetString("current_zone"); zone != "" {
+			pass("Current zone: %s", zone)
+		} else {
+			warn("No current zone. Use 'sapliy zones use <id>'.")
+		}
+
+		baseURL := apiBaseURL()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+		if err != nil {
+			fail("Invalid API URL %q: %v", baseURL, err)
+			os.Exit(1)
+		}
+
+		if proxyURL, err := apiProxy(req); err != nil {
+			fail("Proxy: %v", err)
+		} else if proxyURL != nil {
+			pass("Connecting through proxy %s", proxyURL.Redacted())
+		}
+		if path := viper.GetString("ca_cert"); path != "" {
+			if _, err := apiTLSConfig(); err != nil {
+				fail("%v", err)
+			} else {
+				pass("Trusting extra root CAs from %s", path)
+			}
+		}
+
+		start := time.Now()
+		client := &http.Client{Transport: &tlsHintTransport{base: apiTransport()}}
+		resp, err := client.Do(req)
+		var intercepted *interceptedTLSError
+		if errors.As(err, &intercepted) {
+			fail("TLS to %s is intercepted: the certificate was issued by %s, not a trusted CA.", intercepted.Host, intercepted.issuer())
+			fmt.Println("   A proxy or security product on your network is re-signing HTTPS traffic.")
+			fmt.Println("   Export its root CA as PEM and set ca_cert (or SAPLIY_CA_CERT) to that file.")
+		} else if err != nil {
+			fail("Cannot reach API at %s: %v", baseURL, err)
+		} else {
+			resp.Body.Close()
+			pass("API reachable at %s (%s)", baseURL, time.Since(start).Round(time.Millisecond))
+
+			if skew, ok := responseClockSkew(resp); !ok {
+				warn("Server did not send a Date header; clock skew could not be checked")
+			} else if exceedsTolerance(skew) {
+				fail("Local clock is %s (tolerance %s). Webhook signatures will fail to verify; sync your system clock (e.g. NTP).", formatSkew(skew), signatureTolerance)
+			} else {
+				pass("Clock skew %s (tolerance %s)", skew, signatureTolerance)
+			}
+		}
+
+		// With several API hosts, check the fallbacks too so a broken one is
+		// found before an incident needs it.
+		if hosts := apiHosts(); len(hosts) > 1 {
+			for _, host := range hosts {
+				if host == baseURL {
+					continue
+				}
+				hostReq, err := http.NewRequestWithContext(ctx, http.MethodHead, host, nil)
+				if err != nil {
+					fail("Invalid fallback API URL %q: %v", host, err)
+					continue
+				}
+				start := time.Now()
+				resp, err := client.Do(hostReq)
+				if err != nil {
+					warn("Fallback API host %s is unreachable: %v", host, err)
+					continue
+				}
+				resp.Body.Close()
+				apiHealth.markUp(host)
+				pass("Fallback API host %s reachable (%s)", host, time.Since(start).Round(time.Millisecond))
+			}
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		if failed {
+			fmt.Println("Some checks failed.")
+			os.Exit(1)
+		}
+		fmt.Println("All checks passed.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
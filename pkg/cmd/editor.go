@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// editorHelp heads every file opened by editJSON. Lines starting with //
+// are stripped before the JSON is parsed.
+const editorHelp = `// Edit the JSON below and save to continue. Lines starting with // are
+// ignored; save an empty file to cancel.
+`
+
+// editorCommand is the user's editor, from $VISUAL or $EDITOR. It may
+// include arguments, e.g. "code --wait".
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// stripEditorComments drops the // lines editJSON adds.
+func stripEditorComments(b []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("//")) {
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
+
+// jsonErrorPosition adds the line and column to a JSON syntax error,
+// counted from the first line of JSON below the comments.
+func jsonErrorPosition(b []byte, err error) error {
+	var syntax *json.SyntaxError
+	if !errors.As(err, &syntax) {
+		return err
+	}
+	before := b[:min(int(syntax.Offset), len(b))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Errorf("JSON line %d, column %d: %v", line, col, err)
+}
+
+// editJSON opens initial in the user's editor and returns the saved JSON
+// object once it parses and passes validate (which may be nil). Invalid
+// input is re-opened with the error written above it. It returns nil if
+// the user saved an empty file.
+func editJSON(name string, initial []byte, validate func(map[string]interface{}) error) (map[string]interface{}, error) {
+	f, err := os.CreateTemp("", "sapliy-*-"+name+".json")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	content := initial
+	var problem error
+	for {
+		var buf bytes.Buffer
+		buf.WriteString(editorHelp)
+		if problem != nil {
+			buf.WriteString("//\n")
+			for _, line := range strings.Split(problem.Error(), "\n") {
+				buf.WriteString("// ERROR: " + line + "\n")
+			}
+		}
+		buf.WriteString("\n")
+		buf.Write(content)
+		if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+			return nil, err
+		}
+
+		argv := append(editorCommand(), path)
+		editor := exec.Command(argv[0], argv[1:]...)
+		editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, terminalStdout(), os.Stderr
+		if err := editor.Run(); err != nil {
+			return nil, fmt.Errorf("run editor %s: %w", argv[0], err)
+		}
+
+		saved, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content = bytes.TrimSpace(stripEditorComments(saved))
+		if len(content) == 0 {
+			return nil, nil
+		}
+
+		var obj map[string]interface{}
+		problem = nil
+		if err := json.Unmarshal(content, &obj); err != nil {
+			problem = jsonErrorPosition(content, err)
+		} else if obj == nil {
+			problem = fmt.Errorf("expected a JSON object")
+		} else if validate != nil {
+			problem = validate(obj)
+		}
+		if problem == nil {
+			return obj, nil
+		}
+		content = append(content, '\n')
+	}
+}
@@ -0,0 +1,653 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var webhooksEndpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "Manage webhook endpoints",
+}
+
+// webhookEndpoint is a destination webhooks are delivered to. Events lists
+// the event types it is subscribed to; "*" subscribes to all of them.
+type webhookEndpoint struct {
+	ID          string     `json:"id,omitempty"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Events      []string   `json:"events"`
+	Enabled     bool       `json:"enabled"`
+	Secret      string     `json:"secret,omitempty"`
+	ZoneID      string     `json:"zoneId,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+// maskSecret hides all but the last four characters of a signing secret.
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("•", len(secret))
+	}
+	prefix := ""
+	if i := strings.Index(secret, "_"); i >= 0 && i < len(secret)-4 {
+		prefix = secret[:i+1]
+	}
+	return prefix + "••••" + secret[len(secret)-4:]
+}
+
+func validateEndpointURL(raw string) error {
+	u, err := url.ParseRequestURI(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint URL %q (must be http:// or https://)", raw)
+	}
+	return nil
+}
+
+func printEndpoint(e *webhookEndpoint, showSecret bool) {
+	status := "enabled"
+	if !e.Enabled {
+		status = "disabled"
+	}
+	fmt.Printf("🔗 Endpoint %s\n", e.ID)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("URL:          %s\n", e.URL)
+	if e.Description != "" {
+		fmt.Printf("Description:  %s\n", e.Description)
+	}
+	fmt.Printf("Status:       %s\n", status)
+	fmt.Printf("Events:       %s\n", strings.Join(e.Events, ", "))
+	if e.Secret != "" {
+		secret := maskSecret(e.Secret)
+		if showSecret {
+			secret = e.Secret
+		}
+		fmt.Printf("Secret:       %s\n", secret)
+	}
+	if e.CreatedAt != nil {
+		fmt.Printf("Created:      %s\n", e.CreatedAt.Local().Format(time.RFC1123))
+	}
+}
+
+var webhooksEndpointsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhook endpoints",
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		path := "/v1/webhooks/endpoints"
+		if zone := resolveZone(cmd); zone != "" {
+			path += "?zone=" + url.QueryEscape(zone)
+		}
+		var endpoints []webhookEndpoint
+		if err := apiRequest(context.Background(), http.MethodGet, path, nil, &endpoints); err != nil {
+			fmt.Printf("Error listing endpoints: %v\n", err)
+			os.Exit(1)
+		}
+		for i := range endpoints {
+			endpoints[i].Secret = ""
+		}
+
+		printOutput(endpoints, func() {
+			if len(endpoints) == 0 {
+				fmt.Println("No webhook endpoints. Add one with 'sapliy webhooks endpoints create --url <url>'.")
+				return
+			}
+
+			fmt.Printf("%-20s %-8s %-44s %s\n", "ID", "STATUS", "URL", "EVENTS")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, e := range endpoints {
+				status := "enabled"
+				if !e.Enabled {
+					status = "disabled"
+				}
+				fmt.Printf("%-20s %-8s %-44s %s\n", e.ID, status, truncate(e.URL, 44), truncate(strings.Join(e.Events, ","), 40))
+			}
+		})
+	},
+}
+
+var webhooksEndpointsGetCmd = &cobra.Command{
+	Use:   "get [endpoint_id]",
+	Short: "Show an endpoint and its subscriptions",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		showSecret, _ := cmd.Flags().GetBool("show-secret")
+		var e webhookEndpoint
+		if err := apiRequest(context.Background(), http.MethodGet, endpointPath(args[0], ""), nil, &e); err != nil {
+			fmt.Printf("❌ Failed to fetch endpoint: %v\n", err)
+			os.Exit(1)
+		}
+		if !showSecret {
+			e.Secret = maskSecret(e.Secret)
+		}
+		printOutput(e, func() { printEndpoint(&e, true) })
+	},
+}
+
+var webhooksEndpointsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Add a webhook endpoint",
+	Long: `Add an endpoint that receives webhooks for the current zone. The signing
+secret is shown once; verify deliveries with it (see 'sapliy debug-signature').`,
+	Example: `  sapliy webhooks endpoints create --url https://shop.example.com/webhooks --events payment.succeeded,payment.failed
+  sapliy webhooks endpoints create --url https://ops.example.com/hooks --events 'refund.*' --description "Ops alerts"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		e := webhookEndpoint{Enabled: true, ZoneID: resolveZone(cmd)}
+		e.URL, _ = cmd.Flags().GetString("url")
+		e.Events, _ = cmd.Flags().GetStringSlice("events")
+		e.Description, _ = cmd.Flags().GetString("description")
+		if err := validateEndpointURL(e.URL); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var created webhookEndpoint
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/webhooks/endpoints", &e, &created); err != nil {
+			fmt.Printf("❌ Failed to create endpoint: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(created, func() {
+			printEndpoint(&created, true)
+			if created.Secret != "" {
+				fmt.Println("\n⚠️  Store the signing secret now; it is only shown in full with 'get --show-secret'.")
+			}
+		})
+	},
+}
+
+var webhooksEndpointsUpdateCmd = &cobra.Command{
+	Use:   "update [endpoint_id]",
+	Short: "Change an endpoint's URL, description or subscriptions",
+	Long: `Change an endpoint. Only the flags you pass are changed. --events replaces
+the subscriptions; --add-events and --remove-events edit them.`,
+	Example: `  sapliy webhooks endpoints update we_123 --url https://shop.example.com/v2/webhooks
+  sapliy webhooks endpoints update we_123 --add-events refund.created --remove-events payment.created`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		changes := map[string]interface{}{}
+		if flags.Changed("url") {
+			u, _ := flags.GetString("url")
+			if err := validateEndpointURL(u); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			changes["url"] = u
+		}
+		if flags.Changed("description") {
+			changes["description"], _ = flags.GetString("description")
+		}
+
+		ctx := context.Background()
+		if flags.Changed("events") || flags.Changed("add-events") || flags.Changed("remove-events") {
+			var current webhookEndpoint
+			if err := apiRequest(ctx, http.MethodGet, endpointPath(args[0], ""), nil, &current); err != nil {
+				fmt.Printf("❌ Failed to fetch endpoint: %v\n", err)
+				os.Exit(1)
+			}
+			events := current.Events
+			if flags.Changed("events") {
+				events, _ = flags.GetStringSlice("events")
+			}
+			add, _ := flags.GetStringSlice("add-events")
+			remove, _ := flags.GetStringSlice("remove-events")
+			for _, a := range add {
+				if !slices.Contains(events, a) {
+					events = append(events, a)
+				}
+			}
+			events = slices.DeleteFunc(events, func(e string) bool { return slices.Contains(remove, e) })
+			if len(events) == 0 {
+				fmt.Println("Error: an endpoint must be subscribed to at least one event type (use * for all).")
+				os.Exit(1)
+			}
+			changes["events"] = events
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("Nothing to update. Pass --url, --description or --events.")
+			return
+		}
+
+		var updated webhookEndpoint
+		if err := apiRequest(ctx, http.MethodPatch, endpointPath(args[0], ""), changes, &updated); err != nil {
+			fmt.Printf("❌ Failed to update endpoint: %v\n", err)
+			os.Exit(1)
+		}
+		updated.Secret = ""
+		printOutput(updated, func() {
+			printEndpoint(&updated, false)
+			fmt.Println("\n✅ Endpoint updated!")
+		})
+	},
+}
+
+var webhooksEndpointsDeleteCmd = &cobra.Command{
+	Use:   "delete [endpoint_id]",
+	Short: "Delete a webhook endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Delete endpoint %s? Webhooks will no longer be sent to it. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, endpointPath(args[0], ""), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete endpoint: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted endpoint %s\n", args[0])
+	},
+}
+
+func setEndpointEnabled(enabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		change := map[string]bool{"enabled": enabled}
+		if err := apiRequest(context.Background(), http.MethodPatch, endpointPath(args[0], ""), change, nil); err != nil {
+			fmt.Printf("❌ Failed to update endpoint: %v\n", err)
+			os.Exit(1)
+		}
+		if enabled {
+			fmt.Printf("✅ Endpoint %s enabled\n", args[0])
+		} else {
+			fmt.Printf("⏸️  Endpoint %s disabled; events are not delivered to it until it is enabled again\n", args[0])
+		}
+	}
+}
+
+var webhooksEndpointsEnableCmd = &cobra.Command{
+	Use:   "enable [endpoint_id]",
+	Short: "Resume deliveries to an endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run:   setEndpointEnabled(true),
+}
+
+var webhooksEndpointsDisableCmd = &cobra.Command{
+	Use:   "disable [endpoint_id]",
+	Short: "Pause deliveries to an endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run:   setEndpointEnabled(false),
+}
+
+// retryPolicy controls how failed deliveries to an endpoint are retried.
+type retryPolicy struct {
+	MaxAttempts            int     `json:"maxAttempts"`
+	Backoff                string  `json:"backoff"`
+	Multiplier             float64 `json:"multiplier,omitempty"`
+	InitialIntervalSeconds int64   `json:"initialIntervalSeconds"`
+	MaxIntervalSeconds     int64   `json:"maxIntervalSeconds,omitempty"`
+	TimeoutSeconds         int64   `json:"timeoutSeconds"`
+}
+
+var retryBackoffs = []string{"exponential", "linear", "fixed"}
+
+func (p *retryPolicy) validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be at least 1")
+	}
+	valid := false
+	for _, b := range retryBackoffs {
+		if p.Backoff == b {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("backoff must be one of %s", strings.Join(retryBackoffs, ", "))
+	}
+	if p.Backoff == "exponential" && p.Multiplier != 0 && p.Multiplier <= 1 {
+		return fmt.Errorf("multiplier must be greater than 1")
+	}
+	if p.InitialIntervalSeconds < 1 {
+		return fmt.Errorf("initial interval must be at least 1s")
+	}
+	if p.MaxIntervalSeconds != 0 && p.MaxIntervalSeconds < p.InitialIntervalSeconds {
+		return fmt.Errorf("max interval must not be shorter than the initial interval")
+	}
+	if p.TimeoutSeconds < 1 {
+		return fmt.Errorf("timeout must be at least 1s")
+	}
+	return nil
+}
+
+// schedule returns the delay before each retry, i.e. before attempts
+// 2..MaxAttempts.
+func (p *retryPolicy) schedule() []time.Duration {
+	initial := time.Duration(p.InitialIntervalSeconds) * time.Second
+	maxInterval := time.Duration(p.MaxIntervalSeconds) * time.Second
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	var delays []time.Duration
+	for retry := 1; retry < p.MaxAttempts; retry++ {
+		var d time.Duration
+		switch p.Backoff {
+		case "fixed":
+			d = initial
+		case "linear":
+			d = initial * time.Duration(retry)
+		default:
+			d = time.Duration(float64(initial) * math.Pow(multiplier, float64(retry-1)))
+		}
+		if maxInterval > 0 && d > maxInterval {
+			d = maxInterval
+		}
+		delays = append(delays, d)
+	}
+	return delays
+}
+
+// formatShortDuration renders d the way schedules are usually written:
+// 30s, 5m, 1h30m, 2d.
+func formatShortDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+
+	var b strings.Builder
+	if days > 0 {
+		fmt.Fprintf(&b, "%dd", days)
+	}
+	if hours > 0 {
+		fmt.Fprintf(&b, "%dh", hours)
+	}
+	if minutes > 0 && days == 0 {
+		fmt.Fprintf(&b, "%dm", minutes)
+	}
+	if b.Len() == 0 {
+		return "0m"
+	}
+	return b.String()
+}
+
+func printRetryPolicy(endpointID string, p *retryPolicy) {
+	fmt.Printf("🔁 Retry policy for %s\n", endpointID)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Max attempts:      %d\n", p.MaxAttempts)
+	if p.Backoff == "exponential" {
+		multiplier := p.Multiplier
+		if multiplier == 0 {
+			multiplier = 2
+		}
+		fmt.Printf("Backoff:           exponential (×%g)\n", multiplier)
+	} else {
+		fmt.Printf("Backoff:           %s\n", p.Backoff)
+	}
+	fmt.Printf("Initial interval:  %s\n", formatShortDuration(time.Duration(p.InitialIntervalSeconds)*time.Second))
+	if p.MaxIntervalSeconds > 0 {
+		fmt.Printf("Max interval:      %s\n", formatShortDuration(time.Duration(p.MaxIntervalSeconds)*time.Second))
+	}
+	fmt.Printf("Attempt timeout:   %s\n", formatShortDuration(time.Duration(p.TimeoutSeconds)*time.Second))
+
+	delays := p.schedule()
+	fmt.Println("\nEffective schedule (after the first failed attempt):")
+	if len(delays) == 0 {
+		fmt.Println("  No retries; a failed delivery is not attempted again.")
+		return
+	}
+
+	var at time.Duration
+	var offsets []string
+	for i, d := range delays {
+		at += d
+		offsets = append(offsets, "+"+formatShortDuration(at))
+		fmt.Printf("  Attempt %-3d wait %-8s at %s\n", i+2, formatShortDuration(d), "+"+formatShortDuration(at))
+	}
+	fmt.Printf("\nRetries at %s; gives up after %s.\n", strings.Join(offsets, ", "), formatShortDuration(at))
+}
+
+func endpointPath(endpointID string, suffix string) string {
+	return "/v1/webhooks/endpoints/" + url.PathEscape(endpointID) + suffix
+}
+
+var webhooksRetryPolicyCmd = &cobra.Command{
+	Use:   "retry-policy",
+	Short: "View and tune how failed deliveries to an endpoint are retried",
+}
+
+var webhooksRetryPolicyGetCmd = &cobra.Command{
+	Use:   "get [endpoint_id]",
+	Short: "Show an endpoint's retry policy and the resulting schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var policy retryPolicy
+		if err := apiRequest(context.Background(), http.MethodGet, endpointPath(args[0], "/retry-policy"), nil, &policy); err != nil {
+			fmt.Printf("❌ Failed to fetch retry policy: %v\n", err)
+			os.Exit(1)
+		}
+
+		var schedule []string
+		var at time.Duration
+		for _, d := range policy.schedule() {
+			at += d
+			schedule = append(schedule, "+"+formatShortDuration(at))
+		}
+		out := struct {
+			EndpointID string `json:"endpointId"`
+			retryPolicy
+			Schedule []string `json:"schedule"`
+		}{args[0], policy, schedule}
+
+		printOutput(out, func() { printRetryPolicy(args[0], &policy) })
+	},
+}
+
+var webhooksRetryPolicySetCmd = &cobra.Command{
+	Use:   "set [endpoint_id]",
+	Short: "Change an endpoint's retry policy",
+	Long: `Change the retry policy of an endpoint. Only the flags you pass are
+changed. The new schedule is shown before it is saved; use --dry-run to
+preview it without saving.`,
+	Example: `  sapliy webhooks endpoints retry-policy set we_123 --max-attempts 6 --backoff exponential --initial-interval 1m --multiplier 5
+  sapliy webhooks endpoints retry-policy set we_123 --timeout 15s --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		endpointID := args[0]
+		path := endpointPath(endpointID, "/retry-policy")
+		ctx := context.Background()
+
+		var policy retryPolicy
+		if err := apiRequest(ctx, http.MethodGet, path, nil, &policy); err != nil {
+			fmt.Printf("❌ Failed to fetch retry policy: %v\n", err)
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("max-attempts") {
+			policy.MaxAttempts, _ = flags.GetInt("max-attempts")
+		}
+		if flags.Changed("backoff") {
+			policy.Backoff, _ = flags.GetString("backoff")
+		}
+		if flags.Changed("multiplier") {
+			policy.Multiplier, _ = flags.GetFloat64("multiplier")
+		}
+		if flags.Changed("initial-interval") {
+			d, _ := flags.GetDuration("initial-interval")
+			policy.InitialIntervalSeconds = int64(d.Seconds())
+		}
+		if flags.Changed("max-interval") {
+			d, _ := flags.GetDuration("max-interval")
+			policy.MaxIntervalSeconds = int64(d.Seconds())
+		}
+		if flags.Changed("timeout") {
+			d, _ := flags.GetDuration("timeout")
+			policy.TimeoutSeconds = int64(d.Seconds())
+		}
+
+		if err := policy.validate(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		printRetryPolicy(endpointID, &policy)
+
+		if dryRun, _ := flags.GetBool("dry-run"); dryRun {
+			fmt.Println("\n🏃 Dry run - policy not saved.")
+			return
+		}
+
+		if err := apiRequest(ctx, http.MethodPut, path, &policy, &policy); err != nil {
+			fmt.Printf("\n❌ Failed to update retry policy: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\n✅ Retry policy updated!")
+	},
+}
+
+// captureResponseSettings controls whether the bodies endpoints return to
+// delivery attempts are stored for inspection.
+type captureResponseSettings struct {
+	Enabled  bool `json:"enabled"`
+	MaxBytes int  `json:"maxBytes,omitempty"`
+}
+
+func setCaptureResponse(enabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		settings := captureResponseSettings{Enabled: enabled}
+		if enabled {
+			settings.MaxBytes, _ = cmd.Flags().GetInt("max-bytes")
+		}
+
+		err := apiRequest(context.Background(), http.MethodPut, endpointPath(args[0], "/capture-response"), &settings, &settings)
+		if err != nil {
+			fmt.Printf("❌ Failed to update response capture: %v\n", err)
+			os.Exit(1)
+		}
+
+		if settings.Enabled {
+			fmt.Printf("✅ Capturing response bodies for %s", args[0])
+			if settings.MaxBytes > 0 {
+				fmt.Printf(" (up to %d bytes)", settings.MaxBytes)
+			}
+			fmt.Println()
+			fmt.Println("   View them with 'sapliy webhooks inspect <event_id> --attempts'.")
+		} else {
+			fmt.Printf("✅ Response capture disabled for %s\n", args[0])
+		}
+	}
+}
+
+var webhooksCaptureResponseCmd = &cobra.Command{
+	Use:   "capture-response",
+	Short: "Store what an endpoint returns to each delivery attempt",
+	Long: `When enabled, the response body an endpoint returns to each delivery attempt
+is stored with the attempt, so 'sapliy webhooks inspect <event_id> --attempts'
+can show what your server actually replied.`,
+}
+
+var webhooksCaptureResponseEnableCmd = &cobra.Command{
+	Use:   "enable [endpoint_id]",
+	Short: "Start capturing response bodies for an endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run:   setCaptureResponse(true),
+}
+
+var webhooksCaptureResponseDisableCmd = &cobra.Command{
+	Use:   "disable [endpoint_id]",
+	Short: "Stop capturing response bodies for an endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run:   setCaptureResponse(false),
+}
+
+func init() {
+	webhooksCmd.AddCommand(webhooksEndpointsCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsListCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsGetCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsCreateCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsUpdateCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsDeleteCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsEnableCmd)
+	webhooksEndpointsCmd.AddCommand(webhooksEndpointsDisableCmd)
+
+	webhooksEndpointsGetCmd.Flags().Bool("show-secret", false, "Show the full signing secret")
+	webhooksEndpointsCreateCmd.Flags().String("url", "", "URL webhooks are POSTed to")
+	webhooksEndpointsCreateCmd.Flags().StringSlice("events", []string{"*"}, "Event types to subscribe to (comma-separated, supports payment.*)")
+	webhooksEndpointsCreateCmd.Flags().String("description", "", "Description shown in the dashboard")
+	webhooksEndpointsCreateCmd.MarkFlagRequired("url")
+	webhooksEndpointsUpdateCmd.Flags().String("url", "", "New URL")
+	webhooksEndpointsUpdateCmd.Flags().String("description", "", "New description")
+	webhooksEndpointsUpdateCmd.Flags().StringSlice("events", nil, "Replace the subscribed event types")
+	webhooksEndpointsUpdateCmd.Flags().StringSlice("add-events", nil, "Subscribe to more event types")
+	webhooksEndpointsUpdateCmd.Flags().StringSlice("remove-events", nil, "Unsubscribe from event types")
+	webhooksEndpointsDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	webhooksEndpointsCmd.AddCommand(webhooksRetryPolicyCmd)
+	webhooksRetryPolicyCmd.AddCommand(webhooksRetryPolicyGetCmd)
+	webhooksRetryPolicyCmd.AddCommand(webhooksRetryPolicySetCmd)
+
+	webhooksRetryPolicySetCmd.Flags().Int("max-attempts", 0, "Total delivery attempts, including the first")
+	webhooksRetryPolicySetCmd.Flags().String("backoff", "", "Backoff curve: exponential, linear or fixed")
+	webhooksRetryPolicySetCmd.Flags().Float64("multiplier", 0, "Growth factor between retries for exponential backoff")
+	webhooksRetryPolicySetCmd.Flags().Duration("initial-interval", 0, "Delay before the first retry")
+	webhooksRetryPolicySetCmd.Flags().Duration("max-interval", 0, "Upper bound for the delay between retries (0 for none)")
+	webhooksRetryPolicySetCmd.Flags().Duration("timeout", 0, "How long each attempt waits for the endpoint to respond")
+	webhooksRetryPolicySetCmd.Flags().Bool("dry-run", false, "Show the resulting schedule without saving")
+
+	webhooksEndpointsCmd.AddCommand(webhooksCaptureResponseCmd)
+	webhooksCaptureResponseCmd.AddCommand(webhooksCaptureResponseEnableCmd)
+	webhooksCaptureResponseCmd.AddCommand(webhooksCaptureResponseDisableCmd)
+	webhooksCaptureResponseEnableCmd.Flags().Int("max-bytes", 0, "Maximum bytes of each response body to store (server default if 0)")
+}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/sapliy/fintech-sdk-go"
 	"github.com/spf13/cobra"
@@ -14,19 +19,197 @@ import (
 var eventData string
 var zoneID string
 
+// batchEvent is one line of a trigger --file NDJSON file.
+type batchEvent struct {
+	Type   string                 `json:"type"`
+	Data   map[string]interface{} `json:"data"`
+	ZoneID string                 `json:"zoneId,omitempty"`
+	line   int
+}
+
+// triggerResult is the outcome of one event sent by trigger --file.
+type triggerResult struct {
+	Line  int    `json:"line"`
+	Type  string `json:"type"`
+	Zone  string `json:"zone"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// readBatchEvents parses an NDJSON file of events, one {"type", "data"}
+// object per line. Blank lines and lines starting with # are skipped. With
+// expand set, each line's placeholders are expanded first. Every line is
+// checked before anything is sent.
+func readBatchEvents(r io.Reader, expand bool) ([]batchEvent, error) {
+	var events []batchEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if expand {
+			var err error
+			if line, err = expandPayloadTemplate(line); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+		}
+		e := batchEvent{line: n}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if e.Type == "" {
+			return nil, fmt.Errorf("line %d: missing \"type\"", n)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// triggerBatch sends events, concurrency at a time, and returns one result
+// per event in file order.
+func triggerBatch(client *fintech.Client, events []batchEvent, concurrency int) []triggerResult {
+	ctx := context.Background()
+	results := make([]triggerResult, len(events))
+	progress := newProgressBar(len(events))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				e := events[i]
+				zone := zoneID
+				if e.ZoneID != "" {
+					zone = e.ZoneID
+				}
+				results[i] = triggerResult{Line: e.line, Type: e.Type, Zone: zone, OK: true}
+				if err := client.TriggerEvent(ctx, e.Type, zone, e.Data); err != nil {
+					results[i].OK, results[i].Error = false, err.Error()
+				}
+				progress.step()
+			}
+		}()
+	}
+	for i := range events {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
 var triggerCmd = &cobra.Command{
 	Use:   "trigger [event_type]",
 	Short: "Trigger a mock event for automation flows",
-	Args:  cobra.ExactArgs(1),
+	Long: `Trigger an event in a zone. With --file, trigger every event in an NDJSON
+file instead, one {"type": ..., "data": {...}} object per line (a line's
+"zoneId" overrides --zone), --concurrency at a time, and print a result for
+each. The exit status is 1 if any event failed.
+
+With --template, placeholders in --data (or in each --file line) are
+expanded before sending, so every run gets fresh values:
+
+  {{uuid}}              a random UUID
+  {{now}}               the current time (RFC 3339); {{now "-1h"}} shifts it
+  {{unix}}              the current Unix time
+  {{randint 100 9999}}  a random integer between the bounds
+  {{id "pi"}}           a random ID with the prefix, e.g. pi_3Fx...
+  {{email}}             a random email address
+  {{pick "usd" "eur"}}  one of the options
+  {{env "NAME"}}        an environment variable`,
+	Example: `  sapliy trigger payment.succeeded --zone zone_test --data '{"amount": 2000}'
+  sapliy trigger payment.succeeded --zone zone_test --template \
+    --data '{"id": "{{id "pi"}}", "amount": {{randint 100 9999}}, "created": "{{now}}"}'
+  sapliy trigger --zone zone_test --file seed.ndjson --concurrency 8`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey := viper.GetString("api_key")
 		if apiKey == "" {
 			fmt.Println("Error: API key not set. Use 'sapliy auth login' or set in config.")
 			return
 		}
+		zoneID = resolveZone(cmd)
+
+		if file, _ := cmd.Flags().GetString("file"); file != "" {
+			if len(args) > 0 || cmd.Flags().Changed("data") || cmd.Flags().Changed("build") || cmd.Flags().Changed("edit") {
+				fmt.Println("Error: --file cannot be combined with an event type, --data, --build or --edit.")
+				os.Exit(1)
+			}
+			var in io.Reader = os.Stdin
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				defer f.Close()
+				in = f
+			}
+			tmpl, _ := cmd.Flags().GetBool("template")
+			events, err := readBatchEvents(in, tmpl)
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if len(events) == 0 {
+				fmt.Println("No events in the file.")
+				return
+			}
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			if concurrency < 1 {
+				concurrency = 1
+			}
+
+			if !structuredOutput() {
+				fmt.Printf("Triggering %d event(s) in zone '%s', %d at a time...\n", len(events), zoneID, concurrency)
+			}
+			client := newClient()
+			results := triggerBatch(client, events, concurrency)
+
+			succeeded := 0
+			for _, r := range results {
+				if r.OK {
+					succeeded++
+				}
+			}
+			printOutput(results, func() {
+				for _, r := range results {
+					if r.OK {
+						fmt.Printf("   ✅ line %-5d %-28s %s\n", r.Line, r.Type, r.Zone)
+					} else {
+						fmt.Printf("   ❌ line %-5d %-28s %s: %s\n", r.Line, r.Type, r.Zone, r.Error)
+					}
+				}
+				fmt.Println(strings.Repeat("─", 40))
+				fmt.Printf("Completed: %d succeeded, %d failed\n", succeeded, len(results)-succeeded)
+			})
+			if succeeded < len(results) {
+				os.Exit(1)
+			}
+			return
+		}
+		if len(args) == 0 {
+			fmt.Println("Error: pass an event type, or --file with events to send.")
+			os.Exit(1)
+		}
 
 		eventType := args[0]
 
+		if tmpl, _ := cmd.Flags().GetBool("template"); tmpl {
+			expanded, err := expandPayloadTemplate(eventData)
+			if err != nil {
+				fmt.Printf("Error: --data %v\n", err)
+				os.Exit(1)
+			}
+			eventData = expanded
+		}
+
 		var data map[string]interface{}
 		if eventData != "" {
 			if err := json.Unmarshal([]byte(eventData), &data); err != nil {
@@ -34,7 +217,41 @@ var triggerCmd = &cobra.Command{
 			}
 		}
 
-		client := fintech.NewClient(apiKey, fintech.WithBaseURL(viper.GetString("api_url")))
+		if build, _ := cmd.Flags().GetBool("build"); build {
+			built, err := buildPayload(eventType, data)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if built == nil {
+				fmt.Println("Cancelled.")
+				return
+			}
+			data = built
+		}
+
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			initial := data
+			if len(initial) == 0 {
+				// Start from a sample payload when --data was not given.
+				if fixture, err := generateFixture(eventType, 1); err == nil {
+					initial, _ = fixture["data"].(map[string]interface{})
+				}
+			}
+			b, _ := json.MarshalIndent(initial, "", "  ")
+			edited, err := editJSON("event", append(b, '\n'), nil)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if edited == nil {
+				fmt.Println("Cancelled.")
+				return
+			}
+			data = edited
+		}
+
+		client := newClient()
 
 		// In a real implementation, this would hit a dedicated trigger endpoint
 		// For now, we'll simulate the call
@@ -55,6 +272,11 @@ var triggerCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(triggerCmd)
 	triggerCmd.Flags().StringVarP(&eventData, "data", "d", "{}", "JSON event data")
-	triggerCmd.Flags().StringVarP(&zoneID, "zone", "z", "", "Zone ID to scope the event")
+	triggerCmd.Flags().StringVarP(&zoneID, "zone", "z", "", "Zone ID to scope the event (default: current zone)")
+	triggerCmd.Flags().Bool("build", false, "Compose the payload interactively from the event's schema (--data values become defaults)")
+	triggerCmd.Flags().Bool("edit", false, "Edit the payload in $EDITOR before sending (starts from --data or a sample)")
+	triggerCmd.Flags().StringP("file", "f", "", "Trigger every event in an NDJSON file (- for stdin)")
+	triggerCmd.Flags().Bool("template", false, "Expand placeholders such as {{uuid}}, {{now}} and {{randint 1 100}} in the payload")
+	triggerCmd.Flags().IntP("concurrency", "c", 1, "Events to send at once with --file")
 	triggerCmd.MarkFlagRequired("zone")
 }
@@ -0,0 +1,558 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// manifestName is the file an export writes next to its data files.
+const manifestName = "manifest.json"
+
+// exportManifest describes an export: what was exported and the SHA-256 of
+// every file, optionally signed so later changes to either are detectable.
+type exportManifest struct {
+	Version   int               `json:"version"`
+	Kind      string            `json:"kind"`
+	CreatedAt time.Time         `json:"createdAt"`
+	APIHost   string            `json:"apiHost"`
+	Account   string            `json:"account,omitempty"`
+	Filters   map[string]string `json:"filters,omitempty"`
+	Files     []manifestFile    `json:"files"`
+	Signature *manifestSig      `json:"signature,omitempty"`
+}
+
+type manifestFile struct {
+	Name    string `json:"name"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+}
+
+// manifestSig is an Ed25519 signature over the manifest as JSON with the
+// signature field left out.
+type manifestSig struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId"`
+	PublicKey string `json:"publicKey"`
+	Value     string `json:"value"`
+}
+
+// signedBytes is the exact content a manifest signature covers.
+func (m *exportManifest) signedBytes() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = nil
+	return json.Marshal(&unsigned)
+}
+
+func keyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// signingKeyPath is where the export signing key is kept unless
+// --signing-key is given.
+func signingKeyPath() (string, error) {
+	dir, err := sapliyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "export_signing_key.pem"), nil
+}
+
+// loadSigningKey reads a PKCS #8 PEM Ed25519 private key. With create set,
+// a missing key is generated and saved.
+func loadSigningKey(path string, create bool) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "🔑 Created export signing key %s (key ID %s)\n", path, keyID(priv.Public().(ed25519.PublicKey)))
+		return priv, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 key", path)
+	}
+	return priv, nil
+}
+
+// parsePublicKey accepts an Ed25519 public key as a PEM file, or inline as
+// base64 or PEM text.
+func parsePublicKey(value string) (ed25519.PublicKey, error) {
+	raw := []byte(value)
+	if data, err := os.ReadFile(value); err == nil {
+		raw = data
+	}
+	if block, _ := pem.Decode(raw); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("not an Ed25519 public key")
+		}
+		return pub, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("not a PEM file or base64 Ed25519 public key")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// exportWriter writes one NDJSON data file of an export, hashing it as it
+// goes.
+type exportWriter struct {
+	file  *os.File
+	buf   *bufio.Writer
+	sum   func() []byte
+	entry manifestFile
+}
+
+func newExportWriter(dir, name string) (*exportWriter, error) {
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	return &exportWriter{
+		file:  f,
+		buf:   bufio.NewWriter(io.MultiWriter(f, h)),
+		sum:   func() []byte { return h.Sum(nil) },
+		entry: manifestFile{Name: name},
+	}, nil
+}
+
+func (w *exportWriter) write(record json.RawMessage) error {
+	n, err := w.buf.Write(append(slices.Clip(record), '\n'))
+	w.entry.Size += int64(n)
+	w.entry.Records++
+	return err
+}
+
+func (w *exportWriter) close() (manifestFile, error) {
+	err := w.buf.Flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.entry.SHA256 = hex.EncodeToString(w.sum())
+	return w.entry, err
+}
+
+// runExport pages through path with q and writes every record to
+// <kind>.ndjson in dir, followed by the manifest.
+func runExport(cmd *cobra.Command, kind, path string, q url.Values, filters map[string]string) {
+	if viper.GetString("api_key") == "" {
+		fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+		os.Exit(1)
+	}
+
+	dir, _ := cmd.Flags().GetString("out")
+	force, _ := cmd.Flags().GetBool("force")
+	sign, _ := cmd.Flags().GetBool("sign")
+	keyPath, _ := cmd.Flags().GetString("signing-key")
+
+	// Load the key before exporting so a bad key does not waste the export.
+	var priv ed25519.PrivateKey
+	if sign {
+		create := keyPath == ""
+		if create {
+			p, err := signingKeyPath()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			keyPath = p
+		}
+		var err error
+		if priv, err = loadSigningKey(keyPath, create); err != nil {
+			fmt.Printf("Error loading signing key: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestName)); err == nil && !force {
+		fmt.Printf("Error: %s already holds an export (use --force to overwrite).\n", dir)
+		os.Exit(1)
+	}
+
+	w, err := newExportWriter(dir, kind+".ndjson")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := context.Background()
+	q.Set("limit", "100")
+	for {
+		var page apiPage[json.RawMessage]
+		if err := apiRequest(ctx, http.MethodGet, path+"?"+q.Encode(), nil, &page); err != nil {
+			w.close()
+			fmt.Printf("❌ Export failed: %v\n", err)
+			os.Exit(1)
+		}
+		for _, record := range page.Data {
+			if err := w.write(record); err != nil {
+				w.close()
+				fmt.Printf("❌ Export failed: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		switch {
+		case structuredOutput():
+		case accessibleMode():
+			fmt.Fprintf(os.Stderr, "Progress: %d %s exported\n", w.entry.Records, kind)
+		case stderrIsTerminal():
+			fmt.Fprintf(os.Stderr, "\r%d %s exported", w.entry.Records, kind)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Set("cursor", page.NextCursor)
+	}
+	if !structuredOutput() && !accessibleMode() && stderrIsTerminal() {
+		fmt.Fprintln(os.Stderr)
+	}
+	entry, err := w.close()
+	if err != nil {
+		fmt.Printf("❌ Export failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	m := &exportManifest{
+		Version:   1,
+		Kind:      kind,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		APIHost:   apiBaseURL(),
+		Account:   viper.GetString("account_id"),
+		Filters:   filters,
+		Files:     []manifestFile{entry},
+	}
+	if priv != nil {
+		signed, err := m.signedBytes()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		pub := priv.Public().(ed25519.PublicKey)
+		m.Signature = &manifestSig{
+			Algorithm: "ed25519",
+			KeyID:     keyID(pub),
+			PublicKey: base64.StdEncoding.EncodeToString(pub),
+			Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(priv, signed)),
+		}
+	}
+	data, _ := json.MarshalIndent(m, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, manifestName), append(data, '\n'), 0600); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	printOutput(m, func() {
+		fmt.Printf("📦 Exported %d %s to %s\n", entry.Records, kind, filepath.Join(dir, entry.Name))
+		fmt.Printf("   sha256 %s\n", entry.SHA256)
+		if m.Signature != nil {
+			fmt.Printf("🔏 Manifest signed with key %s\n", m.Signature.KeyID)
+		}
+	})
+}
+
+// verifyExport checks every file in an export's manifest and its signature.
+// It returns the problems found; pub, if not nil, is the key the signature
+// must be made with.
+func verifyExport(dir string, pub ed25519.PublicKey) (*exportManifest, []string, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, nil, err
+	}
+	var m exportManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", manifestName, err)
+	}
+
+	var problems []string
+	for _, f := range m.Files {
+		if f.Name != filepath.Base(f.Name) {
+			problems = append(problems, fmt.Sprintf("%s: file outside the export directory", f.Name))
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, f.Name))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Name, err))
+			continue
+		}
+		h := sha256.New()
+		size, err := io.Copy(h, file)
+		file.Close()
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Name, err))
+		case size != f.Size:
+			problems = append(problems, fmt.Sprintf("%s: size is %d bytes, manifest says %d", f.Name, size, f.Size))
+		case hex.EncodeToString(h.Sum(nil)) != f.SHA256:
+			problems = append(problems, fmt.Sprintf("%s: SHA-256 does not match the manifest", f.Name))
+		}
+	}
+
+	switch {
+	case m.Signature == nil && pub != nil:
+		problems = append(problems, "manifest is not signed")
+	case m.Signature != nil:
+		sigPub, err1 := base64.StdEncoding.DecodeString(m.Signature.PublicKey)
+		sig, err2 := base64.StdEncoding.DecodeString(m.Signature.Value)
+		signed, err3 := m.signedBytes()
+		switch {
+		case m.Signature.Algorithm != "ed25519":
+			problems = append(problems, fmt.Sprintf("unsupported signature algorithm %q", m.Signature.Algorithm))
+		case err1 != nil || err2 != nil || err3 != nil || len(sigPub) != ed25519.PublicKeySize:
+			problems = append(problems, "malformed signature")
+		case pub != nil && !pub.Equal(ed25519.PublicKey(sigPub)):
+			problems = append(problems, fmt.Sprintf("signed with key %s, not the expected key %s", keyID(sigPub), keyID(pub)))
+		case !ed25519.Verify(sigPub, signed, sig):
+			problems = append(problems, "signature does not match the manifest; it was modified after signing")
+		}
+	}
+	return &m, problems, nil
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export events or payments to NDJSON with a checksummed manifest",
+	Long: `Export account data to <kind>.ndjson in --out, with a manifest.json recording
+the SHA-256 of each file and the filters used. With --sign the manifest is
+signed with an Ed25519 key, so 'sapliy export verify' can later prove that
+neither the data nor the manifest was modified. The exported files can be
+queried with 'sapliy query --dir'.`,
+}
+
+var exportEventsCmd = &cobra.Command{
+	Use:         "events",
+	Short:       "Export events",
+	Annotations: map[string]string{"regionScoped": "true"},
+	Example: `  sapliy export events --out ./audit-2026-10 --since 30d --sign
+  sapliy export events --out ./refunds --type refund.created,refund.failed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		zone := requireZone(cmd)
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		types, _ := cmd.Flags().GetStringSlice("type")
+
+		q := url.Values{"zone": {zone}}
+		filters := map[string]string{"zone": zone}
+		if sinceFlag != "" {
+			since, err := parseSince(sinceFlag)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			q.Set("since", since.UTC().Format(time.RFC3339))
+			filters["since"] = q.Get("since")
+		}
+		for _, t := range types {
+			q.Add("type", t)
+		}
+		if len(types) > 0 {
+			filters["type"] = strings.Join(types, ",")
+		}
+		runExport(cmd, "events", "/v1/webhooks/events", q, filters)
+	},
+}
+
+var exportPaymentsCmd = &cobra.Command{
+	Use:         "payments",
+	Short:       "Export payments",
+	Annotations: map[string]string{"regionScoped": "true"},
+	Example:     `  sapliy export payments --out ./q3 --created-after 2026-07-01 --created-before 2026-10-01 --sign`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, _ := cmd.Flags().GetString("status")
+		if status != "" && !slices.Contains(paymentStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(paymentStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{}
+		filters := map[string]string{}
+		if zone := resolveZone(cmd); zone != "" {
+			q.Set("zone", zone)
+			filters["zone"] = zone
+		}
+		if status != "" {
+			q.Set("status", status)
+			filters["status"] = status
+		}
+		for _, name := range []string{"created-after", "created-before"} {
+			v, _ := cmd.Flags().GetString(name)
+			if v == "" {
+				continue
+			}
+			t, err := parseDateFlag(name, v)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			key := "createdAfter"
+			if name == "created-before" {
+				key = "createdBefore"
+			}
+			q.Set(key, t.UTC().Format(time.RFC3339))
+			filters[key] = q.Get(key)
+		}
+		runExport(cmd, "payments", "/v1/payments", q, filters)
+	},
+}
+
+var exportVerifyCmd = &cobra.Command{
+	Use:   "verify [dir]",
+	Short: "Check an export against its manifest and signature",
+	Long: `Recompute the SHA-256 of every file listed in the export's manifest and check
+the manifest's signature. Without --public-key the signature is checked with
+the key embedded in the manifest, which proves the export is unchanged since
+signing but not who signed it; pass the signer's public key (from 'sapliy
+export public-key') to check that too. The exit status is 1 on any mismatch.`,
+	Example: `  sapliy export verify ./audit-2026-10
+  sapliy export verify ./audit-2026-10 --public-key signer.pub`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var pub ed25519.PublicKey
+		if value, _ := cmd.Flags().GetString("public-key"); value != "" {
+			var err error
+			if pub, err = parsePublicKey(value); err != nil {
+				fmt.Printf("Error: --public-key: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		m, problems, err := verifyExport(args[0], pub)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		result := map[string]interface{}{"ok": len(problems) == 0, "problems": problems, "manifest": m}
+		printOutput(result, func() {
+			for _, f := range m.Files {
+				fmt.Printf("   %s  %d records, %d bytes\n", f.Name, f.Records, f.Size)
+			}
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Printf("❌ %s\n", p)
+				}
+				return
+			}
+			switch {
+			case m.Signature == nil:
+				fmt.Println("✅ Checksums match. ⚠️  The manifest is not signed, so it could have been changed along with the data.")
+			case pub == nil:
+				fmt.Printf("✅ Checksums and signature match (key %s, embedded in the manifest; pass --public-key to check the signer).\n", m.Signature.KeyID)
+			default:
+				fmt.Printf("✅ Checksums and signature match; signed by key %s.\n", m.Signature.KeyID)
+			}
+		})
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var exportPublicKeyCmd = &cobra.Command{
+	Use:   "public-key",
+	Short: "Print the public key of the export signing key",
+	Long: `Print the public half of the export signing key as PEM, to hand to auditors
+for 'sapliy export verify --public-key'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keyPath, _ := cmd.Flags().GetString("signing-key")
+		if keyPath == "" {
+			p, err := signingKeyPath()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			keyPath = p
+		}
+		priv, err := loadSigningKey(keyPath, false)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Println("No signing key yet. It is created by the first 'sapliy export ... --sign'.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		der, err := x509.MarshalPKIXPublicKey(priv.Public())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportEventsCmd)
+	exportCmd.AddCommand(exportPaymentsCmd)
+	exportCmd.AddCommand(exportVerifyCmd)
+	exportCmd.AddCommand(exportPublicKeyCmd)
+
+	for _, c := range []*cobra.Command{exportEventsCmd, exportPaymentsCmd} {
+		c.Flags().String("out", "", "Directory to write the export to")
+		c.Flags().Bool("sign", false, "Sign the manifest with the export signing key")
+		c.Flags().String("signing-key", "", "Ed25519 private key (PKCS #8 PEM) to sign with (default ~/.sapliy/export_signing_key.pem)")
+		c.Flags().Bool("force", false, "Overwrite an existing export in --out")
+		c.MarkFlagRequired("out")
+	}
+	exportEventsCmd.Flags().StringVarP(&zoneID, "zone", "z", "", "Zone to export (default: current zone)")
+	exportEventsCmd.Flags().String("since", "", "Only events since this age or time (e.g. 30d)")
+	exportEventsCmd.Flags().StringSlice("type", nil, "Only these event types (comma-separated)")
+	exportPaymentsCmd.Flags().String("status", "", "Only payments with this status")
+	exportPaymentsCmd.Flags().String("created-after", "", "Only payments created after this date, time or age")
+	exportPaymentsCmd.Flags().String("created-before", "", "Only payments created before this date, time or age")
+
+	exportVerifyCmd.Flags().String("public-key", "", "Signer's Ed25519 public key: PEM file, or base64")
+	exportPublicKeyCmd.Flags().String("signing-key", "", "Private key to print the public key of (default ~/.sapliy/export_signing_key.pem)")
+}
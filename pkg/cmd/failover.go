@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// hostCooldown is how long an API host that failed is skipped in favour of
+// the next one. It is remembered across runs, so a command started during
+// an incident goes straight to a healthy host.
+const hostCooldown = 2 * time.Minute
+
+// regionStrict is set for commands that read or export account data when
+// --region is given: they only talk to hosts in that region instead of
+// merely preferring them.
+var regionStrict bool
+
+// regionScoped reports whether --region restricts cmd to hosts in the
+// region: list commands, and commands annotated regionScoped.
+func regionScoped(cmd *cobra.Command) bool {
+	return cmd.Name() == "list" || cmd.Annotations["regionScoped"] == "true"
+}
+
+// apiHosts returns the API base URLs in the order they are tried: hosts in
+// --region first, then api_url, then the rest of api_urls. api_urls only
+// apply when api_url is unset or one of them, so a profile pointing at
+// another environment never fails over to production hosts. With a
+// residency lock, or a region-scoped command, hosts outside the region are
+// left out.
+func apiHosts() []string {
+	primary := strings.TrimRight(viper.GetString("api_url"), "/")
+	var hosts []string
+	for _, u := range viper.GetStringSlice("api_urls") {
+		if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" && !slices.Contains(hosts, u) {
+			hosts = append(hosts, u)
+		}
+	}
+	switch {
+	case primary != "" && !slices.Contains(hosts, primary):
+		hosts = []string{primary}
+	case primary != "":
+		hosts = append([]string{primary}, slices.DeleteFunc(hosts, func(h string) bool { return h == primary })...)
+	case len(hosts) == 0:
+		hosts = []string{defaultAPIURL}
+	}
+
+	if residency := viper.GetString("residency"); residency != "" {
+		hosts = slices.DeleteFunc(hosts, func(h string) bool { return !inRegion(h, residency) })
+	}
+	region := viper.GetString("region")
+	if region != "" && regionStrict {
+		hosts = slices.DeleteFunc(hosts, func(h string) bool { return !inRegion(h, region) })
+	}
+	if region != "" {
+		slices.SortStableFunc(hosts, func(a, b string) int {
+			ra, rb := hostRegion(a) == region, hostRegion(b) == region
+			switch {
+			case ra && !rb:
+				return -1
+			case rb && !ra:
+				return 1
+			}
+			return 0
+		})
+	}
+	return hosts
+}
+
+// hostRegion is the region of an API URL: the first label of its host name,
+// e.g. "eu" for https://eu.api.sapliy.io.
+func hostRegion(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return hostnameRegion(u.Hostname())
+}
+
+func hostnameRegion(hostname string) string {
+	if net.ParseIP(hostname) != nil {
+		return ""
+	}
+	label, _, found := strings.Cut(hostname, ".")
+	if !found {
+		return ""
+	}
+	return label
+}
+
+// isLocalHost reports whether hostname is this machine. Local development
+// servers are exempt from the residency lock.
+func isLocalHost(hostname string) bool {
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// inRegion reports whether an API URL is in region, counting local
+// development servers as in every region.
+func inRegion(apiURL, region string) bool {
+	u, err := url.Parse(apiURL)
+	return err == nil && (isLocalHost(u.Hostname()) || hostnameRegion(u.Hostname()) == region)
+}
+
+// validateRegion reports a --region that no configured API host is in, or
+// that conflicts with the residency lock.
+func validateRegion(cmd *cobra.Command) error {
+	region := viper.GetString("region")
+	residency := viper.GetString("residency")
+	if residency != "" && region != "" && region != residency {
+		return fmt.Errorf("--region %q: data residency is locked to %q in the config file", region, residency)
+	}
+	regionStrict = region != "" && regionScoped(cmd)
+
+	if len(apiHosts()) == 0 {
+		if region == "" {
+			region = residency
+		}
+		var regions []string
+		for _, h := range viper.GetStringSlice("api_urls") {
+			if r := hostRegion(h); r != "" && !slices.Contains(regions, r) {
+				regions = append(regions, r)
+			}
+		}
+		if len(regions) == 0 {
+			return fmt.Errorf("no API host in region %q (set api_urls to regional hosts such as https://%s.api.sapliy.io)", region, region)
+		}
+		return fmt.Errorf("no API host in region %q (configured: %s)", region, strings.Join(regions, ", "))
+	}
+	if region != "" && !slices.ContainsFunc(apiHosts(), func(h string) bool { return hostRegion(h) == region }) {
+		return fmt.Errorf("--region %q: no API host in that region (set api_urls)", region)
+	}
+	return nil
+}
+
+// residencyError is a request the residency lock refused to send.
+type residencyError struct {
+	Host      string
+	Residency string
+}
+
+func (e *residencyError) Error() string {
+	return fmt.Sprintf("refusing to connect to %s: data residency is locked to %q (residency in the config file)", e.Host, e.Residency)
+}
+
+// checkResidency refuses hosts outside the residency region, if one is set.
+func checkResidency(hostname string) error {
+	residency := viper.GetString("residency")
+	if residency == "" || isLocalHost(hostname) || hostnameRegion(hostname) == residency {
+		return nil
+	}
+	return &residencyError{Host: hostname, Residency: residency}
+}
+
+// residencyTransport enforces the residency lock on every request,
+// including ones to hosts outside api_urls such as file downloads.
+type residencyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *residencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkResidency(req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// apiBaseURL is the API host requests are sent to: the first one in
+// apiHosts order that has not failed recently.
+func apiBaseURL() string {
+	hosts := apiHosts()
+	for _, h := range hosts {
+		if !apiHealth.isDown(h) {
+			return h
+		}
+	}
+	if len(hosts) == 0 {
+		return defaultAPIURL
+	}
+	return hosts[0]
+}
+
+// hostHealthState records until when each API host that failed is skipped.
+// It is kept in ~/.sapliy/api_health.json.
+type hostHealthState struct {
+	mu     sync.Mutex
+	loaded bool
+	down   map[string]time.Time
+}
+
+var apiHealth = &hostHealthState{}
+
+func (s *hostHealthState) path() (string, error) {
+	dir, err := sapliyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "api_health.json"), nil
+}
+
+// load reads the state file once. s.mu must be held.
+func (s *hostHealthState) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.down = map[string]time.Time{}
+	if path, err := s.path(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &s.down)
+		}
+	}
+}
+
+// save writes the state file, dropping expired entries. s.mu must be held.
+func (s *hostHealthState) save() {
+	for h, until := range s.down {
+		if time.Now().After(until) {
+			delete(s.down, h)
+		}
+	}
+	path, err := s.path()
+	if err != nil {
+		return
+	}
+	data, _ := json.MarshalIndent(s.down, "", "  ")
+	os.WriteFile(path, data, 0600)
+}
+
+func (s *hostHealthState) isDown(host string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	until, ok := s.down[host]
+	return ok && time.Now().Before(until)
+}
+
+func (s *hostHealthState) markDown(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	s.down[host] = time.Now().Add(hostCooldown)
+	s.save()
+}
+
+func (s *hostHealthState) markUp(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	if _, ok := s.down[host]; ok {
+		delete(s.down, host)
+		s.save()
+	}
+}
+
+// matchAPIHost returns the configured host rawURL belongs to, or "".
+func matchAPIHost(hosts []string, rawURL string) string {
+	for _, h := range hosts {
+		if rawURL == h || strings.HasPrefix(rawURL, h+"/") || strings.HasPrefix(rawURL, h+"?") {
+			return h
+		}
+	}
+	return ""
+}
+
+// hostUnreachable reports whether err means the host could not be reached
+// at all. With sent set, only errors from before the request was written
+// count, since a request that reached the server must not be repeated.
+func hostUnreachable(err error, sent bool) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// The caller gave up (Ctrl+C or --timeout); the host may be fine.
+		return false
+	}
+	var certErr *tls.CertificateVerificationError
+	var unknown x509.UnknownAuthorityError
+	var residency *residencyError
+	if errors.As(err, &certErr) || errors.As(err, &unknown) || errors.As(err, &residency) {
+		// Every host would fail the same way.
+		return false
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial") {
+		return true
+	}
+	return !sent
+}
+
+// idempotent reports whether req can be repeated safely on another host
+// after it may have reached the first one.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// failoverTransport retries API requests on the next configured host when
+// the one they were sent to is unreachable, or answers 502/503/504 to a
+// request that is safe to repeat. Hosts that fail are skipped for
+// hostCooldown.
+type failoverTransport struct {
+	base http.RoundTripper
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hosts := apiHosts()
+	current := matchAPIHost(hosts, req.URL.String())
+	if len(hosts) < 2 || current == "" || (req.Body != nil && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	// Try the host the request was built for, then the others in order,
+	// leaving hosts known to be down until last.
+	order := []string{current}
+	var down []string
+	for _, h := range hosts {
+		switch {
+		case h == current:
+		case apiHealth.isDown(h):
+			down = append(down, h)
+		default:
+			order = append(order, h)
+		}
+	}
+	order = append(order, down...)
+	rest := strings.TrimPrefix(req.URL.String(), current)
+
+	var lastErr error
+	for i, host := range order {
+		r := req
+		if i > 0 {
+			u, err := url.Parse(host + rest)
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.URL, r.Host = u, ""
+			if req.GetBody != nil {
+				if r.Body, err = req.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  %s is unavailable (%v); trying %s\n", order[i-1], lastErr, host)
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if req.Context().Err() != nil {
+			// Out of time: other hosts would fail at once, and a slow host
+			// is not a down one.
+			return resp, err
+		}
+		retry := false
+		switch {
+		case err != nil:
+			retry = hostUnreachable(err, !idempotent(req))
+			lastErr = err
+		case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout:
+			retry = idempotent(req)
+			lastErr = fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+		if !retry || i == len(order)-1 {
+			if err == nil && !retry {
+				apiHealth.markUp(host)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		apiHealth.markDown(host)
+	}
+	return nil, lastErr
+}
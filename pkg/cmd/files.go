@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// fileIDPattern matches the IDs of uploaded files, which events and objects
+// such as dispute evidence and report runs refer to.
+var fileIDPattern = regexp.MustCompile(`^file_[A-Za-z0-9]+$`)
+
+// maxFileRefs is how many file references an inspect command looks up.
+const maxFileRefs = 20
+
+func filePath(fileID, suffix string) string {
+	return "/v1/files/" + url.PathEscape(fileID) + suffix
+}
+
+// formatSize formats a file size the way download messages do.
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	return fmt.Sprintf("%d KB", (size+1023)/1024)
+}
+
+// downloadFile writes the contents of a file to w and returns the number of
+// bytes written.
+func downloadFile(ctx context.Context, fileID string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL()+filePath(fileID, "/contents"), nil)
+	if err != nil {
+		return 0, err
+	}
+	setAuthHeaders(req)
+
+	resp, err := transferHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, &apiError{StatusCode: resp.StatusCode, Message: apiErrorMessage(resp.Body)}
+	}
+	return io.Copy(w, resp.Body)
+}
+
+// saveFile downloads f to out, a file or a directory to save it in under
+// its own name, and returns the path written and its size. An existing file
+// is only replaced with force. The download goes to a temporary file first
+// so a failed one does not leave a truncated file behind.
+func saveFile(ctx context.Context, f *uploadedFile, out string, force bool) (string, int64, error) {
+	if fi, err := os.Stat(out); (err == nil && fi.IsDir()) || strings.HasSuffix(out, string(os.PathSeparator)) || strings.HasSuffix(out, "/") {
+		// The name comes from the server, so only its base is used.
+		name := filepath.Base(filepath.FromSlash(f.Filename))
+		if name == "." || name == ".." || name == string(os.PathSeparator) {
+			name = f.ID
+		}
+		out = filepath.Join(out, name)
+	}
+	if !force {
+		if _, err := os.Stat(out); err == nil {
+			return "", 0, fmt.Errorf("%s already exists (use --force to overwrite)", out)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return "", 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".sapliy-file-*")
+	if err != nil {
+		return "", 0, err
+	}
+	size, err := downloadFile(ctx, f.ID, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && f.Size > 0 && size != f.Size {
+		err = fmt.Errorf("received %d of %d bytes", size, f.Size)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), out)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return out, size, nil
+}
+
+// fileRef is a file ID found in a payload, at a path --path accepts.
+type fileRef struct {
+	Path string
+	ID   string
+}
+
+// findFileRefs returns the file IDs in v, in the order they appear, each
+// once.
+func findFileRefs(v interface{}) []fileRef {
+	n, err := parseJSONNode(v)
+	if err != nil {
+		return nil
+	}
+	var refs []fileRef
+	seen := map[string]bool{}
+	var walk func(n *jsonNode, path string)
+	walk = func(n *jsonNode, path string) {
+		if s, ok := n.scalar.(string); ok && fileIDPattern.MatchString(s) && !seen[s] {
+			seen[s] = true
+			refs = append(refs, fileRef{Path: path, ID: s})
+		}
+		for i, child := range n.values {
+			if n.kind == '{' {
+				key := n.keys[i]
+				if path != "" {
+					key = path + "." + key
+				}
+				walk(child, key)
+			} else {
+				walk(child, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(n, "")
+	return refs
+}
+
+// printFileRefs prints what the files referenced in v are, with the command
+// that downloads each, so inspect commands do not leave opaque IDs. It
+// prints nothing when v has no file IDs.
+func printFileRefs(ctx context.Context, v interface{}) {
+	refs := findFileRefs(v)
+	if len(refs) == 0 {
+		return
+	}
+	fmt.Println("\nFiles:")
+	fmt.Println(strings.Repeat("─", 60))
+	for i, ref := range refs {
+		if i == maxFileRefs {
+			fmt.Printf("… and %d more\n", len(refs)-maxFileRefs)
+			break
+		}
+		var f uploadedFile
+		if err := apiRequest(ctx, http.MethodGet, filePath(ref.ID, ""), nil, &f); err != nil {
+			fmt.Printf("%s  %s  (%v)\n", ref.ID, ref.Path, err)
+			continue
+		}
+		fmt.Printf("%s  %s\n", ref.ID, ref.Path)
+		fmt.Printf("   %s, %s, %s\n", firstNonEmpty(f.Filename, "unnamed"), firstNonEmpty(f.Type, "unknown type"), formatSize(f.Size))
+		fmt.Printf("   sapliy files download %s\n", ref.ID)
+	}
+}
+
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "Look up and download uploaded files",
+	Long: `Files are documents stored by Sapliy, such as dispute evidence and report
+artifacts. Objects and events refer to them by ID (file_...); inspect commands
+list the files a payload refers to.`,
+}
+
+var filesGetCmd = &cobra.Command{
+	Use:   "get [file_id]",
+	Short: "Show a file's name, type and size",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var f uploadedFile
+		if err := apiRequest(context.Background(), http.MethodGet, filePath(args[0], ""), nil, &f); err != nil {
+			fmt.Printf("❌ Failed to fetch file: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(f, func() {
+			fmt.Printf("📎 File %s\n", f.ID)
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("Name:     %s\n", f.Filename)
+			fmt.Printf("Type:     %s\n", f.Type)
+			fmt.Printf("Size:     %s\n", formatSize(f.Size))
+			if f.Purpose != "" {
+				fmt.Printf("Purpose:  %s\n", f.Purpose)
+			}
+		})
+	},
+}
+
+var filesDownloadCmd = &cobra.Command{
+	Use:   "download [file_id]",
+	Short: "Download a file",
+	Long: `Download a file by ID. --out names the file to write, or a directory to save
+it in under its original name (the current directory by default); --out -
+writes it to stdout.`,
+	Example: `  sapliy files download file_123
+  sapliy files download file_123 --out evidence/
+  sapliy files download file_123 --out - | less`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{"regionScoped": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		out, _ := cmd.Flags().GetString("out")
+		if out == "-" {
+			if _, err := downloadFile(ctx, args[0], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to download file: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		var f uploadedFile
+		if err := apiRequest(ctx, http.MethodGet, filePath(args[0], ""), nil, &f); err != nil {
+			fmt.Printf("❌ Failed to fetch file: %v\n", err)
+			os.Exit(1)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		out, size, err := saveFile(ctx, &f, out, force)
+		if err != nil {
+			fmt.Printf("❌ Failed to download file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📎 Saved %s (%s, %s)\n", out, firstNonEmpty(f.Type, "unknown type"), formatSize(size))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+	filesCmd.AddCommand(filesGetCmd)
+	filesCmd.AddCommand(filesDownloadCmd)
+	filesDownloadCmd.Flags().String("out", ".", "File or directory to save to, or - for stdout")
+	filesDownloadCmd.Flags().Bool("force", false, "Overwrite an existing file")
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Deployed flows are their flow.json definition as the API returns it, with
+// "enabled" and "version" added, so they are kept as maps like the flows
+// 'flows lint --remote' checks.
+
+// flowEnabled reports whether a deployed flow runs; flows are enabled unless
+// the API says otherwise.
+func flowEnabled(doc map[string]interface{}) bool {
+	enabled, ok := doc["enabled"].(bool)
+	return !ok || enabled
+}
+
+func flowStatus(doc map[string]interface{}) string {
+	if flowEnabled(doc) {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func flowVersion(doc map[string]interface{}) string {
+	if v, ok := doc["version"].(float64); ok && v > 0 {
+		return fmt.Sprintf("v%d", int(v))
+	}
+	return "-"
+}
+
+func printFlow(doc map[string]interface{}) {
+	name := stepString(doc, "name")
+	if name == "" {
+		name = stepString(doc, "id")
+	}
+	fmt.Printf("⚡ Flow %s\n", name)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("ID:        %s\n", stepString(doc, "id"))
+	fmt.Printf("Status:    %s\n", flowStatus(doc))
+	fmt.Printf("Version:   %s\n", flowVersion(doc))
+	if events := flowEvents(doc); len(events) > 0 {
+		fmt.Printf("Triggers:  %s\n", strings.Join(events, ", "))
+	}
+	if updated := stepString(doc, "updatedAt"); updated != "" {
+		fmt.Printf("Updated:   %s\n", updated)
+	}
+
+	var steps []map[string]interface{}
+	walkSteps(doc["steps"], func(step map[string]interface{}) { steps = append(steps, step) })
+	if len(steps) == 0 {
+		return
+	}
+	fmt.Printf("\nSteps (%d):\n", len(steps))
+	for _, s := range steps {
+		fmt.Printf("  %-24s %s\n", stepString(s, "id"), stepString(s, "type"))
+	}
+}
+
+var flowsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the flows deployed to a zone",
+	Example: `  sapliy flows list
+  sapliy flows list --zone zone_123 -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		zone := requireZone(cmd)
+		var flows []map[string]interface{}
+		if err := apiRequest(context.Background(), http.MethodGet, zonePath(zone, "/flows"), nil, &flows); err != nil {
+			fmt.Printf("Error listing flows: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(flows, func() {
+			if len(flows) == 0 {
+				fmt.Printf("No flows in %s. Deploy one with 'sapliy flows deploy <flow.json>'.\n", zone)
+				return
+			}
+			fmt.Printf("%-24s %-8s %-7s %-30s %s\n", "ID", "STATUS", "VERSION", "TRIGGERS", "NAME")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, f := range flows {
+				fmt.Printf("%-24s %-8s %-7s %-30s %s\n", stepString(f, "id"), flowStatus(f), flowVersion(f),
+					truncate(strings.Join(flowEvents(f), ","), 30), stepString(f, "name"))
+			}
+		})
+	},
+}
+
+var flowsGetCmd = &cobra.Command{
+	Use:   "get [flow_id]",
+	Short: "Show a deployed flow and its steps",
+	Long: `Show a deployed flow: its status, version, triggers and steps. The full
+definition is printed with -o json.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var doc map[string]interface{}
+		if err := apiRequest(context.Background(), http.MethodGet, flowPath(requireZone(cmd), args[0], ""), nil, &doc); err != nil {
+			fmt.Printf("❌ Failed to fetch flow: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(doc, func() { printFlow(doc) })
+	},
+}
+
+func setFlowEnabled(enabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		change := map[string]bool{"enabled": enabled}
+		if err := apiRequest(context.Background(), http.MethodPatch, flowPath(requireZone(cmd), args[0], ""), change, nil); err != nil {
+			fmt.Printf("❌ Failed to update flow: %v\n", err)
+			os.Exit(1)
+		}
+		if enabled {
+			fmt.Printf("✅ Flow %s enabled\n", args[0])
+		} else {
+			fmt.Printf("⏸️  Flow %s disabled; events do not trigger it until it is enabled again\n", args[0])
+		}
+	}
+}
+
+var flowsEnableCmd = &cobra.Command{
+	Use:   "enable [flow_id]",
+	Short: "Let events trigger a flow again",
+	Args:  cobra.ExactArgs(1),
+	Run:   setFlowEnabled(true),
+}
+
+var flowsDisableCmd = &cobra.Command{
+	Use:   "disable [flow_id]",
+	Short: "Stop events from triggering a flow, keeping its definition",
+	Args:  cobra.ExactArgs(1),
+	Run:   setFlowEnabled(false),
+}
+
+var flowsDeleteCmd = &cobra.Command{
+	Use:   "delete [flow_id]",
+	Short: "Delete a deployed flow",
+	Long: `Delete a flow and all its versions from the zone. To stop it running but
+keep it, use 'sapliy flows disable'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		zone := requireZone(cmd)
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Delete flow %s from %s? This cannot be undone. [y/N]: ", args[0], zone)) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, flowPath(zone, args[0], ""), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete flow: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted flow %s\n", args[0])
+	},
+}
+
+func init() {
+	flowsCmd.AddCommand(flowsListCmd)
+	flowsCmd.AddCommand(flowsGetCmd)
+	flowsCmd.AddCommand(flowsEnableCmd)
+	flowsCmd.AddCommand(flowsDisableCmd)
+	flowsCmd.AddCommand(flowsDeleteCmd)
+
+	flowsDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
@@ -0,0 +1,374 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// fuzzKeptFields are never removed from a payload: without them the event
+// would not describe an object at all, rather than miss an optional field.
+var fuzzKeptFields = []string{"object", "id", "status", "amount", "currency"}
+
+// fuzzIntegers and fuzzFractions replace numbers, keeping integers integral
+// so payloads stay valid for the schema.
+var (
+	fuzzIntegers  = []float64{0, -1, 1, 2147483647, 2147483648, -2147483648, 9007199254740992, 1e21}
+	fuzzFractions = []float64{0.1, 0.005, 1e-9, -0.5}
+)
+
+// fuzzStrings replace strings.
+var fuzzStrings = []struct{ name, value string }{
+	{"empty string", ""},
+	{"whitespace", "   "},
+	{"10k chars", strings.Repeat("A", 10000)},
+	{"emoji ZWJ sequence", "👩‍👩‍👧‍👦"},
+	{"right-to-left override", "‮gnp.exe"},
+	{"NUL byte", "a\x00b"},
+	{"combining marks", "é̂̃̄"},
+	{"astral plane", "𝕊𝕒𝕡𝕝𝕚𝕪"},
+	{"newlines", "line1\nline2\r\n"},
+	{"template syntax", "{{event.data}}"},
+	{"quotes", `"';--`},
+	{"impossible date", "2026-02-30T25:61:00Z"},
+}
+
+// fuzzField is one value in a payload that can be mutated.
+type fuzzField struct {
+	path  string
+	value interface{}
+	set   func(v interface{})
+	drop  func() // nil for fields that are kept
+}
+
+// fuzzFields lists every field under v, parents before their children.
+func fuzzFields(v interface{}, path string, out []fuzzField) []fuzzField {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f := fuzzField{path: path + "." + k, value: c[k], set: func(v interface{}) { c[k] = v }}
+			if !slices.Contains(fuzzKeptFields, k) {
+				f.drop = func() { delete(c, k) }
+			}
+			out = append(out, f)
+			out = fuzzFields(c[k], f.path, out)
+		}
+	case []interface{}:
+		for i := range c {
+			f := fuzzField{path: fmt.Sprintf("%s.%d", path, i), value: c[i], set: func(v interface{}) { c[i] = v }}
+			out = append(out, f)
+			out = fuzzFields(c[i], f.path, out)
+		}
+	}
+	return out
+}
+
+// mutateField replaces a field with an adversarial value of the same type,
+// or removes it, and describes what it did. Objects and nulls are left
+// alone; their fields are mutated instead.
+func mutateField(rng *rand.Rand, f fuzzField) string {
+	if f.drop != nil && rng.IntN(4) == 0 {
+		f.drop()
+		return f.path + " removed"
+	}
+	switch v := f.value.(type) {
+	case float64:
+		choices := fuzzIntegers
+		if v != float64(int64(v)) {
+			choices = fuzzFractions
+		}
+		n := choices[rng.IntN(len(choices))]
+		f.set(n)
+		b, _ := json.Marshal(n)
+		return fmt.Sprintf("%s = %s", f.path, b)
+	case string:
+		s := fuzzStrings[rng.IntN(len(fuzzStrings))]
+		f.set(s.value)
+		return f.path + " = " + s.name
+	case bool:
+		f.set(!v)
+		return fmt.Sprintf("%s = %t", f.path, !v)
+	case []interface{}:
+		if len(v) > 0 && rng.IntN(2) == 0 {
+			items := make([]interface{}, 1000)
+			for i := range items {
+				items[i] = v[0]
+			}
+			f.set(items)
+			return f.path + " = 1000 items"
+		}
+		f.set([]interface{}{})
+		return f.path + " = []"
+	}
+	return ""
+}
+
+// fuzzPayload mutates one to three fields of data in place.
+func fuzzPayload(rng *rand.Rand, data map[string]interface{}) []string {
+	fields := fuzzFields(data, "data", nil)
+	var mutations, touched []string
+	for want := 1 + rng.IntN(3); len(mutations) < want && len(fields) > 0; {
+		i := rng.IntN(len(fields))
+		f := fields[i]
+		fields = slices.Delete(fields, i, i+1)
+		if slices.ContainsFunc(touched, func(p string) bool { return f.path == p || strings.HasPrefix(f.path, p+".") }) {
+			continue
+		}
+		if m := mutateField(rng, f); m != "" {
+			mutations = append(mutations, m)
+			touched = append(touched, f.path)
+		}
+	}
+	return mutations
+}
+
+// missingTemplateRefs returns the {{...}} references in a step's config that
+// the payload does not have. The runner renders them as empty strings, which
+// is rarely what the step meant.
+func missingTemplateRefs(v interface{}, scope map[string]interface{}) []string {
+	var missing []string
+	switch c := v.(type) {
+	case string:
+		for _, m := range stepTemplateExpr.FindAllStringSubmatch(c, -1) {
+			if val, ok := lookupPath(scope, m[1]); !ok || val == nil {
+				missing = append(missing, "{{"+m[1]+"}}")
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range c {
+			missing = append(missing, missingTemplateRefs(item, scope)...)
+		}
+	case []interface{}:
+		for _, item := range c {
+			missing = append(missing, missingTemplateRefs(item, scope)...)
+		}
+	}
+	sort.Strings(missing)
+	return slices.Compact(missing)
+}
+
+// fuzzFinding is a problem one fuzzed payload caused: a failed run, or a
+// step that used a field the payload did not have.
+type fuzzFinding struct {
+	Run       int                    `json:"run"`
+	Kind      string                 `json:"kind"` // failed or missing_field
+	Step      string                 `json:"step"`
+	Detail    string                 `json:"detail"`
+	Mutations []string               `json:"mutations"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+type fuzzReport struct {
+	Flow     string        `json:"flow"`
+	Source   string        `json:"source"`
+	Event    string        `json:"event"`
+	Seed     uint64        `json:"seed"`
+	Runs     int           `json:"runs"`
+	Failed   int           `json:"failed"`
+	Findings []fuzzFinding `json:"findings"`
+}
+
+// findFlow resolves the flow argument: a *.flow.json file, or the ID or name
+// of a flow under the current directory.
+func findFlow(arg string) (*localFlow, error) {
+	paths := []string{"."}
+	if _, err := os.Stat(arg); err == nil {
+		paths = []string{arg}
+	}
+	docs, err := loadLocalFlows(paths)
+	if err != nil {
+		return nil, err
+	}
+	var found []*localFlow
+	for _, doc := range docs {
+		f := newLocalFlow(doc.Source, doc.Doc)
+		if paths[0] == arg || f.ID == arg || f.Name == arg {
+			found = append(found, f)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no flow %q: pass a *.flow.json file, or the ID or name of a flow under the current directory", arg)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("%s has %d flows; pass a single *.flow.json file", arg, len(found))
+}
+
+var flowsFuzzCmd = &cobra.Command{
+	Use:   "fuzz [flow]",
+	Short: "Run a local flow against adversarial payloads",
+	Long: `Run a local flow many times against payloads that are valid for the event's
+schema but adversarial: boundary amounts, removed optional fields, huge
+strings, odd unicode, flipped booleans and empty or huge lists. Each payload
+is a sample of the event type (see 'sapliy webhooks generate-fixture') with
+one to three fields mutated.
+
+The flow is a *.flow.json file, or the ID or name of a flow under the current
+directory. It runs with the local flow runner, so http and webhook steps
+really call their URLs (point them at your local handlers) unless --dry-run
+is set. Runs that fail are reported with the mutations that caused them, and
+so are steps that use a {{field}} the payload did not have.
+
+Runs are reproducible: the same --seed gives the same payloads. --out saves
+the failing payloads as NDJSON, to replay with 'sapliy flows test --event'.
+The exit status is 1 if any run failed.`,
+	Example: `  sapliy flows fuzz flows/checkout.flow.json --event payment.created --runs 500
+  sapliy flows fuzz flow_checkout --seed 42 --out fuzz-failures.jsonl
+  sapliy flows test flows/checkout.flow.json --event fuzz-failures.jsonl`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		eventType, _ := cmd.Flags().GetString("event")
+		runs, _ := cmd.Flags().GetInt("runs")
+		seed, _ := cmd.Flags().GetUint64("seed")
+		out, _ := cmd.Flags().GetString("out")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		f, err := findFlow(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if eventType == "" {
+			var concrete []string
+			for _, e := range f.Events {
+				if !strings.Contains(e, "*") {
+					concrete = append(concrete, e)
+				}
+			}
+			if len(concrete) != 1 {
+				fmt.Printf("Error: %s triggers on %s; pick one with --event.\n", f.Name, firstNonEmpty(strings.Join(f.Events, ", "), "no events"))
+				os.Exit(1)
+			}
+			eventType = concrete[0]
+		} else if !matchesEventTypes(eventType, f.Events) {
+			fmt.Printf("⚠️  %s does not trigger on %s; running it anyway.\n", f.Name, eventType)
+		}
+		if _, err := generateFixture(eventType, seed); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			fmt.Println("   Run 'sapliy webhooks generate-fixture --list' for the supported event types.")
+			os.Exit(1)
+		}
+
+		runner := &localRunner{client: &http.Client{Timeout: timeout}, out: io.Discard, dryRun: dryRun}
+		report := fuzzReport{Flow: f.Name, Source: f.Source, Event: eventType, Seed: seed, Runs: runs, Findings: []fuzzFinding{}}
+		var failing []batchEvent
+		for n := 1; n <= runs; n++ {
+			rng := rand.New(rand.NewPCG(seed, uint64(n)))
+			fixture, _ := generateFixture(eventType, seed+uint64(n))
+			data, _ := fixture["data"].(map[string]interface{})
+			mutations := fuzzPayload(rng, data)
+			e := &localEvent{ID: fmt.Sprintf("evt_fuzz_%d", n), Type: eventType, Data: data, CreatedAt: flowTestEpoch}
+
+			run := runner.run(f, e)
+			scope := eventScope(e)
+			for _, s := range run.Steps {
+				if s.Status == "failed" && run.Status == "failed" {
+					report.Findings = append(report.Findings, fuzzFinding{Run: n, Kind: "failed", Step: s.Step, Detail: s.Detail, Mutations: mutations, Data: data})
+					continue
+				}
+				if s.Status == "skipped" || s.Status == "filtered" {
+					continue
+				}
+				for _, ref := range missingTemplateRefs(f.steps[s.Step]["config"], scope) {
+					report.Findings = append(report.Findings, fuzzFinding{Run: n, Kind: "missing_field", Step: s.Step, Detail: ref, Mutations: mutations, Data: data})
+				}
+			}
+			if run.Status == "failed" {
+				report.Failed++
+				failing = append(failing, batchEvent{Type: eventType, Data: data, line: n})
+			}
+		}
+
+		if out != "" {
+			var buf strings.Builder
+			for _, e := range failing {
+				line, _ := json.Marshal(e)
+				fmt.Fprintf(&buf, "# run %d\n%s\n", e.line, line)
+			}
+			if err := os.WriteFile(out, []byte(buf.String()), 0644); err != nil {
+				fmt.Printf("Error writing %s: %v\n", out, err)
+				os.Exit(1)
+			}
+		}
+
+		printOutput(report, func() {
+			fmt.Printf("🎲 Fuzzed %s (%s) with %d %s payloads, seed %d\n", f.Name, f.Source, runs, eventType, seed)
+
+			// Group findings by what went wrong where, most frequent first.
+			groups := map[string][]fuzzFinding{}
+			var keys []string
+			for _, fd := range report.Findings {
+				key := fd.Kind + "\x00" + fd.Step
+				if fd.Kind == "missing_field" {
+					key += "\x00" + fd.Detail
+				}
+				if groups[key] == nil {
+					keys = append(keys, key)
+				}
+				groups[key] = append(groups[key], fd)
+			}
+			sort.SliceStable(keys, func(i, j int) bool { return len(groups[keys[i]]) > len(groups[keys[j]]) })
+
+			for _, key := range keys {
+				g := groups[key]
+				if g[0].Kind == "failed" {
+					fmt.Printf("\n❌ step %s failed in %d run(s)\n", firstNonEmpty(g[0].Step, "(runner)"), len(g))
+				} else {
+					fmt.Printf("\n⚠️  step %s used %s, which was missing in %d run(s)\n", g[0].Step, g[0].Detail, len(g))
+				}
+				// The examples with the fewest mutations are the easiest to
+				// understand.
+				sort.SliceStable(g, func(i, j int) bool { return len(g[i].Mutations) < len(g[j].Mutations) })
+				for _, fd := range g[:min(3, len(g))] {
+					fmt.Printf("   run %d: %s", fd.Run, strings.Join(fd.Mutations, ", "))
+					if fd.Kind == "failed" {
+						fmt.Printf(" → %s", truncate(fd.Detail, 60))
+					}
+					fmt.Println()
+				}
+			}
+
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("%d run(s): %d succeeded, %d failed; %d finding(s)\n", runs, runs-report.Failed, report.Failed, len(report.Findings))
+			if report.Failed > 0 {
+				if out != "" {
+					fmt.Printf("Replay the failing payloads with: sapliy flows test %s --event %s\n", f.Source, out)
+				} else {
+					fmt.Println("Save the failing payloads with --out to replay them with 'sapliy flows test --event'.")
+				}
+			}
+		})
+
+		if report.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	flowsCmd.AddCommand(flowsFuzzCmd)
+	flowsFuzzCmd.Flags().String("event", "", "Event type to fuzz (default: the flow's trigger)")
+	flowsFuzzCmd.Flags().Int("runs", 500, "Number of payloads to run")
+	flowsFuzzCmd.Flags().Uint64("seed", 1, "Seed for the payloads (same seed, same payloads)")
+	flowsFuzzCmd.Flags().String("out", "", "Write the payloads of failed runs to this NDJSON file")
+	flowsFuzzCmd.Flags().Bool("dry-run", false, "Simulate http and webhook steps instead of calling them")
+	flowsFuzzCmd.Flags().Duration("timeout", 10*time.Second, "Timeout for each HTTP call a step makes")
+}
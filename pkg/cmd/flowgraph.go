@@ -0,0 +1,513 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A flow is a list of steps that run in order. A step can jump elsewhere
+// with "next" (a step ID or a list of them), route failures to a step with
+// "onError", and contain nested "steps" or "branches", each of which falls
+// through to whatever follows the containing step. Conditions live in a
+// step's or branch's "condition".
+
+// sideEffectStepTypes mark steps that change something outside the flow and
+// so should say what happens when they fail.
+var sideEffectStepTypes = []string{"http", "webhook", "email", "sms", "notify", "charge", "refund", "payout", "transfer", "ledger"}
+
+// loopLimitKeys bound a cycle; a loop with none of them can run forever.
+var loopLimitKeys = []string{"maxIterations", "maxAttempts", "limit"}
+
+type flowGraph struct {
+	steps   map[string]map[string]interface{}
+	order   []string
+	edges   map[string][]string
+	entries []string
+	// missing maps a step to next/onError targets that do not exist.
+	missing map[string][]string
+	dupes   []string
+}
+
+func stepString(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// stepTargets reads a "next" value, which is a step ID or a list of them.
+func stepTargets(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var targets []string
+		for _, t := range v {
+			if s, ok := t.(string); ok && s != "" {
+				targets = append(targets, s)
+			}
+		}
+		return targets
+	}
+	return nil
+}
+
+func stepList(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var steps []map[string]interface{}
+	for _, s := range list {
+		if step, ok := s.(map[string]interface{}); ok && stepString(step, "id") != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// errorHandler returns the step a step's failures are routed to.
+func errorHandler(step map[string]interface{}) string {
+	if h := stepString(step, "onError"); h != "" {
+		return h
+	}
+	config, _ := step["config"].(map[string]interface{})
+	return stepString(config, "onError")
+}
+
+func buildFlowGraph(doc map[string]interface{}) *flowGraph {
+	g := &flowGraph{
+		steps:   map[string]map[string]interface{}{},
+		edges:   map[string][]string{},
+		missing: map[string][]string{},
+	}
+
+	var add func(steps []map[string]interface{}, after string)
+	add = func(steps []map[string]interface{}, after string) {
+		for i, step := range steps {
+			id := stepString(step, "id")
+			if _, dup := g.steps[id]; dup {
+				g.dupes = append(g.dupes, id)
+				continue
+			}
+			g.steps[id] = step
+			g.order = append(g.order, id)
+
+			following := after
+			if i+1 < len(steps) {
+				following = stepString(steps[i+1], "id")
+			}
+
+			nested := stepList(step["steps"])
+			branches, _ := step["branches"].([]interface{})
+			switch {
+			case step["next"] != nil:
+				g.edges[id] = append(g.edges[id], stepTargets(step["next"])...)
+			case len(nested) > 0:
+				g.edges[id] = append(g.edges[id], stepString(nested[0], "id"))
+			case len(branches) > 0:
+			case step["type"] == "end":
+			case following != "":
+				g.edges[id] = append(g.edges[id], following)
+			}
+			if len(nested) > 0 {
+				add(nested, following)
+			}
+
+			for _, b := range branches {
+				branch, ok := b.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				branchSteps := stepList(branch["steps"])
+				switch {
+				case branch["next"] != nil:
+					g.edges[id] = append(g.edges[id], stepTargets(branch["next"])...)
+				case len(branchSteps) > 0:
+					g.edges[id] = append(g.edges[id], stepString(branchSteps[0], "id"))
+					add(branchSteps, following)
+				case following != "":
+					g.edges[id] = append(g.edges[id], following)
+				}
+			}
+
+			if h := errorHandler(step); h != "" {
+				g.edges[id] = append(g.edges[id], h)
+			}
+		}
+	}
+	add(stepList(doc["steps"]), "")
+
+	for _, id := range g.order {
+		if g.steps[id]["type"] == "trigger" {
+			g.entries = append(g.entries, id)
+		}
+		for _, to := range g.edges[id] {
+			if _, ok := g.steps[to]; !ok {
+				g.missing[id] = append(g.missing[id], to)
+			}
+		}
+	}
+	if len(g.entries) == 0 && len(g.order) > 0 {
+		g.entries = []string{g.order[0]}
+	}
+	return g
+}
+
+func (g *flowGraph) reachable() map[string]bool {
+	seen := map[string]bool{}
+	queue := append([]string(nil), g.entries...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		queue = append(queue, g.edges[id]...)
+	}
+	return seen
+}
+
+// cycles returns each cycle once, as the steps on it in traversal order.
+func (g *flowGraph) cycles() [][]string {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	var found [][]string
+	seen := map[string]bool{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = onStack
+		stack = append(stack, id)
+		for _, to := range g.edges[id] {
+			if _, ok := g.steps[to]; !ok {
+				continue
+			}
+			switch state[to] {
+			case unvisited:
+				visit(to)
+			case onStack:
+				start := len(stack) - 1
+				for stack[start] != to {
+					start--
+				}
+				cycle := append([]string(nil), stack[start:]...)
+				key := append([]string(nil), cycle...)
+				sort.Strings(key)
+				if k := strings.Join(key, "\x00"); !seen[k] {
+					seen[k] = true
+					found = append(found, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+	for _, id := range g.order {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return found
+}
+
+// conditionLiteral parses a literal operand: a number, a quoted string,
+// true, false or null.
+func conditionLiteral(s string) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null", "nil":
+		return nil, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return nil, false
+}
+
+var conditionComparison = regexp.MustCompile(`^(.+?)\s*(==|!=|>=|<=|>|<)\s*(.+)$`)
+
+func compareLiterals(a interface{}, op string, b interface{}) bool {
+	af, aNum := a.(float64)
+	bf, bNum := b.(float64)
+	if aNum && bNum {
+		switch op {
+		case "==":
+			return af == bf
+		case "!=":
+			return af != bf
+		case ">":
+			return af > bf
+		case ">=":
+			return af >= bf
+		case "<":
+			return af < bf
+		case "<=":
+			return af <= bf
+		}
+	}
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	}
+	// Ordering non-numbers is left to the runtime.
+	return true
+}
+
+// operandBounds collects what a conjunction requires of one operand.
+type operandBounds struct {
+	equals     []interface{}
+	notEquals  []interface{}
+	lower      *float64
+	lowerIncl  bool
+	upper      *float64
+	upperIncl  bool
+	contradict string
+}
+
+func (b *operandBounds) add(op string, v interface{}) {
+	f, isNum := v.(float64)
+	switch op {
+	case "==":
+		b.equals = append(b.equals, v)
+	case "!=":
+		b.notEquals = append(b.notEquals, v)
+	case ">", ">=":
+		if isNum && (b.lower == nil || f > *b.lower || (f == *b.lower && op == ">")) {
+			b.lower, b.lowerIncl = &f, op == ">="
+		}
+	case "<", "<=":
+		if isNum && (b.upper == nil || f < *b.upper || (f == *b.upper && op == "<")) {
+			b.upper, b.upperIncl = &f, op == "<="
+		}
+	}
+}
+
+func (b *operandBounds) impossible() bool {
+	for _, e := range b.equals[min(1, len(b.equals)):] {
+		if e != b.equals[0] {
+			return true
+		}
+	}
+	for _, e := range b.equals {
+		for _, n := range b.notEquals {
+			if e == n {
+				return true
+			}
+		}
+		if f, ok := e.(float64); ok {
+			if b.lower != nil && (f < *b.lower || (f == *b.lower && !b.lowerIncl)) {
+				return true
+			}
+			if b.upper != nil && (f > *b.upper || (f == *b.upper && !b.upperIncl)) {
+				return true
+			}
+		}
+	}
+	if b.lower != nil && b.upper != nil {
+		return *b.lower > *b.upper || (*b.lower == *b.upper && !(b.lowerIncl && b.upperIncl))
+	}
+	return false
+}
+
+// conditionNeverTrue reports why expr can never be true, or "" when it can
+// (or cannot be decided statically). It understands literals, comparisons
+// and && / || of them.
+func conditionNeverTrue(expr string) string {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}"))
+	if expr == "" {
+		return ""
+	}
+
+	var reasons []string
+	for _, disjunct := range strings.Split(expr, "||") {
+		reason := conjunctionNeverTrue(disjunct)
+		if reason == "" {
+			return ""
+		}
+		reasons = append(reasons, reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func conjunctionNeverTrue(expr string) string {
+	bounds := map[string]*operandBounds{}
+	for _, term := range strings.Split(expr, "&&") {
+		term = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(term), "("), ")"))
+		if v, ok := conditionLiteral(term); ok {
+			if v == false || v == nil || v == 0.0 || v == "" {
+				return fmt.Sprintf("%q is always false", term)
+			}
+			continue
+		}
+
+		m := conditionComparison.FindStringSubmatch(term)
+		if m == nil {
+			continue
+		}
+		left, op, right := strings.TrimSpace(m[1]), m[2], strings.TrimSpace(m[3])
+		lv, lLit := conditionLiteral(left)
+		rv, rLit := conditionLiteral(right)
+		switch {
+		case lLit && rLit:
+			if !compareLiterals(lv, op, rv) {
+				return fmt.Sprintf("%q compares two constants and is always false", term)
+			}
+		case left == right:
+			if op == "!=" || op == ">" || op == "<" {
+				return fmt.Sprintf("%q compares a value with itself and is always false", term)
+			}
+		case rLit:
+			if bounds[left] == nil {
+				bounds[left] = &operandBounds{}
+			}
+			bounds[left].add(op, rv)
+		case lLit:
+			flipped := map[string]string{">": "<", "<": ">", ">=": "<=", "<=": ">="}[op]
+			if flipped == "" {
+				flipped = op
+			}
+			if bounds[right] == nil {
+				bounds[right] = &operandBounds{}
+			}
+			bounds[right].add(flipped, lv)
+		}
+	}
+
+	operands := make([]string, 0, len(bounds))
+	for operand := range bounds {
+		operands = append(operands, operand)
+	}
+	sort.Strings(operands)
+	for _, operand := range operands {
+		if bounds[operand].impossible() {
+			return fmt.Sprintf("the constraints on %s contradict each other", operand)
+		}
+	}
+	return ""
+}
+
+func isSideEffectStep(stepType string) bool {
+	for _, t := range sideEffectStepTypes {
+		if strings.Contains(stepType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeFlowLogic looks for logic bugs in a flow: references to steps that
+// do not exist, unreachable steps, loops that cannot end, conditions that
+// can never be true and side effects without an error handler.
+func analyzeFlowLogic(f lintedFlow) []lintFinding {
+	flowName := resourceName(f.Doc)
+	var findings []lintFinding
+	add := func(step, severity, rule, message, hint string) {
+		findings = append(findings, lintFinding{
+			Source:   f.Source,
+			Flow:     flowName,
+			Step:     step,
+			Severity: severity,
+			Rule:     rule,
+			Message:  message,
+			Hint:     hint,
+		})
+	}
+
+	g := buildFlowGraph(f.Doc)
+	for _, id := range g.dupes {
+		add(id, "error", "duplicate-step", "Step ID "+id+" is used more than once", "Give every step a unique id; later duplicates are ignored.")
+	}
+	for _, id := range g.order {
+		for _, to := range g.missing[id] {
+			add(id, "error", "unknown-step", fmt.Sprintf("Refers to step %q, which does not exist", to), "Fix the next/onError reference.")
+		}
+	}
+
+	reachable := g.reachable()
+	handlers := map[string]bool{}
+	for _, id := range g.order {
+		if h := errorHandler(g.steps[id]); h != "" {
+			handlers[h] = true
+		}
+	}
+	for _, id := range g.order {
+		if !reachable[id] {
+			add(id, "warning", "unreachable-step", "Step can never run: no path from the trigger leads to it", "Connect it with next or remove it.")
+		}
+	}
+
+	for _, cycle := range g.cycles() {
+		if !reachable[cycle[0]] {
+			continue
+		}
+		onCycle := map[string]bool{}
+		limited := false
+		for _, id := range cycle {
+			onCycle[id] = true
+			config, _ := g.steps[id]["config"].(map[string]interface{})
+			for _, key := range loopLimitKeys {
+				limited = limited || g.steps[id][key] != nil || config[key] != nil
+			}
+		}
+		if limited {
+			continue
+		}
+		exits := false
+		for _, id := range cycle {
+			for _, to := range g.edges[id] {
+				exits = exits || !onCycle[to]
+			}
+		}
+		path := strings.Join(append(cycle, cycle[0]), " → ")
+		if exits {
+			add(cycle[0], "warning", "unbounded-loop", "Loop "+path+" has no iteration limit", "Set maxIterations on one of its steps so a stuck condition cannot loop forever.")
+		} else {
+			add(cycle[0], "error", "infinite-loop", "Loop "+path+" has no way out and never ends", "Add a condition branch that leaves the loop, or a maxIterations limit.")
+		}
+	}
+
+	checkCondition := func(step string, v interface{}) {
+		expr, _ := v.(string)
+		if reason := conditionNeverTrue(expr); reason != "" {
+			add(step, "warning", "never-true", fmt.Sprintf("Condition %q can never be true: %s", expr, reason), "Steps behind it never run; fix the comparison or remove the branch.")
+		}
+	}
+	for _, id := range g.order {
+		step := g.steps[id]
+		config, _ := step["config"].(map[string]interface{})
+		checkCondition(id, step["condition"])
+		checkCondition(id, config["condition"])
+		branches, _ := step["branches"].([]interface{})
+		for _, b := range branches {
+			if branch, ok := b.(map[string]interface{}); ok {
+				checkCondition(id, branch["condition"])
+			}
+		}
+	}
+
+	if stepString(f.Doc, "onError") == "" {
+		for _, id := range g.order {
+			if isSideEffectStep(stepString(g.steps[id], "type")) && errorHandler(g.steps[id]) == "" && !handlers[id] {
+				add(id, "warning", "missing-error-handler", fmt.Sprintf("%s step has no error handler; a failure stops the flow silently", stepString(g.steps[id], "type")), "Set onError to a step that compensates or alerts, or a flow-level onError.")
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,494 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRunSteps stops a local run that loops without an end.
+const maxRunSteps = 1000
+
+// localFlow is a flow from the manifests directory, indexed for running.
+type localFlow struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Source string   `json:"source"`
+	Events []string `json:"events"`
+
+	entry  string
+	steps  map[string]map[string]interface{}
+	follow map[string]string // the step each step falls through to
+}
+
+func newLocalFlow(source string, doc map[string]interface{}) *localFlow {
+	f := &localFlow{
+		ID:     stepString(doc, "id"),
+		Name:   stepString(doc, "name"),
+		Source: source,
+		Events: flowEvents(doc),
+		steps:  map[string]map[string]interface{}{},
+		follow: map[string]string{},
+	}
+	if f.Name == "" {
+		f.Name = f.ID
+	}
+	var index func(steps []map[string]interface{}, after string)
+	index = func(steps []map[string]interface{}, after string) {
+		for i, step := range steps {
+			id := stepString(step, "id")
+			f.steps[id] = step
+			f.follow[id] = after
+			if i+1 < len(steps) {
+				f.follow[id] = stepString(steps[i+1], "id")
+			}
+			index(stepList(step["steps"]), f.follow[id])
+			branches, _ := step["branches"].([]interface{})
+			for _, b := range branches {
+				if branch, ok := b.(map[string]interface{}); ok {
+					index(stepList(branch["steps"]), f.follow[id])
+				}
+			}
+		}
+	}
+	index(stepList(doc["steps"]), "")
+	if g := buildFlowGraph(doc); len(g.entries) > 0 {
+		f.entry = g.entries[0]
+	}
+	return f
+}
+
+// localEvent is an event triggered against sapliy dev.
+type localEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Zone      string                 `json:"zoneId,omitempty"`
+	Data      map[string]interface{} `json:"data"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// stepResult is what one step did in a local run.
+type stepResult struct {
+	Step   string `json:"step"`
+	Type   string `json:"type"`
+	Status string `json:"status"` // ok, failed, skipped, filtered or simulated
+	Detail string `json:"detail,omitempty"`
+	// Output is what the step produced: a log message, the request an http
+	// step sent and the status it got, or a simulated step's config.
+	Output interface{} `json:"output,omitempty"`
+}
+
+// flowRun is one execution of a flow by the local runner.
+type flowRun struct {
+	ID         string       `json:"id"`
+	Flow       string       `json:"flow"`
+	Event      string       `json:"event"`
+	EventType  string       `json:"eventType"`
+	Status     string       `json:"status"` // succeeded or failed
+	Steps      []stepResult `json:"steps"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+}
+
+// localRunner runs the flows loaded from a manifests directory against
+// events, the way the Flow Runner would. Steps that call out over HTTP
+// (http, webhook) really run so local handlers can be exercised; steps with
+// other side effects (email, charge, ...) are simulated and only logged.
+type localRunner struct {
+	client *http.Client
+	out    io.Writer
+	// dryRun simulates http and webhook steps too, so runs are repeatable.
+	dryRun bool
+
+	mu    sync.RWMutex
+	flows []*localFlow
+	runs  []*flowRun
+	seq   int
+}
+
+func (r *localRunner) setFlows(flows []*localFlow) {
+	r.mu.Lock()
+	r.flows = flows
+	r.mu.Unlock()
+}
+
+func (r *localRunner) loadedFlows() []*localFlow {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.flows
+}
+
+// recentRuns returns copies of the latest runs, newest first.
+func (r *localRunner) recentRuns(limit int) []flowRun {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	runs := []flowRun{}
+	for i := len(r.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		runs = append(runs, *r.runs[i])
+	}
+	return runs
+}
+
+// dispatch runs every flow triggered by the event's type.
+func (r *localRunner) dispatch(e *localEvent) {
+	for _, f := range r.loadedFlows() {
+		if len(f.Events) == 0 || !matchesEventTypes(e.Type, f.Events) {
+			continue
+		}
+		run := r.run(f, e)
+		icon := "✅"
+		if run.Status != "succeeded" {
+			icon = "❌"
+		}
+		fmt.Fprintf(r.out, "[%s] %s %s ← %s (%d step(s), %s)\n", run.StartedAt.Format("15:04:05"), icon, f.Name, e.Type,
+			len(run.Steps), run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond))
+		for _, s := range run.Steps {
+			if s.Status == "ok" && s.Detail == "" {
+				continue
+			}
+			fmt.Fprintf(r.out, "     %-9s %-20s %s\n", s.Status, s.Step, s.Detail)
+		}
+	}
+}
+
+func (r *localRunner) run(f *localFlow, e *localEvent) *flowRun {
+	r.mu.Lock()
+	r.seq++
+	run := &flowRun{ID: fmt.Sprintf("run_local_%d", r.seq), Flow: f.Name, Event: e.ID, EventType: e.Type, StartedAt: time.Now()}
+	r.runs = append(r.runs, run)
+	if len(r.runs) > 500 {
+		r.runs = r.runs[len(r.runs)-500:]
+	}
+	r.mu.Unlock()
+
+	scope := eventScope(e)
+	var results []stepResult
+	status := "succeeded"
+	queue := []string{f.entry}
+	for executed := 0; len(queue) > 0; executed++ {
+		if executed == maxRunSteps {
+			results = append(results, stepResult{Status: "failed", Detail: fmt.Sprintf("stopped after %d steps; does the flow loop?", maxRunSteps)})
+			status = "failed"
+			break
+		}
+		id := queue[0]
+		queue = queue[1:]
+		if id == "" {
+			continue
+		}
+		step, ok := f.steps[id]
+		if !ok {
+			results = append(results, stepResult{Step: id, Status: "failed", Detail: "no such step"})
+			status = "failed"
+			break
+		}
+
+		if cond, ok := step["condition"].(string); ok && cond != "" && !evalCondition(cond, scope) {
+			results = append(results, stepResult{Step: id, Type: stepString(step, "type"), Status: "skipped", Detail: "condition false"})
+			queue = append(queue, f.follow[id])
+			continue
+		}
+		res := r.execute(id, step, scope)
+		results = append(results, res)
+		if res.Status == "failed" {
+			if h := errorHandler(step); h != "" {
+				queue = append(queue, h)
+				continue
+			}
+			status = "failed"
+			break
+		}
+		if step["type"] == "end" || res.Status == "filtered" {
+			break
+		}
+		queue = append(queue, f.nextSteps(id, step, scope)...)
+	}
+
+	r.mu.Lock()
+	run.Steps, run.Status, run.FinishedAt = results, status, time.Now()
+	r.mu.Unlock()
+	return run
+}
+
+// eventScope is what step templates and conditions see: the triggering
+// event as {{event.*}}.
+func eventScope(e *localEvent) map[string]interface{} {
+	return map[string]interface{}{"event": map[string]interface{}{
+		"id": e.ID, "type": e.Type, "zoneId": e.Zone, "data": e.Data, "createdAt": e.CreatedAt.Format(time.RFC3339),
+	}}
+}
+
+// nextSteps returns where a step that ran goes next: its "next", the first
+// branch whose condition holds, its nested steps, or the step after it.
+func (f *localFlow) nextSteps(id string, step map[string]interface{}, scope map[string]interface{}) []string {
+	if step["next"] != nil {
+		return stepTargets(step["next"])
+	}
+	if branches, ok := step["branches"].([]interface{}); ok && len(branches) > 0 {
+		for _, b := range branches {
+			branch, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if cond, ok := branch["condition"].(string); ok && cond != "" && !evalCondition(cond, scope) {
+				continue
+			}
+			if branch["next"] != nil {
+				return stepTargets(branch["next"])
+			}
+			if nested := stepList(branch["steps"]); len(nested) > 0 {
+				return []string{stepString(nested[0], "id")}
+			}
+			break
+		}
+		return []string{f.follow[id]}
+	}
+	if nested := stepList(step["steps"]); len(nested) > 0 {
+		return []string{stepString(nested[0], "id")}
+	}
+	return []string{f.follow[id]}
+}
+
+func (r *localRunner) execute(id string, step map[string]interface{}, scope map[string]interface{}) stepResult {
+	kind := stepString(step, "type")
+	res := stepResult{Step: id, Type: kind, Status: "ok"}
+	config, _ := step["config"].(map[string]interface{})
+	switch kind {
+	case "trigger", "end", "":
+	case "condition", "filter":
+		if cond := stepString(config, "condition"); cond != "" && !evalCondition(cond, scope) {
+			res.Status, res.Detail = "filtered", "condition false, run ends"
+		}
+	case "log":
+		res.Detail = expandStepTemplate(stepString(config, "message"), scope)
+		res.Output = res.Detail
+	case "delay", "wait":
+		res.Status, res.Detail = "simulated", "would wait "+firstNonEmpty(stepString(config, "duration"), "(no duration)")
+	case "http", "webhook":
+		return r.callHTTP(res, config, scope)
+	default:
+		if step["branches"] != nil || step["steps"] != nil {
+			break // only routes to other steps
+		}
+		res.Status = "simulated"
+		res.Output = expandStepValue(config, scope)
+		b, _ := json.Marshal(res.Output)
+		res.Detail = truncate(string(b), 80)
+	}
+	return res
+}
+
+// callHTTP sends an http or webhook step's request. The body is the step's
+// "body" config, templated, or the triggering event.
+func (r *localRunner) callHTTP(res stepResult, config map[string]interface{}, scope map[string]interface{}) stepResult {
+	target := expandStepTemplate(stepString(config, "url"), scope)
+	if target == "" {
+		res.Status, res.Detail = "failed", "no url in config"
+		return res
+	}
+	method := strings.ToUpper(firstNonEmpty(stepString(config, "method"), http.MethodPost))
+	payload := scope["event"]
+	if body, ok := config["body"]; ok {
+		payload = expandStepValue(body, scope)
+	}
+	sent := map[string]interface{}{"method": method, "url": target, "body": payload}
+	res.Output = sent
+	if r.dryRun {
+		res.Status, res.Detail = "simulated", method+" "+target
+		return res
+	}
+	b, _ := json.Marshal(payload)
+	req, err := http.NewRequest(method, target, bytes.NewReader(b))
+	if err != nil {
+		res.Status, res.Detail = "failed", err.Error()
+		return res
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for k, v := range headers {
+			req.Header.Set(k, expandStepTemplate(fmt.Sprint(v), scope))
+		}
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		res.Status, res.Detail = "failed", err.Error()
+		return res
+	}
+	resp.Body.Close()
+	sent["status"] = resp.StatusCode
+	res.Detail = fmt.Sprintf("%s %s → %d", method, target, resp.StatusCode)
+	if resp.StatusCode >= 400 {
+		res.Status = "failed"
+	}
+	return res
+}
+
+var stepTemplateExpr = regexp.MustCompile(`\{\{\s*([^}]+?)\s*\}\}`)
+
+// expandStepTemplate replaces {{event.data.x}} references with their values.
+// References to missing fields become empty.
+func expandStepTemplate(s string, scope map[string]interface{}) string {
+	return stepTemplateExpr.ReplaceAllStringFunc(s, func(m string) string {
+		v, ok := lookupPath(scope, stepTemplateExpr.FindStringSubmatch(m)[1])
+		if !ok || v == nil {
+			return ""
+		}
+		if _, isString := v.(string); !isString {
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+		return v.(string)
+	})
+}
+
+// expandStepValue expands templates in every string of a config value. A
+// string that is a single reference keeps the referenced value's type.
+func expandStepValue(v interface{}, scope map[string]interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if m := stepTemplateExpr.FindStringSubmatch(v); m != nil && m[0] == strings.TrimSpace(v) {
+			if ref, ok := lookupPath(scope, m[1]); ok {
+				return ref
+			}
+		}
+		return expandStepTemplate(v, scope)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			out[k] = expandStepValue(child, scope)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = expandStepValue(child, scope)
+		}
+		return out
+	}
+	return v
+}
+
+// lookupPath resolves a dotted path such as event.data.amount in scope.
+// A leading $ is ignored.
+func lookupPath(scope map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = scope
+	for _, part := range strings.Split(strings.TrimPrefix(path, "$"), ".") {
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			v, ok := c[part]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			cur = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// evalCondition evaluates a condition at run time. It understands the same
+// expressions the linter does: literals, event field references,
+// comparisons, and && / || of them.
+func evalCondition(expr string, scope map[string]interface{}) bool {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}"))
+	for _, disjunct := range strings.Split(expr, "||") {
+		all := true
+		for _, term := range strings.Split(disjunct, "&&") {
+			term = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(term), "("), ")"))
+			if !evalTerm(term, scope) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func evalTerm(term string, scope map[string]interface{}) bool {
+	negate := strings.HasPrefix(term, "!") && !strings.HasPrefix(term, "!=")
+	if negate {
+		return !evalTerm(strings.TrimSpace(term[1:]), scope)
+	}
+	if m := conditionComparison.FindStringSubmatch(term); m != nil {
+		return compareValues(operandValue(m[1], scope), m[2], operandValue(m[3], scope))
+	}
+	return truthy(operandValue(term, scope))
+}
+
+func operandValue(s string, scope map[string]interface{}) interface{} {
+	s = strings.TrimSpace(s)
+	if v, ok := conditionLiteral(s); ok {
+		return v
+	}
+	v, _ := lookupPath(scope, s)
+	return v
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// compareValues compares two operands: numerically when both are numbers,
+// as strings otherwise.
+func compareValues(a interface{}, op string, b interface{}) bool {
+	_, aNum := a.(float64)
+	_, bNum := b.(float64)
+	if aNum && bNum {
+		return compareLiterals(a, op, b)
+	}
+	if a == nil || b == nil {
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+		return false
+	}
+	c := strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
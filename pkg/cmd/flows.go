@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// A deprecation is one entry of the platform's deprecation feed. Kind says
+// what it applies to:
+//
+//	step_type    Target is a step type that is going away
+//	event_field  Field (e.g. data.card_last4) of Event is going away
+//	schema       the payload schema of Event is about to change
+//
+// Severity is "deprecated", "removed" or "changing".
+type deprecation struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Target      string `json:"target,omitempty"`
+	Event       string `json:"event,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Severity    string `json:"severity"`
+	Replacement string `json:"replacement,omitempty"`
+	Hint        string `json:"hint,omitempty"`
+	EffectiveAt string `json:"effectiveAt,omitempty"`
+	DocsURL     string `json:"docsUrl,omitempty"`
+}
+
+// lintFinding is a problem found in a flow: the use of a deprecated
+// feature (Rule "deprecation") or a logic bug (see analyzeFlowLogic).
+type lintFinding struct {
+	Source      string `json:"source"`
+	Flow        string `json:"flow"`
+	Step        string `json:"step,omitempty"`
+	Severity    string `json:"severity"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+	Hint        string `json:"hint,omitempty"`
+	Deprecation string `json:"deprecation,omitempty"`
+}
+
+// lintedFlow is a flow document and where it came from: a file path for
+// local flows, or "zone <id>" for deployed ones.
+type lintedFlow struct {
+	Source string
+	Doc    map[string]interface{}
+}
+
+// eventFieldRef matches references to event payload fields in step configs,
+// e.g. {{event.data.amount}} or $event.data.customer.id.
+var eventFieldRef = regexp.MustCompile(`\bevent\.([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)`)
+
+func loadDeprecations(ctx context.Context, feedPath string) ([]deprecation, error) {
+	var feed []deprecation
+	if feedPath != "" {
+		raw, err := os.ReadFile(feedPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &feed); err != nil {
+			return nil, fmt.Errorf("%s: %w", feedPath, err)
+		}
+		return feed, nil
+	}
+	if err := apiRequest(ctx, http.MethodGet, "/v1/deprecations", nil, &feed); err != nil {
+		return nil, fmt.Errorf("failed to fetch the deprecation feed: %w", err)
+	}
+	return feed, nil
+}
+
+func loadLocalFlows(paths []string) ([]lintedFlow, error) {
+	var files []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (path == root || strings.HasSuffix(path, ".flow.json")) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+
+	flows := make([]lintedFlow, 0, len(files))
+	for _, path := range files {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		flows = append(flows, lintedFlow{Source: path, Doc: doc})
+	}
+	return flows, nil
+}
+
+// walkSteps calls fn for every step of a flow, including steps nested in
+// branches.
+func walkSteps(steps interface{}, fn func(step map[string]interface{})) {
+	list, _ := steps.([]interface{})
+	for _, s := range list {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fn(step)
+		walkSteps(step["steps"], fn)
+		if branches, ok := step["branches"].([]interface{}); ok {
+			for _, b := range branches {
+				if branch, ok := b.(map[string]interface{}); ok {
+					walkSteps(branch["steps"], fn)
+				}
+			}
+		}
+	}
+}
+
+// flowEvents returns the event types that trigger a flow.
+func flowEvents(doc map[string]interface{}) []string {
+	var events []string
+	if e, ok := doc["event"].(string); ok && e != "" {
+		events = append(events, e)
+	}
+	walkSteps(doc["steps"], func(step map[string]interface{}) {
+		if step["type"] != "trigger" {
+			return
+		}
+		config, _ := step["config"].(map[string]interface{})
+		for _, key := range []string{"event", "eventType"} {
+			if e, ok := config[key].(string); ok && e != "" {
+				events = append(events, e)
+			}
+		}
+	})
+	return events
+}
+
+// eventFieldRefs returns the event fields a step refers to anywhere in its
+// config.
+func eventFieldRefs(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case string:
+		for _, m := range eventFieldRef.FindAllStringSubmatch(v, -1) {
+			refs = append(refs, m[1])
+		}
+	case map[string]interface{}:
+		for _, child := range v {
+			refs = append(refs, eventFieldRefs(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			refs = append(refs, eventFieldRefs(child)...)
+		}
+	}
+	return refs
+}
+
+func matchesEvent(pattern string, events []string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	for _, e := range events {
+		if e == pattern || (strings.HasSuffix(pattern, ".*") && strings.HasPrefix(e, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeDeprecation(d deprecation) string {
+	verb := map[string]string{"removed": "was removed", "changing": "is changing"}[d.Severity]
+	if verb == "" {
+		verb = "is deprecated"
+	}
+	var what string
+	switch d.Kind {
+	case "step_type":
+		what = fmt.Sprintf("Step type %q %s", d.Target, verb)
+	case "event_field":
+		what = fmt.Sprintf("Field %s of %s %s", d.Field, d.Event, verb)
+	default:
+		what = fmt.Sprintf("The %s payload schema %s", d.Event, verb)
+	}
+	if d.EffectiveAt != "" {
+		what += " (" + d.EffectiveAt + ")"
+	}
+	return what
+}
+
+func deprecationHint(d deprecation) string {
+	hint := d.Hint
+	if hint == "" && d.Replacement != "" {
+		hint = "Use " + d.Replacement + " instead."
+	}
+	if d.DocsURL != "" {
+		hint = strings.TrimSpace(hint + " See " + d.DocsURL)
+	}
+	return hint
+}
+
+// lintFlow checks one flow against the deprecation feed.
+func lintFlow(f lintedFlow, feed []deprecation) []lintFinding {
+	flowName := resourceName(f.Doc)
+	events := flowEvents(f.Doc)
+
+	var findings []lintFinding
+	add := func(step string, d deprecation) {
+		findings = append(findings, lintFinding{
+			Source:      f.Source,
+			Flow:        flowName,
+			Step:        step,
+			Severity:    d.Severity,
+			Rule:        "deprecation",
+			Message:     describeDeprecation(d),
+			Hint:        deprecationHint(d),
+			Deprecation: d.ID,
+		})
+	}
+
+	for _, d := range feed {
+		if d.Kind == "schema" && d.Event != "" && matchesEvent(d.Event, events) {
+			add("", d)
+		}
+	}
+	walkSteps(f.Doc["steps"], func(step map[string]interface{}) {
+		stepID, _ := step["id"].(string)
+		stepType, _ := step["type"].(string)
+		refs := eventFieldRefs(step["config"])
+		for _, d := range feed {
+			switch d.Kind {
+			case "step_type":
+				if stepType == d.Target {
+					add(stepID, d)
+				}
+			case "event_field":
+				if !matchesEvent(d.Event, events) {
+					continue
+				}
+				for _, ref := range refs {
+					if ref == d.Field || strings.HasPrefix(ref, d.Field+".") {
+						add(stepID, d)
+						break
+					}
+				}
+			}
+		}
+	})
+	return findings
+}
+
+var flowsCmd = &cobra.Command{
+	Use:   "flows",
+	Short: "Work with automation flows",
+}
+
+var flowsLintCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Check flows for logic bugs and deprecated features",
+	Long: `Check flows for logic bugs and for features that are going away:
+
+  - steps no path from the trigger reaches, and references to missing steps
+  - loops without an exit or an iteration limit
+  - conditions that can never be true, e.g. amount > 100 && amount < 50
+  - side-effecting steps (HTTP calls, emails, refunds, ...) without onError
+  - deprecated or removed step types and event fields, and event schemas
+    about to change, from the platform's deprecation feed
+
+Every finding comes with a hint on how to fix it. --no-deprecations skips
+the feed, so the logic checks also run offline.
+
+Local *.flow.json files under the given paths (default: the current
+directory) are checked, or the flows deployed to a zone with --remote.
+--strict exits with status 1 when anything is found, for use in CI.`,
+	Example: `  sapliy flows lint
+  sapliy flows lint flows/ --strict
+  sapliy flows lint --remote --zone zone_123
+  sapliy flows lint --feed deprecations.json --strict -o json
+  sapliy flows lint --no-deprecations`,
+	Run: func(cmd *cobra.Command, args []string) {
+		remote, _ := cmd.Flags().GetBool("remote")
+		feedPath, _ := cmd.Flags().GetString("feed")
+		strict, _ := cmd.Flags().GetBool("strict")
+		noDeprecations, _ := cmd.Flags().GetBool("no-deprecations")
+		if (remote || (feedPath == "" && !noDeprecations)) && viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		var flows []lintedFlow
+		if remote {
+			zone := requireZone(cmd)
+			var docs []map[string]interface{}
+			if err := apiRequest(ctx, http.MethodGet, zonePath(zone, "/flows"), nil, &docs); err != nil {
+				fmt.Printf("❌ Failed to fetch flows: %v\n", err)
+				os.Exit(1)
+			}
+			for _, doc := range docs {
+				flows = append(flows, lintedFlow{Source: "zone " + zone, Doc: doc})
+			}
+		} else {
+			paths := args
+			if len(paths) == 0 {
+				paths = []string{"."}
+			}
+			var err error
+			if flows, err = loadLocalFlows(paths); err != nil {
+				fmt.Printf("Error reading flows: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var feed []deprecation
+		if !noDeprecations {
+			var err error
+			if feed, err = loadDeprecations(ctx, feedPath); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var findings []lintFinding
+		for _, f := range flows {
+			findings = append(findings, analyzeFlowLogic(f)...)
+			findings = append(findings, lintFlow(f, feed)...)
+		}
+
+		printOutput(findings, func() {
+			if len(flows) == 0 {
+				fmt.Println("No flows found.")
+				return
+			}
+			icons := map[string]string{"error": "❌", "removed": "❌", "changing": "🔄"}
+			for _, f := range findings {
+				icon := icons[f.Severity]
+				if icon == "" {
+					icon = "⚠️ "
+				}
+				where := f.Source + " › " + f.Flow
+				if f.Step != "" {
+					where += " › step " + f.Step
+				}
+				fmt.Printf("%s %s\n", icon, where)
+				fmt.Printf("   %s\n", f.Message)
+				if f.Hint != "" {
+					fmt.Printf("   → %s\n", f.Hint)
+				}
+			}
+			if len(findings) == 0 {
+				fmt.Printf("✅ %d flow(s) checked, no problems found\n", len(flows))
+				return
+			}
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("%d finding(s) in %d flow(s)\n", len(findings), len(flows))
+		})
+
+		if strict && len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(flowsCmd)
+	flowsCmd.AddCommand(flowsLintCmd)
+
+	flowsLintCmd.Flags().Bool("remote", false, "Lint the flows deployed to a zone instead of local files")
+	flowsCmd.PersistentFlags().StringP("zone", "z", "", "Zone to work in (default: current zone)")
+	flowsLintCmd.Flags().String("feed", "", "Read the deprecation feed from a JSON file instead of the API")
+	flowsLintCmd.Flags().Bool("no-deprecations", false, "Only run the logic checks, without the deprecation feed")
+	flowsLintCmd.Flags().Bool("strict", false, "Exit with status 1 if anything is found")
+}
@@ -0,0 +1,76 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed flowtemplates/*.flow.json
+var flowTemplateFiles embed.FS
+
+// flowTemplate is a flow 'generate flow --template' can start from. Its
+// file is flowtemplates/<name>.flow.json, with __NAME__ where the flow's
+// name goes.
+type flowTemplate struct {
+	Name        string `json:"name"`
+	Event       string `json:"event"`
+	Description string `json:"description"`
+}
+
+var flowTemplates = []flowTemplate{
+	{"payment-retry", "payment.failed", "Retry a failed payment once a day, up to three times"},
+	{"dunning", "invoice.failed", "Remind, retry the invoice, then cancel the subscription"},
+	{"kyc-check", "customer.created", "Verify new customers; send high-risk ones to manual review"},
+	{"refund-approval", "refund.requested", "Refund small amounts at once; ask finance to approve large ones"},
+}
+
+func flowTemplateNames() []string {
+	names := make([]string, len(flowTemplates))
+	for i, t := range flowTemplates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// renderFlowTemplate returns the named template as a flow called name.
+func renderFlowTemplate(template, name string) (string, error) {
+	raw, err := flowTemplateFiles.ReadFile("flowtemplates/" + template + ".flow.json")
+	if err != nil {
+		return "", fmt.Errorf("unknown template %q (available: %s)", template, strings.Join(flowTemplateNames(), ", "))
+	}
+	return strings.ReplaceAll(string(raw), "__NAME__", name), nil
+}
+
+var generateTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Browse the templates 'generate flow --template' starts from",
+}
+
+var generateTemplatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in flow templates",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printOutput(flowTemplates, func() {
+			fmt.Println("📋 Available Flow Templates")
+			fmt.Println(strings.Repeat("─", 90))
+			fmt.Printf("%-17s %-18s %s\n", "NAME", "TRIGGER", "DESCRIPTION")
+			fmt.Println(strings.Repeat("─", 90))
+			for _, t := range flowTemplates {
+				fmt.Printf("%-17s %-18s %s\n", t.Name, t.Event, t.Description)
+			}
+			fmt.Println()
+			fmt.Println("Use 'sapliy generate flow <name> --template <template>' to start a flow from one.")
+		})
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(generateTemplatesCmd)
+	generateTemplatesCmd.AddCommand(generateTemplatesListCmd)
+}
@@ -0,0 +1,333 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// flowTestCase is one event a flow is run against by flows test.
+type flowTestCase struct {
+	Name  string
+	Event *localEvent
+}
+
+// flowTestEpoch is when test events happened, so runs are repeatable.
+var flowTestEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// flowTestCases returns the events to run a flow against: the matching ones
+// from --event, or else a sample fixture for each event type the flow
+// triggers on.
+func flowTestCases(f *localFlow, events []batchEvent) []flowTestCase {
+	var cases []flowTestCase
+	if events != nil {
+		for _, e := range events {
+			if matchesEventTypes(e.Type, f.Events) {
+				cases = append(cases, flowTestCase{
+					Name:  fmt.Sprintf("%s (line %d)", e.Type, e.line),
+					Event: &localEvent{ID: fmt.Sprintf("evt_test_%d", e.line), Type: e.Type, Zone: e.ZoneID, Data: e.Data, CreatedAt: flowTestEpoch},
+				})
+			}
+		}
+		return cases
+	}
+
+	for _, pattern := range f.Events {
+		eventType := pattern
+		if strings.HasSuffix(pattern, ".*") {
+			eventType = ""
+			for _, t := range fixtureEvents {
+				if matchesEventTypes(t, []string{pattern}) {
+					eventType = t
+					break
+				}
+			}
+			if eventType == "" {
+				continue
+			}
+		}
+		data := map[string]interface{}{}
+		if fixture, err := generateFixture(eventType, 1); err == nil {
+			data, _ = fixture["data"].(map[string]interface{})
+		}
+		cases = append(cases, flowTestCase{
+			Name:  eventType,
+			Event: &localEvent{ID: "evt_test_1", Type: eventType, Data: data, CreatedAt: flowTestEpoch},
+		})
+	}
+	return cases
+}
+
+// snapshotPath is where a flow file's snapshots are kept:
+// __snapshots__/<name>.snap.json next to it.
+func snapshotPath(source string) string {
+	name := strings.TrimSuffix(filepath.Base(source), ".flow.json")
+	return filepath.Join(filepath.Dir(source), "__snapshots__", name+".snap.json")
+}
+
+// flowSnapshots maps "<flow> › <case>" to the steps that ran.
+type flowSnapshots map[string][]stepResult
+
+func readSnapshots(path string) (flowSnapshots, error) {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return flowSnapshots{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snaps := flowSnapshots{}
+	if err := json.Unmarshal(raw, &snaps); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return snaps, nil
+}
+
+func writeSnapshots(path string, snaps flowSnapshots) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(snaps, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// normalizeSteps round-trips steps through JSON so they compare equal to
+// steps read back from a snapshot file.
+func normalizeSteps(steps []stepResult) []stepResult {
+	b, _ := json.Marshal(steps)
+	var out []stepResult
+	json.Unmarshal(b, &out)
+	return out
+}
+
+// diffLines returns a line diff of a and b, with "- " for lines only in a,
+// "+ " for lines only in b and two lines of context around changes.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var all []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			all = append(all, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			all = append(all, "- "+a[i])
+			i++
+		default:
+			all = append(all, "+ "+b[j])
+			j++
+		}
+	}
+
+	const context = 2
+	var out []string
+	last := -1
+	for k, line := range all {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		start := max(k-context, last+1)
+		if last >= 0 && start > last+1 {
+			out = append(out, "  ...")
+		}
+		for c := start; c < k; c++ {
+			out = append(out, all[c])
+		}
+		out = append(out, line)
+		last = k
+		for c := k + 1; c < len(all) && c <= k+context && strings.HasPrefix(all[c], "  "); c++ {
+			out = append(out, all[c])
+			last = c
+		}
+	}
+	return out
+}
+
+var flowsTestCmd = &cobra.Command{
+	Use:   "test [path...]",
+	Short: "Run local flows against sample events",
+	Long: `Run the local *.flow.json files under the given paths (default: the current
+directory) with the local flow runner, against the events in --event or,
+without it, a sample fixture for each event type a flow triggers on. Nothing
+is sent anywhere: http and webhook steps record the request they would make.
+
+With --snapshot, each step's output is recorded in __snapshots__/ next to
+the flow on the first run and compared on later runs, so an unintended
+change in what a flow does fails the test, like Jest snapshots. Review the
+diff, then accept intended changes with --update.
+
+The exit status is 1 if a run fails or a snapshot does not match.`,
+	Example: `  sapliy flows test --snapshot
+  sapliy flows test flows/refunds.flow.json --snapshot --event testdata/refunds.jsonl
+  sapliy flows test --snapshot --update`,
+	Run: func(cmd *cobra.Command, args []string) {
+		eventFile, _ := cmd.Flags().GetString("event")
+		snapshot, _ := cmd.Flags().GetBool("snapshot")
+		update, _ := cmd.Flags().GetBool("update")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if update {
+			snapshot = true
+		}
+
+		paths := args
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		docs, err := loadLocalFlows(paths)
+		if err != nil {
+			fmt.Printf("Error reading flows: %v\n", err)
+			os.Exit(1)
+		}
+		if len(docs) == 0 {
+			fmt.Println("No flows found.")
+			return
+		}
+
+		var events []batchEvent
+		if eventFile != "" {
+			r, err := os.Open(eventFile)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			events, err = readBatchEvents(r, false)
+			r.Close()
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", eventFile, err)
+				os.Exit(1)
+			}
+			if events == nil {
+				events = []batchEvent{}
+			}
+		}
+
+		runner := &localRunner{client: http.DefaultClient, out: io.Discard, dryRun: true}
+		var passed, failed, written, mismatched int
+		for _, doc := range docs {
+			f := newLocalFlow(doc.Source, doc.Doc)
+			cases := flowTestCases(f, events)
+			if len(cases) == 0 {
+				continue
+			}
+
+			snapPath := snapshotPath(f.Source)
+			var snaps flowSnapshots
+			if snapshot {
+				if snaps, err = readSnapshots(snapPath); err != nil {
+					fmt.Printf("Error reading snapshots: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			changed := false
+
+			for _, c := range cases {
+				run := runner.run(f, c.Event)
+				steps := normalizeSteps(run.Steps)
+				key := f.Name + " › " + c.Name
+				ok := run.Status == "succeeded"
+
+				note, mismatch := "", false
+				if snapshot {
+					recorded, exists := snaps[key]
+					switch {
+					case !exists || update && !reflect.DeepEqual(recorded, steps):
+						snaps[key] = steps
+						changed = true
+						written++
+						note = "  📸 snapshot written"
+					case !reflect.DeepEqual(recorded, steps):
+						ok, mismatch = false, true
+						mismatched++
+						note = "  snapshot mismatch"
+					}
+				}
+
+				icon := "✅"
+				if !ok {
+					icon = "❌"
+					failed++
+				} else {
+					passed++
+				}
+				fmt.Printf("%s %s › %s (%d step(s))%s\n", icon, f.Source, key, len(steps), note)
+				if run.Status != "succeeded" {
+					for _, s := range steps {
+						if s.Status == "failed" {
+							fmt.Printf("   step %s failed: %s\n", s.Step, s.Detail)
+						}
+					}
+				}
+				if mismatch {
+					want, _ := json.MarshalIndent(snaps[key], "", "  ")
+					got, _ := json.MarshalIndent(steps, "", "  ")
+					for _, line := range diffLines(strings.Split(string(want), "\n"), strings.Split(string(got), "\n")) {
+						fmt.Printf("   %s\n", line)
+					}
+				}
+				if verbose {
+					for _, s := range steps {
+						fmt.Printf("   %-9s %-20s %s\n", s.Status, s.Step, s.Detail)
+					}
+				}
+			}
+
+			if changed {
+				if err := writeSnapshots(snapPath, snaps); err != nil {
+					fmt.Printf("Error writing snapshots: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		summary := fmt.Sprintf("%d passed, %d failed", passed, failed)
+		if snapshot {
+			summary += fmt.Sprintf("; %d snapshot(s) written, %d mismatched", written, mismatched)
+		}
+		fmt.Println(summary)
+		if mismatched > 0 {
+			fmt.Println("If the changes are intended, accept them with --update.")
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	flowsCmd.AddCommand(flowsTestCmd)
+	flowsTestCmd.Flags().String("event", "", "NDJSON file of events to run, one {\"type\": ..., \"data\": {...}} per line")
+	flowsTestCmd.Flags().Bool("snapshot", false, "Compare each step's output with the recorded snapshot (recorded on first run)")
+	flowsTestCmd.Flags().BoolP("update", "u", false, "Re-record snapshots that differ")
+	flowsTestCmd.Flags().BoolP("verbose", "v", false, "Show every step of each run")
+}
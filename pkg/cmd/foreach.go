@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// readOnlyVerbs are the subcommand names foreach-account is willing to run.
+// Anything that changes state has to be run one account at a time.
+var readOnlyVerbs = map[string]bool{
+	"list": true, "get": true, "show": true, "inspect": true, "stats": true,
+	"status": true, "logs": true, "validate": true, "lint": true, "query": true,
+}
+
+// accountFilter matches accounts against "key:value" terms, all of which
+// must hold: label:<label>, mode:<test|live>, name:<substring>, org:<id>.
+type accountFilter []string
+
+func parseAccountFilter(expr string) (accountFilter, error) {
+	var terms accountFilter
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, _, ok := strings.Cut(term, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter term %q (expected key:value)", term)
+		}
+		switch key {
+		case "label", "mode", "name", "org":
+		default:
+			return nil, fmt.Errorf("unknown filter key %q (use label, mode, name or org)", key)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func (f accountFilter) match(a account) bool {
+	for _, term := range f {
+		key, value, _ := strings.Cut(term, ":")
+		switch key {
+		case "label":
+			found := false
+			for _, l := range a.Labels {
+				if l == value {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		case "mode":
+			if a.Mode != value {
+				return false
+			}
+		case "name":
+			if !strings.Contains(strings.ToLower(a.Name), strings.ToLower(value)) {
+				return false
+			}
+		case "org":
+			if a.OrgID != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// accessibleAccounts lists the accounts of every organization the API key
+// can see.
+func accessibleAccounts(ctx context.Context) ([]account, error) {
+	var orgs []organization
+	if err := apiRequest(ctx, http.MethodGet, "/v1/orgs", nil, &orgs); err != nil {
+		return nil, err
+	}
+
+	var all []account
+	for _, o := range orgs {
+		accounts, err := listAccounts(ctx, o.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list accounts of %s: %w", o.ID, err)
+		}
+		for i := range accounts {
+			if accounts[i].OrgID == "" {
+				accounts[i].OrgID = o.ID
+			}
+		}
+		all = append(all, accounts...)
+	}
+	return all, nil
+}
+
+type accountRun struct {
+	account account
+	lines   []string
+	err     error
+	stderr  string
+}
+
+// runForAccount runs the CLI itself with args, scoped to one account through
+// the environment.
+func runForAccount(ctx context.Context, self string, args []string, a account) accountRun {
+	cmd := exec.CommandContext(ctx, self, args...)
+	cmd.Env = append(os.Environ(), "SAPLIY_ACCOUNT_ID="+a.ID, "SAPLIY_ORG_ID="+a.OrgID)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	out := strings.TrimRight(stdout.String(), "\n")
+	var lines []string
+	if out != "" {
+		lines = strings.Split(out, "\n")
+	}
+	return accountRun{account: a, lines: lines, err: err, stderr: strings.TrimSpace(stderr.String())}
+}
+
+// printAggregated prints all successful runs as one table with an ACCOUNT
+// column. Leading lines every run has in common (table headers) are printed
+// once.
+func printAggregated(runs []accountRun) {
+	var ok []accountRun
+	for _, r := range runs {
+		if r.err == nil {
+			ok = append(ok, r)
+		}
+	}
+	if len(ok) == 0 {
+		return
+	}
+
+	width := len("ACCOUNT")
+	for _, r := range ok {
+		if len(r.account.ID) > width {
+			width = len(r.account.ID)
+		}
+	}
+
+	common := len(ok[0].lines)
+	for _, r := range ok[1:] {
+		n := 0
+		for n < common && n < len(r.lines) && r.lines[n] == ok[0].lines[n] {
+			n++
+		}
+		common = n
+	}
+	// Only a true header is shared: every run must have rows of its own.
+	for _, r := range ok {
+		if len(ok) == 1 || common == len(r.lines) {
+			common = 0
+		}
+	}
+
+	for i, line := range ok[0].lines[:common] {
+		switch {
+		case i == 0:
+			fmt.Printf("%-*s  %s\n", width, "ACCOUNT", line)
+		case line != "" && strings.Trim(line, "─-= ") == "":
+			fmt.Printf("%s  %s\n", strings.Repeat("─", width), line)
+		default:
+			fmt.Printf("%-*s  %s\n", width, "", line)
+		}
+	}
+	for _, r := range ok {
+		rest := r.lines[common:]
+		if len(rest) == 0 {
+			fmt.Printf("%-*s  (no output)\n", width, r.account.ID)
+		}
+		for _, line := range rest {
+			fmt.Printf("%-*s  %s\n", width, r.account.ID, line)
+		}
+	}
+}
+
+// printAggregatedStructured prints every run as {account, result, error}
+// in the --output format.
+func printAggregatedStructured(runs []accountRun) {
+	type accountResult struct {
+		Account string          `json:"account"`
+		Result  json.RawMessage `json:"result,omitempty"`
+		Error   string          `json:"error,omitempty"`
+	}
+
+	results := make([]accountResult, 0, len(runs))
+	for _, r := range runs {
+		res := accountResult{Account: r.account.ID}
+		out := strings.Join(r.lines, "\n")
+		switch {
+		case r.err != nil:
+			res.Error = r.err.Error()
+			if r.stderr != "" {
+				res.Error += ": " + r.stderr
+			}
+		case json.Valid([]byte(out)):
+			res.Result = json.RawMessage(out)
+		default:
+			res.Error = "command did not produce JSON output"
+		}
+		results = append(results, res)
+	}
+	printOutput(results, func() {})
+}
+
+var foreachAccountCmd = &cobra.Command{
+	Use:   "foreach-account [flags] -- [command...]",
+	Short: "Run a read-only command across many accounts and aggregate the output",
+	Long: `Run a read-only command (list, get, inspect, stats, ...) once for every
+account the API key can access, concurrently, and print the results as one
+table with an ACCOUNT column.
+
+--filter narrows the accounts with comma-separated key:value terms that must
+all match: label:<label>, mode:<test|live>, name:<substring>, org:<org_id>.`,
+	Example: `  sapliy foreach-account --filter 'label:agency-managed' -- webhooks list --limit 5
+  sapliy foreach-account --filter 'mode:live,org:org_acme' --concurrency 8 -- zones list`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		filterExpr, _ := cmd.Flags().GetString("filter")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if concurrency < 1 {
+			concurrency = 1
+		}
+
+		filter, err := parseAccountFilter(filterExpr)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target, _, err := rootCmd.Find(args)
+		if err != nil || target == rootCmd {
+			fmt.Printf("Error: unknown command %q\n", strings.Join(args, " "))
+			os.Exit(1)
+		}
+		if !readOnlyVerbs[target.Name()] {
+			fmt.Printf("Error: '%s' is not a read-only command; foreach-account only runs list/get/inspect/stats-style commands.\n", target.CommandPath())
+			os.Exit(1)
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		childArgs := args
+		if structuredOutput() {
+			// Children emit JSON that is merged into one document below.
+			childArgs = append([]string{"--output", outputJSON}, childArgs...)
+		}
+		if cfgFile != "" {
+			childArgs = append([]string{"--config", cfgFile}, childArgs...)
+		}
+		if profileFlag != "" {
+			childArgs = append([]string{"--profile", profileFlag}, childArgs...)
+		}
+
+		accounts, err := accessibleAccounts(context.Background())
+		if err != nil {
+			fmt.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+		var selected []account
+		for _, a := range accounts {
+			if filter.match(a) {
+				selected = append(selected, a)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Println("No accounts match the filter.")
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "🔁 Running '%s' across %d account(s)...\n", strings.Join(args, " "), len(selected))
+
+		runs := make([]accountRun, len(selected))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, a := range selected {
+			wg.Add(1)
+			go func(i int, a account) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				runs[i] = runForAccount(ctx, self, childArgs, a)
+			}(i, a)
+		}
+		wg.Wait()
+
+		if structuredOutput() {
+			printAggregatedStructured(runs)
+		} else {
+			printAggregated(runs)
+		}
+
+		failed := 0
+		for _, r := range runs {
+			if r.err == nil {
+				continue
+			}
+			failed++
+			if structuredOutput() {
+				continue
+			}
+			if failed == 1 {
+				fmt.Println(strings.Repeat("─", 60))
+			}
+			detail := r.stderr
+			if detail == "" && len(r.lines) > 0 {
+				detail = r.lines[len(r.lines)-1]
+			}
+			fmt.Printf("❌ %s: %v", r.account.ID, r.err)
+			if detail != "" {
+				fmt.Printf(": %s", truncate(detail, 120))
+			}
+			fmt.Println()
+		}
+
+		fmt.Fprintf(os.Stderr, "Completed: %d succeeded, %d failed\n", len(runs)-failed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(foreachAccountCmd)
+	foreachAccountCmd.Flags().String("filter", "", "Only include matching accounts (e.g. label:agency-managed,mode:live)")
+	foreachAccountCmd.Flags().Int("concurrency", 4, "Accounts to run at the same time")
+	foreachAccountCmd.Flags().Duration("timeout", time.Minute, "Time limit per account")
+}
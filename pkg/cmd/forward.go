@@ -0,0 +1,647 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// forwarder POSTs events to a local target and tracks delivery in the cursor
+// store. The cursor only advances over a contiguous run of successful
+// deliveries, so after a failure a restart redelivers from the first event
+// that did not make it (at-least-once).
+type forwarder struct {
+	target string
+	client *http.Client
+
+	store      *cursorStore
+	cursorName string
+	stalled    bool
+
+	// dedup skips events already delivered within the dedup window, keyed
+	// by a hash of the event ID.
+	dedup bool
+
+	// transform, when set, rewrites each payload before it is sent.
+	transform payloadTransform
+
+	// secret signs each request with a Sapliy-Signature header, the same
+	// way the platform signs real deliveries.
+	secret string
+
+	// retries is how many times a delivery that hit a network error or a
+	// 5xx response is retried, with exponential backoff from retryDelay.
+	retries    int
+	retryDelay time.Duration
+
+	// assertStatus and assertTimeout, when set, are checked against every
+	// delivery's final response; violations are counted in assertFailed.
+	assertStatus  []int
+	assertTimeout time.Duration
+
+	// ctx, when set, abandons the delivery in progress once it is done. The
+	// event is not acknowledged, so it is sent again on the next run.
+	ctx context.Context
+
+	delivered    int
+	failed       int
+	deduplicated int
+	recovered    int
+	dropped      int
+	assertFailed int
+}
+
+// assertion returns why a delivery that got status after elapsed fails the
+// --assert-* checks, or "" if it passes them.
+func (f *forwarder) assertion(status int, elapsed time.Duration) string {
+	if len(f.assertStatus) > 0 && !slices.Contains(f.assertStatus, status) {
+		return fmt.Sprintf("status %d, expected %s", status, joinInts(f.assertStatus, " or "))
+	}
+	if f.assertTimeout > 0 && elapsed > f.assertTimeout {
+		return fmt.Sprintf("took %s, limit %s", elapsed, f.assertTimeout)
+	}
+	return ""
+}
+
+func joinInts(values []int, sep string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, sep)
+}
+
+// process runs the transform script over a raw event and forwards the
+// result. Events the script drops still advance the cursor; a script error
+// holds it so the event is retried once the script is fixed.
+func (f *forwarder) process(eventID, eventType string, payload []byte) bool {
+	if f.transform == nil {
+		return f.forward(eventID, eventType, payload)
+	}
+
+	timestamp := time.Now().Format("15:04:05")
+	out, err := f.transform.Apply(payload)
+	if err != nil {
+		fmt.Printf("[%s] ❌ %-30s %s: transform: %v\n", timestamp, eventType, eventID, err)
+		f.fail(eventID, eventHash(eventID))
+		return false
+	}
+	if out == nil {
+		fmt.Printf("[%s] 🗑️  %-30s %s (dropped by transform)\n", timestamp, eventType, eventID)
+		f.dropped++
+		f.ack(eventID)
+		return true
+	}
+	return f.forward(eventID, eventType, out)
+}
+
+// forward delivers one raw event and reports whether the target accepted it.
+func (f *forwarder) forward(eventID, eventType string, payload []byte) bool {
+	start := time.Now()
+	timestamp := start.Format("15:04:05")
+	hash := eventHash(eventID)
+
+	if f.dedup && eventID != "" {
+		status, err := f.store.deliveryStatus(f.cursorName, hash)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  dedup lookup failed: %v\n", err)
+		}
+		switch status {
+		case deliveryDelivered:
+			fmt.Printf("[%s] ⏭️  dup %-30s %s (already delivered)\n", timestamp, eventType, eventID)
+			f.deduplicated++
+			f.ack(eventID)
+			return true
+		case deliveryPending:
+			fmt.Printf("[%s] ↩️  %-30s %s was interrupted mid-forward last run, resending with the same Idempotency-Key\n", timestamp, eventType, eventID)
+			f.recovered++
+		}
+		if err := f.store.markDelivery(f.cursorName, hash, deliveryPending); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  dedup write failed: %v\n", err)
+		}
+	}
+
+	var status int
+	var err error
+	delay := f.retryDelay
+	for attempt := 1; ; attempt++ {
+		status, err = f.send(eventID, eventType, hash, payload)
+		if f.context().Err() != nil {
+			fmt.Printf("[%s] ⏹️  %-30s %s: interrupted\n", timestamp, eventType, eventID)
+			return false
+		}
+		retryable := err != nil || status >= 500
+		if !retryable || attempt > f.retries {
+			break
+		}
+
+		reason := fmt.Sprint(err)
+		if err == nil {
+			reason = fmt.Sprintf("%d %s", status, http.StatusText(status))
+		}
+		fmt.Printf("[%s] ↻  %-30s %s: %s, retrying in %s (%d/%d)\n", time.Now().Format("15:04:05"), eventType, eventID, reason, delay, attempt, f.retries)
+		select {
+		case <-f.context().Done():
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Printf("[%s] ❌ %-30s %s: %v\n", timestamp, eventType, eventID, err)
+		if len(f.assertStatus) > 0 || f.assertTimeout > 0 {
+			f.assertFailed++
+		}
+		f.fail(eventID, hash)
+		return false
+	}
+	if reason := f.assertion(status, elapsed); reason != "" {
+		fmt.Printf("[%s] ❌ %d %-30s %s (%s): assertion failed: %s\n", timestamp, status, eventType, eventID, elapsed, reason)
+		f.assertFailed++
+	}
+	if status < 200 || status >= 300 {
+		fmt.Printf("[%s] ❌ %d %-30s %s (%s)\n", timestamp, status, eventType, eventID, elapsed)
+		f.fail(eventID, hash)
+		return false
+	}
+
+	fmt.Printf("[%s] ✅ %d %-30s %s (%s)\n", timestamp, status, eventType, eventID, elapsed)
+	f.delivered++
+	if f.dedup && eventID != "" {
+		if err := f.store.markDelivery(f.cursorName, hash, deliveryDelivered); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  dedup write failed: %v\n", err)
+		}
+	}
+	f.ack(eventID)
+	return true
+}
+
+// send makes one delivery attempt and returns the response status.
+func (f *forwarder) send(eventID, eventType, hash string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(f.context(), http.MethodPost, f.target, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Sapliy-Event-Id", eventID)
+	req.Header.Set("Sapliy-Event-Type", eventType)
+	// Lets the target drop the rare duplicate the dedup window cannot catch
+	// (a crash after the target accepted the event but before it was marked).
+	req.Header.Set("Idempotency-Key", hash)
+	if f.secret != "" {
+		ts := time.Now().Unix()
+		req.Header.Set(signatureHeader, fmt.Sprintf("t=%d,v1=%s", ts, computeSignature(f.secret, ts, payload)))
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// newForwardSecret returns a random signing secret for a listen session.
+func newForwardSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func (f *forwarder) context() context.Context {
+	if f.ctx == nil {
+		return context.Background()
+	}
+	return f.ctx
+}
+
+func (f *forwarder) ack(eventID string) {
+	if f.store == nil || f.stalled || eventID == "" {
+		return
+	}
+	if err := f.store.ack(f.cursorName, eventID); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  could not save cursor: %v\n", err)
+	}
+}
+
+func (f *forwarder) fail(eventID, hash string) {
+	f.failed++
+	if f.dedup && eventID != "" {
+		f.store.forgetDelivery(f.cursorName, hash)
+	}
+	if f.store != nil && !f.stalled {
+		f.stalled = true
+		fmt.Fprintf(os.Stderr, "⚠️  Cursor held before %s; it will be redelivered on the next run.\n", eventID)
+	}
+}
+
+// errExitAfter is the cause of the context of a listen that ran for
+// --exit-after.
+var errExitAfter = errors.New("--exit-after reached")
+
+func defaultCursorName(zone, target string) string {
+	if zone == "" {
+		zone = "all"
+	}
+	return fmt.Sprintf("listen:%s:%s", zone, target)
+}
+
+var webhooksListenCmd = &cobra.Command{
+	Use:     "listen",
+	Aliases: []string{"forward"},
+	Short:   "Stream events and forward them to a local server",
+	Long: `Subscribe to the event stream and POST every event to a local endpoint,
+so webhook handlers can be developed without exposing a public URL.
+
+Every request is signed with a Sapliy-Signature header, exactly like real
+deliveries. Pass the endpoint secret your handler verifies with --secret (or
+SAPLIY_WEBHOOK_SECRET); otherwise a secret is generated for the session and
+printed at startup. Network errors and 5xx responses are retried --retries
+times with exponential backoff before the delivery counts as failed.
+
+The last delivered event is stored as a cursor (see 'sapliy cursor show').
+When restarted, forwarding resumes after that event so nothing is lost while
+the CLI was not running. Use --reset-cursor to start from the live stream.
+
+Delivered event IDs are also remembered for --dedup-window, so an event the
+stream sends again (for example after a crash mid-forward) is not POSTed to
+your target twice. Each request carries an Idempotency-Key header derived
+from the event ID.
+
+--transform runs a Starlark (.star) or JavaScript (.js) script over each
+payload first. The script defines transform(event), which returns the event
+to send (modified or not), or None/null to drop it.
+
+For end-to-end tests in CI, --assert-status and --assert-timeout check every
+delivery's response, --count stops after that many events, and --exit-after
+gives up after a time limit. The exit status is 1 if any delivery failed an
+assertion, or if fewer than --count events arrived in time.
+
+With --daemon a local control API lets 'sapliy daemon status|pause|resume|
+filter' manage the running forwarder. Events that arrive while it is paused
+are skipped, not forwarded later.
+
+A running listener picks up config file edits and SIGHUP without dropping
+its connection: listen.filter and listen.sinks apply at once, and without
+--zone a new current_zone re-subscribes (resuming that zone's cursor).`,
+	Example: `  sapliy webhooks listen --forward-to http://localhost:4242/webhook
+  sapliy webhooks forward --to http://localhost:4000/webhook --secret whsec_local
+  sapliy webhooks listen --forward-to http://localhost:4242/webhook --transform ./transform.star
+
+  # CI: expect 10 events, each answered 200 within 3s, within 2 minutes
+  sapliy webhooks listen --forward-to http://localhost:4242/webhook --no-cursor \
+    --assert-status 200 --assert-timeout 3s --count 10 --exit-after 2m &
+  sapliy trigger --zone zone_ci --file ./ci-events.ndjson
+  wait $!`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		zone := resolveZone(cmd)
+		// Without --zone the listener follows the current zone in the
+		// config, re-subscribing when it changes.
+		flagZone, _ := cmd.Flags().GetString("zone")
+		followConfig := flagZone == ""
+
+		target, _ := cmd.Flags().GetString("forward-to")
+		events, _ := cmd.Flags().GetStringSlice("events")
+		cursorName, _ := cmd.Flags().GetString("cursor")
+		resetCursor, _ := cmd.Flags().GetBool("reset-cursor")
+		noCursor, _ := cmd.Flags().GetBool("no-cursor")
+		dedupWindow, _ := cmd.Flags().GetDuration("dedup-window")
+		transformPath, _ := cmd.Flags().GetString("transform")
+		secret, _ := cmd.Flags().GetString("secret")
+		retries, _ := cmd.Flags().GetInt("retries")
+		assertStatus, _ := cmd.Flags().GetIntSlice("assert-status")
+		assertTimeout, _ := cmd.Flags().GetDuration("assert-timeout")
+		count, _ := cmd.Flags().GetInt("count")
+		exitAfter, _ := cmd.Flags().GetDuration("exit-after")
+		daemon, _ := cmd.Flags().GetBool("daemon")
+		controlSocket, _ := cmd.Flags().GetString("control-socket")
+		sinkSpecs, _ := cmd.Flags().GetStringArray("sink")
+
+		if target == "" {
+			fmt.Println("Error: --forward-to is required.")
+			os.Exit(1)
+		}
+		if _, err := url.ParseRequestURI(target); err != nil {
+			fmt.Printf("Error: invalid --forward-to URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, code := range assertStatus {
+			if code < 100 || code > 599 {
+				fmt.Printf("Error: invalid --assert-status %d\n", code)
+				os.Exit(1)
+			}
+		}
+		if count < 0 || exitAfter < 0 || assertTimeout < 0 {
+			fmt.Println("Error: --count, --exit-after and --assert-timeout cannot be negative.")
+			os.Exit(1)
+		}
+
+		if secret == "" {
+			secret = os.Getenv("SAPLIY_WEBHOOK_SECRET")
+		}
+		// With the endpoint's own secret, the handler checks the timestamps
+		// this host signs with, so a wrong clock fails every delivery.
+		strictClockSkew = secret != ""
+		if secret == "" {
+			generated, err := newForwardSecret()
+			if err != nil {
+				fmt.Printf("Error generating signing secret: %v\n", err)
+				os.Exit(1)
+			}
+			secret = generated
+			fmt.Printf("🔑 Signing secret for this session: %s\n", secret)
+		}
+
+		fwd := &forwarder{
+			target:     target,
+			client:     &http.Client{Timeout: 10 * time.Second},
+			secret:     secret,
+			retries:    retries,
+			retryDelay: time.Second,
+
+			assertStatus:  assertStatus,
+			assertTimeout: assertTimeout,
+		}
+
+		if transformPath != "" {
+			t, err := loadTransform(transformPath)
+			if err != nil {
+				fmt.Printf("Error loading transform: %v\n", err)
+				os.Exit(1)
+			}
+			fwd.transform = t
+			fmt.Printf("🧩 Transforming payloads with %s\n", transformPath)
+		}
+
+		plugins, err := loadPlugins()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer plugins.close()
+
+		state := newListenState("webhooks listen", zone, "")
+		if daemon {
+			if controlSocket == "" {
+				controlSocket = defaultControlSocket()
+			}
+			stop, err := startControlServer(controlSocket, state)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			defer stop()
+			fmt.Printf("🎛️  Control API on %s (manage with 'sapliy daemon status|pause|resume')\n", controlSocket)
+		}
+
+		sinks, err := newSinkSet(sinkSpecs)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer sinks.close()
+		for _, spec := range sinkSpecs {
+			fmt.Printf("📤 Streaming events to %s\n", spec)
+		}
+
+		if !noCursor {
+			store, err := openCursorStore()
+			if err != nil {
+				fmt.Printf("Error opening cursor store: %v\n", err)
+				os.Exit(1)
+			}
+			defer store.Close()
+			fwd.store = store
+
+			if resetCursor {
+				name := firstNonEmpty(cursorName, defaultCursorName(zone, target))
+				if err := store.reset(name); err != nil {
+					fmt.Printf("Error resetting cursor: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("🧹 Cursor %s reset\n", name)
+			}
+		}
+
+		// streamURL returns the event stream URL for zone, after the zone's
+		// cursor if there is one, and points the forwarder at that cursor.
+		streamURL := func(zone string) (string, error) {
+			wsURL := eventStreamURL(apiKey, zone)
+			if fwd.store == nil {
+				return wsURL, nil
+			}
+			name := firstNonEmpty(cursorName, defaultCursorName(zone, target))
+			cursor, err := fwd.store.get(name)
+			if err != nil {
+				return "", fmt.Errorf("reading cursor: %w", err)
+			}
+			if cursor != nil {
+				wsURL += "&after=" + url.QueryEscape(cursor.EventID)
+				fmt.Printf("⏩ Resuming after %s (cursor %s)\n", cursor.EventID, name)
+			}
+			fwd.cursorName = name
+			fwd.stalled = false
+
+			if dedupWindow > 0 {
+				recent, err := fwd.store.pruneDeliveries(name, dedupWindow)
+				if err != nil {
+					return "", fmt.Errorf("preparing dedup window: %w", err)
+				}
+				fwd.dedup = true
+				fmt.Printf("🧮 Dedup window %s (%d recent deliveries)\n", dedupWindow, recent)
+			}
+			return wsURL, nil
+		}
+
+		reload := watchConfigReload()
+		ctx, stop := interruptContext()
+		defer stop()
+		if exitAfter > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, exitAfter, errExitAfter)
+			defer cancel()
+		}
+		fwd.ctx = ctx
+
+		// The connection reads events into frames, which are forwarded
+		// here one at a time. A zone change replaces the connection.
+		frames := make(chan []byte)
+		var stream *streamGroup
+		subscribe := func() error {
+			wsURL, err := streamURL(zone)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("🔌 Connecting to %s...\n", wsURL)
+			conn, resp, err := dialEventStream(ctx, wsURL, nil)
+			if err != nil {
+				return fmt.Errorf("Failed to connect: %v", err)
+			}
+			if err := checkStreamClockSkew(resp); err != nil {
+				conn.Close()
+				return err
+			}
+			fmt.Printf("✅ Ready! Forwarding events to %s (Ctrl+C to stop)\n", target)
+			fmt.Println(strings.Repeat("─", 60))
+
+			stream = newStreamGroup(ctx)
+			stream.Go(func(ctx context.Context) error {
+				defer conn.Close()
+				return readStream(ctx, conn, func(message []byte) error {
+					select {
+					case frames <- message:
+					case <-ctx.Done():
+					}
+					return nil
+				})
+			})
+			return nil
+		}
+		if err := subscribe(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		processed := 0
+	loop:
+		for {
+			select {
+			case <-stream.Done():
+				err = stream.Wait()
+				break loop
+			case <-reload:
+				newZone := applyListenReload(os.Stdout, state, sinks, zone)
+				if followConfig && newZone != zone {
+					fmt.Printf("🔁 Zone changed to %q, re-subscribing...\n", newZone)
+					stream.stop()
+					zone = newZone
+					state.setZone(zone)
+					if err = subscribe(); err != nil {
+						break loop
+					}
+				}
+			case message := <-frames:
+				var event struct {
+					ID   string `json:"id"`
+					Type string `json:"type"`
+				}
+				if err := json.Unmarshal(message, &event); err != nil {
+					continue
+				}
+				if len(events) > 0 && !matchesEventTypes(event.Type, events) {
+					continue
+				}
+				if !state.admit(event.Type) || !plugins.keep(message) {
+					continue
+				}
+
+				sinks.send(message)
+				fwd.process(event.ID, event.Type, message)
+				if processed++; count > 0 && processed == count {
+					err = stream.stop()
+					break loop
+				}
+			}
+		}
+		switch {
+		case interrupted(ctx):
+			fmt.Println("\n👋 Disconnecting...")
+		case errors.Is(context.Cause(ctx), errExitAfter):
+			fmt.Printf("\n⏱️  --exit-after %s reached\n", exitAfter)
+		case err == errServerClosed:
+			fmt.Println("Server closed connection")
+		case err != nil:
+			fmt.Printf("❌ %v\n", err)
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		fmt.Printf("Delivered: %d, Failed: %d\n", fwd.delivered, fwd.failed)
+		if fwd.transform != nil {
+			fmt.Printf("Dropped by transform: %d\n", fwd.dropped)
+		}
+		if fwd.dedup {
+			fmt.Printf("Deduplicated: %d, Resent after interruption: %d\n", fwd.deduplicated, fwd.recovered)
+		}
+
+		failed := false
+		if len(assertStatus) > 0 || assertTimeout > 0 {
+			fmt.Printf("Assertion failures: %d\n", fwd.assertFailed)
+			failed = fwd.assertFailed > 0
+		}
+		if count > 0 && processed < count {
+			fmt.Printf("❌ Received %d of %d expected events\n", processed, count)
+			failed = true
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// matchesEventTypes reports whether eventType is one of types. A trailing
+// ".*" matches a whole namespace (payment.*).
+func matchesEventTypes(eventType string, types []string) bool {
+	for _, t := range types {
+		if t == eventType || t == "*" {
+			return true
+		}
+		if strings.HasSuffix(t, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	webhooksCmd.AddCommand(webhooksListenCmd)
+
+	webhooksListenCmd.Flags().String("forward-to", "", "Local URL to POST events to (alias --to)")
+	webhooksListenCmd.Flags().String("secret", "", "Secret to sign requests with (default $SAPLIY_WEBHOOK_SECRET or a generated one)")
+	webhooksListenCmd.Flags().Int("retries", 3, "Retries for network errors and 5xx responses (0 disables)")
+	webhooksListenCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "to" {
+			name = "forward-to"
+		}
+		return pflag.NormalizedName(name)
+	})
+	webhooksListenCmd.Flags().StringSlice("events", nil, "Only forward these event types (comma-separated, supports payment.*)")
+	webhooksListenCmd.Flags().String("cursor", "", "Cursor name (default derived from zone and target)")
+	webhooksListenCmd.Flags().Bool("reset-cursor", false, "Discard the stored cursor and start from the live stream")
+	webhooksListenCmd.Flags().Bool("no-cursor", false, "Do not store or resume from a cursor")
+	webhooksListenCmd.Flags().Duration("dedup-window", 24*time.Hour, "Skip events already delivered within this window (0 disables)")
+	webhooksListenCmd.Flags().IntSlice("assert-status", nil, "Fail unless every delivery gets one of these statuses (e.g. 200 or 200,204)")
+	webhooksListenCmd.Flags().Duration("assert-timeout", 0, "Fail if any delivery takes longer than this")
+	webhooksListenCmd.Flags().Int("count", 0, "Exit after forwarding this many events; fewer by --exit-after is a failure")
+	webhooksListenCmd.Flags().Duration("exit-after", 0, "Exit after this long (0 waits until interrupted)")
+	webhooksListenCmd.Flags().Bool("daemon", false, "Expose a local control API to pause, resume and re-filter forwarding")
+	webhooksListenCmd.Flags().String("control-socket", "", "Control API socket path (default ~/.sapliy/daemon.sock)")
+	webhooksListenCmd.Flags().StringArray("sink", nil, "Also stream forwarded events to a local socket (unix:///path or fifo:///path, repeatable)")
+	webhooksListenCmd.Flags().String("transform", "", "Script (.star or .js) defining transform(event) to rewrite or drop payloads before forwarding")
+}
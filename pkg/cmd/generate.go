@@ -1,27 +1,79 @@
+//go:build !headless
+
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate Sapliy resources (zones, flows)",
-	Long:  `Scaffold configuration files for Sapliy Automation Zones and Flows.`,
+	Long: `Scaffold configuration files for Sapliy Automation Zones and Flows, as JSON
+or, with --format yaml, as YAML. deploy, diff and validate read both.`,
+}
+
+// writeGenerated writes a generated resource, given as JSON, to
+// <name>.<kind>.json, or converted to <name>.<kind>.yaml for --format yaml.
+func writeGenerated(cmd *cobra.Command, name, kind, content string) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	fileName := fmt.Sprintf("%s.%s.json", strings.ToLower(name), kind)
+	data := []byte(content)
+	switch format {
+	case "json":
+	case "yaml", "yml":
+		fileName = strings.TrimSuffix(fileName, ".json") + ".yaml"
+		var err error
+		if data, err = jsonToYAML(data); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("invalid --format %q (use json or yaml)", format)
+	}
+	return fileName, os.WriteFile(fileName, data, 0644)
+}
+
+// jsonToYAML converts a JSON document to block-style YAML, keeping the order
+// of its fields.
+func jsonToYAML(raw []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	var plain func(n *yaml.Node)
+	plain = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			plain(child)
+		}
+	}
+	plain(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
 }
 
 var zoneCmd = &cobra.Command{
 	Use:   "zone [name]",
 	Short: "Generate a new automation zone",
-	Args:  cobra.ExactArgs(1),
+	Long: `Generate a zone file with no triggers or actions, or with --interactive
+answer prompts for its description, environment (test or live), the event
+types that trigger it and its actions, and get a complete zone file.`,
+	Example: `  sapliy generate zone shop
+  sapliy generate zone shop --interactive --format yaml`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
-		fileName := fmt.Sprintf("%s.zone.json", strings.ToLower(name))
-
 		content := fmt.Sprintf(`{
   "id": "zone_%s",
   "name": "%s",
@@ -30,8 +82,20 @@ var zoneCmd = &cobra.Command{
   "triggers": [],
   "actions": []
 }`, name, name, name)
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			var err error
+			if content, err = zoneFromWizard(name); err != nil {
+				fmt.Printf("Error creating zone: %v\n", err)
+				os.Exit(1)
+			}
+			if content == "" {
+				fmt.Println("Cancelled; nothing written.")
+				return
+			}
+		}
 
-		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		fileName, err := writeGenerated(cmd, name, "zone", content)
+		if err != nil {
 			fmt.Printf("Error creating zone: %v\n", err)
 			return
 		}
@@ -42,11 +106,15 @@ var zoneCmd = &cobra.Command{
 var flowCmd = &cobra.Command{
 	Use:   "flow [name]",
 	Short: "Generate a new automation flow",
-	Args:  cobra.ExactArgs(1),
+	Long: `Generate a flow with a single trigger step, or with --template a complete
+multi-step flow to adapt. 'sapliy generate templates list' shows the
+templates.`,
+	Example: `  sapliy generate flow checkout
+  sapliy generate flow retries --template payment-retry --format yaml`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
-		fileName := fmt.Sprintf("%s.flow.json", strings.ToLower(name))
-
+		template, _ := cmd.Flags().GetString("template")
 		content := fmt.Sprintf(`{
   "id": "flow_%s",
   "name": "%s",
@@ -58,12 +126,23 @@ var flowCmd = &cobra.Command{
     }
   ]
 }`, name, name)
+		if template != "" {
+			var err error
+			if content, err = renderFlowTemplate(template, name); err != nil {
+				fmt.Printf("Error creating flow: %v\n", err)
+				return
+			}
+		}
 
-		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		fileName, err := writeGenerated(cmd, name, "flow", content)
+		if err != nil {
 			fmt.Printf("Error creating flow: %v\n", err)
 			return
 		}
 		fmt.Printf("✅ Generated flow file: %s\n", fileName)
+		if template != "" {
+			fmt.Printf("   From template %s; run 'sapliy validate -f %s' after editing it.\n", template, fileName)
+		}
 	},
 }
 
@@ -71,4 +150,7 @@ func init() {
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.AddCommand(zoneCmd)
 	generateCmd.AddCommand(flowCmd)
+	zoneCmd.Flags().BoolP("interactive", "i", false, "Prompt for the description, environment, triggers and actions")
+	flowCmd.Flags().String("template", "", "Start from a built-in template: "+strings.Join(flowTemplateNames(), ", "))
+	generateCmd.PersistentFlags().String("format", "json", "File format to write: json or yaml")
 }
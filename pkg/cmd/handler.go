@@ -0,0 +1,236 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed handlertemplates/*.tmpl
+var handlerTemplateFiles embed.FS
+
+// handlerLangs are the languages 'generate handler' writes a receiver in:
+// the files of each, rendered from handlertemplates/<file>.tmpl, and how to
+// run it.
+var handlerLangs = map[string]struct {
+	files []string
+	run   string
+}{
+	"go":     {[]string{"main.go", "go.mod"}, "go run ."},
+	"node":   {[]string{"server.js", "package.json"}, "node server.js"},
+	"python": {[]string{"server.py"}, "python3 server.py"},
+}
+
+// handlerField is a field of an event's data.object, typed in each
+// language.
+type handlerField struct {
+	JSON, GoName, GoType, JSType, PyType string
+}
+
+// handlerType is the type of the data.object of one resource's events,
+// inferred from the fixture generate-fixture builds for it.
+type handlerType struct {
+	Name   string
+	Events []string
+	Fields []handlerField
+}
+
+func (t handlerType) EventList() string {
+	return strings.Join(t.Events, ", ")
+}
+
+// handlerCase is one case of the receiver's event switch.
+type handlerCase struct {
+	Event                  string
+	Var                    string // the variable the case decodes data.object into
+	GoType, JSType, PyType string
+	GoID                   string // the object's ID in Go
+}
+
+// concreteEventType matches an event type a switch case can match, with no
+// wildcard.
+var concreteEventType = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)+$`)
+
+// goFieldName turns a JSON field name into an exported Go name, with ID
+// and URL in capitals as Go style has them.
+func goFieldName(name string) string {
+	n := strings.ToUpper(name[:1]) + name[1:]
+	for _, initialism := range []string{"Id", "Url"} {
+		if strings.HasSuffix(n, initialism) {
+			n = strings.TrimSuffix(n, initialism) + strings.ToUpper(initialism)
+		}
+	}
+	return n
+}
+
+// fieldTypes names the type of a fixture value in Go, JSDoc and Python.
+func fieldTypes(v interface{}) (goType, jsType, pyType string) {
+	switch v := v.(type) {
+	case string:
+		return "string", "string", "str"
+	case bool:
+		return "bool", "boolean", "bool"
+	case int:
+		return "int64", "number", "int"
+	case float64:
+		return "float64", "number", "float"
+	case map[string]interface{}:
+		return "map[string]any", "Object", "dict[str, Any]"
+	case []interface{}:
+		if len(v) > 0 {
+			if _, ok := v[0].(map[string]interface{}); ok {
+				return "[]map[string]any", "Object[]", "list[dict[str, Any]]"
+			}
+		}
+		return "[]any", "Array", "list[Any]"
+	}
+	return "any", "*", "Any"
+}
+
+// handlerTypes infers a type for each resource of events that has a
+// fixture, and the switch case of every event.
+func handlerTypes(events []string) ([]handlerType, []handlerCase) {
+	var types []handlerType
+	byResource := map[string]int{}
+	var cases []handlerCase
+	for _, event := range events {
+		resource, _, _ := strings.Cut(event, ".")
+		c := handlerCase{Event: event, Var: strings.ReplaceAll(resource, "_", ""), GoType: "map[string]any", JSType: "Object", PyType: "dict[str, Any]"}
+		c.GoID = fmt.Sprintf("%s[\"id\"]", c.Var)
+		fixture, err := generateFixture(event, 1)
+		if err == nil {
+			i, ok := byResource[resource]
+			if !ok {
+				object := fixture["data"].(map[string]interface{})["object"].(map[string]interface{})
+				t := handlerType{Name: goFieldName(c.Var)}
+				keys := make([]string, 0, len(object))
+				for k := range object {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					goType, jsType, pyType := fieldTypes(object[k])
+					t.Fields = append(t.Fields, handlerField{JSON: k, GoName: goFieldName(k), GoType: goType, JSType: jsType, PyType: pyType})
+				}
+				i = len(types)
+				byResource[resource] = i
+				types = append(types, t)
+			}
+			types[i].Events = append(types[i].Events, event)
+			name := types[i].Name
+			c.GoType, c.JSType, c.PyType = name, name, name
+			c.GoID = c.Var + ".ID"
+		}
+		cases = append(cases, c)
+	}
+	return types, cases
+}
+
+// renderHandler renders the files of a receiver in lang.
+func renderHandler(lang string, events []string, port int) (map[string][]byte, error) {
+	types, cases := handlerTypes(events)
+	data := map[string]interface{}{"Types": types, "Cases": cases, "Port": port}
+	files := map[string][]byte{}
+	for _, name := range handlerLangs[lang].files {
+		tmpl, err := template.ParseFS(handlerTemplateFiles, "handlertemplates/"+name+".tmpl")
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		out := buf.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			if out, err = format.Source(out); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		files[name] = out
+	}
+	return files, nil
+}
+
+var generateHandlerCmd = &cobra.Command{
+	Use:   "handler",
+	Short: "Generate a webhook receiver in Go, Node.js or Python",
+	Long: `Write a small webhook receiver: an HTTP server on /webhook that checks the
+Sapliy-Signature header with $SAPLIY_WEBHOOK_SECRET, rejects stale or forged
+requests, and switches on the event type with a case for each of --events.
+
+Events whose payload generate-fixture knows (payment.*, refund.*, invoice.*,
+...) get a type for their data.object: a struct in Go, a JSDoc typedef in
+Node.js and a TypedDict in Python. Only the standard library is used, so the
+receiver runs as is (Python 3.10 or later, Node.js 18, Go 1.22).`,
+	Example: `  sapliy generate handler --lang go --events payment.succeeded,payment.failed
+  sapliy generate handler --lang python --dir receiver --port 8000`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		lang, _ := cmd.Flags().GetString("lang")
+		events, _ := cmd.Flags().GetStringSlice("events")
+		dir, _ := cmd.Flags().GetString("dir")
+		port, _ := cmd.Flags().GetInt("port")
+		force, _ := cmd.Flags().GetBool("force")
+
+		spec, ok := handlerLangs[lang]
+		if !ok {
+			fmt.Printf("Error: invalid --lang %q (use go, node or python)\n", lang)
+			os.Exit(1)
+		}
+		seen := map[string]bool{}
+		var unique []string
+		for _, e := range events {
+			e = strings.TrimSpace(e)
+			if !concreteEventType.MatchString(e) {
+				fmt.Printf("Error: invalid event type %q (expected e.g. payment.succeeded, without wildcards)\n", e)
+				os.Exit(1)
+			}
+			if !seen[e] {
+				seen[e] = true
+				unique = append(unique, e)
+			}
+		}
+
+		files, err := renderHandler(lang, unique, port)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, name := range spec.files {
+			path := filepath.Join(dir, name)
+			if err := writeNewFile(path, files[name], force); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				continue
+			}
+			fmt.Printf("✅ Created %s\n", path)
+		}
+
+		fmt.Println("\nRun it, then forward events to it:")
+		fmt.Printf("  cd %s && %s\n", dir, spec.run)
+		fmt.Printf("  SAPLIY_WEBHOOK_SECRET=whsec_... sapliy webhooks listen --forward-to http://localhost:%d/webhook\n", port)
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(generateHandlerCmd)
+	generateHandlerCmd.Flags().String("lang", "node", "Language to write the receiver in: go, node or python")
+	generateHandlerCmd.Flags().StringSlice("events", []string{"payment.succeeded", "payment.failed"}, "Event types to write a case for")
+	generateHandlerCmd.Flags().String("dir", "webhook-handler", "Directory to write the receiver to")
+	generateHandlerCmd.Flags().Int("port", 4242, "Port the receiver listens on")
+	generateHandlerCmd.Flags().Bool("force", false, "Overwrite existing files")
+}
@@ -0,0 +1,58 @@
+//go:build headless
+
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// The headless build (go build -tags headless ./cmd/sapliyd) is sapliyd, for
+// servers and containers: no Studio, REPL or local dev environment, and only
+// the commands that run unattended.
+
+// headlessCommands are the commands sapliyd keeps, with all their
+// subcommands: forwarders and bridges that stream events elsewhere, the
+// scheduled jobs a cron or CronJob runs, and what is needed to operate them.
+var headlessCommands = []string{
+	"webhooks listen",
+	"webhooks sink",
+	"connect",
+	"debug listen",
+	"debug record",
+	"audit stream",
+	"export",
+	"script run",
+	"daemon",
+	"agent",
+	"plugins",
+	"config",
+	"doctor",
+	"version",
+}
+
+// configureCommands drops every command sapliyd does not keep.
+func configureCommands() {
+	rootCmd.Use = "sapliyd"
+	rootCmd.Short = "Sapliy headless agent for forwarders, bridges and scheduled jobs"
+	rootCmd.Long = `sapliyd is the headless build of the Sapliy CLI, for servers and containers.
+It runs event forwarders and bridges and scheduled jobs, configured with
+SAPLIY_* environment variables or a config file; the Studio and interactive
+commands are left out.`
+	pruneCommands(rootCmd, "")
+}
+
+func pruneCommands(parent *cobra.Command, prefix string) {
+	for _, c := range parent.Commands() {
+		path := strings.TrimSpace(prefix + " " + c.Name())
+		switch {
+		case slices.Contains(headlessCommands, path):
+		case slices.ContainsFunc(headlessCommands, func(kept string) bool { return strings.HasPrefix(kept, path+" ") }):
+			pruneCommands(c, path)
+		default:
+			parent.RemoveCommand(c)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !headless
+
+package cmd
+
+// configureCommands adjusts the command tree for the build; the full CLI
+// keeps every command.
+func configureCommands() {}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// initProjectConfig is the project config written by 'sapliy init'. %[1]s is
+// the zone ID.
+const initProjectConfig = `# Project settings for sapliy, used in this directory and below. They
+# override ~/.sapliy.yaml, and SAPLIY_* variables and flags override them.
+# This file is meant to be committed: keep api_key and other secrets out.
+config_version: 2
+current_zone: %[1]s
+defaults:
+  deploy.file: sapliy
+  diff.file: sapliy
+  validate.file: sapliy
+  webhooks.listen.forward-to: http://localhost:4242/webhook
+`
+
+const initGitignore = `# Secrets and local state; API keys belong in ~/.sapliy.yaml or the keychain.
+.env
+*.db
+*.db-shm
+*.db-wal
+sink-data/
+node_modules/
+`
+
+// initZone and initFlow are the example resources. %[1]s is the zone ID
+// and %[2]s the project name.
+const initZone = `{
+  "id": "%[1]s",
+  "name": "%[2]s",
+  "description": "Automation zone for %[2]s",
+  "triggers": [],
+  "actions": []
+}
+`
+
+const initFlow = `{
+  "id": "flow_large_payments",
+  "name": "Large payments",
+  "description": "Logs payments of 100.00 or more; replace the log step with what should happen.",
+  "steps": [
+    {
+      "id": "start",
+      "type": "trigger",
+      "config": { "event": "payment.succeeded" }
+    },
+    {
+      "id": "is_large",
+      "type": "condition",
+      "config": { "condition": "event.data.amount >= 10000" }
+    },
+    {
+      "id": "log",
+      "type": "log",
+      "config": { "message": "Large payment {{event.data.id}} of {{event.data.amount}} {{event.data.currency}}" }
+    },
+    {
+      "id": "done",
+      "type": "end"
+    }
+  ]
+}
+`
+
+// initHandler is the webhook handler stub: it verifies the Sapliy-Signature
+// header and dispatches on the event type.
+const initHandler = `// Webhook handler for Sapliy events, generated by 'sapliy init'.
+//
+// Run it with 'node webhook/server.js', then forward events to it:
+//   SAPLIY_WEBHOOK_SECRET=... sapliy webhooks listen
+const http = require("http");
+const crypto = require("crypto");
+
+const secret = process.env.SAPLIY_WEBHOOK_SECRET || "";
+
+function verify(header, body) {
+  const parts = Object.fromEntries((header || "").split(",").map((p) => p.split("=")));
+  if (!parts.t || !parts.v1) return false;
+  const expected = crypto.createHmac("sha256", secret).update(parts.t + "." + body).digest("hex");
+  return expected.length === parts.v1.length &&
+    crypto.timingSafeEqual(Buffer.from(expected), Buffer.from(parts.v1));
+}
+
+async function handle(event) {
+  switch (event.type) {
+    case "payment.succeeded":
+      // TODO: fulfil the order for event.data.
+      break;
+    case "payment.failed":
+      // TODO: tell the customer.
+      break;
+    default:
+      console.log("unhandled event type", event.type);
+  }
+}
+
+http.createServer((req, res) => {
+  if (req.method !== "POST" || req.url !== "/webhook") {
+    res.writeHead(404).end();
+    return;
+  }
+  let body = "";
+  req.on("data", (chunk) => (body += chunk));
+  req.on("end", async () => {
+    if (secret && !verify(req.headers["sapliy-signature"], body)) {
+      res.writeHead(400).end();
+      return;
+    }
+    const event = JSON.parse(body || "{}");
+    try {
+      await handle(event);
+      res.writeHead(200, { "Content-Type": "application/json" }).end('{"received":true}');
+    } catch (err) {
+      // A 5xx makes Sapliy retry the delivery.
+      console.error("failed to handle", event.id, err);
+      res.writeHead(500).end();
+    }
+  });
+}).listen(4242, () => console.log("webhook handler listening on :4242/webhook"));
+`
+
+var unsafeZoneChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// writeNewFile writes a generated file, refusing to replace an existing one
+// unless force is set.
+func writeNewFile(path string, data []byte, force bool) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	if force {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init [name]",
+	Short: "Create a new project with example zone and flow files",
+	Long: `Create a project directory with everything needed to start integrating:
+
+  .sapliy.yaml                 project config: the zone, and flag defaults
+                               so deploy, diff and validate use sapliy/
+  .gitignore                   keeps local state and secrets out of git
+  sapliy/<name>.zone.json      the project's zone
+  sapliy/large_payments.flow.json
+                               an example flow
+  webhook/server.js            a webhook handler stub that checks signatures
+
+Commands run in the directory, or below it, read .sapliy.yaml on top of
+~/.sapliy.yaml. With no name the current directory is used. Existing files
+are left alone unless --force is given.`,
+	Example: `  sapliy init shop
+  cd shop && sapliy validate && sapliy deploy`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		name := filepath.Base(abs)
+		slug := strings.Trim(unsafeZoneChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+		if slug == "" {
+			slug = "project"
+		}
+		zone := "zone_" + slug
+
+		files := []struct {
+			path string
+			data string
+		}{
+			{projectConfigName, fmt.Sprintf(initProjectConfig, zone)},
+			{".gitignore", initGitignore},
+			{filepath.Join("sapliy", slug+".zone.json"), fmt.Sprintf(initZone, zone, name)},
+			{filepath.Join("sapliy", "large_payments.flow.json"), initFlow},
+			{filepath.Join("webhook", "server.js"), initHandler},
+		}
+		for _, sub := range []string{"sapliy", "webhook"} {
+			if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		for _, f := range files {
+			path := filepath.Join(dir, f.path)
+			if err := writeNewFile(path, []byte(f.data), force); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				continue
+			}
+			fmt.Printf("✅ Created %s\n", path)
+		}
+
+		fmt.Println("\nNext steps:")
+		if dir != "." {
+			if strings.ContainsAny(dir, " '\"") {
+				dir = fmt.Sprintf("%q", dir)
+			}
+			fmt.Printf("  cd %s\n", dir)
+		}
+		fmt.Println("  sapliy validate              # check the files in sapliy/")
+		fmt.Println("  sapliy deploy                # create the zone and flow")
+		fmt.Println("  node webhook/server.js &")
+		fmt.Println("  sapliy webhooks listen       # forward events to the handler")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().Bool("force", false, "Overwrite existing files")
+}
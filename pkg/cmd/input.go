@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinReader buffers stdin for every prompt, so input typed ahead of one
+// prompt is not lost to the next.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readPlainLine prints prompt and reads a line from stdin as it is, without
+// line editing.
+func readPlainLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptLine asks for one line of input.
+func promptLine(prompt string) (string, error) {
+	return (&lineReader{}).readLine(prompt)
+}
+
+// confirm asks a yes/no question and reports whether the answer was "y".
+// Ctrl-C, Ctrl-D and anything else mean no.
+func confirm(prompt string) bool {
+	answer, err := promptLine(prompt)
+	return err == nil && strings.ToLower(strings.TrimSpace(answer)) == "y"
+}
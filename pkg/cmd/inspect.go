@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// executionStep is one step of a flow execution as the Flow Runner ran it.
+type executionStep struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Status     string          `json:"status"` // succeeded, failed, skipped, running or pending
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Output     json.RawMessage `json:"output,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// flowExecution is one run of a flow for an event.
+type flowExecution struct {
+	ID         string          `json:"id"`
+	FlowID     string          `json:"flowId"`
+	FlowName   string          `json:"flowName,omitempty"`
+	EventID    string          `json:"eventId,omitempty"`
+	EventType  string          `json:"eventType,omitempty"`
+	Status     string          `json:"status"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	Error      string          `json:"error,omitempty"`
+	Steps      []executionStep `json:"steps"`
+}
+
+var stepStatusIcons = map[string]string{
+	"succeeded": "✅",
+	"failed":    "❌",
+	"skipped":   "⏭️ ",
+	"running":   "⏳",
+	"pending":   "⏸️ ",
+}
+
+func formatMillis(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// printStepPayload prints a step's input or output indented under it, with
+// secrets masked.
+func printStepPayload(view jsonView, label string, raw json.RawMessage) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+	pretty, err := view.render(raw, "      ")
+	if err != nil {
+		pretty = err.Error()
+	}
+	fmt.Printf("      %s: %s\n", label, pretty)
+}
+
+var debugInspectCmd = &cobra.Command{
+	Use:   "inspect [execution_id]",
+	Short: "Inspect a specific flow execution",
+	Long: `Show a flow execution as a timeline of its steps, with each step's status and
+duration. The step that failed is marked with its error. --verbose adds every
+step's input and output payload, with secrets masked, in the order the API
+sent their fields: --sort-keys sorts them, --collapse-depth 2 folds what is
+nested deeper onto one line, and --path shows only part of each payload.`,
+	Example: `  sapliy debug inspect exec_123
+  sapliy debug inspect exec_123 --verbose
+  sapliy debug inspect exec_123 -v --path data.items[0] --collapse-depth 1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set.")
+			os.Exit(1)
+		}
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		view := jsonViewFromFlags(cmd)
+
+		var ex flowExecution
+		if err := apiGetTrimmed(context.Background(), "/v1/executions/"+url.PathEscape(args[0]), &ex); err != nil {
+			fmt.Printf("❌ Failed to fetch execution: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(ex, func() {
+			name := ex.FlowID
+			if ex.FlowName != "" {
+				name = fmt.Sprintf("%s (%s)", ex.FlowName, ex.FlowID)
+			}
+			fmt.Printf("🔍 Execution %s of flow %s\n", ex.ID, name)
+			if ex.EventType != "" {
+				fmt.Printf("   Event:   %s %s\n", ex.EventType, ex.EventID)
+			}
+			status := firstNonEmpty(stepStatusIcons[ex.Status], "•") + " " + ex.Status
+			if ex.StartedAt != nil {
+				status += fmt.Sprintf(", started %s", ex.StartedAt.Local().Format("2006-01-02 15:04:05"))
+			}
+			if ex.DurationMs > 0 {
+				status += ", took " + formatMillis(ex.DurationMs)
+			}
+			fmt.Printf("   Status:  %s\n", status)
+			fmt.Println(strings.Repeat("─", 60))
+
+			if len(ex.Steps) == 0 {
+				fmt.Println("No steps ran.")
+			}
+			for _, s := range ex.Steps {
+				duration := ""
+				if s.Status != "skipped" && s.Status != "pending" {
+					duration = formatMillis(s.DurationMs)
+				}
+				line := strings.TrimRight(fmt.Sprintf(" %s %-24s %-12s %8s", firstNonEmpty(stepStatusIcons[s.Status], "• "), truncate(s.ID, 24), s.Type, duration), " ")
+				if s.Status == "failed" {
+					line += "  ◀ failed here"
+				}
+				fmt.Println(line)
+				if s.Error != "" {
+					fmt.Printf("      error: %s\n", s.Error)
+				}
+				if verbose {
+					printStepPayload(view, "input", s.Input)
+					printStepPayload(view, "output", s.Output)
+				}
+			}
+			if ex.Error != "" && ex.Status == "failed" {
+				fmt.Println(strings.Repeat("─", 60))
+				fmt.Printf("❌ %s\n", ex.Error)
+			}
+			printFileRefs(context.Background(), ex)
+		})
+	},
+}
+
+func init() {
+	debugCmd.AddCommand(debugInspectCmd)
+	debugInspectCmd.Flags().BoolP("verbose", "v", false, "Show each step's input and output payloads")
+	addJSONViewFlags(debugInspectCmd)
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// invoiceStatuses are the statuses invoices list --status accepts.
+var invoiceStatuses = []string{"draft", "open", "paid", "uncollectible", "void"}
+
+// invoice bills a customer, usually for a subscription period. Drafts can
+// still change; finalizing one assigns its number and renders the PDF.
+type invoice struct {
+	ID           string     `json:"id"`
+	Number       string     `json:"number,omitempty"`
+	Customer     string     `json:"customer"`
+	Subscription string     `json:"subscription,omitempty"`
+	Status       string     `json:"status"`
+	Currency     string     `json:"currency"`
+	Total        int64      `json:"total"`
+	AmountDue    int64      `json:"amountDue"`
+	AmountPaid   int64      `json:"amountPaid"`
+	DueDate      *time.Time `json:"dueDate,omitempty"`
+	HostedURL    string     `json:"hostedInvoiceUrl,omitempty"`
+	PDF          string     `json:"invoicePdf,omitempty"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"`
+}
+
+func invoicePath(invoiceID, suffix string) string {
+	return "/v1/invoices/" + url.PathEscape(invoiceID) + suffix
+}
+
+func printInvoice(inv *invoice) {
+	title := inv.ID
+	if inv.Number != "" {
+		title += " (" + inv.Number + ")"
+	}
+	currency := strings.ToUpper(inv.Currency)
+	fmt.Printf("🧾 Invoice %s — %s\n", title, inv.Status)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Customer:      %s\n", inv.Customer)
+	if inv.Subscription != "" {
+		fmt.Printf("Subscription:  %s\n", inv.Subscription)
+	}
+	fmt.Printf("Total:         %s %s\n", formatAmount(inv.Total, inv.Currency), currency)
+	fmt.Printf("Paid:          %s %s\n", formatAmount(inv.AmountPaid, inv.Currency), currency)
+	fmt.Printf("Due:           %s %s\n", formatAmount(inv.AmountDue, inv.Currency), currency)
+	if inv.DueDate != nil {
+		fmt.Printf("Due date:      %s\n", inv.DueDate.Local().Format("Jan 02, 2006"))
+	}
+	if inv.HostedURL != "" {
+		fmt.Printf("Hosted page:   %s\n", inv.HostedURL)
+	}
+	if inv.CreatedAt != nil {
+		fmt.Printf("Created:       %s\n", inv.CreatedAt.Local().Format(time.RFC1123))
+	}
+}
+
+// downloadInvoicePDF writes the invoice's rendered PDF to w. The API key is
+// only sent when the PDF is served by the API itself, not by a file host.
+func downloadInvoicePDF(ctx context.Context, pdfURL string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if base, err := url.Parse(apiBaseURL()); err == nil && base.Host == req.URL.Host {
+		setAuthHeaders(req)
+	}
+	req.Header.Set("Accept", "application/pdf")
+
+	resp, err := transferHTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, &apiError{StatusCode: resp.StatusCode, Message: apiErrorMessage(resp.Body)}
+	}
+
+	// Check the magic bytes so an HTML error page is not saved as a PDF.
+	head := make([]byte, 5)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	if !bytes.Equal(head[:n], []byte("%PDF-")) {
+		return 0, fmt.Errorf("the server did not return a PDF (Content-Type %q)", resp.Header.Get("Content-Type"))
+	}
+	written, err := io.Copy(w, io.MultiReader(bytes.NewReader(head[:n]), resp.Body))
+	return written, err
+}
+
+var invoicesCmd = &cobra.Command{
+	Use:   "invoices",
+	Short: "List, finalize, void and download invoices",
+}
+
+var invoicesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List invoices",
+	Example: `  sapliy invoices list --status open
+  sapliy invoices list --customer cus_123 --created-after 2026-01-01 --all -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		limit, _ := flags.GetInt("limit")
+		status, _ := flags.GetString("status")
+		customerID, _ := flags.GetString("customer")
+		subscriptionID, _ := flags.GetString("subscription")
+		cursor, _ := flags.GetString("cursor")
+		all, _ := flags.GetBool("all")
+		if status != "" && !slices.Contains(invoiceStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(invoiceStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		for key, v := range map[string]string{"status": status, "customer": customerID, "subscription": subscriptionID} {
+			if v != "" {
+				q.Set(key, v)
+			}
+		}
+		for _, name := range []string{"created-after", "created-before"} {
+			s, _ := flags.GetString(name)
+			if s == "" {
+				continue
+			}
+			t, err := parseDateFlag(name, s)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			key := "createdAfter"
+			if name == "created-before" {
+				key = "createdBefore"
+			}
+			q.Set(key, t.UTC().Format(time.RFC3339))
+		}
+
+		invoices, cursor, err := listPages[invoice](context.Background(), "/v1/invoices", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing invoices: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(invoices, func() {
+			if len(invoices) == 0 {
+				fmt.Println("No invoices found.")
+				return
+			}
+
+			fmt.Printf("%-24s %-14s %-20s %12s %-4s %-14s %s\n", "ID", "NUMBER", "CUSTOMER", "TOTAL", "", "STATUS", "DUE")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, inv := range invoices {
+				due := ""
+				if inv.DueDate != nil {
+					due = inv.DueDate.Local().Format("2006-01-02")
+				}
+				fmt.Printf("%-24s %-14s %-20s %12s %-4s %-14s %s\n",
+					inv.ID, inv.Number, inv.Customer, formatAmount(inv.Total, inv.Currency), strings.ToUpper(inv.Currency), inv.Status, due)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore invoices available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var invoicesGetCmd = &cobra.Command{
+	Use:   "get [invoice_id]",
+	Short: "Show an invoice",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var inv invoice
+		if err := apiRequest(context.Background(), http.MethodGet, invoicePath(args[0], ""), nil, &inv); err != nil {
+			fmt.Printf("❌ Failed to fetch invoice: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(inv, func() { printInvoice(&inv) })
+	},
+}
+
+var invoicesFinalizeCmd = &cobra.Command{
+	Use:   "finalize [invoice_id]",
+	Short: "Finalize a draft invoice so it can be paid",
+	Long: `Finalize a draft invoice: it gets its number, its PDF is rendered, and it
+can no longer be edited. With --send the customer is emailed a link to pay.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		send, _ := cmd.Flags().GetBool("send")
+		req := map[string]interface{}{"send": send}
+		var inv invoice
+		if err := apiRequest(context.Background(), http.MethodPost, invoicePath(args[0], "/finalize"), req, &inv); err != nil {
+			fmt.Printf("❌ Failed to finalize invoice: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(inv, func() {
+			printInvoice(&inv)
+			if send {
+				fmt.Println("\n✅ Invoice finalized and sent to the customer!")
+			} else {
+				fmt.Println("\n✅ Invoice finalized!")
+			}
+		})
+	},
+}
+
+var invoicesVoidCmd = &cobra.Command{
+	Use:   "void [invoice_id]",
+	Short: "Void an open invoice",
+	Long: `Void an open invoice so it can no longer be paid. Voiding cannot be undone;
+the invoice keeps its number for your records.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Void invoice %s? This cannot be undone. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		var inv invoice
+		if err := apiRequest(context.Background(), http.MethodPost, invoicePath(args[0], "/void"), nil, &inv); err != nil {
+			fmt.Printf("❌ Failed to void invoice: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(inv, func() {
+			fmt.Printf("🚫 Voided invoice %s\n", inv.ID)
+		})
+	},
+}
+
+var invoicesDownloadCmd = &cobra.Command{
+	Use:   "download [invoice_id]",
+	Short: "Save an invoice's PDF to a local file",
+	Long: `Download the rendered PDF of a finalized invoice. It is saved as
+<number>.pdf in the current directory unless --out is given; --out - writes
+it to stdout.`,
+	Example: `  sapliy invoices download in_123
+  sapliy invoices download in_123 --out ~/invoices/2026-10.pdf
+  sapliy invoices download in_123 --out - | lpr`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{"regionScoped": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		var inv invoice
+		if err := apiRequest(ctx, http.MethodGet, invoicePath(args[0], ""), nil, &inv); err != nil {
+			fmt.Printf("❌ Failed to fetch invoice: %v\n", err)
+			os.Exit(1)
+		}
+		if inv.PDF == "" {
+			if inv.Status == "draft" {
+				fmt.Printf("Error: invoice %s is a draft; finalize it first to render the PDF.\n", inv.ID)
+			} else {
+				fmt.Printf("Error: invoice %s has no PDF.\n", inv.ID)
+			}
+			os.Exit(1)
+		}
+
+		out, _ := cmd.Flags().GetString("out")
+		if out == "-" {
+			if _, err := downloadInvoicePDF(ctx, inv.PDF, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to download invoice: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if out == "" {
+			name := inv.Number
+			if name == "" {
+				name = inv.ID
+			}
+			out = name + ".pdf"
+		}
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if _, err := os.Stat(out); err == nil {
+				fmt.Printf("Error: %s already exists (use --force to overwrite).\n", out)
+				os.Exit(1)
+			}
+		}
+
+		// Write to a temporary file first so a failed download does not
+		// leave a truncated PDF behind.
+		tmp, err := os.CreateTemp(filepath.Dir(out), ".sapliy-invoice-*.pdf")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		size, err := downloadInvoicePDF(ctx, inv.PDF, tmp)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), out)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			fmt.Printf("❌ Failed to download invoice: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📄 Saved %s (%d KB)\n", out, (size+1023)/1024)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(invoicesCmd)
+	invoicesCmd.AddCommand(invoicesListCmd)
+	invoicesCmd.AddCommand(invoicesGetCmd)
+	invoicesCmd.AddCommand(invoicesFinalizeCmd)
+	invoicesCmd.AddCommand(invoicesVoidCmd)
+	invoicesCmd.AddCommand(invoicesDownloadCmd)
+
+	invoicesListCmd.Flags().IntP("limit", "l", 20, "Number of invoices per page")
+	invoicesListCmd.Flags().StringP("status", "s", "", "Filter by status ("+strings.Join(invoiceStatuses, ", ")+")")
+	invoicesListCmd.Flags().String("customer", "", "Filter by customer ID")
+	invoicesListCmd.Flags().String("subscription", "", "Filter by subscription ID")
+	invoicesListCmd.Flags().String("created-after", "", "Only invoices created after this date or age (e.g. 2026-01-01, 30d)")
+	invoicesListCmd.Flags().String("created-before", "", "Only invoices created before this date or age")
+	invoicesListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	invoicesListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	invoicesFinalizeCmd.Flags().Bool("send", false, "Email the invoice to the customer")
+	invoicesVoidCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	invoicesDownloadCmd.Flags().String("out", "", "File to save the PDF to, or - for stdout (default <number>.pdf)")
+	invoicesDownloadCmd.Flags().BoolP("force", "f", false, "Overwrite an existing file")
+}
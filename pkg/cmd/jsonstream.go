@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+// payloadFields are the fields that carry event and step payloads, which
+// can run to megabytes. List and inspect commands cut them to
+// --max-payload-bytes while the response is decoded.
+var payloadFields = map[string]bool{"data": true, "payload": true, "input": true, "output": true, "body": true}
+
+// truncatedKey is added to a payload object that was cut; its value says how
+// much was left out.
+const truncatedKey = "_truncated"
+
+// maxPayloadBytes returns the --max-payload-bytes limit, 0 for none.
+func maxPayloadBytes() int {
+	return max(viper.GetInt("max_payload_bytes"), 0)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the
+// rest.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	n     int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.n += len(p)
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// decodeTrimmed decodes the JSON read from r into out, cutting payload
+// fields longer than limit bytes as it reads, so a huge payload is never
+// held in memory whole. Cut strings end with "… [N bytes truncated]"; cut
+// objects keep the fields that fit, plus a "_truncated" field saying how
+// much was left out.
+func decodeTrimmed(r io.Reader, out interface{}, limit int) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := trimValue(dec, &buf, limit); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), out)
+}
+
+func writeToken(w io.Writer, tok json.Token) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// trimValue copies the next value from dec to w, cutting the payload fields
+// in it.
+func trimValue(dec *json.Decoder, w io.Writer, limit int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); ok {
+		return trimMembers(dec, w, delim, limit)
+	}
+	return writeToken(w, tok)
+}
+
+// trimMembers copies the members of the object or array opened by delim,
+// and its closing delimiter, cutting the payload fields in them.
+func trimMembers(dec *json.Decoder, w io.Writer, delim json.Delim, limit int) error {
+	io.WriteString(w, delim.String())
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			io.WriteString(w, ",")
+		}
+		payload := false
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			writeToken(w, key)
+			io.WriteString(w, ":")
+			payload = limit > 0 && payloadFields[key.(string)]
+		}
+		var err error
+		if payload {
+			err = trimPayload(dec, w, limit)
+		} else {
+			err = trimValue(dec, w, limit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	end, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, end.(json.Delim).String())
+	return err
+}
+
+// trimPayload copies the next value from dec to w, cut to about limit bytes
+// if it is a string or an object. Arrays are lists, such as the "data" of a
+// page, so only the payloads in them are cut.
+func trimPayload(dec *json.Decoder, w io.Writer, limit int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('['):
+		return trimMembers(dec, w, '[', limit)
+	case json.Delim('{'):
+	default:
+		s, ok := tok.(string)
+		if ok && len(s) > limit {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+			tok = fmt.Sprintf("%s… [%d bytes truncated]", s[:cut], len(s)-cut)
+		}
+		return writeToken(w, tok)
+	}
+
+	// Keep the fields that fit and count the bytes of the ones that do not.
+	part := &cappedBuffer{limit: limit}
+	kept, omitted := 0, 0
+	for dec.More() {
+		start, counted := part.buf.Len(), part.n
+		if kept > 0 {
+			io.WriteString(part, ",")
+		}
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		writeToken(part, key)
+		io.WriteString(part, ":")
+		if err := trimValue(dec, part, 0); err != nil {
+			return err
+		}
+		if size := part.n - counted; start+size > limit {
+			omitted += size
+			part.buf.Truncate(start)
+			continue
+		}
+		kept++
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	io.WriteString(w, "{")
+	w.Write(part.buf.Bytes())
+	if omitted > 0 {
+		if kept > 0 {
+			io.WriteString(w, ",")
+		}
+		writeToken(w, truncatedKey)
+		io.WriteString(w, ":")
+		writeToken(w, fmt.Sprintf("%d bytes omitted (--max-payload-bytes)", omitted))
+	}
+	_, err = io.WriteString(w, "}")
+	return err
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// jsonNode is a decoded JSON value that, unlike interface{}, keeps the
+// order of object keys, so payloads print in the order the API sent them.
+type jsonNode struct {
+	kind   byte // '{' for an object, '[' for an array, 0 for a scalar
+	keys   []string
+	values []*jsonNode
+	scalar interface{} // string, json.Number, bool or nil
+}
+
+// parseJSONNode decodes v, which may be raw JSON, as a jsonNode.
+func parseJSONNode(v interface{}) (*jsonNode, error) {
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeJSONNode(dec)
+}
+
+func decodeJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return &jsonNode{scalar: tok}, nil
+	}
+	n := &jsonNode{kind: byte(delim)}
+	for dec.More() {
+		if n.kind == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key.(string))
+		}
+		child, err := decodeJSONNode(dec)
+		if err != nil {
+			return nil, err
+		}
+		n.values = append(n.values, child)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// redact masks secrets the way redactValue does.
+func (n *jsonNode) redact() {
+	for i, child := range n.values {
+		if n.kind == '{' && isSensitiveKey(n.keys[i]) {
+			n.values[i] = &jsonNode{scalar: redacted}
+			continue
+		}
+		child.redact()
+	}
+	if s, ok := n.scalar.(string); ok {
+		n.scalar = redactText(s)
+	}
+}
+
+// jsonPathPart is one dot-separated part of a --path: a key followed by
+// any number of [n] indexes.
+var (
+	jsonPathPart  = regexp.MustCompile(`^([^.\[\]]*)((?:\[\d+\])*)$`)
+	jsonPathIndex = regexp.MustCompile(`\d+`)
+)
+
+// lookup returns the value at a path such as data.items[0].id.
+func (n *jsonNode) lookup(path string) (*jsonNode, error) {
+	cur := n
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		m := jsonPathPart.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("invalid path element %q", part)
+		}
+		if key := m[1]; key != "" {
+			if cur.kind != '{' {
+				return nil, fmt.Errorf("%s: not an object", key)
+			}
+			i := slices.Index(cur.keys, key)
+			if i < 0 {
+				return nil, fmt.Errorf("no field %q", key)
+			}
+			cur = cur.values[i]
+		}
+		for _, index := range jsonPathIndex.FindAllString(m[2], -1) {
+			i, _ := strconv.Atoi(index)
+			if cur.kind != '[' {
+				return nil, fmt.Errorf("[%d]: not an array", i)
+			}
+			if i >= len(cur.values) {
+				return nil, fmt.Errorf("[%d]: the array has %d items", i, len(cur.values))
+			}
+			cur = cur.values[i]
+		}
+	}
+	return cur, nil
+}
+
+// jsonView is how inspect commands print a payload: indented, colored on a
+// terminal, optionally with sorted keys, and with objects and arrays below
+// collapseDepth summarized on one line.
+type jsonView struct {
+	color         bool
+	sortKeys      bool
+	collapseDepth int // 0 shows everything
+	path          string
+}
+
+// jsonColors are the ANSI colors for each kind of JSON token.
+var jsonColors = map[string]string{"key": "34", "string": "32", "number": "33", "bool": "35", "null": "90"}
+
+func addJSONViewFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("sort-keys", false, "Print payload keys in alphabetical order")
+	cmd.Flags().Int("collapse-depth", 0, "Summarize objects and arrays nested deeper than this (0 shows all)")
+	cmd.Flags().String("path", "", "Print only this part of the payload, e.g. data.items[0]")
+}
+
+func jsonViewFromFlags(cmd *cobra.Command) jsonView {
+	v := jsonView{color: stdoutIsTerminal() && os.Getenv("NO_COLOR") == ""}
+	v.sortKeys, _ = cmd.Flags().GetBool("sort-keys")
+	v.collapseDepth, _ = cmd.Flags().GetInt("collapse-depth")
+	v.path, _ = cmd.Flags().GetString("path")
+	return v
+}
+
+// render formats v, with secrets masked, each line after the first
+// starting with indent.
+func (jv jsonView) render(v interface{}, indent string) (string, error) {
+	n, err := parseJSONNode(v)
+	if err != nil {
+		return "", err
+	}
+	n.redact()
+	if jv.path != "" {
+		if n, err = n.lookup(jv.path); err != nil {
+			return "", fmt.Errorf("--path %s: %w", jv.path, err)
+		}
+	}
+	var b strings.Builder
+	jv.write(&b, n, indent, 0)
+	return b.String(), nil
+}
+
+func (jv jsonView) paint(kind, s string) string {
+	if !jv.color {
+		return s
+	}
+	return "\x1b[" + jsonColors[kind] + "m" + s + "\x1b[0m"
+}
+
+func (jv jsonView) write(b *strings.Builder, n *jsonNode, indent string, depth int) {
+	switch n.kind {
+	case 0:
+		switch s := n.scalar.(type) {
+		case string:
+			b.WriteString(jv.paint("string", quoteJSON(s)))
+		case json.Number:
+			b.WriteString(jv.paint("number", s.String()))
+		case bool:
+			b.WriteString(jv.paint("bool", strconv.FormatBool(s)))
+		default:
+			b.WriteString(jv.paint("null", "null"))
+		}
+		return
+	}
+
+	open, end, noun := "{", "}", "key"
+	if n.kind == '[' {
+		open, end, noun = "[", "]", "item"
+	}
+	if len(n.values) != 1 {
+		noun += "s"
+	}
+	switch {
+	case len(n.values) == 0:
+		b.WriteString(open + end)
+		return
+	case jv.collapseDepth > 0 && depth >= jv.collapseDepth:
+		b.WriteString(open + jv.paint("null", fmt.Sprintf(" … %d %s ", len(n.values), noun)) + end)
+		return
+	}
+
+	order := make([]int, len(n.values))
+	for i := range order {
+		order[i] = i
+	}
+	if n.kind == '{' && jv.sortKeys {
+		sort.SliceStable(order, func(a, b int) bool { return n.keys[order[a]] < n.keys[order[b]] })
+	}
+	inner := indent + "  "
+	b.WriteString(open + "\n")
+	for i, idx := range order {
+		b.WriteString(inner)
+		if n.kind == '{' {
+			b.WriteString(jv.paint("key", quoteJSON(n.keys[idx])) + ": ")
+		}
+		jv.write(b, n.values[idx], inner, depth+1)
+		if i < len(order)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + end)
+}
+
+// quoteJSON quotes s as JSON, leaving <, > and & as they are.
+func quoteJSON(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/sync/errgroup"
+)
+
+// Streaming commands (debug listen, debug record, connect, webhooks listen,
+// debug play) stop one way: their context is canceled, by Ctrl+C, a
+// deadline or a failed goroutine, and every goroutine returns once it is.
+// Connections are closed when the context is done, which is what unblocks
+// their readers.
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C, and is
+// the cause of a context canceled by interruptContext.
+var errInterrupted = errors.New("interrupted")
+
+// errServerClosed is returned by readStream when the server closed the
+// stream normally.
+var errServerClosed = errors.New("server closed connection")
+
+// errStreamDone is returned by a readStream handler to end the stream, e.g.
+// once --count events have arrived; readStream then returns nil.
+var errStreamDone = errors.New("stream done")
+
+// interruptContext returns a context canceled with cause errInterrupted on
+// Ctrl+C or SIGTERM. After the first signal the default handling is back,
+// so a second Ctrl+C exits at once if shutting down hangs.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sig:
+			cancel(errInterrupted)
+		case <-ctx.Done():
+		}
+		signal.Stop(sig)
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// interrupted reports whether ctx was canceled by Ctrl+C or SIGTERM.
+func interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errInterrupted)
+}
+
+// streamGroup runs the goroutines of a streaming command. Its context is
+// canceled when the parent's is or when any goroutine returns an error, and
+// Wait returns only once every goroutine has, so none outlives the command.
+type streamGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	g      *errgroup.Group
+}
+
+func newStreamGroup(parent context.Context) *streamGroup {
+	ctx, cancel := context.WithCancel(parent)
+	g, ctx := errgroup.WithContext(ctx)
+	return &streamGroup{ctx: ctx, cancel: cancel, g: g}
+}
+
+// Go runs fn in the group. fn must return once its ctx is done.
+func (s *streamGroup) Go(fn func(ctx context.Context) error) {
+	s.g.Go(func() error { return fn(s.ctx) })
+}
+
+// Done is closed once the group has started stopping.
+func (s *streamGroup) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Wait waits for every goroutine and returns the first error.
+func (s *streamGroup) Wait() error {
+	defer s.cancel()
+	return s.g.Wait()
+}
+
+// stop cancels the group and waits for it.
+func (s *streamGroup) stop() error {
+	s.cancel()
+	return s.Wait()
+}
+
+// closeOnDone closes conn, sending a close frame first, once ctx is done.
+// Call the returned function when finished with conn.
+func closeOnDone(ctx context.Context, conn *websocket.Conn) func() bool {
+	return context.AfterFunc(ctx, func() {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		conn.Close()
+	})
+}
+
+// readStream passes each event frame from conn to handle until ctx is done
+// or handle returns errStreamDone (returning nil), handle returns another
+// error, or the connection ends (errServerClosed for a normal or going-away
+// close frame, the wrapped read error for anything else).
+func readStream(ctx context.Context, conn *websocket.Conn, handle func(message []byte) error) error {
+	defer closeOnDone(ctx, conn)()
+	for {
+		message, err := readEventFrame(conn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			var ce *websocket.CloseError
+			if errors.As(err, &ce) && (ce.Code == websocket.CloseNormalClosure || ce.Code == websocket.CloseGoingAway) {
+				return errServerClosed
+			}
+			return fmt.Errorf("connection error: %w", err)
+		}
+		if err := handle(message); err == errStreamDone {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// logLevels are the levels logs --level accepts, least severe first.
+var logLevels = []string{"info", "warn", "error"}
+
+// logLevelColors are the ANSI colors of levels above info.
+var logLevelColors = map[string]string{"warn": "33", "error": "31"}
+
+// flowLogEntry is one line of a flow's execution log: an execution or a
+// step starting or finishing, a retry, or an error.
+type flowLogEntry struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Level       string    `json:"level"` // info, warn or error
+	Kind        string    `json:"kind"`  // e.g. execution.started, step.finished, step.retrying
+	FlowID      string    `json:"flowId"`
+	ExecutionID string    `json:"executionId"`
+	Step        string    `json:"step,omitempty"`
+	Attempt     int       `json:"attempt,omitempty"`
+	DurationMs  int64     `json:"durationMs,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// text is what a log line says after its time, execution and step.
+func (e *flowLogEntry) text() string {
+	s := firstNonEmpty(e.Message, e.Kind)
+	if e.Attempt > 1 {
+		s += fmt.Sprintf(" (attempt %d)", e.Attempt)
+	}
+	if e.DurationMs > 0 {
+		s += " in " + formatMillis(e.DurationMs)
+	}
+	if e.Error != "" {
+		s += ": " + e.Error
+	}
+	return s
+}
+
+func printFlowLogEntry(e *flowLogEntry, color bool) {
+	level := fmt.Sprintf("%-5s", strings.ToUpper(e.Level))
+	if c, ok := logLevelColors[e.Level]; ok && color {
+		level = "\x1b[" + c + "m" + level + "\x1b[0m"
+	}
+	fmt.Printf("%s %s %-20s %-20s %s\n", e.Time.Local().Format("2006-01-02 15:04:05.000"), level, e.ExecutionID, firstNonEmpty(e.Step, "-"), e.text())
+}
+
+// fetchFlowLogs fetches a page of a flow's log, oldest first: the lines
+// after the one with ID after, or since a time, or without either the
+// latest ones. It reports whether more lines follow the page.
+func fetchFlowLogs(ctx context.Context, path string, q url.Values, after string, since time.Time) ([]flowLogEntry, bool, error) {
+	q.Set("order", "asc")
+	switch {
+	case after != "":
+		q.Set("after", after)
+	case !since.IsZero():
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	default:
+		q.Set("order", "desc")
+	}
+	var page apiPage[flowLogEntry]
+	if err := apiGetTrimmed(ctx, path+"?"+q.Encode(), &page); err != nil {
+		return nil, false, err
+	}
+	if q.Get("order") == "desc" {
+		slices.Reverse(page.Data)
+		return page.Data, false, nil
+	}
+	return page.Data, page.NextCursor != "", nil
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show and follow a flow's execution logs",
+	Long: `Show the execution log of a flow: each execution and step starting and
+finishing, retries and errors, one line each with the execution and step it
+belongs to. Without --since the last --limit lines are shown; --follow then
+keeps printing new lines as the Flow Runner writes them, like
+'kubectl logs -f', until Ctrl+C.
+
+--level warn or error leaves out routine lines, and --execution keeps one
+execution's. With -o json, --follow prints one JSON object per line.
+'sapliy debug inspect <execution_id>' shows an execution's payloads.`,
+	Example: `  sapliy logs --flow flow_abc
+  sapliy logs --flow flow_abc --since 1h --follow
+  sapliy logs --flow flow_abc --level error --since 7d
+  sapliy logs --flow flow_abc -f -o json | jq -r 'select(.kind == "step.retrying") | .executionId'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flowID, _ := cmd.Flags().GetString("flow")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+		follow, _ := cmd.Flags().GetBool("follow")
+		level, _ := cmd.Flags().GetString("level")
+		execution, _ := cmd.Flags().GetString("execution")
+		interval, _ := cmd.Flags().GetDuration("poll-interval")
+		if flowID == "" {
+			fmt.Println("Error: --flow is required.")
+			os.Exit(1)
+		}
+		if level != "" && !slices.Contains(logLevels, level) {
+			fmt.Printf("Error: invalid --level %q (use %s)\n", level, strings.Join(logLevels, ", "))
+			os.Exit(1)
+		}
+		if limit < 1 || limit > 1000 {
+			fmt.Println("Error: --limit must be between 1 and 1000.")
+			os.Exit(1)
+		}
+		if interval < time.Second {
+			fmt.Println("Error: --poll-interval must be at least 1s.")
+			os.Exit(1)
+		}
+		var since time.Time
+		if sinceFlag != "" {
+			var err error
+			if since, err = parseSince(sinceFlag); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		path := flowPath(requireZone(cmd), flowID, "/logs")
+		query := func() url.Values {
+			q := url.Values{"limit": {strconv.Itoa(limit)}}
+			if level != "" {
+				q.Set("level", level)
+			}
+			if execution != "" {
+				q.Set("execution", execution)
+			}
+			return q
+		}
+		color := stdoutIsTerminal() && os.Getenv("NO_COLOR") == ""
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		// Without --follow the lines are one list for -o json; with it,
+		// they are printed as they arrive.
+		var entries []flowLogEntry
+		after := ""
+		for {
+			page, more, err := fetchFlowLogs(ctx, path, query(), after, since)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				fmt.Printf("❌ Failed to fetch logs: %v\n", err)
+				os.Exit(1)
+			}
+			if len(page) > 0 {
+				after = page[len(page)-1].ID
+			}
+			if follow {
+				printFlowLogPage(page, color)
+			} else {
+				entries = append(entries, page...)
+			}
+			if !more {
+				break
+			}
+		}
+		if !follow {
+			printOutput(entries, func() {
+				if len(entries) == 0 {
+					fmt.Println("No log lines found.")
+				}
+				for i := range entries {
+					printFlowLogEntry(&entries[i], color)
+				}
+			})
+			return
+		}
+
+		var backoff streamBackoff
+		for {
+			wait := interval
+			page, more, err := fetchFlowLogs(ctx, path, query(), after, since)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				wait = backoff.next()
+				fmt.Fprintf(os.Stderr, "⚠️  Fetching logs failed: %v; retrying in %s\n", err, wait.Round(100*time.Millisecond))
+			default:
+				backoff.reset()
+				if len(page) > 0 {
+					after = page[len(page)-1].ID
+				}
+				printFlowLogPage(page, color)
+				if more {
+					continue
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	},
+}
+
+// printFlowLogPage prints lines as --follow receives them: as they are, or
+// one JSON object per line with -o json.
+func printFlowLogPage(page []flowLogEntry, color bool) {
+	for i := range page {
+		if structuredOutput() {
+			line, _ := json.Marshal(page[i])
+			fmt.Println(string(line))
+			continue
+		}
+		printFlowLogEntry(&page[i], color)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().String("flow", "", "Flow to show the logs of (required)")
+	logsCmd.Flags().String("since", "", "Show lines since this age or time (e.g. 1h, 7d, or RFC 3339)")
+	logsCmd.Flags().IntP("limit", "l", 100, "Lines to show without --since, and per request")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new lines until Ctrl+C")
+	logsCmd.Flags().String("level", "", "Only lines at this level or above: info, warn or error")
+	logsCmd.Flags().String("execution", "", "Only the lines of this execution")
+	logsCmd.Flags().Duration("poll-interval", 2*time.Second, "How often --follow checks for new lines")
+	logsCmd.Flags().StringP("zone", "z", "", "Zone of the flow (default: current zone)")
+}
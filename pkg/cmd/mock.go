@@ -0,0 +1,728 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+)
+
+// mockDeliveryAttempts is how many times the mock API tries to deliver an
+// event to an endpoint, doubling the wait from mockRetryDelay each time.
+const (
+	mockDeliveryAttempts = 3
+	mockRetryDelay       = time.Second
+)
+
+// mockDeclinedMethod is the payment method that makes a mock payment fail.
+const mockDeclinedMethod = "pm_card_declined"
+
+// mockPayment is a payment intent in the mock API.
+type mockPayment struct {
+	paymentView
+	CaptureMethod string                 `json:"captureMethod"`
+	PaymentMethod string                 `json:"paymentMethod,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// mockEvent is an event in the mock API with the state of its webhook
+// deliveries.
+type mockEvent struct {
+	*localEvent
+	Status   string            `json:"status,omitempty"` // pending, succeeded or failed; empty with no endpoint
+	attempts []deliveryAttempt // guarded by mockAPI.mu
+}
+
+// mockAPI is the in-memory Sapliy API served by 'sapliy mock': payments,
+// events and webhook endpoints behave like the real ones, events are
+// streamed and delivered to the endpoints subscribed to them, and
+// everything is lost when it stops.
+type mockAPI struct {
+	stream *devStream
+	client *http.Client
+	out    io.Writer
+	// onEvent, if set, is called in the background with every event, after
+	// it is streamed.
+	onEvent func(*localEvent)
+
+	mu        sync.Mutex
+	seq       map[string]int // prefix → last ID number
+	payments  []*mockPayment // oldest first, as are events and endpoints
+	events    []*mockEvent
+	endpoints []*webhookEndpoint
+}
+
+// mockError is the error body the real API sends.
+func mockError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeDevJSON(w, status, map[string]interface{}{"error": map[string]string{"message": fmt.Sprintf(format, args...)}})
+}
+
+func newMockAPI(out io.Writer) *mockAPI {
+	return &mockAPI{
+		stream: &devStream{
+			upgrader: websocket.Upgrader{
+				Subprotocols: []string{frameProtocolJSON},
+				CheckOrigin:  func(r *http.Request) bool { return true },
+			},
+			keep:    1000,
+			clients: map[*websocket.Conn]string{},
+		},
+		client: &http.Client{Timeout: 10 * time.Second},
+		out:    out,
+		seq:    map[string]int{},
+	}
+}
+
+func (a *mockAPI) logf(format string, args ...interface{}) {
+	fmt.Fprintf(a.out, "[%s] "+format+"\n", append([]interface{}{time.Now().Format("15:04:05")}, args...)...)
+}
+
+// nextID returns a new ID with prefix. Callers hold a.mu.
+func (a *mockAPI) nextID(prefix string) string {
+	a.seq[prefix]++
+	return fmt.Sprintf("%s_mock_%d", prefix, a.seq[prefix])
+}
+
+func (a *mockAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/payments", a.createPayment)
+	mux.HandleFunc("GET /v1/payments", a.listPayments)
+	mux.HandleFunc("GET /v1/payments/{id}", a.withPayment(func(w http.ResponseWriter, r *http.Request, p *mockPayment) {
+		writeDevJSON(w, http.StatusOK, p)
+	}))
+	mux.HandleFunc("POST /v1/payments/{id}/capture", a.withPayment(a.capturePayment))
+	mux.HandleFunc("POST /v1/payments/{id}/cancel", a.withPayment(a.cancelPayment))
+	mux.HandleFunc("POST /v1/payments/{id}/refunds", a.withPayment(a.refundPayment))
+
+	mux.HandleFunc("POST /v1/events", a.triggerEvent)
+	mux.HandleFunc("GET /v1/events", a.listEvents)
+	mux.HandleFunc("GET /v1/events/{id}", a.withEvent(func(w http.ResponseWriter, r *http.Request, e *mockEvent) {
+		writeDevJSON(w, http.StatusOK, e)
+	}))
+
+	mux.HandleFunc("GET /v1/webhooks/events", a.listEvents)
+	mux.HandleFunc("GET /v1/webhooks/events/{id}", a.withEvent(func(w http.ResponseWriter, r *http.Request, e *mockEvent) {
+		writeDevJSON(w, http.StatusOK, e)
+	}))
+	mux.HandleFunc("GET /v1/webhooks/events/{id}/attempts", a.withEvent(func(w http.ResponseWriter, r *http.Request, e *mockEvent) {
+		writeDevJSON(w, http.StatusOK, append([]deliveryAttempt{}, e.attempts...))
+	}))
+	mux.HandleFunc("POST /v1/webhooks/events/{id}/replay", a.withEvent(a.replayEvent))
+
+	mux.HandleFunc("GET /v1/webhooks/endpoints", a.listEndpoints)
+	mux.HandleFunc("POST /v1/webhooks/endpoints", a.createEndpoint)
+	mux.HandleFunc("GET /v1/webhooks/endpoints/{id}", a.withEndpoint(func(w http.ResponseWriter, r *http.Request, e *webhookEndpoint) {
+		writeDevJSON(w, http.StatusOK, e)
+	}))
+	mux.HandleFunc("PATCH /v1/webhooks/endpoints/{id}", a.withEndpoint(a.updateEndpoint))
+	mux.HandleFunc("DELETE /v1/webhooks/endpoints/{id}", a.withEndpoint(a.deleteEndpoint))
+
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeDevJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mockError(w, http.StatusNotImplemented, "%s %s is not available in the mock API", r.Method, r.URL.Path)
+	})
+
+	// Like the real API, every request needs an API key; any key will do.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && r.Header.Get("Authorization") == "" && r.Header.Get("X-API-Key") == "" && r.URL.Query().Get("api_key") == "" {
+			mockError(w, http.StatusUnauthorized, "no API key; send one in the Authorization header")
+			return
+		}
+		a.mu.Lock()
+		mux.ServeHTTP(w, r)
+		a.mu.Unlock()
+	})
+}
+
+// The handlers below run with a.mu held.
+
+func (a *mockAPI) withPayment(fn func(http.ResponseWriter, *http.Request, *mockPayment)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		for _, p := range a.payments {
+			if p.ID == id {
+				fn(w, r, p)
+				return
+			}
+		}
+		mockError(w, http.StatusNotFound, "no such payment: %s", id)
+	}
+}
+
+func (a *mockAPI) withEvent(fn func(http.ResponseWriter, *http.Request, *mockEvent)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		for _, e := range a.events {
+			if e.ID == id {
+				fn(w, r, e)
+				return
+			}
+		}
+		mockError(w, http.StatusNotFound, "no such event: %s", id)
+	}
+}
+
+func (a *mockAPI) withEndpoint(fn func(http.ResponseWriter, *http.Request, *webhookEndpoint)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		for _, e := range a.endpoints {
+			if e.ID == id {
+				fn(w, r, e)
+				return
+			}
+		}
+		mockError(w, http.StatusNotFound, "no such endpoint: %s", id)
+	}
+}
+
+// decodeMockBody decodes a JSON request body into v, answering 400 if it
+// cannot.
+func decodeMockBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		mockError(w, http.StatusBadRequest, "invalid JSON: %v", err)
+		return false
+	}
+	return true
+}
+
+// mockPage returns the page of items (newest last) that a list request
+// asks for with limit and cursor, newest first. The cursor is the ID of the
+// last item of the previous page.
+func mockPage[T any](r *http.Request, items []T, id func(T) string) apiPage[T] {
+	page := apiPage[T]{Data: []T{}}
+	cursor := r.URL.Query().Get("cursor")
+	limit := devLimit(r)
+	for i := len(items) - 1; i >= 0; i-- {
+		if cursor != "" {
+			if id(items[i]) == cursor {
+				cursor = ""
+			}
+			continue
+		}
+		if len(page.Data) == limit {
+			page.NextCursor = id(page.Data[len(page.Data)-1])
+			break
+		}
+		page.Data = append(page.Data, items[i])
+	}
+	return page
+}
+
+// emit records an event and streams and delivers it. Callers hold a.mu.
+func (a *mockAPI) emit(eventType, zone string, data map[string]interface{}) *mockEvent {
+	e := &mockEvent{localEvent: &localEvent{
+		ID:        a.nextID("evt"),
+		Type:      eventType,
+		Zone:      zone,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	}}
+	a.events = append(a.events, e)
+	a.logf("⚡ %-24s %s", e.Type, e.ID)
+	a.stream.publish(e.localEvent)
+	a.deliver(e)
+	if a.onEvent != nil {
+		go a.onEvent(e.localEvent)
+	}
+	return e
+}
+
+// deliver sends e to every enabled endpoint subscribed to it, in the
+// background. Callers hold a.mu.
+func (a *mockAPI) deliver(e *mockEvent) {
+	payload, _ := json.Marshal(e.localEvent)
+	var targets []webhookEndpoint
+	for _, ep := range a.endpoints {
+		if ep.Enabled && matchesEventTypes(e.Type, ep.Events) && (ep.ZoneID == "" || e.Zone == "" || ep.ZoneID == e.Zone) {
+			targets = append(targets, *ep)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	e.Status = "pending"
+	go func() {
+		ok := true
+		for _, ep := range targets {
+			ok = a.deliverTo(e, ep, payload) && ok
+		}
+		a.mu.Lock()
+		e.Status = "failed"
+		if ok {
+			e.Status = "succeeded"
+		}
+		a.mu.Unlock()
+	}()
+}
+
+// deliverTo posts a signed payload to one endpoint, retrying network errors
+// and 5xx responses, and reports whether it was accepted.
+func (a *mockAPI) deliverTo(e *mockEvent, ep webhookEndpoint, payload []byte) bool {
+	// Attempts are numbered on from those of earlier deliveries, which a
+	// replay follows.
+	a.mu.Lock()
+	first := len(e.attempts) + 1
+	a.mu.Unlock()
+	delay := mockRetryDelay
+	for attempt := 1; ; attempt++ {
+		result := deliveryAttempt{Attempt: first + attempt - 1, Endpoint: ep.URL, StartedAt: time.Now().UTC()}
+		status, body, err := a.post(ep, e, payload)
+		result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+		result.StatusCode = status
+		if err != nil {
+			result.Error = err.Error()
+			a.logf("❌ %-24s %s → %s: %v", e.Type, e.ID, ep.URL, err)
+		} else {
+			const maxBody = 4096
+			if len(body) > maxBody {
+				body, result.Truncated = body[:maxBody], true
+			}
+			s := string(body)
+			result.ResponseBody = &s
+			icon := "📨"
+			if status < 200 || status >= 300 {
+				icon = "❌"
+			}
+			a.logf("%s %-24s %s → %s: %d", icon, e.Type, e.ID, ep.URL, status)
+		}
+		a.mu.Lock()
+		e.attempts = append(e.attempts, result)
+		a.mu.Unlock()
+
+		if err == nil && status < 500 {
+			return status >= 200 && status < 300
+		}
+		if attempt == mockDeliveryAttempts {
+			return false
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (a *mockAPI) post(ep webhookEndpoint, e *mockEvent, payload []byte) (int, []byte, error) {
+	req, err := http.NewRequest(http.MethodPost, ep.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Sapliy-Event-Id", e.ID)
+	req.Header.Set("Sapliy-Event-Type", e.Type)
+	req.Header.Set(signatureHeader, fmt.Sprintf("t=%d,v1=%s", ts, computeSignature(ep.Secret, ts, payload)))
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, body, err
+}
+
+func (a *mockAPI) createPayment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount        int64                  `json:"amount"`
+		Currency      string                 `json:"currency"`
+		ZoneID        string                 `json:"zoneId"`
+		Customer      string                 `json:"customer"`
+		Description   string                 `json:"description"`
+		CaptureMethod string                 `json:"captureMethod"`
+		PaymentMethod string                 `json:"paymentMethod"`
+		Metadata      map[string]interface{} `json:"metadata"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	switch {
+	case req.Amount <= 0:
+		mockError(w, http.StatusBadRequest, "amount must be a positive number of minor units")
+		return
+	case req.Currency == "":
+		mockError(w, http.StatusBadRequest, "currency is required")
+		return
+	case req.CaptureMethod != "" && req.CaptureMethod != "automatic" && req.CaptureMethod != "manual":
+		mockError(w, http.StatusBadRequest, "captureMethod must be automatic or manual")
+		return
+	}
+
+	p := &mockPayment{
+		paymentView: paymentView{
+			ID:          a.nextID("pi"),
+			Amount:      req.Amount,
+			Currency:    strings.ToLower(req.Currency),
+			Status:      "succeeded",
+			Customer:    req.Customer,
+			Description: req.Description,
+			Zone:        req.ZoneID,
+			CreatedAt:   time.Now().UTC(),
+		},
+		CaptureMethod: firstNonEmpty(req.CaptureMethod, "automatic"),
+		PaymentMethod: req.PaymentMethod,
+		Metadata:      req.Metadata,
+	}
+	switch {
+	case p.PaymentMethod == mockDeclinedMethod:
+		p.Status = "failed"
+	case p.CaptureMethod == "manual":
+		p.Status = "requires_capture"
+	}
+	a.payments = append(a.payments, p)
+	a.logf("💳 %s %s %s, %s", p.ID, formatAmount(p.Amount, p.Currency), strings.ToUpper(p.Currency), p.Status)
+
+	a.emit("payment.created", p.Zone, map[string]interface{}{"object": *p})
+	switch p.Status {
+	case "succeeded", "failed":
+		a.emit("payment."+p.Status, p.Zone, map[string]interface{}{"object": *p})
+	}
+	writeDevJSON(w, http.StatusCreated, p)
+}
+
+func (a *mockAPI) listPayments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var after, before time.Time
+	if s := q.Get("createdAfter"); s != "" {
+		after, _ = time.Parse(time.RFC3339, s)
+	}
+	if s := q.Get("createdBefore"); s != "" {
+		before, _ = time.Parse(time.RFC3339, s)
+	}
+	var matched []*mockPayment
+	for _, p := range a.payments {
+		switch {
+		case q.Get("status") != "" && p.Status != q.Get("status"),
+			q.Get("customer") != "" && p.Customer != q.Get("customer"),
+			q.Get("zone") != "" && p.Zone != "" && p.Zone != q.Get("zone"),
+			!after.IsZero() && p.CreatedAt.Before(after),
+			!before.IsZero() && !p.CreatedAt.Before(before):
+			continue
+		}
+		matched = append(matched, p)
+	}
+	writeDevJSON(w, http.StatusOK, mockPage(r, matched, func(p *mockPayment) string { return p.ID }))
+}
+
+func (a *mockAPI) capturePayment(w http.ResponseWriter, r *http.Request, p *mockPayment) {
+	var req struct {
+		AmountToCapture int64 `json:"amountToCapture"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	if p.Status != "requires_capture" {
+		mockError(w, http.StatusConflict, "payment %s is %s and cannot be captured", p.ID, p.Status)
+		return
+	}
+	if req.AmountToCapture < 0 || req.AmountToCapture > p.Amount {
+		mockError(w, http.StatusBadRequest, "amountToCapture must be between 1 and %d", p.Amount)
+		return
+	}
+	if req.AmountToCapture > 0 {
+		p.Amount = req.AmountToCapture
+	}
+	p.Status = "succeeded"
+	a.logf("💳 %s captured %s %s", p.ID, formatAmount(p.Amount, p.Currency), strings.ToUpper(p.Currency))
+	a.emit("payment.succeeded", p.Zone, map[string]interface{}{"object": *p})
+	writeDevJSON(w, http.StatusOK, p)
+}
+
+func (a *mockAPI) cancelPayment(w http.ResponseWriter, r *http.Request, p *mockPayment) {
+	var req struct {
+		CancellationReason string `json:"cancellationReason"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	if !slices.Contains([]string{"requires_payment_method", "requires_capture", "processing"}, p.Status) {
+		mockError(w, http.StatusConflict, "payment %s is %s and cannot be canceled", p.ID, p.Status)
+		return
+	}
+	p.Status = "canceled"
+	if req.CancellationReason != "" {
+		if p.Metadata == nil {
+			p.Metadata = map[string]interface{}{}
+		}
+		p.Metadata["cancellationReason"] = req.CancellationReason
+	}
+	a.logf("💳 %s canceled", p.ID)
+	a.emit("payment.canceled", p.Zone, map[string]interface{}{"object": *p})
+	writeDevJSON(w, http.StatusOK, p)
+}
+
+func (a *mockAPI) refundPayment(w http.ResponseWriter, r *http.Request, p *mockPayment) {
+	var req struct {
+		Amount int64  `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	remaining := p.Amount - p.Refunded
+	if p.Status != "succeeded" {
+		mockError(w, http.StatusConflict, "payment %s is %s and cannot be refunded", p.ID, p.Status)
+		return
+	}
+	if req.Amount == 0 {
+		req.Amount = remaining
+	}
+	if req.Amount < 0 || req.Amount > remaining {
+		mockError(w, http.StatusBadRequest, "amount must be between 1 and the %d left to refund", remaining)
+		return
+	}
+	p.Refunded += req.Amount
+	refund := refundView{
+		ID:        a.nextID("re"),
+		PaymentID: p.ID,
+		Amount:    req.Amount,
+		Currency:  p.Currency,
+		Reason:    req.Reason,
+		Status:    "succeeded",
+		CreatedAt: time.Now().UTC(),
+	}
+	a.logf("↩️  %s refunded %s %s of %s", refund.ID, formatAmount(refund.Amount, refund.Currency), strings.ToUpper(refund.Currency), p.ID)
+	a.emit("refund.completed", p.Zone, map[string]interface{}{"object": refund})
+	a.emit("payment.refunded", p.Zone, map[string]interface{}{"object": *p})
+	writeDevJSON(w, http.StatusCreated, refund)
+}
+
+func (a *mockAPI) triggerEvent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type   string                 `json:"type"`
+		Zone   string                 `json:"zone"`
+		ZoneID string                 `json:"zoneId"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	if req.Type == "" {
+		mockError(w, http.StatusBadRequest, "type is required")
+		return
+	}
+	if req.Data == nil {
+		req.Data = map[string]interface{}{}
+	}
+	writeDevJSON(w, http.StatusCreated, a.emit(req.Type, firstNonEmpty(req.ZoneID, req.Zone), req.Data))
+}
+
+// listEvents serves both the events and the webhook events lists. object
+// keeps the events about one object, for payment timelines.
+func (a *mockAPI) listEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var types []string
+	for _, t := range q["type"] {
+		types = append(types, strings.Split(t, ",")...)
+	}
+	var matched []*mockEvent
+	for _, e := range a.events {
+		switch {
+		case len(types) > 0 && !matchesEventTypes(e.Type, types),
+			q.Get("status") != "" && e.Status != q.Get("status"),
+			q.Get("zone") != "" && e.Zone != "" && e.Zone != q.Get("zone"),
+			q.Get("object") != "" && !eventConcerns(e, q.Get("object")):
+			continue
+		}
+		matched = append(matched, e)
+	}
+	writeDevJSON(w, http.StatusOK, mockPage(r, matched, func(e *mockEvent) string { return e.ID }))
+}
+
+// eventConcerns reports whether e is about the object with ID id, or one
+// that belongs to it, such as a refund of a payment.
+func eventConcerns(e *mockEvent, id string) bool {
+	raw, _ := json.Marshal(e.Data["object"])
+	var object struct {
+		ID        string `json:"id"`
+		PaymentID string `json:"paymentId"`
+	}
+	json.Unmarshal(raw, &object)
+	return object.ID == id || object.PaymentID == id
+}
+
+func (a *mockAPI) replayEvent(w http.ResponseWriter, r *http.Request, e *mockEvent) {
+	var req struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if !decodeMockBody(w, r, &req) {
+		return
+	}
+	if req.Data != nil {
+		// A replay with a different payload is a new event of the same type.
+		e = a.emit(e.Type, e.Zone, req.Data)
+	} else {
+		a.logf("🔄 %-24s %s", e.Type, e.ID)
+		a.deliver(e)
+	}
+	writeDevJSON(w, http.StatusAccepted, e)
+}
+
+func (a *mockAPI) listEndpoints(w http.ResponseWriter, r *http.Request) {
+	zone := r.URL.Query().Get("zone")
+	endpoints := []*webhookEndpoint{}
+	for _, e := range a.endpoints {
+		if zone == "" || e.ZoneID == "" || e.ZoneID == zone {
+			endpoints = append(endpoints, e)
+		}
+	}
+	writeDevJSON(w, http.StatusOK, endpoints)
+}
+
+func (a *mockAPI) createEndpoint(w http.ResponseWriter, r *http.Request) {
+	var e webhookEndpoint
+	if !decodeMockBody(w, r, &e) {
+		return
+	}
+	if err := validateEndpointURL(e.URL); err != nil {
+		mockError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if len(e.Events) == 0 {
+		mockError(w, http.StatusBadRequest, "events is required (use * for all)")
+		return
+	}
+	secret, err := newForwardSecret()
+	if err != nil {
+		mockError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	now := time.Now().UTC()
+	e.ID, e.Secret, e.CreatedAt = a.nextID("we"), secret, &now
+	a.endpoints = append(a.endpoints, &e)
+	a.logf("🔗 %s → %s (%s)", e.ID, e.URL, strings.Join(e.Events, ", "))
+	writeDevJSON(w, http.StatusCreated, e)
+}
+
+func (a *mockAPI) updateEndpoint(w http.ResponseWriter, r *http.Request, e *webhookEndpoint) {
+	var changes struct {
+		URL         *string  `json:"url"`
+		Description *string  `json:"description"`
+		Events      []string `json:"events"`
+		Enabled     *bool    `json:"enabled"`
+	}
+	if !decodeMockBody(w, r, &changes) {
+		return
+	}
+	if changes.URL != nil {
+		if err := validateEndpointURL(*changes.URL); err != nil {
+			mockError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		e.URL = *changes.URL
+	}
+	if changes.Description != nil {
+		e.Description = *changes.Description
+	}
+	if changes.Events != nil {
+		e.Events = changes.Events
+	}
+	if changes.Enabled != nil {
+		e.Enabled = *changes.Enabled
+	}
+	writeDevJSON(w, http.StatusOK, e)
+}
+
+func (a *mockAPI) deleteEndpoint(w http.ResponseWriter, r *http.Request, e *webhookEndpoint) {
+	a.endpoints = slices.DeleteFunc(a.endpoints, func(x *webhookEndpoint) bool { return x == e })
+	a.logf("🔗 %s deleted", e.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Serve a fake Sapliy API for offline development",
+	Long: `Serve an in-memory fake of the Sapliy REST API and event stream, so the CLI
+and your own apps can be developed and tested without network access or an
+account. State starts empty and is lost when the mock stops.
+
+The mock covers payments (create, list, get, capture, cancel, refund),
+events (trigger, list, get, and the WebSocket stream on /v1/events/stream)
+and webhooks (endpoints, delivered events, attempts and replay). Payments
+raise the events the real API raises, and every event is streamed and
+delivered to the enabled endpoints subscribed to it, signed with the
+endpoint's secret and retried on errors. Any API key is accepted.
+
+Payments are captured at once unless created with "captureMethod": "manual";
+a "paymentMethod" of pm_card_declined makes a payment fail. Other endpoints
+answer 501.`,
+	Example: `  sapliy mock
+  sapliy mock --port 9000
+
+  # In another terminal
+  export SAPLIY_API_URL=http://localhost:8089 SAPLIY_API_KEY=sk_test_mock
+  sapliy webhooks endpoints create --url http://localhost:4242/webhook --events 'payment.*'
+  sapliy trigger payment.succeeded --data '{"amount": 5000}'
+  sapliy payments list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+
+		api := newMockAPI(os.Stdout)
+		// The stream is not served under api.mu, as it holds the connection.
+		mux := http.NewServeMux()
+		mux.Handle("GET /v1/events/stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("api_key") == "" && r.Header.Get("Authorization") == "" {
+				mockError(w, http.StatusUnauthorized, "no API key; pass ?api_key=")
+				return
+			}
+			api.stream.ServeHTTP(w, r)
+		}))
+		mux.Handle("/", api.handler())
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		server := &http.Server{Handler: mux}
+
+		fmt.Println("🧪 Sapliy mock API (Ctrl+C to stop)")
+		fmt.Printf("   ├── REST API:     http://localhost:%d\n", port)
+		fmt.Printf("   └── Event stream: ws://localhost:%d/v1/events/stream\n", port)
+		fmt.Println("\nPoint the CLI or your app at it:")
+		fmt.Printf("  export SAPLIY_API_URL=http://localhost:%d SAPLIY_API_KEY=sk_test_mock\n", port)
+		fmt.Println(strings.Repeat("─", 60))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		errs := make(chan error, 1)
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+		select {
+		case <-ctx.Done():
+		case err := <-errs:
+			fmt.Printf("❌ %v\n", err)
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+		fmt.Println("\n👋 Mock API stopped")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+	mockCmd.Flags().String("host", "127.0.0.1", "Address to listen on (0.0.0.0 in a container)")
+	mockCmd.Flags().IntP("port", "p", 8089, "Port to serve the API and event stream on")
+}
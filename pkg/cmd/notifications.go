@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// notificationTest asks the API to render a notification template with the
+// given data and send the result to a test recipient.
+type notificationTest struct {
+	Channel string                 `json:"channel"`
+	To      string                 `json:"to"`
+	Data    map[string]interface{} `json:"data"`
+	ZoneID  string                 `json:"zoneId,omitempty"`
+}
+
+type notificationTestResult struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Channel  string `json:"channel"`
+	To       string `json:"to"`
+	Subject  string `json:"subject,omitempty"`
+	Preview  string `json:"preview,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// readTemplateData loads --data, which is inline JSON, @file or @- for stdin.
+func readTemplateData(value string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if value == "" {
+		return data, nil
+	}
+	raw, err := readArgValue(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("--data must be a JSON object: %w", err)
+	}
+	return data, nil
+}
+
+// renderedNotification is a template rendered by the API without sending.
+type renderedNotification struct {
+	Template string `json:"template"`
+	Subject  string `json:"subject,omitempty"`
+	Text     string `json:"text,omitempty"`
+	HTML     string `json:"html,omitempty"`
+}
+
+var notificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Work with the notification templates used in flows",
+}
+
+var notificationsTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification rendered from a template",
+}
+
+var notificationsRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a notification template without sending it",
+	Long: `Render a notification template with sample data and print the subject,
+text and HTML versions. Nothing is sent.
+
+--source renders a local copy of the template instead of the saved one, so
+edits can be checked before they are uploaded. --open shows the HTML version
+in the browser.`,
+	Example: `  sapliy notifications render --template payment_receipt --data @payload.json
+  sapliy notifications render --template payment_receipt --source ./payment_receipt.html --data @payload.json --open`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		template, _ := cmd.Flags().GetString("template")
+		dataArg, _ := cmd.Flags().GetString("data")
+		sourcePath, _ := cmd.Flags().GetString("source")
+		openHTML, _ := cmd.Flags().GetBool("open")
+
+		data, err := readTemplateData(dataArg)
+		if err != nil {
+			fmt.Printf("Error reading data: %v\n", err)
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{"data": data}
+		if sourcePath != "" {
+			source, err := os.ReadFile(sourcePath)
+			if err != nil {
+				fmt.Printf("Error reading template source: %v\n", err)
+				os.Exit(1)
+			}
+			req["source"] = string(source)
+		}
+		if zone := resolveZone(cmd); zone != "" {
+			req["zoneId"] = zone
+		}
+
+		path := "/v1/notifications/templates/" + url.PathEscape(template) + "/render"
+		var rendered renderedNotification
+		if err := apiRequest(context.Background(), http.MethodPost, path, req, &rendered); err != nil {
+			fmt.Printf("❌ Failed to render %s: %v\n", template, err)
+			os.Exit(1)
+		}
+		if rendered.Template == "" {
+			rendered.Template = template
+		}
+
+		opened := false
+		if openHTML {
+			if rendered.HTML == "" {
+				fmt.Fprintln(os.Stderr, "⚠️  The template has no HTML version to open.")
+			} else if err := openRenderedHTML(rendered.HTML); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not open the browser: %v\n", err)
+			} else {
+				opened = true
+			}
+		}
+
+		printOutput(rendered, func() {
+			if rendered.Subject != "" {
+				fmt.Printf("Subject: %s\n", rendered.Subject)
+			}
+			if rendered.Text != "" {
+				fmt.Println(strings.Repeat("─", 25) + " text " + strings.Repeat("─", 29))
+				fmt.Println(rendered.Text)
+			}
+			if rendered.HTML != "" && !opened {
+				fmt.Println(strings.Repeat("─", 25) + " html " + strings.Repeat("─", 29))
+				fmt.Println(rendered.HTML)
+			}
+		})
+	},
+}
+
+// openRenderedHTML writes html to a temporary file and opens it in the
+// browser. The file is left behind so the browser can still load it.
+func openRenderedHTML(html string) error {
+	f, err := os.CreateTemp("", "sapliy-render-*.html")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(html); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "🌐 Opening %s\n", f.Name())
+	return openBrowser("file://" + filepath.ToSlash(f.Name()))
+}
+
+// newNotificationTestCmd builds the test command for one channel.
+func newNotificationTestCmd(channel, short, recipient, example string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     channel,
+		Short:   short,
+		Example: example,
+		Run: func(cmd *cobra.Command, args []string) {
+			if viper.GetString("api_key") == "" {
+				fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+				os.Exit(1)
+			}
+
+			template, _ := cmd.Flags().GetString("template")
+			to, _ := cmd.Flags().GetString("to")
+			dataArg, _ := cmd.Flags().GetString("data")
+
+			data, err := readTemplateData(dataArg)
+			if err != nil {
+				fmt.Printf("Error reading data: %v\n", err)
+				os.Exit(1)
+			}
+
+			req := notificationTest{Channel: channel, To: to, Data: data, ZoneID: resolveZone(cmd)}
+			path := "/v1/notifications/templates/" + url.PathEscape(template) + "/test"
+			var result notificationTestResult
+			if err := apiRequest(context.Background(), http.MethodPost, path, &req, &result); err != nil {
+				fmt.Printf("❌ Failed to send test %s: %v\n", channel, err)
+				os.Exit(1)
+			}
+
+			printOutput(result, func() {
+				fmt.Printf("✅ Sent test %s from template %s to %s\n", channel, template, to)
+				if result.Subject != "" {
+					fmt.Printf("   Subject: %s\n", result.Subject)
+				}
+				if result.Preview != "" {
+					fmt.Printf("   Preview: %s\n", truncate(result.Preview, 100))
+				}
+				fmt.Printf("   Message %s: %s\n", result.ID, result.Status)
+			})
+		},
+	}
+	cmd.Flags().String("template", "", "Template to render (e.g. payment_receipt)")
+	cmd.Flags().String("to", "", recipient)
+	cmd.Flags().String("data", "", "Template data as JSON, @file or @- for stdin")
+	cmd.MarkFlagRequired("template")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(notificationsCmd)
+	notificationsCmd.AddCommand(notificationsTestCmd)
+	notificationsCmd.AddCommand(notificationsRenderCmd)
+	notificationsTestCmd.AddCommand(newNotificationTestCmd("email", "Render a template and send it as a test email", "Email address to send the test to",
+		"  sapliy notifications test email --template payment_receipt --to dev@co.com --data @payload.json"))
+	notificationsTestCmd.AddCommand(newNotificationTestCmd("sms", "Render a template and send it as a test SMS", "Phone number to send the test to, in E.164 format",
+		"  sapliy notifications test sms --template payment_failed --to +15555550123 --data '{\"amount\": 2000}'"))
+
+	notificationsRenderCmd.Flags().String("template", "", "Template to render (e.g. payment_receipt)")
+	notificationsRenderCmd.Flags().String("data", "", "Template data as JSON, @file or @- for stdin")
+	notificationsRenderCmd.Flags().String("source", "", "Render this local template file instead of the saved template")
+	notificationsRenderCmd.Flags().Bool("open", false, "Open the HTML version in the browser")
+	notificationsRenderCmd.MarkFlagRequired("template")
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// OAuth device authorization grant (RFC 8628), for users who sign in through
+// the dashboard instead of holding a raw API key. The access token is stored
+// as the api_key credential so every command and the SDK use it unchanged;
+// refresh_token and token_expiry let it be renewed before it runs out.
+const (
+	oauthClientID   = "sapliy-cli"
+	oauthScope      = "cli"
+	deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type oauthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+// oauthPost sends a form-encoded request to an OAuth endpoint and decodes a
+// successful response into out. Error responses come back as *oauthError.
+func oauthPost(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiBaseURL()+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := apiHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var oe oauthError
+		if json.NewDecoder(resp.Body).Decode(&oe) == nil && oe.Code != "" {
+			return &oe
+		}
+		return &apiError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func requestDeviceCode(ctx context.Context) (*deviceAuthorization, error) {
+	var da deviceAuthorization
+	err := oauthPost(ctx, "/oauth/device/code", url.Values{
+		"client_id": {oauthClientID},
+		"scope":     {oauthScope},
+	}, &da)
+	if err != nil {
+		return nil, err
+	}
+	if da.Interval <= 0 {
+		da.Interval = 5
+	}
+	return &da, nil
+}
+
+// pollDeviceToken polls the token endpoint until the user approves or denies
+// the request, or the device code expires.
+func pollDeviceToken(ctx context.Context, da *deviceAuthorization) (*oauthToken, error) {
+	interval := time.Duration(da.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(da.ExpiresIn) * time.Second)
+
+	for {
+		if da.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("the code expired before it was approved; run 'sapliy auth login' again")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var tok oauthToken
+		err := oauthPost(ctx, "/oauth/token", url.Values{
+			"grant_type":  {deviceCodeGrant},
+			"device_code": {da.DeviceCode},
+			"client_id":   {oauthClientID},
+		}, &tok)
+		if err == nil {
+			return &tok, nil
+		}
+
+		oe, ok := err.(*oauthError)
+		if !ok {
+			return nil, err
+		}
+		switch oe.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, fmt.Errorf("the request was denied in the browser")
+		case "expired_token":
+			return nil, fmt.Errorf("the code expired before it was approved; run 'sapliy auth login' again")
+		default:
+			return nil, oe
+		}
+	}
+}
+
+// storeToken saves an OAuth token in the credential store and its expiry in
+// the config file.
+func storeToken(tok *oauthToken) error {
+	if err := setCredential("api_key", tok.AccessToken); err != nil {
+		return err
+	}
+	if tok.RefreshToken != "" {
+		if err := setCredential("refresh_token", tok.RefreshToken); err != nil {
+			return err
+		}
+	}
+	expiry := ""
+	if tok.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second).UTC().Format(time.RFC3339)
+	}
+	viper.Set("token_expiry", expiry)
+	return saveConfig("token_expiry")
+}
+
+// refreshTokenIfNeeded renews the OAuth access token when it is about to
+// expire. It does nothing for API keys, which have no refresh token.
+func refreshTokenIfNeeded() {
+	refresh := viper.GetString("refresh_token")
+	expiry, err := time.Parse(time.RFC3339, viper.GetString("token_expiry"))
+	if refresh == "" || err != nil || time.Until(expiry) > time.Minute {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var tok oauthToken
+	err = oauthPost(ctx, "/oauth/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refresh},
+		"client_id":     {oauthClientID},
+	}, &tok)
+	if err == nil {
+		err = storeToken(&tok)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not refresh your login (%v). Run 'sapliy auth login' again.\n", err)
+	}
+}
+
+// openBrowser tries to open url in the default browser.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
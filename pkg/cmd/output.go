@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats for --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q (use table, json or yaml)", outputFormat)
+}
+
+// structuredOutput reports whether --output asks for machine-readable
+// output. Commands skip progress messages and decorations in that case.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal, where
+// output can be colored.
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// stderrIsTerminal reports whether stderr is an interactive terminal, where
+// progress can be redrawn in place.
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// printOutput renders v in the --output format. For table output, table is
+// called to print the human-readable view, or in accessible mode v is
+// printed as labeled lines. A nil slice is printed as an
+// empty list rather than null.
+func printOutput(v interface{}, table func()) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	case outputYAML:
+		// Round-trip through JSON so the json struct tags name the keys.
+		raw, err := json.Marshal(v)
+		var generic interface{}
+		if err == nil {
+			err = json.Unmarshal(raw, &generic)
+		}
+		var out []byte
+		if err == nil {
+			out, err = yaml.Marshal(generic)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding YAML: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(out))
+	default:
+		if accessibleMode() {
+			printLabeled(v)
+			return
+		}
+		table()
+	}
+}
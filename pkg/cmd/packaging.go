@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// packagedFile is a file of the packaging bundle and where each package
+// format installs it: an absolute path for deb, the formula's install
+// method for Homebrew, and a path in the app directory for Scoop.
+type packagedFile struct {
+	Path    string            `json:"path"`
+	Kind    string            `json:"kind"` // completion, man or config
+	Install map[string]string `json:"install"`
+}
+
+type packagingManifest struct {
+	Name    string         `json:"name"`
+	Version string         `json:"version"`
+	Files   []packagedFile `json:"files"`
+}
+
+// completionInstall is where each shell's completion script goes, per
+// package format. %s is the binary name.
+var completionInstall = map[string]map[string]string{
+	"bash":       {"deb": "/usr/share/bash-completion/completions/%s", "homebrew": "bash_completion"},
+	"zsh":        {"deb": "/usr/share/zsh/vendor-completions/_%s", "homebrew": "zsh_completion"},
+	"fish":       {"deb": "/usr/share/fish/vendor_completions.d/%s.fish", "homebrew": "fish_completion"},
+	"powershell": {"scoop": "completions/%s.ps1"},
+}
+
+// exampleConfig renders a config file with every key of configSchema
+// commented out, with its description, for packages to ship as an example.
+func exampleConfig(bin string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Example %s configuration. Copy it to ~/.%s.yaml and uncomment what you\n", bin, bin)
+	b.WriteString("# need; 'sapliy config validate' checks the result.\n")
+	fmt.Fprintf(&b, "config_version: %d\n", currentConfigVersion)
+	for _, f := range configSchema {
+		if f.Key == "config_version" {
+			continue
+		}
+		indent := ""
+		key := f.Key
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			indent, key = "  ", key[i+1:]
+		}
+		fmt.Fprintf(&b, "\n%s# %s\n", indent, f.Description)
+		switch f.Kind {
+		case kindSection:
+			fmt.Fprintf(&b, "# %s:\n", key)
+		case kindMap:
+			fmt.Fprintf(&b, "# %s: {}\n", key)
+		case kindStringList:
+			fmt.Fprintf(&b, "%s# %s: []\n", indent, key)
+		case kindBool:
+			fmt.Fprintf(&b, "%s# %s: false\n", indent, key)
+		case kindInt:
+			fmt.Fprintf(&b, "%s# %s: 0\n", indent, key)
+		default:
+			fmt.Fprintf(&b, "%s# %s: \"\"\n", indent, key)
+		}
+	}
+	return b.String()
+}
+
+var packagingCmd = &cobra.Command{
+	Use:   "packaging",
+	Short: "Generate the files distribution packages install",
+}
+
+var packagingBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Write completions, man pages and an example config for packagers",
+	Long: `Write everything a Homebrew formula, Scoop manifest or Debian package
+installs next to the binary, generated from the binary itself so packages
+always match its commands and flags:
+
+  completions/   bash, zsh, fish and PowerShell completion scripts
+  man/man1/      a man page for every command
+  config/        an example config with every key, commented out
+  manifest.json  every file with where deb, Homebrew and Scoop install it
+
+Run it with the binary being packaged, as part of the package build. Man
+pages are dated $SOURCE_DATE_EPOCH when it is set.`,
+	Example: `  sapliy packaging bundle --out dist/bundle
+  jq -r '.files[] | select(.install.deb) | "\(.path) \(.install.deb)"' dist/bundle/manifest.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		root := cmd.Root()
+		bin := root.Name()
+		version := root.Version
+		if version == "" {
+			version = "dev"
+		}
+		fail := func(err error) {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		rel := func(path string) string {
+			r, _ := filepath.Rel(out, path)
+			return filepath.ToSlash(r)
+		}
+
+		m := packagingManifest{Name: bin, Version: version}
+		completions, err := writeCompletions(root, filepath.Join(out, "completions"))
+		if err != nil {
+			fail(err)
+		}
+		for _, c := range completionFiles {
+			install := map[string]string{}
+			for format, dest := range completionInstall[c.shell] {
+				if strings.Contains(dest, "%s") {
+					dest = fmt.Sprintf(dest, bin)
+				}
+				install[format] = dest
+			}
+			m.Files = append(m.Files, packagedFile{Path: rel(completions[c.shell]), Kind: "completion", Install: install})
+		}
+
+		pages, err := writeManPages(root, filepath.Join(out, "man", "man1"))
+		if err != nil {
+			fail(err)
+		}
+		for _, page := range pages {
+			m.Files = append(m.Files, packagedFile{Path: rel(page), Kind: "man", Install: map[string]string{
+				"deb":      "/usr/share/man/man1/" + filepath.Base(page),
+				"homebrew": "man1",
+			}})
+		}
+
+		config := filepath.Join(out, "config", bin+".yaml.example")
+		if err := os.MkdirAll(filepath.Dir(config), 0755); err != nil {
+			fail(err)
+		}
+		if err := os.WriteFile(config, []byte(exampleConfig(bin)), 0644); err != nil {
+			fail(err)
+		}
+		m.Files = append(m.Files, packagedFile{Path: rel(config), Kind: "config", Install: map[string]string{
+			"deb":      "/usr/share/doc/" + bin + "/" + filepath.Base(config),
+			"homebrew": "pkgshare",
+			"scoop":    "config/" + filepath.Base(config),
+		}})
+
+		manifest, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			fail(err)
+		}
+		if err := os.WriteFile(filepath.Join(out, "manifest.json"), append(manifest, '\n'), 0644); err != nil {
+			fail(err)
+		}
+
+		printOutput(m, func() {
+			fmt.Printf("✅ Packaging bundle for %s %s in %s\n", bin, version, out)
+			fmt.Printf("   %d completion scripts, %d man pages, 1 example config\n", len(completions), len(pages))
+			fmt.Printf("   Install targets are listed in %s\n", filepath.Join(out, "manifest.json"))
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(packagingCmd)
+	packagingCmd.AddCommand(packagingBundleCmd)
+	packagingBundleCmd.Flags().String("out", "dist/packaging", "Directory to write the bundle to")
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// eventSchema is the subset of JSON Schema the schema registry uses to
+// describe event payloads.
+type eventSchema struct {
+	Type        string                  `json:"type"`
+	Description string                  `json:"description,omitempty"`
+	Properties  map[string]*eventSchema `json:"properties,omitempty"`
+	Required    []string                `json:"required,omitempty"`
+	Enum        []interface{}           `json:"enum,omitempty"`
+	Items       *eventSchema            `json:"items,omitempty"`
+	Default     interface{}             `json:"default,omitempty"`
+	Format      string                  `json:"format,omitempty"`
+}
+
+func fetchEventSchema(ctx context.Context, eventType string) (*eventSchema, error) {
+	var s eventSchema
+	if err := apiRequest(ctx, http.MethodGet, "/v1/schemas/"+url.PathEscape(eventType), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// payloadForm asks for each field of a schema on the terminal. Fields are
+// asked in order: required ones first, then optional ones, each group
+// alphabetically.
+type payloadForm struct {
+	lines *lineReader
+	out   io.Writer
+}
+
+func (f *payloadForm) ask(prompt string) (string, error) {
+	line, err := f.lines.readLine(prompt)
+	return strings.TrimSpace(line), err
+}
+
+// object fills in the properties of an object schema. initial values (from
+// --data) are offered as defaults.
+func (f *payloadForm) object(path string, s *eventSchema, initial map[string]interface{}) (map[string]interface{}, error) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := slices.Contains(s.Required, names[i]), slices.Contains(s.Required, names[j])
+		if ri != rj {
+			return ri
+		}
+		return names[i] < names[j]
+	})
+
+	obj := map[string]interface{}{}
+	for _, name := range names {
+		field := s.Properties[name]
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		required := slices.Contains(s.Required, name)
+
+		if field.Type == "object" && len(field.Properties) > 0 {
+			nested, _ := initial[name].(map[string]interface{})
+			if !required && nested == nil {
+				answer, err := f.ask(fmt.Sprintf("Add %s? [y/N]: ", fieldPath))
+				if err != nil {
+					return nil, err
+				}
+				if strings.ToLower(answer) != "y" {
+					continue
+				}
+			}
+			v, err := f.object(fieldPath, field, nested)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+			continue
+		}
+
+		def := field.Default
+		if v, ok := initial[name]; ok {
+			def = v
+		}
+		v, set, err := f.field(fieldPath, field, required, def)
+		if err != nil {
+			return nil, err
+		}
+		if set {
+			obj[name] = v
+		}
+	}
+	return obj, nil
+}
+
+// field asks for one value until it parses. It reports false if an optional
+// field was left empty.
+func (f *payloadForm) field(path string, s *eventSchema, required bool, def interface{}) (interface{}, bool, error) {
+	label := path
+	if s.Type != "" {
+		label += " (" + s.Type + ")"
+	}
+	if required {
+		label += " *"
+	}
+	if s.Description != "" {
+		fmt.Fprintf(f.out, "  %s\n", s.Description)
+	}
+	if len(s.Enum) > 0 {
+		for i, e := range s.Enum {
+			fmt.Fprintf(f.out, "  %d) %v\n", i+1, e)
+		}
+	}
+	if def != nil {
+		b, _ := json.Marshal(def)
+		label += " [" + string(b) + "]"
+	}
+
+	for {
+		answer, err := f.ask(label + ": ")
+		if err != nil {
+			return nil, false, err
+		}
+		if answer == "" {
+			if def != nil {
+				return def, true, nil
+			}
+			if !required {
+				return nil, false, nil
+			}
+			fmt.Fprintln(f.out, "  ✗ required")
+			continue
+		}
+		v, err := parseSchemaValue(s, answer)
+		if err != nil {
+			fmt.Fprintf(f.out, "  ✗ %v\n", err)
+			continue
+		}
+		return v, true, nil
+	}
+}
+
+// parseSchemaValue converts typed input to the schema's type. Enum fields
+// take an option number or the value itself, arrays of scalars take a
+// comma-separated list, and anything else takes JSON.
+func parseSchemaValue(s *eventSchema, input string) (interface{}, error) {
+	if len(s.Enum) > 0 {
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(s.Enum) {
+			return s.Enum[n-1], nil
+		}
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == input {
+				return e, nil
+			}
+		}
+		return nil, fmt.Errorf("pick one of the %d options", len(s.Enum))
+	}
+
+	switch s.Type {
+	case "string":
+		return input, nil
+	case "integer":
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not an integer")
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not a number")
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("use true or false")
+		}
+		return b, nil
+	case "array":
+		if s.Items != nil && s.Items.Type != "object" && s.Items.Type != "array" && !strings.HasPrefix(input, "[") {
+			var items []interface{}
+			for _, part := range strings.Split(input, ",") {
+				v, err := parseSchemaValue(s.Items, strings.TrimSpace(part))
+				if err != nil {
+					return nil, fmt.Errorf("item %q: %v", part, err)
+				}
+				items = append(items, v)
+			}
+			return items, nil
+		}
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		return nil, fmt.Errorf("not valid JSON")
+	}
+	return v, nil
+}
+
+// buildPayload runs the form for an event type's schema and returns the
+// payload the user confirmed, or nil if they cancelled.
+func buildPayload(eventType string, initial map[string]interface{}) (map[string]interface{}, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil, fmt.Errorf("--build needs an interactive terminal")
+	}
+
+	schema, err := fetchEventSchema(context.Background(), eventType)
+	if err != nil {
+		return nil, fmt.Errorf("fetch schema for %s: %w", eventType, err)
+	}
+	if len(schema.Properties) == 0 {
+		return nil, fmt.Errorf("the schema for %s has no fields", eventType)
+	}
+
+	f := &payloadForm{lines: &lineReader{}, out: os.Stdout}
+	fmt.Printf("🧱 Building a %s payload (* = required, Enter keeps the [default])\n", eventType)
+	data, err := f.object("", schema, initial)
+	if err != nil {
+		return nil, err
+	}
+
+	pretty, _ := json.MarshalIndent(data, "", "  ")
+	fmt.Printf("\n%s\n\n", pretty)
+	answer, err := f.ask("Send this event? [Y/n]: ")
+	if err != nil {
+		return nil, err
+	}
+	if a := strings.ToLower(answer); a != "" && a != "y" {
+		return nil, nil
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// payloadTemplateFuncs are the placeholders trigger --template expands.
+// Every call produces a fresh value, so {{uuid}} twice gives two IDs.
+func payloadTemplateFuncs() template.FuncMap {
+	faker := newFixtureFaker(mathrand.Uint64())
+	return template.FuncMap{
+		"uuid": func() string {
+			b := make([]byte, 16)
+			rand.Read(b)
+			b[6] = b[6]&0x0f | 0x40
+			b[8] = b[8]&0x3f | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+		},
+		// now is the current time in RFC 3339, optionally shifted by a
+		// duration such as "-1h" or "72h".
+		"now": func(offset ...string) (string, error) {
+			t := time.Now().UTC()
+			if len(offset) > 0 {
+				d, err := time.ParseDuration(offset[0])
+				if err != nil {
+					return "", err
+				}
+				t = t.Add(d)
+			}
+			return t.Format(time.RFC3339), nil
+		},
+		"unix": func() int64 { return time.Now().Unix() },
+		// randint returns an integer in [min, max].
+		"randint": func(min, max int) (int, error) {
+			if max < min {
+				return 0, fmt.Errorf("randint %d %d: max is less than min", min, max)
+			}
+			return min + mathrand.IntN(max-min+1), nil
+		},
+		"id": func(prefix string) string { return faker.id(prefix) },
+		"email": func() string {
+			c := faker.customer()
+			return c["email"].(string)
+		},
+		"pick": func(options ...string) (string, error) {
+			if len(options) == 0 {
+				return "", fmt.Errorf("pick needs at least one option")
+			}
+			return faker.pick(options), nil
+		},
+		"env": os.Getenv,
+	}
+}
+
+// expandPayloadTemplate expands the placeholders in a trigger payload.
+func expandPayloadTemplate(text string) (string, error) {
+	t, err := template.New("data").Funcs(payloadTemplateFuncs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
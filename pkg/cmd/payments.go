@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	fintech "github.com/sapliy/fintech-sdk-go"
 	"github.com/spf13/cobra"
@@ -28,8 +36,8 @@ var createPaymentCmd = &cobra.Command{
 		amount, _ := cmd.Flags().GetInt64("amount")
 		currency, _ := cmd.Flags().GetString("currency")
 
-		client := fintech.NewClient(apiKey)
-		zone := viper.GetString("current_zone")
+		client := newClient()
+		zone := resolveZone(cmd)
 		payment, err := client.Payments.CreateIntent(context.Background(), &fintech.PaymentIntentRequest{
 			Amount:   amount,
 			Currency: currency,
@@ -41,7 +49,414 @@ var createPaymentCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Printf("Payment created successfully! ID: %s\n", payment.ID)
+		printOutput(payment, func() {
+			fmt.Printf("Payment created successfully! ID: %s\n", payment.ID)
+		})
+	},
+}
+
+// paymentStatuses are the payment intent statuses --status accepts.
+var paymentStatuses = []string{"requires_payment_method", "requires_capture", "processing", "succeeded", "canceled", "failed"}
+
+// zeroDecimalCurrencies have no minor unit, so amounts are whole units.
+var zeroDecimalCurrencies = map[string]bool{"JPY": true, "KRW": true, "VND": true, "CLP": true, "ISK": true, "UGX": true}
+
+// formatAmount renders an amount in minor units, e.g. 4999 USD as "49.99".
+func formatAmount(amount int64, currency string) string {
+	if zeroDecimalCurrencies[strings.ToUpper(currency)] {
+		return strconv.FormatInt(amount, 10)
+	}
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// parseDateFlag parses a --created-after/--created-before value: a date
+// (2006-01-02), an RFC 3339 time or an age such as 24h or 7d.
+func parseDateFlag(name, s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := parseSince(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q (use e.g. 2026-01-31, 7d or an RFC 3339 time)", name, s)
+	}
+	return t, nil
+}
+
+// paymentView is a payment intent as printed by the payments commands.
+type paymentView struct {
+	ID          string    `json:"id"`
+	Amount      int64     `json:"amount"`
+	Refunded    int64     `json:"amountRefunded,omitempty"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	Customer    string    `json:"customer,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Zone        string    `json:"zoneId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+var listPaymentsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List payments",
+	Long: `List payments in the current zone, newest first. Filters are applied on the
+server. One page of --limit payments is shown; pass the printed cursor to
+--cursor for the next page, or use --all to fetch every page.`,
+	Example: `  sapliy payments list --status succeeded --created-after 7d
+  sapliy payments list --customer cus_123 --created-after 2026-01-01 --created-before 2026-02-01
+  sapliy payments list --status requires_capture --all -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		customer, _ := cmd.Flags().GetString("customer")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "" && !slices.Contains(paymentStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(paymentStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		if zone := resolveZone(cmd); zone != "" {
+			q.Set("zone", zone)
+		}
+		if status != "" {
+			q.Set("status", status)
+		}
+		if customer != "" {
+			q.Set("customer", customer)
+		}
+		for _, name := range []string{"created-after", "created-before"} {
+			v, _ := cmd.Flags().GetString(name)
+			if v == "" {
+				continue
+			}
+			t, err := parseDateFlag(name, v)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			key := "createdAfter"
+			if name == "created-before" {
+				key = "createdBefore"
+			}
+			q.Set(key, t.UTC().Format(time.RFC3339))
+		}
+
+		payments, cursor, err := listPages[paymentView](context.Background(), "/v1/payments", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing payments: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(payments, func() {
+			if len(payments) == 0 {
+				fmt.Println("No payments found.")
+				return
+			}
+
+			fmt.Printf("%-28s %12s %-8s %-24s %-20s %s\n", "ID", "AMOUNT", "CURRENCY", "STATUS", "CUSTOMER", "CREATED")
+			fmt.Println(strings.Repeat("─", 110))
+			for _, p := range payments {
+				fmt.Printf("%-28s %12s %-8s %-24s %-20s %s\n",
+					p.ID, formatAmount(p.Amount, p.Currency), strings.ToUpper(p.Currency), p.Status, p.Customer, p.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore payments available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+// refundReasons are the reasons --reason accepts.
+var refundReasons = []string{"duplicate", "fraudulent", "requested_by_customer"}
+
+// refundView is a refund of all or part of a payment.
+type refundView struct {
+	ID        string    `json:"id"`
+	PaymentID string    `json:"paymentId"`
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reason    string    `json:"reason,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func paymentPath(paymentID, suffix string) string {
+	return "/v1/payments/" + url.PathEscape(paymentID) + suffix
+}
+
+var refundPaymentCmd = &cobra.Command{
+	Use:   "refund [payment_id]",
+	Short: "Refund a payment in full or in part",
+	Long: `Refund a succeeded payment. Without --amount the whole remaining amount is
+refunded; with it, a partial refund of that many cents is made. A payment can
+be partially refunded several times until nothing is left.`,
+	Example: `  sapliy payments refund pi_123
+  sapliy payments refund pi_123 --amount 500 --reason requested_by_customer --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		amount, _ := cmd.Flags().GetInt64("amount")
+		reason, _ := cmd.Flags().GetString("reason")
+		force, _ := cmd.Flags().GetBool("force")
+		if cmd.Flags().Changed("amount") && amount <= 0 {
+			fmt.Println("Error: --amount must be positive.")
+			os.Exit(1)
+		}
+		if reason != "" && !slices.Contains(refundReasons, reason) {
+			fmt.Printf("Error: invalid --reason %q (use %s)\n", reason, strings.Join(refundReasons, ", "))
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		var payment paymentView
+		if err := apiRequest(ctx, http.MethodGet, paymentPath(args[0], ""), nil, &payment); err != nil {
+			fmt.Printf("Error fetching payment: %v\n", err)
+			os.Exit(1)
+		}
+		remaining := payment.Amount - payment.Refunded
+		if remaining <= 0 {
+			fmt.Printf("Error: payment %s is already fully refunded.\n", payment.ID)
+			os.Exit(1)
+		}
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			fmt.Printf("Error: --amount %s is more than the %s %s left to refund.\n",
+				formatAmount(amount, payment.Currency), formatAmount(remaining, payment.Currency), strings.ToUpper(payment.Currency))
+			os.Exit(1)
+		}
+
+		if !force {
+			kind := "partial"
+			if amount == remaining {
+				kind = "full"
+			}
+			if !confirm(fmt.Sprintf("Refund %s %s of payment %s (%s refund)? [y/N]: ",
+				formatAmount(amount, payment.Currency), strings.ToUpper(payment.Currency), payment.ID, kind)) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		req := map[string]interface{}{"amount": amount}
+		if reason != "" {
+			req["reason"] = reason
+		}
+		var refund refundView
+		if err := apiRequest(ctx, http.MethodPost, paymentPath(payment.ID, "/refunds"), req, &refund); err != nil {
+			if reportPendingApproval(err) {
+				return
+			}
+			fmt.Printf("❌ Failed to refund payment: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(refund, func() {
+			fmt.Printf("✅ Refund %s created: %s %s (%s)\n",
+				refund.ID, formatAmount(refund.Amount, refund.Currency), strings.ToUpper(refund.Currency), refund.Status)
+		})
+	},
+}
+
+// fetchPaymentTimeline loads every event about a payment, oldest first.
+func fetchPaymentTimeline(ctx context.Context, paymentID string) ([]webhookEventView, error) {
+	q := url.Values{"object": {paymentID}, "limit": {"100"}}
+	var events []webhookEventView
+	for {
+		page, err := fetchWebhookEventPage[webhookEventView](ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Data...)
+		if page.NextCursor == "" {
+			break
+		}
+		q.Set("cursor", page.NextCursor)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].CreatedAt.Before(events[j].CreatedAt) })
+	return events, nil
+}
+
+var inspectPaymentCmd = &cobra.Command{
+	Use:     "inspect [payment_id]",
+	Aliases: []string{"get"},
+	Short:   "Inspect a payment and its event timeline",
+	Long: `Show a payment with every field the API returns, plus a timeline of the
+events raised for it (created, authorized, captured, refunded, ...) taken
+from the events API. --path, --collapse-depth and --sort-keys change how
+the object is printed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		// Kept as raw JSON too, so the object prints in the API's field order.
+		var object json.RawMessage
+		if err := apiRequest(ctx, http.MethodGet, paymentPath(args[0], ""), nil, &object); err != nil {
+			fmt.Printf("Error fetching payment: %v\n", err)
+			os.Exit(1)
+		}
+		var raw map[string]interface{}
+		var payment paymentView
+		json.Unmarshal(object, &raw)
+		json.Unmarshal(object, &payment)
+		view := jsonViewFromFlags(cmd)
+
+		timeline, timelineErr := fetchPaymentTimeline(ctx, args[0])
+		out := map[string]interface{}{"payment": raw, "timeline": timeline}
+
+		printOutput(out, func() {
+			currency := strings.ToUpper(payment.Currency)
+			fmt.Printf("💳 Payment: %s\n", payment.ID)
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("Amount:      %s %s\n", formatAmount(payment.Amount, payment.Currency), currency)
+			if payment.Refunded > 0 {
+				fmt.Printf("Refunded:    %s %s\n", formatAmount(payment.Refunded, payment.Currency), currency)
+			}
+			fmt.Printf("Status:      %s\n", payment.Status)
+			if payment.Customer != "" {
+				fmt.Printf("Customer:    %s\n", payment.Customer)
+			}
+			if payment.Description != "" {
+				fmt.Printf("Description: %s\n", payment.Description)
+			}
+			fmt.Printf("Created:     %s\n", payment.CreatedAt.Local().Format(time.RFC1123))
+
+			fmt.Println("\nTimeline:")
+			fmt.Println(strings.Repeat("─", 60))
+			switch {
+			case timelineErr != nil:
+				fmt.Printf("❌ Failed to fetch events: %v\n", timelineErr)
+			case len(timeline) == 0:
+				fmt.Println("No events yet.")
+			default:
+				for i, evt := range timeline {
+					connector := "├─"
+					if i == len(timeline)-1 {
+						connector = "└─"
+					}
+					fmt.Printf("%s %s  %-28s %s\n", connector, evt.CreatedAt.Local().Format("Jan 02 15:04:05"), evt.Type, evt.ID)
+				}
+			}
+
+			fmt.Println("\nObject:")
+			pretty, err := view.render(object, "")
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(pretty)
+			printFileRefs(ctx, object)
+		})
+
+		if timelineErr != nil && structuredOutput() {
+			fmt.Fprintf(os.Stderr, "Error: failed to fetch events: %v\n", timelineErr)
+			os.Exit(1)
+		}
+	},
+}
+
+// cancelReasons are the reasons payments cancel --reason accepts.
+var cancelReasons = []string{"duplicate", "fraudulent", "requested_by_customer", "abandoned"}
+
+var capturePaymentCmd = &cobra.Command{
+	Use:   "capture [payment_id]",
+	Short: "Capture an authorized payment",
+	Long: `Capture a payment intent created with manual capture (status
+requires_capture). Without --amount the full authorized amount is captured;
+with it, only that many cents are and the rest of the authorization is
+released.`,
+	Example: `  sapliy payments capture pi_123
+  sapliy payments capture pi_123 --amount 2500`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{}
+		if cmd.Flags().Changed("amount") {
+			amount, _ := cmd.Flags().GetInt64("amount")
+			if amount <= 0 {
+				fmt.Println("Error: --amount must be positive.")
+				os.Exit(1)
+			}
+			req["amountToCapture"] = amount
+		}
+
+		var payment paymentView
+		if err := apiRequest(context.Background(), http.MethodPost, paymentPath(args[0], "/capture"), req, &payment); err != nil {
+			fmt.Printf("❌ Failed to capture payment: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(payment, func() {
+			fmt.Printf("✅ Captured %s %s on payment %s (%s)\n",
+				formatAmount(payment.Amount, payment.Currency), strings.ToUpper(payment.Currency), payment.ID, payment.Status)
+		})
+	},
+}
+
+var cancelPaymentCmd = &cobra.Command{
+	Use:   "cancel [payment_id]",
+	Short: "Cancel a payment that has not been captured",
+	Long: `Cancel a payment intent that has not been captured yet, releasing any
+authorization on the customer's card. Captured payments must be refunded
+instead.`,
+	Example: `  sapliy payments cancel pi_123
+  sapliy payments cancel pi_123 --reason abandoned --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		reason, _ := cmd.Flags().GetString("reason")
+		if reason != "" && !slices.Contains(cancelReasons, reason) {
+			fmt.Printf("Error: invalid --reason %q (use %s)\n", reason, strings.Join(cancelReasons, ", "))
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Cancel payment %s? This cannot be undone. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		req := map[string]interface{}{}
+		if reason != "" {
+			req["cancellationReason"] = reason
+		}
+		var payment paymentView
+		if err := apiRequest(context.Background(), http.MethodPost, paymentPath(args[0], "/cancel"), req, &payment); err != nil {
+			fmt.Printf("❌ Failed to cancel payment: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(payment, func() {
+			fmt.Printf("🚫 Payment %s canceled\n", payment.ID)
+		})
 	},
 }
 
@@ -51,4 +466,28 @@ func init() {
 	createPaymentCmd.Flags().Int64P("amount", "a", 0, "Amount in cents")
 	createPaymentCmd.Flags().StringP("currency", "c", "USD", "Currency code")
 	createPaymentCmd.MarkFlagRequired("amount")
+
+	paymentsCmd.AddCommand(listPaymentsCmd)
+	listPaymentsCmd.Flags().IntP("limit", "l", 20, "Number of payments per page")
+	listPaymentsCmd.Flags().StringP("status", "s", "", "Filter by status ("+strings.Join(paymentStatuses, ", ")+")")
+	listPaymentsCmd.Flags().String("customer", "", "Filter by customer ID (cus_...)")
+	listPaymentsCmd.Flags().String("created-after", "", "Only payments created after this date, time or age (e.g. 2026-01-31, 7d)")
+	listPaymentsCmd.Flags().String("created-before", "", "Only payments created before this date, time or age")
+	listPaymentsCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	listPaymentsCmd.Flags().Bool("all", false, "Fetch every page")
+
+	paymentsCmd.AddCommand(inspectPaymentCmd)
+	addJSONViewFlags(inspectPaymentCmd)
+
+	paymentsCmd.AddCommand(refundPaymentCmd)
+	refundPaymentCmd.Flags().Int64P("amount", "a", 0, "Amount to refund in cents (default: everything not yet refunded)")
+	refundPaymentCmd.Flags().String("reason", "", "Refund reason ("+strings.Join(refundReasons, ", ")+")")
+	refundPaymentCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	paymentsCmd.AddCommand(capturePaymentCmd)
+	capturePaymentCmd.Flags().Int64P("amount", "a", 0, "Amount to capture in cents (default: the full authorization)")
+
+	paymentsCmd.AddCommand(cancelPaymentCmd)
+	cancelPaymentCmd.Flags().String("reason", "", "Cancellation reason ("+strings.Join(cancelReasons, ", ")+")")
+	cancelPaymentCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 }
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// A plugin is a WebAssembly module listed under "plugins" in the config file.
+// It must export its memory and an allocator, plus one or both hooks:
+//
+//	alloc(size u32) u32                 reserve size bytes for the host to write into
+//	free(ptr u32, len u32)              optional; release a buffer from alloc or format
+//	filter(ptr u32, len u32) u32        0 drops the event, anything else keeps it
+//	format(ptr u32, len u32) u64        (outPtr << 32 | outLen) of the text to print,
+//	                                    or 0 to fall back to the default output
+//
+// The event is passed as its raw JSON. When free is exported the host calls
+// it on the event buffer after each hook and on format's output once it has
+// been copied out; without it the module must reuse its buffers. WASI is available, so modules built
+// with TinyGo or Rust's wasm32-wasi target work as reactors.
+type wasmPlugin struct {
+	name   string
+	mod    api.Module
+	alloc  api.Function
+	free   api.Function
+	filter api.Function
+	format api.Function
+}
+
+// pluginHost runs the configured plugins. Calls are serialized since wasm
+// instances are single-threaded.
+type pluginHost struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	runtime wazero.Runtime
+	plugins []*wasmPlugin
+}
+
+// loadPlugins instantiates every module listed in the "plugins" config key.
+// It returns nil when none are configured.
+func loadPlugins() (*pluginHost, error) {
+	paths := viper.GetStringSlice("plugins")
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	h := &pluginHost{ctx: ctx, runtime: wazero.NewRuntime(ctx)}
+	wasi_snapshot_preview1.MustInstantiate(ctx, h.runtime)
+
+	for _, path := range paths {
+		p, err := h.load(expandHome(path))
+		if err != nil {
+			h.close()
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		h.plugins = append(h.plugins, p)
+	}
+	return h, nil
+}
+
+func (h *pluginHost) load(path string) (*wasmPlugin, error) {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := h.runtime.CompileModule(h.ctx, wasm)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	cfg := wazero.NewModuleConfig().
+		WithName(name).
+		WithStderr(os.Stderr).
+		WithStartFunctions("_initialize")
+	mod, err := h.runtime.InstantiateModule(h.ctx, compiled, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &wasmPlugin{
+		name:   name,
+		mod:    mod,
+		alloc:  mod.ExportedFunction("alloc"),
+		free:   mod.ExportedFunction("free"),
+		filter: mod.ExportedFunction("filter"),
+		format: mod.ExportedFunction("format"),
+	}
+	if mod.Memory() == nil || p.alloc == nil {
+		return nil, fmt.Errorf("module must export memory and alloc")
+	}
+	if p.filter == nil && p.format == nil {
+		return nil, fmt.Errorf("module exports neither filter nor format")
+	}
+	return p, nil
+}
+
+func (h *pluginHost) close() {
+	if h == nil {
+		return
+	}
+	h.runtime.Close(h.ctx)
+}
+
+// write copies event into the plugin's memory.
+func (p *wasmPlugin) write(ctx context.Context, event []byte) (uint64, uint64, error) {
+	res, err := p.alloc.Call(ctx, uint64(len(event)))
+	if err != nil {
+		return 0, 0, err
+	}
+	ptr := res[0]
+	if !p.mod.Memory().Write(uint32(ptr), event) {
+		return 0, 0, fmt.Errorf("alloc returned out-of-range pointer %d", ptr)
+	}
+	return ptr, uint64(len(event)), nil
+}
+
+// release hands a buffer back to the plugin's allocator, if it exports one.
+func (p *wasmPlugin) release(ctx context.Context, ptr, size uint64) error {
+	if p.free == nil || size == 0 {
+		return nil
+	}
+	_, err := p.free.Call(ctx, ptr, size)
+	return err
+}
+
+// keep runs every filter hook and reports whether all of them kept the event.
+// A failing plugin is reported and treated as keeping the event.
+func (h *pluginHost) keep(event []byte) bool {
+	if h == nil {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range h.plugins {
+		if p.filter == nil {
+			continue
+		}
+		keep, err := p.keep(h.ctx, event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  plugin %s filter: %v\n", p.name, err)
+		}
+		if !keep {
+			return false
+		}
+	}
+	return true
+}
+
+// render returns the output of the first format hook that produced any.
+func (h *pluginHost) render(event []byte) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range h.plugins {
+		if p.format == nil {
+			continue
+		}
+		out, err := p.render(h.ctx, event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  plugin %s format: %v\n", p.name, err)
+			continue
+		}
+		if out != "" {
+			return out, true
+		}
+	}
+	return "", false
+}
+
+// keep runs the filter hook. Errors before the hook decides keep the event.
+func (p *wasmPlugin) keep(ctx context.Context, event []byte) (bool, error) {
+	ptr, size, err := p.write(ctx, event)
+	if err != nil {
+		return true, err
+	}
+	res, err := p.filter.Call(ctx, ptr, size)
+	if err != nil {
+		return true, err
+	}
+	return uint32(res[0]) != 0, p.release(ctx, ptr, size)
+}
+
+func (p *wasmPlugin) render(ctx context.Context, event []byte) (string, error) {
+	ptr, size, err := p.write(ctx, event)
+	if err != nil {
+		return "", err
+	}
+	res, err := p.format.Call(ctx, ptr, size)
+	if err != nil {
+		return "", err
+	}
+	if err := p.release(ctx, ptr, size); err != nil {
+		return "", err
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	if outLen == 0 {
+		return "", nil
+	}
+	out, ok := p.mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return "", fmt.Errorf("output out of range")
+	}
+	// Memory.Read returns a view into the module's memory, so copy it out
+	// before the buffer is freed.
+	text := string(out)
+	return text, p.release(ctx, uint64(outPtr), uint64(outLen))
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage WebAssembly plugins",
+	Long: `Plugins are WebAssembly modules that add custom filters and output
+formatters to streaming commands (debug listen, webhooks listen). List them
+in the config file:
+
+  plugins:
+    - ~/.sapliy/plugins/redact.wasm
+
+A module exports memory, alloc(size), optionally free(ptr, len), and
+filter(ptr, len) and/or format(ptr, len); see 'sapliy plugins list' to check what was loaded.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Load the configured plugins and show their hooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		host, err := loadPlugins()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if host == nil {
+			printOutput([]pluginView{}, func() {
+				fmt.Println("No plugins configured. Add .wasm paths under 'plugins' in the config file.")
+			})
+			return
+		}
+		defer host.close()
+
+		views := make([]pluginView, 0, len(host.plugins))
+		for _, p := range host.plugins {
+			v := pluginView{Name: p.name, Hooks: []string{}}
+			if p.filter != nil {
+				v.Hooks = append(v.Hooks, "filter")
+			}
+			if p.format != nil {
+				v.Hooks = append(v.Hooks, "format")
+			}
+			views = append(views, v)
+		}
+
+		printOutput(views, func() {
+			fmt.Printf("%-24s %s\n", "PLUGIN", "HOOKS")
+			fmt.Println(strings.Repeat("─", 40))
+			for _, v := range views {
+				fmt.Printf("%-24s %s\n", v.Name, strings.Join(v.Hooks, ", "))
+			}
+		})
+	},
+}
+
+type pluginView struct {
+	Name  string   `json:"name"`
+	Hooks []string `json:"hooks"`
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+}
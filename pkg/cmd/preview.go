@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	fintech "github.com/sapliy/fintech-sdk-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Preview zones are short-lived zones built from a repository's manifests
+// for one branch, so CI can test a pull request against its own flows. The
+// API deletes them once expiresAt passes.
+const previewZonePrefix = "preview-"
+
+// zoneManifests holds the flows and triggers read from *.flow.json and
+// *.zone.json files, as generated by 'sapliy generate'.
+type zoneManifests struct {
+	Flows    []map[string]interface{}
+	FlowIDs  []string
+	Triggers []map[string]interface{}
+	Files    int
+}
+
+func loadZoneManifests(dir string) (*zoneManifests, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(path, ".flow.json") || strings.HasSuffix(path, ".zone.json")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	m := &zoneManifests{}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		m.Files++
+
+		if strings.HasSuffix(path, ".flow.json") {
+			id, _ := doc["id"].(string)
+			stripServerFields(doc)
+			m.Flows = append(m.Flows, doc)
+			m.FlowIDs = append(m.FlowIDs, id)
+			continue
+		}
+
+		triggers, _ := doc["triggers"].([]interface{})
+		for _, t := range triggers {
+			trigger, ok := t.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: triggers must be objects", path)
+			}
+			stripServerFields(trigger)
+			m.Triggers = append(m.Triggers, trigger)
+		}
+	}
+	if m.Files == 0 {
+		return nil, fmt.Errorf("no *.flow.json or *.zone.json files in %s", dir)
+	}
+	return m, nil
+}
+
+// currentBranch names the branch being previewed: CI variables first, then
+// the local git checkout.
+func currentBranch() string {
+	for _, env := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BRANCH_NAME"} {
+		if b := os.Getenv(env); b != "" {
+			return b
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func previewZoneName(branch string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(branch), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return previewZonePrefix + slug
+}
+
+// findPreviewZone returns the ID of the zone named name, or "" if there is
+// none.
+func findPreviewZone(ctx context.Context, client *fintech.Client, orgID, name string) (string, error) {
+	zones, err := client.Zones.List(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	for _, z := range zones {
+		if z.Name == name {
+			return z.ID, nil
+		}
+	}
+	return "", nil
+}
+
+type previewZone struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Branch    string    `json:"branch"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Flows     int       `json:"flows"`
+	Triggers  int       `json:"triggers"`
+}
+
+var zonesPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Manage temporary zones for pull requests",
+}
+
+var zonesPreviewCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a temporary zone from the repository's manifests",
+	Long: `Create a zone named after the current branch (preview-<branch>) and load
+the flows and triggers from the *.flow.json and *.zone.json files under
+--from. The zone is deleted automatically once --ttl has passed.
+
+An existing preview zone for the same branch is replaced, so the command can
+run on every push. The branch is taken from --branch, the usual CI variables
+or the local git checkout.`,
+	Example: `  sapliy zones preview create --ttl 48h --from ./manifests
+  ZONE=$(sapliy zones preview create --from ./manifests -o json | jq -r .id)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		orgID := viper.GetString("org_id")
+		if apiKey == "" || orgID == "" {
+			fmt.Println("Error: Not authenticated or org_id not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		dir, _ := cmd.Flags().GetString("from")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		branch, _ := cmd.Flags().GetString("branch")
+		mode, _ := cmd.Flags().GetString("mode")
+
+		if ttl <= 0 {
+			fmt.Println("Error: --ttl must be positive.")
+			os.Exit(1)
+		}
+		if branch == "" {
+			branch = currentBranch()
+		}
+		if branch == "" {
+			fmt.Println("Error: could not detect the branch; pass --branch.")
+			os.Exit(1)
+		}
+
+		manifests, err := loadZoneManifests(dir)
+		if err != nil {
+			fmt.Printf("Error reading manifests: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Progress goes to stderr so stdout stays clean for CI scripts.
+		log := func(format string, a ...interface{}) {
+			if !structuredOutput() {
+				fmt.Fprintf(os.Stderr, format, a...)
+			}
+		}
+
+		ctx := context.Background()
+		client := newClient()
+		name := previewZoneName(branch)
+
+		existing, err := findPreviewZone(ctx, client, orgID, name)
+		if err != nil {
+			fmt.Printf("Error listing zones: %v\n", err)
+			os.Exit(1)
+		}
+		if existing != "" {
+			log("♻️  Replacing preview zone %s (%s)\n", existing, name)
+			if err := apiRequest(ctx, http.MethodDelete, zonePath(existing, ""), nil, nil); err != nil {
+				fmt.Printf("❌ Failed to delete %s: %v\n", existing, err)
+				os.Exit(1)
+			}
+		}
+
+		z, err := client.Zones.Create(ctx, &fintech.CreateZoneRequest{
+			OrgID: orgID,
+			Name:  name,
+			Mode:  mode,
+		})
+		if err != nil {
+			fmt.Printf("❌ Error creating zone: %v\n", err)
+			os.Exit(1)
+		}
+
+		expiresAt := time.Now().Add(ttl).UTC().Truncate(time.Second)
+		settings := map[string]interface{}{
+			"expiresAt": expiresAt,
+			"labels":    map[string]string{"preview": "true", "branch": branch},
+		}
+		if err := apiRequest(ctx, http.MethodPatch, zonePath(z.ID, ""), settings, nil); err != nil {
+			apiRequest(ctx, http.MethodDelete, zonePath(z.ID, ""), nil, nil)
+			fmt.Printf("❌ Failed to set the zone's expiry, zone removed: %v\n", err)
+			os.Exit(1)
+		}
+		log("🧪 Created %s (%s), expires %s\n", z.ID, name, expiresAt.Local().Format(time.RFC1123))
+
+		if _, err := createZoneResources(ctx, z.ID, manifests.Flows, manifests.FlowIDs, manifests.Triggers, nil); err != nil {
+			apiRequest(ctx, http.MethodDelete, zonePath(z.ID, ""), nil, nil)
+			fmt.Printf("❌ %v\n", err)
+			fmt.Printf("   Preview zone %s was removed.\n", z.ID)
+			os.Exit(1)
+		}
+
+		preview := previewZone{
+			ID:        z.ID,
+			Name:      name,
+			Branch:    branch,
+			ExpiresAt: expiresAt,
+			Flows:     len(manifests.Flows),
+			Triggers:  len(manifests.Triggers),
+		}
+		printOutput(preview, func() {
+			log("✅ Loaded %d flow(s) and %d trigger(s) from %d manifest(s)\n", preview.Flows, preview.Triggers, manifests.Files)
+			fmt.Println(z.ID)
+		})
+	},
+}
+
+var zonesPreviewDeleteCmd = &cobra.Command{
+	Use:   "delete [zone_id]",
+	Short: "Delete a preview zone before its TTL runs out",
+	Long: `Delete a preview zone. Without a zone ID, the preview zone of the current
+branch (or --branch) is deleted.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		orgID := viper.GetString("org_id")
+		if apiKey == "" || orgID == "" {
+			fmt.Println("Error: Not authenticated or org_id not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		var id string
+		if len(args) == 1 {
+			id = args[0]
+		} else {
+			branch, _ := cmd.Flags().GetString("branch")
+			if branch == "" {
+				branch = currentBranch()
+			}
+			if branch == "" {
+				fmt.Println("Error: could not detect the branch; pass a zone ID or --branch.")
+				os.Exit(1)
+			}
+
+			client := newClient()
+			found, err := findPreviewZone(ctx, client, orgID, previewZoneName(branch))
+			if err != nil {
+				fmt.Printf("Error listing zones: %v\n", err)
+				os.Exit(1)
+			}
+			if found == "" {
+				fmt.Printf("No preview zone for branch %s.\n", branch)
+				return
+			}
+			id = found
+		}
+
+		if err := apiRequest(ctx, http.MethodDelete, zonePath(id, ""), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete %s: %v\n", id, err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted preview zone %s\n", id)
+	},
+}
+
+func init() {
+	zonesCmd.AddCommand(zonesPreviewCmd)
+	zonesPreviewCmd.AddCommand(zonesPreviewCreateCmd)
+	zonesPreviewCmd.AddCommand(zonesPreviewDeleteCmd)
+
+	zonesPreviewCreateCmd.Flags().String("from", ".", "Directory with *.flow.json and *.zone.json manifests")
+	zonesPreviewCreateCmd.Flags().Duration("ttl", 48*time.Hour, "Delete the zone after this long")
+	zonesPreviewCreateCmd.Flags().String("branch", "", "Branch the zone is for (default detected from CI or git)")
+	zonesPreviewCreateCmd.Flags().StringP("mode", "m", "test", "Mode (test/live)")
+
+	zonesPreviewDeleteCmd.Flags().String("branch", "", "Delete the preview zone of this branch")
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Profiles group the settings that differ between environments (sandbox,
+// production, ...) under profiles.<name> in the config file. The top-level
+// settings form the "default" profile. A profile is selected with --profile,
+// SAPLIY_PROFILE or active_profile, and its values replace the top-level
+// ones while it is active. Its secrets are kept in the credential store
+// under "<profile>/<key>".
+const defaultProfile = "default"
+
+// profileKeys are the settings a profile can hold.
+var profileKeys = []string{"api_key", "api_url", "current_zone", "org_id", "account_id", "refresh_token", "token_expiry"}
+
+var profileFlag string
+
+// activeProfile is the profile selected for this run.
+func activeProfile() string {
+	if profileFlag != "" {
+		return profileFlag
+	}
+	if p := os.Getenv("SAPLIY_PROFILE"); p != "" {
+		return p
+	}
+	if p := viper.GetString("active_profile"); p != "" {
+		return p
+	}
+	return defaultProfile
+}
+
+func profileSettings(settings map[string]interface{}, name string) (map[string]interface{}, bool) {
+	profiles, _ := settings["profiles"].(map[string]interface{})
+	p, ok := profiles[name].(map[string]interface{})
+	return p, ok
+}
+
+// credentialKey is the name a secret is stored under for the active profile.
+func credentialKey(key string) string {
+	if p := activeProfile(); p != defaultProfile {
+		return p + "/" + key
+	}
+	return key
+}
+
+// envOverrides reports whether SAPLIY_<KEY> is set, in which case it takes
+// precedence over both the config file and the credential store.
+func envOverrides(key string) bool {
+	return os.Getenv("SAPLIY_"+strings.ToUpper(key)) != ""
+}
+
+// applyProfile overlays the active profile's settings onto the top-level
+// ones. Environment variables still win.
+func applyProfile() error {
+	name := activeProfile()
+	if name == defaultProfile {
+		return nil
+	}
+	p, ok := profileSettings(viper.AllSettings(), name)
+	if !ok {
+		return fmt.Errorf("profile %q does not exist (see 'sapliy config profiles list')", name)
+	}
+	for _, key := range profileKeys {
+		// Secrets come from the credential store (see loadCredentials).
+		if envOverrides(key) || isSecretConfigKey(key) {
+			continue
+		}
+		// Anything the profile does not set is cleared rather than
+		// inherited from the default profile.
+		value, _ := p[key].(string)
+		viper.Set(key, value)
+	}
+	return nil
+}
+
+// setProfileSetting sets key in profile name's section of the config file
+// settings, removing it when value is empty so the profile does not keep a
+// cleared value.
+func setProfileSetting(settings map[string]interface{}, name, key string, value interface{}) {
+	profiles, _ := settings["profiles"].(map[string]interface{})
+	if profiles == nil {
+		profiles = map[string]interface{}{}
+	}
+	p, _ := profiles[name].(map[string]interface{})
+	if p == nil {
+		p = map[string]interface{}{}
+	}
+	if value != nil && value != "" {
+		p[key] = value
+	} else {
+		delete(p, key)
+	}
+	profiles[name] = p
+	settings["profiles"] = profiles
+}
+
+func validateProfiles(settings map[string]interface{}) []configProblem {
+	var problems []configProblem
+	profiles, _ := settings["profiles"].(map[string]interface{})
+	for name, value := range profiles {
+		p, ok := value.(map[string]interface{})
+		if !ok {
+			problems = append(problems, configProblem{"profiles." + name, fmt.Sprintf("expected section, got %T", value)})
+			continue
+		}
+		for key := range p {
+			known := false
+			for _, k := range profileKeys {
+				known = known || k == key
+			}
+			if !known {
+				problems = append(problems, configProblem{"profiles." + name + "." + key, "not a profile setting (use " + strings.Join(profileKeys, ", ") + ")"})
+			}
+		}
+	}
+	return problems
+}
+
+// readConfigOrEmpty reads the config file, treating a missing one as empty.
+func readConfigOrEmpty() (string, map[string]interface{}, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return "", nil, err
+	}
+	settings, err := readConfigFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return path, map[string]interface{}{"config_version": currentConfigVersion}, nil
+	}
+	return path, settings, err
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage named configuration profiles",
+	Long: `Profiles keep separate API keys, API URLs, zones and accounts for each
+environment you work with. Run any command with --profile <name>, or make a
+profile the default with 'sapliy config profiles use <name>'. The top-level
+settings form the "default" profile.`,
+	Example: `  sapliy config profiles create sandbox --api-url https://sandbox.sapliy.io
+  sapliy --profile sandbox auth login
+  sapliy config profiles use sandbox
+  sapliy --profile default zones list`,
+}
+
+var configProfilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, settings, err := readConfigOrEmpty()
+		if err != nil {
+			fmt.Printf("Error reading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		type profileView struct {
+			Name    string `json:"name"`
+			Active  bool   `json:"active"`
+			APIURL  string `json:"apiUrl,omitempty"`
+			Zone    string `json:"zone,omitempty"`
+			Account string `json:"account,omitempty"`
+		}
+		active := activeProfile()
+		str := func(m map[string]interface{}, key string) string {
+			s, _ := m[key].(string)
+			return s
+		}
+
+		views := []profileView{{
+			Name:    defaultProfile,
+			Active:  active == defaultProfile,
+			APIURL:  str(settings, "api_url"),
+			Zone:    str(settings, "current_zone"),
+			Account: str(settings, "account_id"),
+		}}
+		profiles, _ := settings["profiles"].(map[string]interface{})
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p, _ := profileSettings(settings, name)
+			views = append(views, profileView{
+				Name:    name,
+				Active:  active == name,
+				APIURL:  str(p, "api_url"),
+				Zone:    str(p, "current_zone"),
+				Account: str(p, "account_id"),
+			})
+		}
+
+		printOutput(views, func() {
+			fmt.Printf("  %-16s %-36s %-20s %s\n", "PROFILE", "API URL", "ZONE", "ACCOUNT")
+			fmt.Println(strings.Repeat("─", 90))
+			for _, v := range views {
+				marker := " "
+				if v.Active {
+					marker = "*"
+				}
+				apiURL := v.APIURL
+				if apiURL == "" {
+					apiURL = "(default)"
+				}
+				fmt.Printf("%s %-16s %-36s %-20s %s\n", marker, v.Name, truncate(apiURL, 36), v.Zone, v.Account)
+			}
+		})
+	},
+}
+
+var configProfilesCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a profile",
+	Long: `Create a profile. Log in to it afterwards with
+'sapliy --profile <name> auth login', or pass --copy-current to start from the
+settings and credentials of the active profile.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if name == defaultProfile || strings.ContainsAny(name, "./ ") {
+			fmt.Printf("Error: invalid profile name %q\n", name)
+			os.Exit(1)
+		}
+
+		path, settings, err := readConfigOrEmpty()
+		if err != nil {
+			fmt.Printf("Error reading config: %v\n", err)
+			os.Exit(1)
+		}
+		if _, exists := profileSettings(settings, name); exists {
+			fmt.Printf("Error: profile %q already exists\n", name)
+			os.Exit(1)
+		}
+
+		p := map[string]interface{}{}
+		if copyCurrent, _ := cmd.Flags().GetBool("copy-current"); copyCurrent {
+			for _, key := range profileKeys {
+				if v := viper.GetString(key); v != "" && !isSecretConfigKey(key) {
+					p[key] = v
+				}
+			}
+		}
+		for flag, key := range map[string]string{"api-url": "api_url", "zone": "current_zone", "org": "org_id", "account": "account_id"} {
+			if v, _ := cmd.Flags().GetString(flag); v != "" {
+				p[key] = v
+			}
+		}
+
+		profiles, _ := settings["profiles"].(map[string]interface{})
+		if profiles == nil {
+			profiles = map[string]interface{}{}
+		}
+		profiles[name] = p
+		settings["profiles"] = profiles
+		if err := writeConfigFile(path, settings); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if copyCurrent, _ := cmd.Flags().GetBool("copy-current"); copyCurrent {
+			if err := copyCredentials(name); err != nil {
+				fmt.Printf("⚠️  Could not copy credentials: %v\n", err)
+			}
+		}
+
+		fmt.Printf("✅ Created profile %s\n", name)
+		if viper.GetString("api_key") == "" || !cmd.Flags().Changed("copy-current") {
+			fmt.Printf("   Log in with 'sapliy --profile %s auth login'\n", name)
+		}
+	},
+}
+
+var configProfilesUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Make a profile the default for every command",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		path, settings, err := readConfigOrEmpty()
+		if err != nil {
+			fmt.Printf("Error reading config: %v\n", err)
+			os.Exit(1)
+		}
+		if _, exists := profileSettings(settings, name); !exists && name != defaultProfile {
+			fmt.Printf("Error: profile %q does not exist\n", name)
+			os.Exit(1)
+		}
+
+		if name == defaultProfile {
+			delete(settings, "active_profile")
+		} else {
+			settings["active_profile"] = name
+		}
+		if err := writeConfigFile(path, settings); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Switched to profile: %s\n", name)
+	},
+}
+
+var configProfilesDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a profile and its stored credentials",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		path, settings, err := readConfigOrEmpty()
+		if err != nil {
+			fmt.Printf("Error reading config: %v\n", err)
+			os.Exit(1)
+		}
+		if _, exists := profileSettings(settings, name); !exists {
+			fmt.Printf("Error: profile %q does not exist\n", name)
+			os.Exit(1)
+		}
+
+		profiles := settings["profiles"].(map[string]interface{})
+		delete(profiles, name)
+		if settings["active_profile"] == name {
+			delete(settings, "active_profile")
+		}
+		if err := writeConfigFile(path, settings); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if store, err := credentialStoreInUse(); err == nil {
+			for _, key := range secretConfigKeys {
+				store.Delete(name + "/" + key)
+			}
+		}
+		fmt.Printf("🗑️  Deleted profile %s\n", name)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configProfilesCmd)
+	configProfilesCmd.AddCommand(configProfilesListCmd)
+	configProfilesCmd.AddCommand(configProfilesCreateCmd)
+	configProfilesCmd.AddCommand(configProfilesUseCmd)
+	configProfilesCmd.AddCommand(configProfilesDeleteCmd)
+
+	configProfilesCreateCmd.Flags().String("api-url", "", "API endpoint for this profile")
+	configProfilesCreateCmd.Flags().String("zone", "", "Zone to start in")
+	configProfilesCreateCmd.Flags().String("org", "", "Organization to start in")
+	configProfilesCreateCmd.Flags().String("account", "", "Account to start in")
+	configProfilesCreateCmd.Flags().Bool("copy-current", false, "Start from the active profile's settings and credentials")
+}
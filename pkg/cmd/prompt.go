@@ -0,0 +1,400 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// historyLimit is how many lines a history file keeps.
+const historyLimit = 1000
+
+// lineReader reads lines from the terminal with editing: arrow keys,
+// Home/End, backspace and delete by character (not byte), Ctrl-A/E/K/U/W,
+// bracketed paste and, across calls on the same lineReader, history on
+// Up/Down. When stdin is not a terminal it reads plain lines.
+type lineReader struct {
+	history []string
+
+	// historyFile, when set by loadHistory, is where entered lines are
+	// appended so history survives across sessions.
+	historyFile string
+
+	// complete, when set, returns the candidates for the word before the
+	// cursor on Tab, given the line up to the cursor.
+	complete func(head string) []string
+
+	// mask echoes * instead of the typed text and keeps it out of history.
+	mask bool
+}
+
+// loadHistory reads the history saved in path and keeps appending to it.
+// A missing or unreadable file just starts an empty history.
+func (r *lineReader) loadHistory(path string) {
+	r.historyFile = path
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) != "" {
+			r.history = append(r.history, line)
+		}
+	}
+	if len(r.history) > historyLimit {
+		r.history = r.history[len(r.history)-historyLimit:]
+		os.WriteFile(path, []byte(strings.Join(r.history, "\n")+"\n"), 0600)
+	}
+}
+
+// remember adds line to the history, and to the history file if any.
+func (r *lineReader) remember(line string) {
+	if r.mask || strings.TrimSpace(line) == "" || (len(r.history) > 0 && r.history[len(r.history)-1] == line) {
+		return
+	}
+	r.history = append(r.history, line)
+	if r.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(r.historyFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line + "\n")
+}
+
+// runeWidth is the number of terminal columns r takes up.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+func runesWidth(rs []rune) int {
+	n := 0
+	for _, r := range rs {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// lineEdit is the state of one line being edited.
+type lineEdit struct {
+	prompt string
+	mask   bool
+	cols   int // terminal width, 0 if unknown
+
+	buf    []rune
+	pos    int // cursor, as an index into buf
+	offset int // first rune shown when the line is wider than the terminal
+}
+
+func (e *lineEdit) insert(rs ...rune) {
+	e.buf = append(e.buf[:e.pos], append(rs, e.buf[e.pos:]...)...)
+	e.pos += len(rs)
+}
+
+func (e *lineEdit) set(s string) {
+	e.buf = []rune(s)
+	e.pos = len(e.buf)
+}
+
+// render redraws the prompt and line. Lines that do not fit scroll
+// horizontally to keep the cursor visible.
+func (e *lineEdit) render() {
+	shown := e.buf
+	if e.mask {
+		shown = []rune(strings.Repeat("*", len(e.buf)))
+	}
+
+	avail := e.cols - runesWidth([]rune(e.prompt)) - 1
+	if e.cols == 0 || avail < 10 {
+		avail = int(^uint(0) >> 1)
+	}
+	if e.pos < e.offset {
+		e.offset = e.pos
+	}
+	for e.offset < e.pos && runesWidth(shown[e.offset:e.pos]) > avail {
+		e.offset++
+	}
+	end, w := e.offset, 0
+	for end < len(shown) && w+runeWidth(shown[end]) <= avail {
+		w += runeWidth(shown[end])
+		end++
+	}
+
+	var out strings.Builder
+	out.WriteString("\r" + e.prompt + string(shown[e.offset:end]) + "\x1b[K")
+	if back := runesWidth(shown[e.pos:end]); back > 0 {
+		fmt.Fprintf(&out, "\x1b[%dD", back)
+	}
+	fmt.Print(out.String())
+}
+
+// readEscape reads the rest of an escape sequence after ESC, e.g. "[A" for
+// Up or "[3~" for Delete.
+func readEscape() (string, error) {
+	c, _, err := stdinReader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if c != '[' && c != 'O' {
+		return string(c), nil
+	}
+	seq := []rune{c}
+	for {
+		c, _, err := stdinReader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, c)
+		if c >= 0x40 && c <= 0x7e {
+			return string(seq), nil
+		}
+	}
+}
+
+// readPaste reads a bracketed paste up to its end marker. Line breaks and
+// tabs become spaces since the result is a single line.
+func readPaste() ([]rune, error) {
+	var pasted []rune
+	var prev rune
+	for {
+		c, _, err := stdinReader.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		if c == 0x1b {
+			seq, err := readEscape()
+			if err != nil {
+				return nil, err
+			}
+			if seq == "[201~" {
+				return pasted, nil
+			}
+			continue
+		}
+		if c == '\n' && prev == '\r' {
+			continue
+		}
+		prev = c
+		if c < 0x20 {
+			c = ' '
+		}
+		pasted = append(pasted, c)
+	}
+}
+
+// readLine prints prompt and returns the line the user entered, without the
+// newline. It returns io.EOF on Ctrl-D at an empty line and errInterrupted
+// on Ctrl-C.
+func (r *lineReader) readLine(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	// Redrawing the line confuses screen readers, so accessible mode leaves
+	// the editing to the terminal. Masked input still needs raw mode.
+	var restore func()
+	err := errors.New("accessible mode")
+	if !accessibleMode() || r.mask {
+		restore, err = makeRaw(fd)
+	}
+	if err != nil {
+		// Not a terminal (or not supported): read a plain line.
+		return readPlainLine(prompt)
+	}
+	defer restore()
+	fmt.Print("\x1b[?2004h")
+	defer fmt.Print("\x1b[?2004l")
+
+	e := &lineEdit{prompt: prompt, mask: r.mask, cols: terminalWidth(fd)}
+	histPos, draft := len(r.history), ""
+	historyUp := func() {
+		if r.mask || histPos == 0 {
+			return
+		}
+		if histPos == len(r.history) {
+			draft = string(e.buf)
+		}
+		histPos--
+		e.set(r.history[histPos])
+	}
+	historyDown := func() {
+		if histPos == len(r.history) {
+			return
+		}
+		histPos++
+		if histPos == len(r.history) {
+			e.set(draft)
+		} else {
+			e.set(r.history[histPos])
+		}
+	}
+
+	lastTab := false
+	e.render()
+	for {
+		c, _, err := stdinReader.ReadRune()
+		if err != nil {
+			fmt.Print("\r\n")
+			return "", err
+		}
+
+		tabbed := lastTab
+		lastTab = false
+		switch c {
+		case '\t':
+			if r.complete == nil || r.mask {
+				break
+			}
+			lastTab = r.completeWord(e, tabbed)
+		case '\r', '\n':
+			fmt.Print("\r\n")
+			line := string(e.buf)
+			r.remember(line)
+			return line, nil
+		case 3: // Ctrl-C
+			fmt.Print("^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(e.buf) == 0 {
+				fmt.Print("\r\n")
+				return "", io.EOF
+			}
+			if e.pos < len(e.buf) {
+				e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+			}
+		case 1: // Ctrl-A
+			e.pos = 0
+		case 5: // Ctrl-E
+			e.pos = len(e.buf)
+		case 2: // Ctrl-B
+			if e.pos > 0 {
+				e.pos--
+			}
+		case 6: // Ctrl-F
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case 8, 127: // Backspace
+			if e.pos > 0 {
+				e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+				e.pos--
+			}
+		case 11: // Ctrl-K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl-U
+			e.buf = append([]rune(nil), e.buf[e.pos:]...)
+			e.pos = 0
+		case 23: // Ctrl-W
+			start := e.pos
+			for start > 0 && e.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.buf[start-1] != ' ' {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case 16: // Ctrl-P
+			historyUp()
+		case 14: // Ctrl-N
+			historyDown()
+		case 0x1b:
+			seq, err := readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch seq {
+			case "[A", "OA":
+				historyUp()
+			case "[B", "OB":
+				historyDown()
+			case "[C", "OC":
+				if e.pos < len(e.buf) {
+					e.pos++
+				}
+			case "[D", "OD":
+				if e.pos > 0 {
+					e.pos--
+				}
+			case "[H", "OH", "[1~", "[7~":
+				e.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				e.pos = len(e.buf)
+			case "[3~": // Delete
+				if e.pos < len(e.buf) {
+					e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+				}
+			case "[200~":
+				pasted, err := readPaste()
+				if err != nil {
+					return "", err
+				}
+				e.insert(pasted...)
+			}
+		default:
+			if c >= 0x20 {
+				e.insert(c)
+			}
+		}
+		e.render()
+	}
+}
+
+// completeWord completes the word before the cursor: a single candidate
+// replaces it, several extend it to their common prefix and, on a second
+// Tab in a row, are listed below the line. It reports whether candidates
+// were left to list.
+func (r *lineReader) completeWord(e *lineEdit, list bool) bool {
+	start := e.pos
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	word := string(e.buf[start:e.pos])
+	var matches []string
+	for _, c := range r.complete(string(e.buf[:e.pos])) {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return false
+	}
+
+	replace := func(s string) {
+		rest := append([]rune(nil), e.buf[e.pos:]...)
+		e.buf = append(e.buf[:start], []rune(s)...)
+		e.pos = len(e.buf)
+		e.buf = append(e.buf, rest...)
+	}
+	if len(matches) == 1 {
+		replace(matches[0] + " ")
+		return false
+	}
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(word) {
+		replace(prefix)
+		return true
+	}
+	if list {
+		fmt.Print("\r\n" + strings.Join(matches, "  ") + "\r\n")
+	}
+	return true
+}
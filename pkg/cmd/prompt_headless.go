@@ -0,0 +1,17 @@
+//go:build headless
+
+package cmd
+
+// sapliyd runs unattended, so it leaves out the line editor (prompt.go) and
+// reads the few answers it may be asked for as plain lines.
+
+// lineReader reads lines from stdin as they are.
+type lineReader struct {
+	// mask is kept for the callers shared with the full CLI; plain lines
+	// are not masked, since sapliyd reads them from a pipe, not a person.
+	mask bool
+}
+
+func (r *lineReader) readLine(prompt string) (string, error) {
+	return readPlainLine(prompt)
+}
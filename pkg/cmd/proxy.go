@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// proxySchemes are the schemes --proxy accepts. socks5h resolves host names
+// on the proxy, which corporate networks without public DNS often need.
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// configuredProxy returns the proxy from --proxy, SAPLIY_PROXY or the proxy
+// config key, or nil when none is set and the environment decides.
+func configuredProxy() (*url.URL, error) {
+	raw := viper.GetString("proxy")
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || !slices.Contains(proxySchemes, u.Scheme) {
+		return nil, fmt.Errorf("invalid --proxy %q (use e.g. http://proxy.corp:3128 or socks5://127.0.0.1:1080)", raw)
+	}
+	return u, nil
+}
+
+// validateProxy reports a bad --proxy or ca_cert setting before any
+// request is made.
+func validateProxy() error {
+	if _, err := configuredProxy(); err != nil {
+		return err
+	}
+	_, err := apiTLSConfig()
+	return err
+}
+
+// apiProxy picks the proxy for an API request: --proxy if set, otherwise
+// HTTPS_PROXY/HTTP_PROXY. NO_PROXY is honoured either way.
+func apiProxy(req *http.Request) (*url.URL, error) {
+	u, err := configuredProxy()
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+	if bypassProxy(req.URL.Host) {
+		return nil, nil
+	}
+	return u, nil
+}
+
+// streamProxy is apiProxy for websocket connections. The websocket dialer
+// always resolves names on a SOCKS proxy, so socks5h is the same as socks5,
+// and it cannot tunnel through an https:// proxy.
+func streamProxy(req *http.Request) (*url.URL, error) {
+	u, err := apiProxy(req)
+	if u == nil || err != nil {
+		return u, err
+	}
+	switch u.Scheme {
+	case "socks5h":
+		v := *u
+		v.Scheme = "socks5"
+		return &v, nil
+	case "https":
+		return nil, fmt.Errorf("event streams cannot use an https:// proxy (%s); use http:// or socks5://", u.Redacted())
+	}
+	return u, nil
+}
+
+// bypassProxy reports whether NO_PROXY exempts host (host or host:port).
+// Entries are host names, which also match their subdomains, IP addresses,
+// CIDR ranges, or * for everything. Loopback addresses are never proxied.
+func bypassProxy(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiTLSConfig trusts the system roots plus the certificates in ca_cert
+// (SAPLIY_CA_CERT), for networks whose proxy re-signs TLS traffic with
+// an internal root CA.
+var apiTLSConfig = sync.OnceValues(func() (*tls.Config, error) {
+	path := viper.GetString("ca_cert")
+	if path == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ca_cert: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ca_cert %s contains no PEM certificates", path)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+})
+
+// interceptedTLSError is a certificate verification failure caused by a
+// certificate from an authority this machine does not trust, which on
+// corporate networks almost always means a TLS-inspecting proxy.
+type interceptedTLSError struct {
+	Host   string
+	Issuer string
+	err    error
+}
+
+// issuer names the certificate's issuer for messages.
+func (e *interceptedTLSError) issuer() string {
+	if e.Issuer == "" {
+		return "an unknown authority"
+	}
+	return strconv.Quote(e.Issuer)
+}
+
+func (e *interceptedTLSError) Error() string {
+	return fmt.Sprintf("the certificate presented for %s was issued by %s, which this machine does not trust. "+
+		"A TLS-inspecting proxy on your network is probably re-signing traffic; set ca_cert (or SAPLIY_CA_CERT) "+
+		"to your organization's root CA bundle, or run 'sapliy doctor'", e.Host, e.issuer())
+}
+
+func (e *interceptedTLSError) Unwrap() error { return e.err }
+
+// explainTLSError turns an unknown-authority certificate error from a
+// connection to host into an interceptedTLSError; other errors are
+// returned unchanged.
+func explainTLSError(host string, err error) error {
+	var certErr *tls.CertificateVerificationError
+	var unknown x509.UnknownAuthorityError
+	if err == nil || !errors.As(err, &certErr) || !errors.As(err, &unknown) {
+		return err
+	}
+	var intercepted *interceptedTLSError
+	if errors.As(err, &intercepted) {
+		return err
+	}
+
+	e := &interceptedTLSError{Host: host, err: err}
+	if len(certErr.UnverifiedCertificates) > 0 {
+		leaf := certErr.UnverifiedCertificates[0]
+		if issuer := leaf.Issuer.CommonName; issuer != "" {
+			e.Issuer = issuer
+		} else if len(leaf.Issuer.Organization) > 0 {
+			e.Issuer = leaf.Issuer.Organization[0]
+		}
+	}
+	return e
+}
+
+// tlsHintTransport explains certificate errors from TLS-inspecting proxies
+// on every API request, including the SDK's.
+type tlsHintTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tlsHintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, explainTLSError(req.URL.Hostname(), err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// pulledFields are set by the API rather than written in zone and flow
+// files, so they are left out of pulled files. Keeping them would make
+// deploy send them back.
+var pulledFields = map[string][]string{
+	"zone": {"orgId", "createdAt", "updatedAt"},
+	"flow": {"zoneId", "version", "enabled", "createdAt", "updatedAt"},
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// resourceFileName names a pulled file like 'sapliy generate' does: the
+// resource ID without its type prefix, so flow_checkout is
+// checkout.flow.json.
+func resourceFileName(kind, id string) string {
+	name := strings.TrimPrefix(strings.ToLower(id), kind+"_")
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = kind
+	}
+	return name + "." + kind + ".json"
+}
+
+// marshalResource writes doc as indented JSON with "id", "name" and
+// "description" first, as generated files have them, and the other fields
+// in sorted order, so pulls of an unchanged resource give the same file.
+func marshalResource(doc map[string]interface{}) ([]byte, error) {
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	first := []string{"id", "name", "description"}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := slices.Index(first, keys[i]), slices.Index(first, keys[j])
+		if ri < 0 {
+			ri = len(first)
+		}
+		if rj < 0 {
+			rj = len(first)
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, k := range keys {
+		name, _ := json.Marshal(k)
+		value, err := json.MarshalIndent(doc[k], "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "  %s: %s", name, value)
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// writeResource writes a pulled resource to r.File and records whether the
+// file was created, updated or already up to date.
+func writeResource(r *deployResource) error {
+	for _, field := range pulledFields[r.Kind] {
+		delete(r.doc, field)
+	}
+	content, err := marshalResource(r.doc)
+	if err != nil {
+		return err
+	}
+	switch existing, err := os.ReadFile(r.File); {
+	case err != nil:
+		r.Action = "created"
+	case bytes.Equal(existing, content):
+		r.Action = "unchanged"
+		return nil
+	default:
+		r.Action = "updated"
+	}
+	return os.WriteFile(r.File, content, 0644)
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download a zone and its flows to local JSON files",
+	Long: `Download a zone's definition and all its flows into --out, in the formats
+'sapliy generate' writes: <name>.zone.json and one <name>.flow.json per flow.
+Fields the API sets (version, timestamps, ...) are left out, so the files
+can be committed, edited and applied again with 'sapliy deploy -f <dir>'.
+
+Existing files for the same resources are overwritten; other files in --out
+are left alone.`,
+	Example: `  sapliy pull --zone zone_abc --out ./config/
+  sapliy pull --out ./config/ && git diff config/`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+		zone := requireZone(cmd)
+		out, _ := cmd.Flags().GetString("out")
+
+		ctx := context.Background()
+		var zoneDoc map[string]interface{}
+		if err := apiRequest(ctx, http.MethodGet, zonePath(zone, ""), nil, &zoneDoc); err != nil {
+			fmt.Printf("❌ Failed to fetch zone %s: %v\n", zone, err)
+			os.Exit(1)
+		}
+		var flowDocs []map[string]interface{}
+		if err := apiRequest(ctx, http.MethodGet, zonePath(zone, "/flows"), nil, &flowDocs); err != nil {
+			fmt.Printf("❌ Failed to list flows: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := os.MkdirAll(out, 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		zoneDoc["id"] = zone
+		resources := []*deployResource{{Kind: "zone", ID: zone, doc: zoneDoc}}
+		for _, doc := range flowDocs {
+			resources = append(resources, &deployResource{Kind: "flow", ID: stepString(doc, "id"), Zone: zone, doc: doc})
+		}
+		written := map[string]string{}
+		for _, r := range resources {
+			r.File = filepath.Join(out, resourceFileName(r.Kind, r.ID))
+			if other, dup := written[r.File]; dup {
+				fmt.Printf("❌ %s and %s would both be written to %s; nothing was written\n", other, r.ID, r.File)
+				os.Exit(1)
+			}
+			written[r.File] = r.ID
+		}
+		for _, r := range resources {
+			if err := writeResource(r); err != nil {
+				fmt.Printf("❌ %s %s: %v\n", r.Kind, r.ID, err)
+				os.Exit(1)
+			}
+		}
+
+		printOutput(resources, func() {
+			icons := map[string]string{"created": "➕", "updated": "🔄", "unchanged": "✓ "}
+			counts := map[string]int{}
+			for _, r := range resources {
+				counts[r.Action]++
+				fmt.Printf("%s %-4s %-24s %-10s %s\n", icons[r.Action], r.Kind, r.ID, r.Action, r.File)
+			}
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("%d created, %d updated, %d unchanged in %s\n", counts["created"], counts["updated"], counts["unchanged"], out)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringP("zone", "z", "", "Zone to pull (default: current zone)")
+	pullCmd.Flags().String("out", ".", "Directory to write the zone and flow files to")
+}
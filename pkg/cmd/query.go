@@ -0,0 +1,520 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+// sqlTimeFormat is the layout timestamps are normalized to when loaded, so
+// that they compare lexically with the values produced by now().
+const sqlTimeFormat = "2006-01-02T15:04:05.000Z"
+
+var queryCmd = &cobra.Command{
+	Use:   "query [sql]",
+	Short: "Run SQL over locally exported or recorded event files",
+	Long: `Run ad-hoc SQL over local NDJSON/JSON and Parquet files without a warehouse.
+
+Every *.ndjson, *.jsonl, *.json and *.parquet file in --dir is loaded into an
+in-memory SQLite database as a table named after the file (events.ndjson →
+events). Use --table name=path to register files explicitly. Top-level fields
+become columns; nested objects, lists and maps are stored as JSON and can be
+read with json_extract(). Parquet timestamps and dates load as text in the
+same format as JSON timestamps, so they compare with now().
+
+For convenience, now() and "now() - interval N unit" are understood:
+
+  sapliy query "select type, count(*) from events where ts > now() - interval 1 day group by 1"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		tables, _ := cmd.Flags().GetStringArray("table")
+		format, _ := cmd.Flags().GetString("format")
+
+		sources, err := querySources(dir, tables)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(sources) == 0 {
+			fmt.Printf("Error: no data files found in %s. Use --table name=path to register one.\n", dir)
+			os.Exit(1)
+		}
+
+		db, err := sql.Open("sqlite", "file::memory:")
+		if err != nil {
+			fmt.Printf("Error opening query engine: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+		// An in-memory database only lives as long as its connection.
+		db.SetMaxOpenConns(1)
+
+		names := make([]string, 0, len(sources))
+		for name := range sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := loadQueryTable(db, name, sources[name]); err != nil {
+				fmt.Printf("Error loading %s: %v\n", sources[name], err)
+				os.Exit(1)
+			}
+		}
+
+		rows, err := db.Query(rewriteQuery(args[0]))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			fmt.Printf("Loaded tables: %s\n", strings.Join(names, ", "))
+			os.Exit(1)
+		}
+		defer rows.Close()
+
+		columns, records, err := readQueryRows(rows)
+		if err != nil {
+			fmt.Printf("Error reading results: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch format {
+		case "csv":
+			w := csv.NewWriter(os.Stdout)
+			w.Write(columns)
+			w.WriteAll(records)
+		case "json":
+			out := make([]map[string]string, 0, len(records))
+			for _, rec := range records {
+				row := make(map[string]string, len(columns))
+				for i, col := range columns {
+					row[col] = rec[i]
+				}
+				out = append(out, row)
+			}
+			prettyJSON, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(prettyJSON))
+		default:
+			printQueryTable(columns, records)
+		}
+	},
+}
+
+// querySources resolves table names to files, combining files discovered in
+// dir with explicit name=path registrations (which win on conflict).
+func querySources(dir string, tables []string) (map[string]string, error) {
+	sources := make(map[string]string)
+
+	for _, pattern := range []string{"*.ndjson", "*.jsonl", "*.json", "*.parquet"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			name := tableName(strings.TrimSuffix(filepath.Base(m), filepath.Ext(m)))
+			if _, exists := sources[name]; !exists {
+				sources[name] = m
+			}
+		}
+	}
+
+	for _, t := range tables {
+		parts := strings.SplitN(t, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid --table %q, expected name=path", t)
+		}
+		sources[tableName(parts[0])] = parts[1]
+	}
+
+	return sources, nil
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func tableName(s string) string {
+	return nonIdentChars.ReplaceAllString(s, "_")
+}
+
+// loadQueryTable creates a table from an NDJSON file (or a JSON array) or a
+// Parquet file, using the union of top-level keys as columns.
+func loadQueryTable(db *sql.DB, name, path string) error {
+	read := readJSONRecords
+	if strings.EqualFold(filepath.Ext(path), ".parquet") {
+		read = readParquetRecords
+	}
+	records, err := read(path)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var columns []string
+	for _, rec := range records {
+		for k := range rec {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if len(columns) == 0 {
+		columns = []string{"value"}
+	}
+
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = `"` + strings.ReplaceAll(col, `"`, `""`) + `"`
+		placeholders[i] = "?"
+	}
+
+	if _, err := db.Exec(fmt.Sprintf(`CREATE TABLE "%s" (%s)`, name, strings.Join(quoted, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf(`INSERT INTO "%s" (%s) VALUES (%s)`,
+		name, strings.Join(quoted, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	values := make([]interface{}, len(columns))
+	for _, rec := range records {
+		for i, col := range columns {
+			values[i] = sqlValue(rec[col])
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// readJSONRecords reads one JSON object per line, or a single JSON array of
+// objects when the file starts with '['.
+func readJSONRecords(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	first, err := firstNonSpace(reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if first == '[' {
+		var records []map[string]interface{}
+		if err := json.NewDecoder(reader).Decode(&records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// readParquetRecords reads the rows of a Parquet file as records shaped like
+// decoded JSON, so they load the same way.
+func readParquetRecords(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	file, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	schema := file.Schema()
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	var records []map[string]interface{}
+	for {
+		row := make(map[string]interface{})
+		if err := reader.Read(&row); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(records)+1, err)
+		}
+		records = append(records, parquetValue(schema, row).(map[string]interface{}))
+	}
+}
+
+// parquetValue converts a value read from a Parquet column described by node
+// to its JSON-like form: timestamps and dates become text, integers int64,
+// and byte arrays strings if they are UTF-8 or blobs if not.
+func parquetValue(node parquet.Node, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, x := range val {
+			val[k] = parquetValue(parquetChild(node, k), x)
+		}
+		return val
+	case []interface{}:
+		elem := parquetChild(node, "")
+		for i, x := range val {
+			val[i] = parquetValue(elem, x)
+		}
+		return val
+	case []byte:
+		if utf8.Valid(val) {
+			return string(val)
+		}
+		return val
+	case string:
+		if !utf8.ValidString(val) {
+			return []byte(val)
+		}
+		return val
+	case int32:
+		return parquetInt(node, int64(val))
+	case int64:
+		return parquetInt(node, val)
+	case uint32:
+		return int64(val)
+	case uint64:
+		if val <= 1<<63-1 {
+			return int64(val)
+		}
+		return float64(val)
+	case float32:
+		return float64(val)
+	default:
+		return v
+	}
+}
+
+// parquetInt converts an integer column with a timestamp or date logical
+// type to text.
+func parquetInt(node parquet.Node, v int64) interface{} {
+	if node == nil || !node.Leaf() || node.Type().LogicalType() == nil {
+		return v
+	}
+	switch lt := node.Type().LogicalType().Value.(type) {
+	case *format.TimestampType:
+		switch lt.Unit.Value.(type) {
+		case *format.MilliSeconds:
+			return time.UnixMilli(v).UTC().Format(time.RFC3339Nano)
+		case *format.MicroSeconds:
+			return time.UnixMicro(v).UTC().Format(time.RFC3339Nano)
+		case *format.NanoSeconds:
+			return time.Unix(0, v).UTC().Format(time.RFC3339Nano)
+		}
+	case *format.DateType:
+		return time.Unix(v*86400, 0).UTC().Format(time.DateOnly)
+	}
+	return v
+}
+
+// parquetChild returns the schema node of field name of a group, of the
+// values of a MAP, or (for name "") of the elements of a LIST or repeated
+// field. It returns nil when the schema does not say.
+func parquetChild(node parquet.Node, name string) parquet.Node {
+	if node == nil {
+		return nil
+	}
+	if name == "" && node.Repeated() {
+		return parquet.Required(node)
+	}
+	if node.Leaf() {
+		return nil
+	}
+	fields := node.Fields()
+	if lt := node.Type().LogicalType(); lt != nil && len(fields) == 1 {
+		switch lt.Value.(type) {
+		case *format.MapType:
+			if kv := fields[0].Fields(); len(kv) == 2 {
+				return kv[1]
+			}
+		case *format.ListType:
+			if elem := fields[0].Fields(); len(elem) == 1 {
+				return elem[0]
+			}
+		}
+	}
+	for _, f := range fields {
+		if name != "" && f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// sqlValue converts a decoded JSON value to something SQLite can store.
+func sqlValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case bool:
+		if val {
+			return 1
+		}
+		return 0
+	case float64:
+		if val == float64(int64(val)) {
+			return int64(val)
+		}
+		return val
+	case int64, []byte:
+		return val
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			return t.UTC().Format(sqlTimeFormat)
+		}
+		return val
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+var (
+	nowIntervalPattern = regexp.MustCompile(`(?i)now\(\)\s*([-+])\s*interval\s+'?(\d+)'?\s+(second|minute|hour|day|month|year)s?`)
+	nowPattern         = regexp.MustCompile(`(?i)now\(\)`)
+)
+
+// rewriteQuery translates the DuckDB-style now()/interval expressions into
+// SQLite strftime calls that produce sqlTimeFormat timestamps.
+func rewriteQuery(q string) string {
+	q = nowIntervalPattern.ReplaceAllStringFunc(q, func(m string) string {
+		parts := nowIntervalPattern.FindStringSubmatch(m)
+		return fmt.Sprintf("strftime('%%Y-%%m-%%dT%%H:%%M:%%fZ', 'now', '%s%s %ss')", parts[1], parts[2], strings.ToLower(parts[3]))
+	})
+	return nowPattern.ReplaceAllString(q, "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')")
+}
+
+func readQueryRows(rows *sql.Rows) ([]string, [][]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records [][]string
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+
+		rec := make([]string, len(columns))
+		for i, v := range values {
+			switch val := v.(type) {
+			case nil:
+				rec[i] = ""
+			case []byte:
+				rec[i] = string(val)
+			default:
+				rec[i] = fmt.Sprint(val)
+			}
+		}
+		records = append(records, rec)
+	}
+	return columns, records, rows.Err()
+}
+
+func printQueryTable(columns []string, records [][]string) {
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = len(col)
+	}
+	for _, rec := range records {
+		for i, v := range rec {
+			if len(v) > widths[i] {
+				widths[i] = len(v)
+			}
+		}
+	}
+
+	total := 0
+	for i, col := range columns {
+		fmt.Printf("%-*s  ", widths[i], strings.ToUpper(col))
+		total += widths[i] + 2
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", total))
+
+	for _, rec := range records {
+		for i, v := range rec {
+			fmt.Printf("%-*s  ", widths[i], v)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n(%d row(s))\n", len(records))
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().String("dir", ".", "Directory to load *.ndjson, *.jsonl, *.json and *.parquet files from")
+	queryCmd.Flags().StringArray("table", nil, "Register a file as a table (name=path, repeatable)")
+	queryCmd.Flags().String("format", "table", "Output format (table, csv, json)")
+}
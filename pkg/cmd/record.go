@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// recordedEvent is one line of a session file written by debug record: the
+// event as received and when it arrived. The top-level fields make the file
+// directly usable with 'sapliy query'.
+type recordedEvent struct {
+	TS    time.Time       `json:"ts"`
+	ID    string          `json:"id,omitempty"`
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// readSession reads a session file, checking every line before anything is
+// replayed.
+func readSession(r io.Reader) ([]recordedEvent, error) {
+	var events []recordedEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e recordedEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if len(e.Event) == 0 || e.TS.IsZero() {
+			return nil, fmt.Errorf("line %d: not a recorded event (needs \"ts\" and \"event\")", n)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// parseSpeed reads a --speed value: a factor such as 2, 2x or 0.5x, or max
+// to replay without delays (returned as 0).
+func parseSpeed(s string) (float64, error) {
+	if s == "max" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid --speed %q (use e.g. 1x, 2x, 0.5x or max)", s)
+	}
+	return f, nil
+}
+
+var debugRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the event stream to a session file",
+	Long: `Record the zone's event stream to a JSONL session file, one event per line
+with the time it arrived, until interrupted (or --duration / --count). The
+file can be shared and replayed with 'sapliy debug play', or queried with
+'sapliy query'.`,
+	Example: `  sapliy debug record --out session.jsonl
+  sapliy debug record --out checkout-bug.jsonl --filter checkout --duration 10m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		zone := resolveZone(cmd)
+		out, _ := cmd.Flags().GetString("out")
+		filterType, _ := cmd.Flags().GetString("filter")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		count, _ := cmd.Flags().GetInt("count")
+		appendTo, _ := cmd.Flags().GetBool("append")
+
+		flags := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+		if appendTo {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(out, flags, 0600)
+		if os.IsExist(err) {
+			fmt.Printf("Error: %s already exists (use --append to add to it).\n", out)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		wsURL := eventStreamURL(apiKey, zone)
+		ctx, stop := interruptContext()
+		defer stop()
+		if duration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, duration)
+			defer cancel()
+		}
+
+		conn, resp, err := dialEventStream(ctx, wsURL, nil)
+		if err != nil {
+			fmt.Printf("❌ Failed to connect: %v\n", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+		if err := checkStreamClockSkew(resp); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("⏺️  Recording to %s (Ctrl+C to stop)\n", out)
+
+		recorded := 0
+		var writeErr error
+		err = readStream(ctx, conn, func(message []byte) error {
+			var event struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(message, &event); err != nil {
+				return nil
+			}
+			if filterType != "" && !strings.Contains(event.Type, filterType) {
+				return nil
+			}
+
+			line, err := json.Marshal(recordedEvent{TS: time.Now().UTC(), ID: event.ID, Type: event.Type, Event: message})
+			if err != nil {
+				return nil
+			}
+			if _, writeErr = f.Write(append(line, '\n')); writeErr != nil {
+				return writeErr
+			}
+			recorded++
+			switch {
+			case accessibleMode():
+				if recorded%100 == 0 {
+					fmt.Fprintf(os.Stderr, "Progress: %d events recorded\n", recorded)
+				}
+			case stderrIsTerminal():
+				fmt.Fprintf(os.Stderr, "\r%d event(s) recorded, last %s", recorded, event.Type)
+			}
+			if count > 0 && recorded == count {
+				return errStreamDone
+			}
+			return nil
+		})
+
+		if stderrIsTerminal() && !accessibleMode() {
+			fmt.Fprintln(os.Stderr)
+		}
+		if writeErr != nil {
+			fmt.Printf("❌ Writing %s failed: %v\n", out, writeErr)
+			os.Exit(1)
+		}
+		if err != nil && err != errServerClosed && err != writeErr {
+			fmt.Printf("❌ %v\n", err)
+		}
+		fmt.Printf("💾 Recorded %d event(s) to %s. Replay with: sapliy debug play %s\n", recorded, out, out)
+	},
+}
+
+var debugPlayCmd = &cobra.Command{
+	Use:   "play [session.jsonl]",
+	Short: "Replay a recorded session",
+	Long: `Replay a session file written by 'sapliy debug record', in order and with the
+original gaps between events divided by --speed (max replays without
+waiting). Events are printed, or with --forward-to POSTed to a local handler
+signed like real deliveries, as 'webhooks listen' does. The exit status is 1
+if any delivery failed.`,
+	Example: `  sapliy debug play session.jsonl
+  sapliy debug play session.jsonl --speed 2x --forward-to http://localhost:4242/webhook
+  sapliy debug play session.jsonl --speed max --forward-to http://localhost:4242/webhook --secret whsec_local`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		speedFlag, _ := cmd.Flags().GetString("speed")
+		target, _ := cmd.Flags().GetString("forward-to")
+		secret, _ := cmd.Flags().GetString("secret")
+		filterType, _ := cmd.Flags().GetString("filter")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		speed, err := parseSpeed(speedFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		events, err := readSession(f)
+		f.Close()
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		if len(events) == 0 {
+			fmt.Println("The session is empty.")
+			return
+		}
+
+		var fwd *forwarder
+		if target != "" {
+			if _, err := url.ParseRequestURI(target); err != nil {
+				fmt.Printf("Error: invalid --forward-to URL: %v\n", err)
+				os.Exit(1)
+			}
+			if secret == "" {
+				secret = os.Getenv("SAPLIY_WEBHOOK_SECRET")
+			}
+			if secret == "" {
+				if secret, err = newForwardSecret(); err != nil {
+					fmt.Printf("Error generating signing secret: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("🔑 Signing secret for this session: %s\n", secret)
+			}
+			fwd = &forwarder{target: target, client: &http.Client{Timeout: 10 * time.Second}, secret: secret}
+		}
+
+		span := events[len(events)-1].TS.Sub(events[0].TS).Round(time.Second)
+		fmt.Printf("▶️  Replaying %d event(s) spanning %s at %s\n", len(events), span, speedFlag)
+		fmt.Println(strings.Repeat("─", 60))
+
+		ctx, stop := interruptContext()
+		defer stop()
+		if fwd != nil {
+			fwd.ctx = ctx
+		}
+		played := 0
+		for i, e := range events {
+			if i > 0 && speed > 0 {
+				gap := time.Duration(float64(e.TS.Sub(events[i-1].TS)) / speed)
+				select {
+				case <-ctx.Done():
+				case <-time.After(gap):
+				}
+			}
+			if ctx.Err() != nil {
+				fmt.Println("\n⏹️  Stopped")
+				break
+			}
+			if filterType != "" && !strings.Contains(e.Type, filterType) {
+				continue
+			}
+			played++
+
+			if fwd != nil {
+				fwd.forward(e.ID, e.Type, e.Event)
+				continue
+			}
+			timestamp := e.TS.Local().Format("15:04:05")
+			if verbose {
+				var pretty map[string]interface{}
+				json.Unmarshal(e.Event, &pretty)
+				b, _ := json.MarshalIndent(pretty, "", "  ")
+				fmt.Printf("[%s] %s\n%s\n\n", timestamp, e.Type, b)
+			} else {
+				fmt.Printf("[%s] %-30s  %s\n", timestamp, e.Type, e.ID)
+			}
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		if fwd == nil {
+			fmt.Printf("Replayed: %d\n", played)
+			return
+		}
+		fmt.Printf("Delivered: %d, Failed: %d\n", fwd.delivered, fwd.failed)
+		if fwd.failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	debugCmd.AddCommand(debugRecordCmd)
+	debugCmd.AddCommand(debugPlayCmd)
+
+	debugRecordCmd.Flags().String("out", "", "Session file to write (JSONL)")
+	debugRecordCmd.Flags().StringP("zone", "z", "", "Zone ID to record")
+	debugRecordCmd.Flags().StringP("filter", "f", "", "Only record events whose type contains this")
+	debugRecordCmd.Flags().Duration("duration", 0, "Stop after this long")
+	debugRecordCmd.Flags().Int("count", 0, "Stop after this many events")
+	debugRecordCmd.Flags().Bool("append", false, "Add to an existing session file")
+	debugRecordCmd.MarkFlagRequired("out")
+
+	debugPlayCmd.Flags().String("speed", "1x", "Replay speed: a factor such as 2x or 0.5x, or max for no delays")
+	debugPlayCmd.Flags().String("forward-to", "", "POST each event to this local URL instead of printing it")
+	debugPlayCmd.Flags().String("secret", "", "Secret to sign requests with (default $SAPLIY_WEBHOOK_SECRET or a generated one)")
+	debugPlayCmd.Flags().StringP("filter", "f", "", "Only replay events whose type contains this")
+	debugPlayCmd.Flags().BoolP("verbose", "v", false, "Show full event payloads")
+}
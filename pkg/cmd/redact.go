@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are JSON keys whose values are never shown.
+var sensitiveKeys = []string{
+	"password", "secret", "token", "authorization", "api_key", "apikey",
+	"card_number", "cardnumber", "cvc", "cvv", "ssn", "iban",
+}
+
+// sensitivePatterns catch secrets in free text, such as HTML error pages or
+// stack traces that echo request headers.
+var sensitivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(sk|pk|rk|whsec)_(live|test)?_?[A-Za-z0-9]{8,}\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{8,}`),
+	regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{1,7}\b`),
+}
+
+func isSensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody masks secrets in a response or payload body. JSON bodies have
+// the values of sensitive keys replaced; anything else is scanned for
+// secret-looking strings.
+func redactBody(body string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err == nil {
+		if out, err := json.Marshal(redactValue(v)); err == nil {
+			return string(out)
+		}
+	}
+	return redactText(body)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			if isSensitiveKey(k) {
+				val[k] = redacted
+			} else {
+				val[k] = redactValue(e)
+			}
+		}
+		return val
+	case []interface{}:
+		for i, e := range val {
+			val[i] = redactValue(e)
+		}
+		return val
+	case string:
+		return redactText(val)
+	default:
+		return v
+	}
+}
+
+func redactText(s string) string {
+	for _, re := range sensitivePatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// watchConfigReload returns a channel that receives whenever the config file
+// changes on disk or the process gets SIGHUP. The watchers only signal: the
+// receiver re-reads the configuration (see applyListenReload) on its own
+// goroutine, so viper never changes under it.
+func watchConfigReload() <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}
+
+	if file := viper.ConfigFileUsed(); file != "" {
+		if err := watchConfigFile(file, notify); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  not watching %s for changes: %v\n", file, err)
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			notify()
+		}
+	}()
+
+	return reload
+}
+
+// watchConfigFile calls notify when file is written or replaced. It watches
+// the directory, since editors save by renaming a new file over the old one
+// and Kubernetes swaps the symlink a mounted ConfigMap points through.
+func watchConfigFile(file string, notify func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(filepath.Dir(file)); err != nil {
+		w.Close()
+		return err
+	}
+	file = filepath.Clean(file)
+	target, _ := filepath.EvalSymlinks(file)
+	go func() {
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				current, _ := filepath.EvalSymlinks(file)
+				switch {
+				case filepath.Clean(ev.Name) == file && ev.Has(fsnotify.Write|fsnotify.Create):
+				case current != "" && current != target:
+				default:
+					continue
+				}
+				target = current
+				notify()
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadConfig re-reads the configuration in the layers initConfig reads it
+// in: the config file, the project config and the active profile.
+func reloadConfig() error {
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return applyConfigLayers()
+}
+
+// applyListenReload re-reads the configuration and applies listen.filter and
+// listen.sinks to a running listener, returning the zone it should be
+// subscribed to. Settings absent from the config keep their current values,
+// and so does everything when the config cannot be read. Progress is
+// reported on w.
+func applyListenReload(w io.Writer, state *listenState, sinks *sinkSet, zone string) string {
+	if err := reloadConfig(); err != nil {
+		fmt.Fprintf(w, "⚠️  reload: %v\n", err)
+		return zone
+	}
+	fmt.Fprintln(w, "🔄 Configuration reloaded")
+
+	if viper.IsSet("listen.filter") {
+		filter := viper.GetString("listen.filter")
+		if filter != state.status().Filter {
+			state.setFilter(filter)
+			fmt.Fprintf(w, "   filter → %q\n", filter)
+		}
+	}
+
+	if viper.IsSet("listen.sinks") {
+		specs := viper.GetStringSlice("listen.sinks")
+		if !slices.Equal(specs, sinks.current()) {
+			if err := sinks.update(specs); err != nil {
+				fmt.Fprintf(w, "   ⚠️  sinks unchanged: %v\n", err)
+			} else {
+				fmt.Fprintf(w, "   sinks → %v\n", specs)
+			}
+		}
+	}
+
+	if newZone := viper.GetString("current_zone"); newZone != "" {
+		return newZone
+	}
+	return zone
+}
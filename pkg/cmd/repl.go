@@ -0,0 +1,315 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	fintech "github.com/sapliy/fintech-sdk-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// replCommands are the REPL's commands, for Tab completion.
+var replCommands = []string{"emit", "zone", "set", "sleep", "run", "status", "help", "exit"}
+
+// maxScriptDepth bounds scripts running scripts with run.
+const maxScriptDepth = 10
+
+// errReplExit is returned for exit or quit.
+var errReplExit = errors.New("exit")
+
+// replEventTypes lists the account's event types for Tab completion after
+// emit, falling back to the types generate-fixture knows when the API does
+// not answer.
+func replEventTypes() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	types, _, err := listPages[struct {
+		Type string `json:"type"`
+	}](ctx, "/v1/event-types", url.Values{"limit": {"100"}}, "", true)
+	if err != nil || len(types) == 0 {
+		return fixtureEvents
+	}
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Type)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// replComplete completes a command as the first word and an event type
+// after emit.
+func replComplete(eventTypes func() []string) func(head string) []string {
+	return func(head string) []string {
+		words := strings.Fields(head)
+		if strings.HasSuffix(head, " ") {
+			words = append(words, "")
+		}
+		switch {
+		case len(words) <= 1:
+			return replCommands
+		case len(words) == 2 && words[0] == "emit":
+			return eventTypes()
+		}
+		return nil
+	}
+}
+
+// replSession is the state REPL commands share, whether typed or read from
+// a script.
+type replSession struct {
+	apiKey string
+	zone   string
+	client *fintech.Client
+	vars   map[string]string
+	depth  int // scripts currently running
+}
+
+// expand substitutes $NAME and ${NAME} with a variable set with 'set', or
+// else an environment variable. Undefined names are an error so a typo does
+// not send an empty value.
+func (s *replSession) expand(line string) (string, error) {
+	var missing []string
+	out := os.Expand(line, func(name string) string {
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable $%s", missing[0])
+	}
+	return out, nil
+}
+
+// exec runs one REPL command. Blank lines and # comments do nothing.
+func (s *replSession) exec(line string) error {
+	input := strings.TrimSpace(line)
+	if input == "" || strings.HasPrefix(input, "#") {
+		return nil
+	}
+	input, err := s.expand(input)
+	if err != nil {
+		return err
+	}
+	name, rest, _ := strings.Cut(input, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch name {
+	case "exit", "quit":
+		return errReplExit
+	case "help":
+		fmt.Println(`Commands:
+  emit <type> [json]  - Emit an event (e.g., emit payment.created {"amount":100})
+  zone <id>           - Switch to a different zone
+  set <name> <value>  - Set a variable, used as $name or ${name} (no arguments lists them)
+  sleep <duration>    - Wait, e.g. sleep 2s
+  run <file>          - Run the commands in a .sapliy script
+  status              - Show current configuration
+  exit                - Exit the REPL`)
+	case "status":
+		fmt.Printf("API Key: %s...%s\n", s.apiKey[:8], s.apiKey[len(s.apiKey)-4:])
+		fmt.Printf("Org: %s\n", viper.GetString("org_id"))
+		fmt.Printf("Account: %s\n", viper.GetString("account_id"))
+		fmt.Printf("Zone: %s\n", s.zone)
+		fmt.Printf("API URL: %s\n", viper.GetString("api_url"))
+	case "emit":
+		return s.emit(rest)
+	case "zone":
+		if rest == "" {
+			return fmt.Errorf("usage: zone <id>")
+		}
+		s.zone = rest
+		viper.Set("current_zone", s.zone)
+		fmt.Printf("✅ Switched to zone: %s\n", s.zone)
+	case "set":
+		if rest == "" {
+			names := make([]string, 0, len(s.vars))
+			for n := range s.vars {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				fmt.Printf("%s=%s\n", n, s.vars[n])
+			}
+			return nil
+		}
+		varName, value, _ := strings.Cut(rest, " ")
+		s.vars[varName] = strings.TrimSpace(value)
+	case "sleep":
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return fmt.Errorf("usage: sleep <duration>, e.g. sleep 2s")
+		}
+		time.Sleep(d)
+	case "run":
+		if rest == "" {
+			return fmt.Errorf("usage: run <file>")
+		}
+		return s.runScript(rest)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+	return nil
+}
+
+func (s *replSession) emit(args string) error {
+	eventType, raw, _ := strings.Cut(args, " ")
+	if eventType == "" {
+		return fmt.Errorf("usage: emit <type> [json]")
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		raw = "{}"
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return fmt.Errorf("invalid JSON data: %v", err)
+	}
+	if s.zone == "" {
+		return fmt.Errorf("no zone set, use 'zone <id>' first")
+	}
+	fmt.Printf("➡️  Emitting %s: %s\n", eventType, raw)
+	if err := s.client.TriggerEvent(context.Background(), eventType, s.zone, data); err != nil {
+		return fmt.Errorf("failed to emit event: %v", err)
+	}
+	fmt.Println("✅ Event emitted")
+	return nil
+}
+
+// runScript runs a file of REPL commands, echoing each one, and stops at
+// the first that fails. Paths in a nested run are relative to the script.
+func (s *replSession) runScript(path string) error {
+	if s.depth >= maxScriptDepth {
+		return fmt.Errorf("scripts nested more than %d deep", maxScriptDepth)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s.depth++
+	defer func() { s.depth-- }()
+	dir := filepath.Dir(path)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if cmd, file, ok := strings.Cut(line, " "); ok && cmd == "run" && !filepath.IsAbs(strings.TrimSpace(file)) {
+			line = "run " + filepath.Join(dir, strings.TrimSpace(file))
+		}
+		fmt.Printf("%s▶ %s\n", strings.Repeat("  ", s.depth-1), line)
+		if err := s.exec(line); err != nil {
+			if err == errReplExit {
+				return err
+			}
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+var debugReplCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Interactive REPL for testing events",
+	Long: `Start an interactive REPL to test events and flows.
+Type event types and JSON data to trigger events interactively. Tab completes
+commands and event types, and history is kept in ~/.sapliy_history.
+
+With --script, the commands in a file are run instead and the REPL exits,
+with status 1 at the first command that fails. Scripts can set variables
+(set amount 5000, then $amount or ${amount}; environment variables work
+too), wait with sleep, and run other scripts, so smoke tests can be kept in
+version control and replayed.`,
+	Example: `  sapliy debug repl
+  sapliy debug repl --script smoke.sapliy
+
+  # smoke.sapliy
+  set amount 5000
+  emit payment.created {"amount": $amount, "currency": "USD"}
+  sleep 2s
+  emit payment.succeeded {"amount": $amount}`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set.")
+			os.Exit(1)
+		}
+		script, _ := cmd.Flags().GetString("script")
+
+		s := &replSession{
+			apiKey: apiKey,
+			zone:   viper.GetString("current_zone"),
+			client: newClient(),
+			vars:   map[string]string{},
+		}
+		if z, _ := cmd.Flags().GetString("zone"); z != "" {
+			s.zone = z
+		}
+
+		if script != "" {
+			if err := s.runScript(script); err != nil && err != errReplExit {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Println("🎮 Sapliy Debug REPL")
+		fmt.Println("Type 'help' for commands, 'exit' to quit")
+		fmt.Printf("Current zone: %s\n", s.zone)
+		fmt.Println(strings.Repeat("─", 60))
+
+		lines := &lineReader{complete: replComplete(sync.OnceValue(replEventTypes))}
+		if home, err := os.UserHomeDir(); err == nil {
+			lines.loadHistory(filepath.Join(home, ".sapliy_history"))
+		}
+		for {
+			prompt := "sapliy> "
+			if status := contextStatusLine(); status != "" {
+				prompt = "[" + status + "] " + prompt
+			}
+			line, err := lines.readLine(prompt)
+			if err == errInterrupted {
+				continue
+			}
+			if err != nil {
+				break
+			}
+
+			if err := s.exec(line); err == errReplExit {
+				fmt.Println("👋 Goodbye!")
+				return
+			} else if err != nil {
+				fmt.Printf("❌ %v\n", err)
+			}
+		}
+	},
+}
+
+func init() {
+	debugCmd.AddCommand(debugReplCmd)
+	debugReplCmd.Flags().String("script", "", "Run the commands in this file (.sapliy) and exit")
+	debugReplCmd.Flags().StringP("zone", "z", "", "Zone to emit to (default: current zone)")
+}
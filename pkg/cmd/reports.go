@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportFormats = []string{"csv", "xlsx", "json"}
+
+// reportSchedule delivers a report by email on a cron schedule. The API
+// runs the schedule; the CLI only manages it.
+type reportSchedule struct {
+	ID         string     `json:"id,omitempty"`
+	Report     string     `json:"report"`
+	Cron       string     `json:"cron"`
+	Timezone   string     `json:"timezone,omitempty"`
+	Recipients []string   `json:"recipients"`
+	Format     string     `json:"format"`
+	ZoneID     string     `json:"zoneId,omitempty"`
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+}
+
+// reportRun is one run of a report. The API generates it in the background;
+// once it has succeeded, Result is the file to download.
+type reportRun struct {
+	ID          string            `json:"id"`
+	ReportType  string            `json:"reportType"`
+	Parameters  map[string]string `json:"parameters,omitempty"`
+	Format      string            `json:"format"`
+	Status      string            `json:"status"` // pending, running, succeeded or failed
+	Result      *uploadedFile     `json:"result,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	ZoneID      string            `json:"zoneId,omitempty"`
+	CreatedAt   *time.Time        `json:"createdAt,omitempty"`
+	CompletedAt *time.Time        `json:"completedAt,omitempty"`
+}
+
+func (r *reportRun) done() bool {
+	return r.Status == "succeeded" || r.Status == "failed"
+}
+
+func reportRunPath(runID string) string {
+	return "/v1/reports/runs/" + url.PathEscape(runID)
+}
+
+// waitForReport polls a report run until it has succeeded or failed, checking
+// every second at first and less often the longer it takes.
+func waitForReport(ctx context.Context, run *reportRun, log func(string, ...interface{})) error {
+	interval := time.Second
+	status := run.Status
+	for !run.done() {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(interval):
+		}
+		if err := apiRequest(ctx, http.MethodGet, reportRunPath(run.ID), nil, run); err != nil {
+			return err
+		}
+		if run.Status != status {
+			status = run.Status
+			log("   %s\n", status)
+		}
+		interval = min(interval*2, 10*time.Second)
+	}
+	return nil
+}
+
+func printReportRun(r *reportRun) {
+	fmt.Printf("📊 Report run %s — %s\n", r.ID, r.Status)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Report:     %s (%s)\n", r.ReportType, r.Format)
+	if len(r.Parameters) > 0 {
+		keys := make([]string, 0, len(r.Parameters))
+		for k := range r.Parameters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			label := ""
+			if i == 0 {
+				label = "Parameters:"
+			}
+			fmt.Printf("%-11s %s=%s\n", label, k, r.Parameters[k])
+		}
+	}
+	if r.CreatedAt != nil {
+		fmt.Printf("Started:    %s\n", r.CreatedAt.Local().Format(time.RFC1123))
+	}
+	if r.CompletedAt != nil {
+		fmt.Printf("Finished:   %s\n", r.CompletedAt.Local().Format(time.RFC1123))
+	}
+	if r.Error != "" {
+		fmt.Printf("Error:      %s\n", r.Error)
+	}
+	if r.Result != nil {
+		fmt.Printf("Result:     %s (%s, %s)\n", r.Result.Filename, firstNonEmpty(r.Result.Type, "unknown type"), formatSize(r.Result.Size))
+		fmt.Printf("\nDownload it with: sapliy files download %s\n", r.Result.ID)
+	}
+}
+
+// cronFieldRanges are the bounds of the five standard cron fields: minute,
+// hour, day of month, month and day of week.
+var cronFieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// validateCron checks a five-field cron expression. It accepts *, numbers,
+// ranges (a-b), lists (a,b) and steps (*/n, a-b/n).
+func validateCron(expr string) error {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return fmt.Errorf("cron expression needs 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+	names := []string{"minute", "hour", "day of month", "month", "day of week"}
+	for i, field := range fields {
+		lo, hi := cronFieldRanges[i][0], cronFieldRanges[i][1]
+		for _, part := range strings.Split(field, ",") {
+			rng, step, hasStep := strings.Cut(part, "/")
+			if hasStep {
+				if n, err := strconv.Atoi(step); err != nil || n < 1 {
+					return fmt.Errorf("invalid step %q in %s field", step, names[i])
+				}
+			}
+			if rng == "*" {
+				continue
+			}
+			a, b, isRange := strings.Cut(rng, "-")
+			for _, v := range []string{a, b} {
+				if v == "" && !isRange {
+					continue
+				}
+				n, err := strconv.Atoi(v)
+				if err != nil || n < lo || n > hi {
+					return fmt.Errorf("invalid %s value %q (must be %d-%d)", names[i], v, lo, hi)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+var reportsCmd = &cobra.Command{
+	Use:   "reports",
+	Short: "Manage reports",
+}
+
+var reportsRunCmd = &cobra.Command{
+	Use:   "run [report_type]",
+	Short: "Generate a report, optionally waiting for it and downloading it",
+	Long: `Start a report run, such as balance-change-summary, with --params as the
+report's parameters. Reports are generated in the background: without --wait
+the run's ID is printed to check on later with 'sapliy reports get'.
+
+With --wait the command polls until the report is ready and downloads it to
+--out, a file or a directory (the current directory by default), or to
+stdout with --out -. The exit status is 1 if the report fails or is not
+ready within --timeout. Ctrl+C stops waiting but not the run.`,
+	Example: `  sapliy reports run balance-change-summary --params interval_start=2026-09-01,interval_end=2026-10-01 --wait --out report.csv
+  sapliy reports run payouts --params currency=usd --format xlsx
+  sapliy reports run balance-change-summary --wait --out - | csvlook`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{"regionScoped": "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		run := reportRun{ReportType: args[0], ZoneID: resolveZone(cmd)}
+		run.Parameters, _ = cmd.Flags().GetStringToString("params")
+		run.Format, _ = cmd.Flags().GetString("format")
+		wait, _ := cmd.Flags().GetBool("wait")
+		out, _ := cmd.Flags().GetString("out")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		force, _ := cmd.Flags().GetBool("force")
+		if !slices.Contains(reportFormats, run.Format) {
+			fmt.Printf("Error: format must be one of %s\n", strings.Join(reportFormats, ", "))
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("out") && !wait {
+			fmt.Println("Error: --out needs --wait.")
+			os.Exit(1)
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+		if err := apiRequest(ctx, http.MethodPost, "/v1/reports/runs", &run, &run); err != nil {
+			fmt.Printf("❌ Failed to start report: %v\n", err)
+			os.Exit(1)
+		}
+		if !wait {
+			printOutput(run, func() {
+				fmt.Printf("✅ Started %s report %s (%s)\n", run.ReportType, run.ID, run.Status)
+				fmt.Printf("   Check on it with: sapliy reports get %s\n", run.ID)
+			})
+			return
+		}
+
+		// Progress goes to stderr so the report or -o json output on stdout
+		// stays clean.
+		log := func(format string, a ...interface{}) { fmt.Fprintf(os.Stderr, format, a...) }
+		log("⏳ Waiting for %s report %s...\n", run.ReportType, run.ID)
+		waitCtx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("not ready after %s", timeout))
+		defer cancel()
+		if err := waitForReport(waitCtx, &run, log); err != nil {
+			if interrupted(ctx) {
+				log("\nStopped waiting; the report is still being generated.\n")
+			} else {
+				log("❌ Report %s: %v\n", run.ID, err)
+			}
+			log("   Check on it with: sapliy reports get %s\n", run.ID)
+			os.Exit(1)
+		}
+		if run.Status == "failed" {
+			log("❌ Report %s failed: %s\n", run.ID, firstNonEmpty(run.Error, "no reason given"))
+			os.Exit(1)
+		}
+		if run.Result == nil {
+			log("❌ Report %s succeeded but has no file to download.\n", run.ID)
+			os.Exit(1)
+		}
+
+		if out == "-" {
+			if _, err := downloadFile(ctx, run.Result.ID, os.Stdout); err != nil {
+				log("❌ Failed to download report: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		path, size, err := saveFile(ctx, run.Result, out, force)
+		if err != nil {
+			log("❌ Failed to download report: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(run, func() {
+			fmt.Printf("📄 Saved %s (%s)\n", path, formatSize(size))
+		})
+	},
+}
+
+var reportsGetCmd = &cobra.Command{
+	Use:   "get [run_id]",
+	Short: "Show a report run and the file it produced",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var run reportRun
+		if err := apiRequest(context.Background(), http.MethodGet, reportRunPath(args[0]), nil, &run); err != nil {
+			fmt.Printf("❌ Failed to fetch report run: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(run, func() { printReportRun(&run) })
+	},
+}
+
+var reportsScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Schedule reports to be emailed regularly",
+}
+
+var reportsScheduleCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Email a report on a cron schedule",
+	Long: `Have the API generate a report on a cron schedule and email it to one or
+more recipients. The schedule uses the standard five cron fields (minute hour
+day-of-month month day-of-week), evaluated in --timezone.`,
+	Example: `  sapliy reports schedule create --report revenue --cron "0 8 * * 1" --email finance@co.com --format csv
+  sapliy reports schedule create --report payouts --cron "0 6 1 * *" --email a@co.com,b@co.com --timezone Europe/Berlin`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		schedule := reportSchedule{ZoneID: resolveZone(cmd)}
+		schedule.Report, _ = cmd.Flags().GetString("report")
+		schedule.Cron, _ = cmd.Flags().GetString("cron")
+		schedule.Recipients, _ = cmd.Flags().GetStringSlice("email")
+		schedule.Format, _ = cmd.Flags().GetString("format")
+		schedule.Timezone, _ = cmd.Flags().GetString("timezone")
+
+		if err := validateCron(schedule.Cron); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !slices.Contains(reportFormats, schedule.Format) {
+			fmt.Printf("Error: format must be one of %s\n", strings.Join(reportFormats, ", "))
+			os.Exit(1)
+		}
+		if schedule.Timezone != "" {
+			if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+				fmt.Printf("Error: unknown timezone %q\n", schedule.Timezone)
+				os.Exit(1)
+			}
+		}
+		for _, r := range schedule.Recipients {
+			if !strings.Contains(r, "@") {
+				fmt.Printf("Error: invalid email address %q\n", r)
+				os.Exit(1)
+			}
+		}
+
+		var created reportSchedule
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/reports/schedules", &schedule, &created); err != nil {
+			fmt.Printf("❌ Failed to create schedule: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(created, func() {
+			fmt.Printf("✅ Scheduled %s report %s\n", created.Report, created.ID)
+			fmt.Printf("   %s → %s (%s)\n", created.Cron, strings.Join(created.Recipients, ", "), created.Format)
+			if created.NextRunAt != nil {
+				fmt.Printf("   Next run: %s\n", created.NextRunAt.Local().Format(time.RFC1123))
+			}
+		})
+	},
+}
+
+var reportsScheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled reports",
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var schedules []reportSchedule
+		if err := apiRequest(context.Background(), http.MethodGet, "/v1/reports/schedules", nil, &schedules); err != nil {
+			fmt.Printf("Error listing schedules: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(schedules, func() {
+			if len(schedules) == 0 {
+				fmt.Println("No scheduled reports.")
+				return
+			}
+
+			fmt.Printf("%-20s %-12s %-16s %-5s %-18s %s\n", "ID", "REPORT", "CRON", "FMT", "NEXT RUN", "RECIPIENTS")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, s := range schedules {
+				next := "-"
+				if s.NextRunAt != nil {
+					next = s.NextRunAt.Local().Format("Jan 02 15:04")
+				}
+				fmt.Printf("%-20s %-12s %-16s %-5s %-18s %s\n", s.ID, truncate(s.Report, 12), s.Cron, s.Format, next, strings.Join(s.Recipients, ", "))
+			}
+		})
+	},
+}
+
+var reportsScheduleDeleteCmd = &cobra.Command{
+	Use:   "delete [schedule_id]",
+	Short: "Stop a scheduled report",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, "/v1/reports/schedules/"+url.PathEscape(args[0]), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete schedule: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted scheduled report %s\n", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportsCmd)
+	reportsCmd.AddCommand(reportsRunCmd)
+	reportsCmd.AddCommand(reportsGetCmd)
+	reportsCmd.AddCommand(reportsScheduleCmd)
+	reportsScheduleCmd.AddCommand(reportsScheduleCreateCmd)
+	reportsScheduleCmd.AddCommand(reportsScheduleListCmd)
+	reportsScheduleCmd.AddCommand(reportsScheduleDeleteCmd)
+
+	reportsRunCmd.Flags().StringToString("params", nil, "Report parameter as key=value (repeatable)")
+	reportsRunCmd.Flags().String("format", "csv", "File format (csv, xlsx, json)")
+	reportsRunCmd.Flags().Bool("wait", false, "Wait for the report and download it")
+	reportsRunCmd.Flags().String("out", ".", "With --wait, file or directory to save the report to, or - for stdout")
+	reportsRunCmd.Flags().Duration("timeout", 10*time.Minute, "With --wait, how long to wait for the report")
+	reportsRunCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	reportsScheduleCreateCmd.Flags().String("report", "", "Report to deliver (e.g. revenue, payouts, refunds)")
+	reportsScheduleCreateCmd.Flags().String("cron", "", "Cron schedule, e.g. \"0 8 * * 1\" for Mondays at 08:00")
+	reportsScheduleCreateCmd.Flags().StringSlice("email", nil, "Recipients (comma-separated or repeated)")
+	reportsScheduleCreateCmd.Flags().String("format", "csv", "File format (csv, xlsx, json)")
+	reportsScheduleCreateCmd.Flags().String("timezone", "", "Timezone the schedule runs in (default the account's)")
+	reportsScheduleCreateCmd.MarkFlagRequired("report")
+	reportsScheduleCreateCmd.MarkFlagRequired("cron")
+	reportsScheduleCreateCmd.MarkFlagRequired("email")
+}
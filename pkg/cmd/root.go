@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,10 +17,32 @@ var rootCmd = &cobra.Command{
 	Short: "Sapliy Fintech Ecosystem CLI",
 	Long: `Sapliy CLI is the official command line interface for the Sapliy Fintech Ecosystem.
 It allows you to manage automation zones, flows, and interact with the event bus.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		checkLoadedConfig(cmd, args)
+		applyFlagDefaults(cmd)
+		refreshTokenIfNeeded()
+		if err := validateOutputFormat(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := validateTransportProfile(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := validateProxy(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := validateRegion(cmd); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
+	configureCommands()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -30,9 +53,45 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sapliy.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "configuration profile to use (see 'sapliy config profiles')")
 	rootCmd.PersistentFlags().Bool("verbose", false, "enable verbose output")
+	rootCmd.PersistentFlags().Bool("accessible", false, "plain text output for screen readers: no emoji, box drawing or redrawn progress")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format for list and inspect commands (table, json, yaml)")
+	rootCmd.PersistentFlags().Int("max-payload-bytes", 256*1024, "cut event and step payloads larger than this in list and inspect commands (0 for no limit)")
+
+	rootCmd.PersistentFlags().String("region", "", "prefer the API host in this region, e.g. eu (see api_urls)")
+	rootCmd.PersistentFlags().String("proxy", "", "proxy for API and event stream connections (http://, https:// or socks5://; default HTTPS_PROXY)")
+	rootCmd.PersistentFlags().Duration("timeout", defaultRequestTimeout, "time limit for each API request, retries included (0 for none)")
+	rootCmd.PersistentFlags().String("transport-profile", "", "HTTP connection pool preset: default, or bulk for high-volume jobs")
 
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("accessible", rootCmd.PersistentFlags().Lookup("accessible"))
+	viper.BindPFlag("max_payload_bytes", rootCmd.PersistentFlags().Lookup("max-payload-bytes"))
+	viper.BindPFlag("region", rootCmd.PersistentFlags().Lookup("region"))
+	viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	viper.BindPFlag("transport.profile", rootCmd.PersistentFlags().Lookup("transport-profile"))
+}
+
+// sapliyDir returns ~/.sapliy, creating it if needed. It holds local state
+// such as sockets and caches that doesn't belong in the config file.
+func sapliyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".sapliy")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// applyConfigLayers puts the project config and then the active profile
+// over the config file viper has just read.
+func applyConfigLayers() error {
+	loadProjectConfig()
+	return applyProfile()
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -53,9 +112,14 @@ func initConfig() {
 	viper.SetEnvPrefix("SAPLIY")
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil {
-		if viper.GetBool("verbose") {
-			fmt.Println("Using config file:", viper.ConfigFileUsed())
-		}
+	configErr := viper.ReadInConfig()
+	if err := applyConfigLayers(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	runAccessible()
+	if configErr == nil && viper.GetBool("verbose") {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
+	loadCredentials()
 }
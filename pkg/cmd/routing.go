@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// A routingRule sends events from one zone (or any, "*") to another when
+// they match its event types and condition. Rules belong to the active
+// account and are evaluated in priority order, lowest first.
+type routingRule struct {
+	ID        string     `json:"id,omitempty"`
+	Name      string     `json:"name"`
+	FromZone  string     `json:"fromZone"`
+	ToZone    string     `json:"toZone"`
+	Events    []string   `json:"events,omitempty"`
+	Condition string     `json:"condition,omitempty"`
+	Priority  int        `json:"priority"`
+	Enabled   bool       `json:"enabled"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// routingTarget is a flow or endpoint in a routing explanation, and whether
+// the event reaches it.
+type routingTarget struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// routingExplanation is the API's dry run of routing one event: how every
+// rule evaluated, and which flows and endpoints it reaches in each zone.
+type routingExplanation struct {
+	EventType string `json:"eventType"`
+	Zone      string `json:"zone"`
+	Rules     []struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		ToZone  string `json:"toZone"`
+		Matched bool   `json:"matched"`
+		Reason  string `json:"reason,omitempty"`
+	} `json:"rules"`
+	Destinations []struct {
+		Zone      string          `json:"zone"`
+		Rule      string          `json:"rule,omitempty"`
+		Flows     []routingTarget `json:"flows"`
+		Endpoints []routingTarget `json:"endpoints"`
+	} `json:"destinations"`
+}
+
+var routingCmd = &cobra.Command{
+	Use:   "routing",
+	Short: "Manage how events are routed between zones",
+}
+
+var routingRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Manage the account's routing rules",
+}
+
+var routingRulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List routing rules in evaluation order",
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var rules []routingRule
+		if err := apiRequest(context.Background(), http.MethodGet, "/v1/routing/rules", nil, &rules); err != nil {
+			fmt.Printf("Error listing routing rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(rules, func() {
+			if len(rules) == 0 {
+				fmt.Println("No routing rules. Events stay in the zone they are sent to.")
+				return
+			}
+
+			fmt.Printf("%-4s %-18s %-22s %-16s %-16s %s\n", "PRI", "ID", "NAME", "FROM", "TO", "MATCHES")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, r := range rules {
+				matches := strings.Join(r.Events, ",")
+				if matches == "" {
+					matches = "*"
+				}
+				if r.Condition != "" {
+					matches += " if " + r.Condition
+				}
+				name := r.Name
+				if !r.Enabled {
+					name += " (off)"
+				}
+				fmt.Printf("%-4d %-18s %-22s %-16s %-16s %s\n", r.Priority, r.ID, truncate(name, 22), r.FromZone, r.ToZone, truncate(matches, 40))
+			}
+		})
+	},
+}
+
+var routingRulesCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a routing rule",
+	Long: `Create a rule that routes events to another zone. --from limits it to
+events sent to one zone (default: any zone), --events to some event types,
+and --condition to events whose payload matches an expression.`,
+	Example: `  sapliy routing rules create --name "EU traffic" --to zone_eu --condition 'data.customer.country == "DE"'
+  sapliy routing rules create --name "Refunds to ops" --from zone_shop --to zone_ops --events 'refund.*' --priority 10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		rule := routingRule{Enabled: true}
+		rule.Name, _ = cmd.Flags().GetString("name")
+		rule.FromZone, _ = cmd.Flags().GetString("from")
+		rule.ToZone, _ = cmd.Flags().GetString("to")
+		rule.Events, _ = cmd.Flags().GetStringSlice("events")
+		rule.Condition, _ = cmd.Flags().GetString("condition")
+		rule.Priority, _ = cmd.Flags().GetInt("priority")
+		if rule.FromZone == rule.ToZone {
+			fmt.Println("Error: --from and --to must be different zones.")
+			os.Exit(1)
+		}
+		if reason := conditionNeverTrue(rule.Condition); reason != "" {
+			fmt.Printf("⚠️  The condition can never be true (%s); the rule will not route anything.\n", reason)
+		}
+
+		var created routingRule
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/routing/rules", &rule, &created); err != nil {
+			fmt.Printf("❌ Failed to create routing rule: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(created, func() {
+			fmt.Printf("✅ Created routing rule %s: %s → %s\n", created.ID, created.FromZone, created.ToZone)
+		})
+	},
+}
+
+var routingRulesDeleteCmd = &cobra.Command{
+	Use:   "delete [rule_id]",
+	Short: "Delete a routing rule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Delete routing rule %s? Matching events will stay in their zone. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, "/v1/routing/rules/"+url.PathEscape(args[0]), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete routing rule: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted routing rule %s\n", args[0])
+	},
+}
+
+func printRoutingTargets(kind string, targets []routingTarget) {
+	if len(targets) == 0 {
+		fmt.Printf("     %s: none\n", kind)
+		return
+	}
+	fmt.Printf("     %s:\n", kind)
+	for _, t := range targets {
+		mark, name := "✓", t.ID
+		if !t.Matched {
+			mark = "✗"
+		}
+		if t.Name != "" {
+			name += " " + t.Name
+		}
+		if t.Reason != "" {
+			fmt.Printf("       %s %s — %s\n", mark, name, t.Reason)
+		} else {
+			fmt.Printf("       %s %s\n", mark, name)
+		}
+	}
+}
+
+var routingExplainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show which zones, flows and endpoints an event would reach",
+	Long: `Dry-run routing for an event without sending it: every rule is evaluated,
+and for each zone the event ends up in, every flow and endpoint is listed
+with whether it would fire and, if not, why. Use it to find out why a flow
+did not run.`,
+	Example: `  sapliy routing explain --event @event.json
+  sapliy webhooks generate-fixture payment.succeeded | sapliy routing explain --event @- --zone zone_shop`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		eventArg, _ := cmd.Flags().GetString("event")
+		raw, err := readArgValue(eventArg)
+		if err != nil {
+			fmt.Printf("Error reading event: %v\n", err)
+			os.Exit(1)
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal(raw, &event); err != nil {
+			fmt.Printf("Error: --event must be a JSON object: %v\n", err)
+			os.Exit(1)
+		}
+		if stepString(event, "type") == "" {
+			fmt.Println("Error: the event has no \"type\".")
+			os.Exit(1)
+		}
+
+		zone, _ := cmd.Flags().GetString("zone")
+		if zone == "" {
+			zone = stepString(event, "zoneId")
+		}
+		if zone == "" {
+			zone = viper.GetString("current_zone")
+		}
+
+		req := map[string]interface{}{"event": event, "zoneId": zone}
+		var ex routingExplanation
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/routing/explain", req, &ex); err != nil {
+			fmt.Printf("❌ Failed to explain routing: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(ex, func() {
+			fmt.Printf("🧭 %s sent to %s\n", ex.EventType, ex.Zone)
+			fmt.Println(strings.Repeat("─", 60))
+
+			if len(ex.Rules) > 0 {
+				fmt.Println("Rules:")
+				for _, r := range ex.Rules {
+					if r.Matched {
+						fmt.Printf("  ✓ %s %q → %s\n", r.ID, r.Name, r.ToZone)
+					} else {
+						fmt.Printf("  ✗ %s %q — %s\n", r.ID, r.Name, r.Reason)
+					}
+				}
+				fmt.Println()
+			}
+
+			if len(ex.Destinations) == 0 {
+				fmt.Println("❌ The event reaches no zone.")
+				return
+			}
+			fired := 0
+			for _, d := range ex.Destinations {
+				via := "sent directly"
+				if d.Rule != "" {
+					via = "via " + d.Rule
+				}
+				fmt.Printf("  📍 %s (%s)\n", d.Zone, via)
+				printRoutingTargets("flows", d.Flows)
+				printRoutingTargets("endpoints", d.Endpoints)
+				for _, targets := range [][]routingTarget{d.Flows, d.Endpoints} {
+					for _, t := range targets {
+						if t.Matched {
+							fired++
+						}
+					}
+				}
+			}
+			if fired == 0 {
+				fmt.Println("\n⚠️  No flow or endpoint would receive this event; see the ✗ reasons above.")
+			}
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(routingCmd)
+	routingCmd.AddCommand(routingRulesCmd)
+	routingCmd.AddCommand(routingExplainCmd)
+	routingRulesCmd.AddCommand(routingRulesListCmd)
+	routingRulesCmd.AddCommand(routingRulesCreateCmd)
+	routingRulesCmd.AddCommand(routingRulesDeleteCmd)
+
+	routingRulesCreateCmd.Flags().String("name", "", "Name shown in listings")
+	routingRulesCreateCmd.Flags().String("from", "*", "Zone the events are sent to (* for any)")
+	routingRulesCreateCmd.Flags().String("to", "", "Zone to route matching events to")
+	routingRulesCreateCmd.Flags().StringSlice("events", nil, "Event types to route (comma-separated, supports payment.*; default all)")
+	routingRulesCreateCmd.Flags().String("condition", "", "Only route events whose payload matches this expression")
+	routingRulesCreateCmd.Flags().Int("priority", 100, "Evaluation order, lowest first")
+	routingRulesCreateCmd.MarkFlagRequired("name")
+	routingRulesCreateCmd.MarkFlagRequired("to")
+	routingRulesDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	routingExplainCmd.Flags().String("event", "", "Event JSON, @file or @- for stdin")
+	routingExplainCmd.Flags().StringP("zone", "z", "", "Zone the event is sent to (default: the event's zoneId, then the current zone)")
+	routingExplainCmd.MarkFlagRequired("event")
+}
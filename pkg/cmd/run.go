@@ -1,7 +1,8 @@
+//go:build !headless
+
 package cmd
 
 import (
-	"embed"
 	"fmt"
 	"io"
 	"io/fs"
@@ -9,17 +10,13 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"os"
 	"path"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
-// Embedded static files
-//
-//go:embed ui/*
-var content embed.FS
-
 // SPAHandler handles Static files and SPA routing
 type SPAHandler struct {
 	staticFS fs.FS
@@ -94,15 +91,18 @@ var runCmd = &cobra.Command{
 		port, _ := cmd.Flags().GetString("port")
 		apiURL, _ := cmd.Flags().GetString("api")
 
+		fsys, err := studioAssets()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
 		fmt.Printf("🚀 Sapliy Automation Studio starting...\n")
 		fmt.Printf("   ├── UI: http://localhost:%s\n", port)
-		fmt.Printf("   └── API Proxy: %s\n", apiURL)
-
-		// Prepare FS
-		fsys, err := fs.Sub(content, "ui")
-		if err != nil {
-			log.Fatal(err)
+		if status := contextStatusLine(); status != "" {
+			fmt.Printf("   ├── Account: %s\n", status)
 		}
+		fmt.Printf("   └── API Proxy: %s\n", apiURL)
 
 		// API Proxy Handler
 		target, err := url.Parse(apiURL)
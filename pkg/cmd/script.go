@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	fintech "github.com/sapliy/fintech-sdk-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	starjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// scriptEnv holds what the builtins of a running script share.
+type scriptEnv struct {
+	client *fintech.Client
+	zone   string
+}
+
+// builtins returns the predeclared names available to scripts.
+func (e *scriptEnv) builtins(args []string) starlark.StringDict {
+	argv := make([]starlark.Value, len(args))
+	for i, a := range args {
+		argv[i] = starlark.String(a)
+	}
+
+	return starlark.StringDict{
+		"trigger": starlark.NewBuiltin("trigger", e.trigger),
+		"sleep":   starlark.NewBuiltin("sleep", scriptSleep),
+		"payments": &starlarkstruct.Module{
+			Name: "payments",
+			Members: starlark.StringDict{
+				"create": starlark.NewBuiltin("payments.create", e.createPayment),
+			},
+		},
+		"json": starjson.Module,
+		"zone": starlark.String(e.zone),
+		"args": starlark.NewList(argv),
+	}
+}
+
+// trigger(event_type, data={}, zone=None) fires a mock event.
+func (e *scriptEnv) trigger(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var eventType string
+	var data starlark.Value = starlark.NewDict(0)
+	zone := e.zone
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "event_type", &eventType, "data?", &data, "zone?", &zone); err != nil {
+		return nil, err
+	}
+	if zone == "" {
+		return nil, fmt.Errorf("%s: no zone set (pass zone= or use --zone)", b.Name())
+	}
+
+	converted, err := fromStarlark(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	payload, ok := converted.(map[string]interface{})
+	if !ok && converted != nil {
+		return nil, fmt.Errorf("%s: data must be a dict", b.Name())
+	}
+
+	if err := e.client.TriggerEvent(context.Background(), eventType, zone, payload); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	fmt.Printf("⚡ %s → %s\n", eventType, zone)
+	return starlark.None, nil
+}
+
+// payments.create(amount, currency="USD", zone=None) creates a payment
+// intent and returns it as a dict.
+func (e *scriptEnv) createPayment(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var amount int64
+	currency := "USD"
+	zone := e.zone
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "amount", &amount, "currency?", &currency, "zone?", &zone); err != nil {
+		return nil, err
+	}
+
+	payment, err := e.client.Payments.CreateIntent(context.Background(), &fintech.PaymentIntentRequest{
+		Amount:   amount,
+		Currency: currency,
+		ZoneID:   zone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	fmt.Printf("💳 %s %d %s\n", payment.ID, amount, currency)
+
+	raw, err := json.Marshal(payment)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return toStarlark(decoded), nil
+}
+
+// sleep(seconds) pauses the script.
+func scriptSleep(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var seconds starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &seconds); err != nil {
+		return nil, err
+	}
+	f, ok := starlark.AsFloat(seconds)
+	if !ok || f < 0 {
+		return nil, fmt.Errorf("%s: seconds must be a non-negative number", b.Name())
+	}
+	time.Sleep(time.Duration(f * float64(time.Second)))
+	return starlark.None, nil
+}
+
+var scriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: "Run Starlark automation scripts",
+}
+
+var scriptRunCmd = &cobra.Command{
+	Use:   "run [file.star] [args...]",
+	Short: "Run a Starlark script against the API",
+	Long: `Run a Starlark script (a small Python dialect) with loops and conditionals
+that drive the CLI primitives directly:
+
+  trigger(event_type, data={}, zone=None)    fire a mock event
+  payments.create(amount, currency="USD")    create a payment intent, returns a dict
+  sleep(seconds)                             pause
+  json.encode(v) / json.decode(s)            JSON helpers
+  zone                                       the active zone
+  args                                       extra command-line arguments`,
+	Example: `  sapliy script run seed.star
+
+  # seed.star
+  for i in range(10):
+      p = payments.create(amount=1000 + i * 100)
+      trigger("payment.succeeded", {"payment_id": p["id"]})
+      sleep(0.5)`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKey := viper.GetString("api_key")
+		if apiKey == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		zone := resolveZone(cmd)
+
+		path := args[0]
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error reading script: %v\n", err)
+			os.Exit(1)
+		}
+
+		env := &scriptEnv{
+			client: newClient(),
+			zone:   zone,
+		}
+
+		thread := &starlark.Thread{
+			Name:  path,
+			Print: func(_ *starlark.Thread, msg string) { fmt.Println(msg) },
+		}
+
+		opts := &syntax.FileOptions{While: true, TopLevelControl: true, GlobalReassign: true}
+		if _, err := starlark.ExecFileOptions(opts, thread, path, src, env.builtins(args[1:])); err != nil {
+			var evalErr *starlark.EvalError
+			if errors.As(err, &evalErr) {
+				fmt.Fprintln(os.Stderr, evalErr.Backtrace())
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scriptCmd)
+	scriptCmd.AddCommand(scriptRunCmd)
+	scriptRunCmd.Flags().StringP("zone", "z", "", "Zone used by trigger() and payments.create() (default current zone)")
+}
@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Webhook signatures are sent as "t=<unix>,v1=<hex hmac>" where the HMAC-SHA256
+// is computed with the endpoint secret over "<t>.<raw body>".
+const (
+	signatureHeader    = "Sapliy-Signature"
+	signatureTolerance = 5 * time.Minute
+)
+
+type parsedSignature struct {
+	Timestamp  int64
+	Signatures []string
+}
+
+func parseSignatureHeader(header string) (*parsedSignature, error) {
+	sig := &parsedSignature{}
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q", kv[1])
+			}
+			sig.Timestamp = ts
+		case "v1":
+			sig.Signatures = append(sig.Signatures, kv[1])
+		}
+	}
+
+	if sig.Timestamp == 0 {
+		return nil, fmt.Errorf("no t= timestamp in signature header")
+	}
+	if len(sig.Signatures) == 0 {
+		return nil, fmt.Errorf("no v1= signature in signature header")
+	}
+	return sig, nil
+}
+
+func signedPayload(timestamp int64, payload []byte) []byte {
+	return append([]byte(strconv.FormatInt(timestamp, 10)+"."), payload...)
+}
+
+func computeSignature(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(signedPayload(timestamp, payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readArgValue resolves the curl-style "@path" convention: "@file" reads the
+// file, "@-" reads stdin and anything else is used literally.
+func readArgValue(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, "@") {
+		return []byte(value), nil
+	}
+	if value == "@-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(value[1:])
+}
+
+// parseCapturedRequest accepts a raw HTTP request dump (request line, headers,
+// blank line, body) or just headers followed by a body.
+func parseCapturedRequest(raw []byte) (http.Header, []byte, error) {
+	// Only the head is normalized; the body must stay byte-for-byte intact.
+	sep, sepLen := bytes.Index(raw, []byte("\r\n\r\n")), 4
+	if lf := bytes.Index(raw, []byte("\n\n")); lf >= 0 && (sep < 0 || lf < sep) {
+		sep, sepLen = lf, 2
+	}
+	if sep < 0 {
+		return nil, nil, fmt.Errorf("no blank line between headers and body")
+	}
+	head := bytes.ReplaceAll(raw[:sep], []byte("\r\n"), []byte("\n"))
+	body := raw[sep+sepLen:]
+
+	header := http.Header{}
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			first = false
+			// Skip "POST /webhook HTTP/1.1" style request lines.
+			if strings.Contains(line, " HTTP/") {
+				continue
+			}
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	return header, body, nil
+}
+
+var webhooksDebugSignatureCmd = &cobra.Command{
+	Use:   "debug-signature",
+	Short: "Explain why a captured webhook signature does or does not match",
+	Long: `Recompute the expected signature for a captured webhook request and show
+the exact signed string, timestamp skew and where the provided signature
+differs, along with the most common causes of a mismatch.
+
+The request can be a raw HTTP dump (as logged by your server or a proxy):
+
+  sapliy webhooks debug-signature --secret whsec_x --request @captured_request.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		secret, _ := cmd.Flags().GetString("secret")
+		request, _ := cmd.Flags().GetString("request")
+		headerOverride, _ := cmd.Flags().GetString("header")
+		payloadOverride, _ := cmd.Flags().GetString("payload")
+
+		if secret == "" {
+			fmt.Println("Error: --secret is required.")
+			os.Exit(1)
+		}
+
+		header := http.Header{}
+		var body []byte
+		if request != "" {
+			raw, err := readArgValue(request)
+			if err != nil {
+				fmt.Printf("Error reading request: %v\n", err)
+				os.Exit(1)
+			}
+			header, body, err = parseCapturedRequest(raw)
+			if err != nil {
+				fmt.Printf("Error parsing request: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if payloadOverride != "" {
+			b, err := readArgValue(payloadOverride)
+			if err != nil {
+				fmt.Printf("Error reading payload: %v\n", err)
+				os.Exit(1)
+			}
+			body = b
+		}
+
+		sigHeader := header.Get(signatureHeader)
+		if headerOverride != "" {
+			sigHeader = headerOverride
+		}
+
+		requireSignatureClock()
+		fmt.Println("🔏 Webhook Signature Debugger")
+		fmt.Println(strings.Repeat("─", 60))
+
+		if sigHeader == "" {
+			fmt.Printf("❌ No %s header found. Pass it with --header or include it in --request.\n", signatureHeader)
+			os.Exit(1)
+		}
+		fmt.Printf("Header:      %s\n", sigHeader)
+
+		sig, err := parseSignatureHeader(sigHeader)
+		if err != nil {
+			fmt.Printf("❌ Malformed header: %v\n", err)
+			fmt.Println("   Expected format: t=<unix timestamp>,v1=<hex signature>")
+			os.Exit(1)
+		}
+
+		signedAt := time.Unix(sig.Timestamp, 0)
+		skew := time.Since(signedAt).Round(time.Second)
+		fmt.Printf("Timestamp:   %d (%s)\n", sig.Timestamp, signedAt.UTC().Format(time.RFC3339))
+		if skew > signatureTolerance || skew < -signatureTolerance {
+			fmt.Printf("Skew:        %s ⚠️  outside the %s tolerance (replayed or clock skew?)\n", skew, signatureTolerance)
+		} else {
+			fmt.Printf("Skew:        %s (within %s tolerance)\n", skew, signatureTolerance)
+		}
+		fmt.Printf("Body:        %d bytes\n", len(body))
+
+		signed := signedPayload(sig.Timestamp, body)
+		fmt.Println("\nSigned string:")
+		fmt.Printf("  %s\n", truncate(strconv.Quote(string(signed)), 200))
+
+		expected := computeSignature(secret, sig.Timestamp, body)
+		fmt.Printf("\nExpected v1: %s\n", expected)
+
+		matched := false
+		for _, provided := range sig.Signatures {
+			if hmac.Equal([]byte(provided), []byte(expected)) {
+				matched = true
+			}
+		}
+		for _, provided := range sig.Signatures {
+			fmt.Printf("Provided v1: %s\n", provided)
+			if !matched {
+				printSignatureDiff(expected, provided)
+			}
+		}
+
+		fmt.Println(strings.Repeat("─", 60))
+		if matched {
+			fmt.Println("✅ Signature matches.")
+			return
+		}
+
+		fmt.Println("❌ Signature mismatch. Checking common causes...")
+		if hint := diagnoseSignature(secret, sig, body); hint != "" {
+			fmt.Printf("💡 %s\n", hint)
+		} else {
+			fmt.Println("   No variant of the body or secret matched. Double-check that the")
+			fmt.Println("   secret belongs to the endpoint that received this request.")
+		}
+		os.Exit(1)
+	},
+}
+
+// signatureCheck is the outcome of verifying a payload against a signature
+// header. Failed names the part that did not check out: "header",
+// "timestamp" or "signature".
+type signatureCheck struct {
+	Valid     bool   `json:"valid"`
+	Failed    string `json:"failed,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Age       string `json:"age,omitempty"`
+	Tolerance string `json:"tolerance"`
+	InWindow  bool   `json:"inWindow"`
+	Expected  string `json:"expected,omitempty"`
+	Provided  string `json:"provided,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+}
+
+// verifySignature checks header the way a handler should: parse it, reject
+// timestamps outside tolerance, then compare the HMAC in constant time.
+func verifySignature(secret, header string, payload []byte, tolerance time.Duration, now time.Time) *signatureCheck {
+	check := &signatureCheck{Tolerance: tolerance.String()}
+	sig, err := parseSignatureHeader(header)
+	if err != nil {
+		check.Failed, check.Reason = "header", err.Error()
+		check.Hint = "Expected format: t=<unix timestamp>,v1=<hex signature>"
+		return check
+	}
+
+	check.Timestamp = sig.Timestamp
+	age := now.Sub(time.Unix(sig.Timestamp, 0)).Round(time.Second)
+	check.Age = age.String()
+	check.InWindow = tolerance <= 0 || (age <= tolerance && age >= -tolerance)
+	check.Expected = computeSignature(secret, sig.Timestamp, payload)
+	check.Provided = sig.Signatures[0]
+
+	for _, provided := range sig.Signatures {
+		if hmac.Equal([]byte(provided), []byte(check.Expected)) {
+			check.Provided = provided
+			check.Valid = true
+		}
+	}
+	if !check.Valid {
+		check.Failed, check.Reason = "signature", "no v1 signature matches the payload and secret"
+		check.Hint = diagnoseSignature(secret, sig, payload)
+		return check
+	}
+	if !check.InWindow {
+		check.Valid = false
+		check.Failed = "timestamp"
+		check.Reason = fmt.Sprintf("signed %s ago, outside the %s tolerance", age, tolerance)
+		check.Hint = "The HMAC is correct, so this is a replayed delivery or the clocks disagree. Check NTP on the receiving host."
+	}
+	return check
+}
+
+var webhooksVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check a webhook payload against its signature",
+	Long: `Recompute the HMAC for a webhook payload and report whether the signature
+is valid, whether its timestamp is inside the tolerance window, and which part
+failed if it is not. The exit status is 1 when the signature is not valid.
+
+--signature is the full Sapliy-Signature header (t=...,v1=...). --payload is
+the raw request body exactly as received, or - for stdin. The secret defaults
+to $SAPLIY_WEBHOOK_SECRET.`,
+	Example: `  sapliy webhooks verify --payload body.json --signature "t=1767225600,v1=5257a8..." --secret whsec_...
+  pbpaste | sapliy webhooks verify --payload - --signature "$SIG" --tolerance 0`,
+	Run: func(cmd *cobra.Command, args []string) {
+		payloadPath, _ := cmd.Flags().GetString("payload")
+		header, _ := cmd.Flags().GetString("signature")
+		secret, _ := cmd.Flags().GetString("secret")
+		tolerance, _ := cmd.Flags().GetDuration("tolerance")
+
+		if secret == "" {
+			secret = os.Getenv("SAPLIY_WEBHOOK_SECRET")
+		}
+		if secret == "" {
+			fmt.Println("Error: --secret is required (or set SAPLIY_WEBHOOK_SECRET).")
+			os.Exit(1)
+		}
+
+		var payload []byte
+		var err error
+		if payloadPath == "-" {
+			payload, err = io.ReadAll(os.Stdin)
+		} else {
+			payload, err = os.ReadFile(strings.TrimPrefix(payloadPath, "@"))
+		}
+		if err != nil {
+			fmt.Printf("Error reading payload: %v\n", err)
+			os.Exit(1)
+		}
+
+		if tolerance > 0 {
+			requireSignatureClock()
+		}
+		check := verifySignature(secret, header, payload, tolerance, time.Now())
+		printOutput(check, func() {
+			if check.Timestamp != 0 {
+				fmt.Printf("Timestamp:   %d (%s, %s ago)\n", check.Timestamp, time.Unix(check.Timestamp, 0).UTC().Format(time.RFC3339), check.Age)
+				switch {
+				case tolerance <= 0:
+					fmt.Println("Tolerance:   not checked")
+				case check.InWindow:
+					fmt.Printf("Tolerance:   %s, timestamp is inside the window\n", tolerance)
+				default:
+					fmt.Printf("Tolerance:   %s, ⚠️  timestamp is outside the window\n", tolerance)
+				}
+				fmt.Printf("Expected v1: %s\n", check.Expected)
+				fmt.Printf("Provided v1: %s\n", check.Provided)
+				if check.Failed == "signature" {
+					printSignatureDiff(check.Expected, check.Provided)
+				}
+				fmt.Println(strings.Repeat("─", 60))
+			}
+
+			if check.Valid {
+				fmt.Println("✅ Signature is valid.")
+				return
+			}
+			fmt.Printf("❌ Invalid %s: %s\n", check.Failed, check.Reason)
+			if check.Hint != "" {
+				fmt.Printf("💡 %s\n", check.Hint)
+			} else if check.Failed == "signature" {
+				fmt.Println("   Make sure the secret belongs to the endpoint that received the payload,")
+				fmt.Println("   and that the payload is the raw body, not re-encoded JSON.")
+			}
+		})
+		if !check.Valid {
+			os.Exit(1)
+		}
+	},
+}
+
+// printSignatureDiff marks the first differing character between two hex
+// signatures.
+func printSignatureDiff(expected, provided string) {
+	n := len(expected)
+	if len(provided) < n {
+		n = len(provided)
+	}
+	pos := n
+	for i := 0; i < n; i++ {
+		if expected[i] != provided[i] {
+			pos = i
+			break
+		}
+	}
+	if pos == n && len(expected) == len(provided) {
+		return
+	}
+	fmt.Printf("%s^ first difference at byte %d", strings.Repeat(" ", len("Provided v1: ")+pos), pos/2)
+	if len(expected) != len(provided) {
+		fmt.Printf(" (length %d, expected %d)", len(provided), len(expected))
+	}
+	fmt.Println()
+}
+
+// diagnoseSignature retries the signature against common transformations of
+// the body and secret and reports the first one that matches.
+func diagnoseSignature(secret string, sig *parsedSignature, body []byte) string {
+	matches := func(s string, b []byte) bool {
+		expected := computeSignature(s, sig.Timestamp, b)
+		for _, provided := range sig.Signatures {
+			if hmac.Equal([]byte(provided), []byte(expected)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	type variant struct {
+		secret string
+		body   []byte
+		hint   string
+	}
+	variants := []variant{
+		{secret, bytes.TrimRight(body, "\r\n"), "The body had a trailing newline added after signing. Verify against the raw bytes received."},
+		{secret, append(append([]byte{}, body...), '\n'), "The body lost a trailing newline. Verify against the raw bytes received."},
+		{secret, bytes.ReplaceAll(body, []byte("\n"), []byte("\r\n")), "Line endings were converted from CRLF to LF. Verify against the raw bytes received."},
+		{secret, bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), "The body contains a UTF-8 BOM that was not present when signed."},
+		{strings.TrimPrefix(secret, "whsec_"), body, "The signature was computed with the secret minus its whsec_ prefix."},
+		{"whsec_" + secret, body, "The signature was computed with the full whsec_-prefixed secret."},
+		{strings.TrimSpace(secret), body, "The secret contains leading or trailing whitespace."},
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		variants = append(variants, variant{secret, compact.Bytes(), "The body was re-serialized (pretty-printed) after signing. Verify against the raw bytes, not parsed-and-reencoded JSON."})
+	}
+	var decoded interface{}
+	if json.Unmarshal(body, &decoded) == nil {
+		if reencoded, err := json.Marshal(decoded); err == nil {
+			variants = append(variants, variant{secret, reencoded, "The body was parsed and re-encoded (key order or spacing changed). Verify against the raw bytes."})
+		}
+	}
+
+	for _, v := range variants {
+		if v.secret == secret && bytes.Equal(v.body, body) {
+			continue
+		}
+		if matches(v.secret, v.body) {
+			return v.hint
+		}
+	}
+	return ""
+}
+
+func init() {
+	webhooksCmd.AddCommand(webhooksDebugSignatureCmd)
+	webhooksCmd.AddCommand(webhooksVerifyCmd)
+
+	webhooksVerifyCmd.Flags().String("payload", "", "File with the raw request body, or - for stdin")
+	webhooksVerifyCmd.Flags().String("signature", "", "Sapliy-Signature header value (t=...,v1=...)")
+	webhooksVerifyCmd.Flags().String("secret", "", "Endpoint signing secret (default $SAPLIY_WEBHOOK_SECRET)")
+	webhooksVerifyCmd.Flags().Duration("tolerance", signatureTolerance, "Maximum age of the timestamp (0 disables the check)")
+	webhooksVerifyCmd.MarkFlagRequired("payload")
+	webhooksVerifyCmd.MarkFlagRequired("signature")
+
+	webhooksDebugSignatureCmd.Flags().String("secret", "", "Endpoint signing secret (whsec_...)")
+	webhooksDebugSignatureCmd.Flags().String("request", "", "Captured raw HTTP request (@file or @- for stdin)")
+	webhooksDebugSignatureCmd.Flags().String("header", "", "Signature header value (overrides the one in --request)")
+	webhooksDebugSignatureCmd.Flags().String("payload", "", "Raw request body (@file, overrides the one in --request)")
+}
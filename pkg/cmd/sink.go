@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// eventSink receives every event a streaming command emits, in addition to
+// the terminal output. Events are written as frames of a 4-byte big-endian
+// length followed by the raw JSON payload, so consumers never have to parse
+// decorated stdout.
+type eventSink interface {
+	Send(event []byte) error
+	Close() error
+}
+
+// openSink creates a sink from a URL such as unix:///tmp/sapliy.sock or
+// fifo:///tmp/sapliy.pipe.
+func openSink(spec string) (eventSink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+	}
+
+	path := u.Path
+	if path == "" {
+		path = u.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sink %q has no path", spec)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		return newUnixSink(path)
+	case "fifo":
+		return newFifoSink(path)
+	default:
+		return nil, fmt.Errorf("unsupported sink scheme %q (use unix:// or fifo://)", u.Scheme)
+	}
+}
+
+// sinkSet is the group of sinks a streaming command writes to. It can be
+// updated while the command runs, e.g. after a config reload.
+type sinkSet struct {
+	mu    sync.Mutex
+	specs []string
+	sinks map[string]eventSink
+}
+
+func newSinkSet(specs []string) (*sinkSet, error) {
+	s := &sinkSet{sinks: make(map[string]eventSink)}
+	if err := s.update(specs); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// update opens sinks that are new in specs and closes the ones no longer
+// listed. If any new sink fails to open the set is left unchanged.
+func (s *sinkSet) update(specs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	opened := make(map[string]eventSink)
+	for _, spec := range specs {
+		if _, ok := s.sinks[spec]; ok {
+			continue
+		}
+		if _, ok := opened[spec]; ok {
+			continue
+		}
+		sink, err := openSink(spec)
+		if err != nil {
+			for _, o := range opened {
+				o.Close()
+			}
+			return err
+		}
+		opened[spec] = sink
+	}
+
+	next := make(map[string]eventSink, len(specs))
+	for _, spec := range specs {
+		if sink, ok := s.sinks[spec]; ok {
+			next[spec] = sink
+		} else if sink, ok := opened[spec]; ok {
+			next[spec] = sink
+		}
+	}
+	for spec, sink := range s.sinks {
+		if _, ok := next[spec]; !ok {
+			sink.Close()
+		}
+	}
+
+	s.specs = append([]string(nil), specs...)
+	s.sinks = next
+	return nil
+}
+
+func (s *sinkSet) current() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.specs...)
+}
+
+func (s *sinkSet) send(event []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for spec, sink := range s.sinks {
+		if err := sink.Send(event); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  sink %s: %v\n", spec, err)
+		}
+	}
+}
+
+func (s *sinkSet) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sink := range s.sinks {
+		sink.Close()
+	}
+	s.sinks = nil
+}
+
+func writeFrame(w io.Writer, payload []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(payload)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// unixSink listens on a Unix domain socket and broadcasts each event to all
+// connected clients. Slow clients are dropped rather than stalling the stream.
+type unixSink struct {
+	path     string
+	listener net.Listener
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+	closed  bool
+}
+
+const sinkWriteTimeout = 2 * time.Second
+
+func newUnixSink(path string) (*unixSink, error) {
+	// Remove a stale socket left behind by a previous run.
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+
+	s := &unixSink{path: path, listener: l, clients: make(map[net.Conn]struct{})}
+	go s.accept()
+	return s, nil
+}
+
+func (s *unixSink) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			// Accepted just before Close; there is nothing to stream to it.
+			conn.Close()
+		} else {
+			s.clients[conn] = struct{}{}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *unixSink) Send(event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.clients {
+		conn.SetWriteDeadline(time.Now().Add(sinkWriteTimeout))
+		if err := writeFrame(conn, event); err != nil {
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+	return nil
+}
+
+func (s *unixSink) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.clients {
+		conn.Close()
+		delete(s.clients, conn)
+	}
+	s.mu.Unlock()
+
+	os.Remove(s.path)
+	return err
+}
+
+// fifoSink writes frames to a named pipe. Opening blocks until a reader
+// attaches, so the pipe is opened lazily on the first event.
+type fifoSink struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newFifoSink(path string) (*fifoSink, error) {
+	if err := ensureFifo(path); err != nil {
+		return nil, err
+	}
+	return &fifoSink{path: path}, nil
+}
+
+func (s *fifoSink) Send(event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		f, err := openFifoWriter(s.path)
+		if err != nil {
+			// No reader attached yet; drop the event.
+			return nil
+		}
+		s.f = f
+	}
+
+	// A reader that stops draining the pipe must not stall the stream.
+	s.f.SetWriteDeadline(time.Now().Add(sinkWriteTimeout))
+	if err := writeFrame(s.f, event); err != nil {
+		// The reader went away or fell behind; reopen on the next event.
+		s.f.Close()
+		s.f = nil
+		return fmt.Errorf("write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *fifoSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f != nil {
+		return s.f.Close()
+	}
+	return nil
+}
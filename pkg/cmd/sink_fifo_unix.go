@@ -0,0 +1,29 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func ensureFifo(path string) error {
+	fi, err := os.Stat(path)
+	if err == nil {
+		if fi.Mode()&os.ModeNamedPipe == 0 {
+			return fmt.Errorf("%s exists and is not a named pipe", path)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return syscall.Mkfifo(path, 0600)
+}
+
+// openFifoWriter opens the pipe without blocking; it fails with ENXIO while
+// no process has the pipe open for reading.
+func openFifoWriter(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+}
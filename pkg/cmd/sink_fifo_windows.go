@@ -0,0 +1,16 @@
+//go:build windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+func ensureFifo(path string) error {
+	return fmt.Errorf("fifo sinks are not supported on Windows, use unix:// instead")
+}
+
+func openFifoWriter(path string) (*os.File, error) {
+	return nil, fmt.Errorf("fifo sinks are not supported on Windows")
+}
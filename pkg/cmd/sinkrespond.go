@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sinkStep is one answer in a --respond sequence: a status code, how long
+// to wait before sending it, and how many attempts in a row it covers.
+type sinkStep struct {
+	status  int
+	latency time.Duration
+	times   int
+}
+
+// sinkRule scripts the sink's answers for the event types matching pattern.
+// Attempts are counted per event, so a platform retrying one delivery walks
+// through the steps in order; the last step repeats once they run out.
+type sinkRule struct {
+	pattern string
+	steps   []sinkStep
+}
+
+// parseSinkRule reads a --respond value: TYPE=STEP[,STEP...] where each STEP
+// is STATUS[xN][ after DURATION], e.g. "payment.failed=503 after 2s" or
+// "payment.*=500x2,200". TYPE may be an exact type, a namespace such as
+// payment.* or * for every event.
+func parseSinkRule(s string) (*sinkRule, error) {
+	pattern, script, ok := strings.Cut(s, "=")
+	pattern = strings.TrimSpace(pattern)
+	if !ok || pattern == "" || strings.TrimSpace(script) == "" {
+		return nil, fmt.Errorf("invalid --respond %q (use TYPE=STATUS[xN][ after DURATION][,...])", s)
+	}
+	rule := &sinkRule{pattern: pattern}
+	for _, part := range strings.Split(script, ",") {
+		step, err := parseSinkStep(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --respond %q: %v", s, err)
+		}
+		rule.steps = append(rule.steps, step)
+	}
+	return rule, nil
+}
+
+func parseSinkStep(s string) (sinkStep, error) {
+	step := sinkStep{times: 1}
+	code, wait, hasWait := strings.Cut(s, " after ")
+	code = strings.TrimSpace(code)
+	if hasWait {
+		d, err := time.ParseDuration(strings.TrimSpace(wait))
+		if err != nil || d < 0 {
+			return step, fmt.Errorf("bad delay %q", strings.TrimSpace(wait))
+		}
+		step.latency = d
+	}
+	if c, n, ok := strings.Cut(code, "x"); ok {
+		times, err := strconv.Atoi(n)
+		if err != nil || times < 1 {
+			return step, fmt.Errorf("bad repeat count %q", n)
+		}
+		code, step.times = c, times
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return step, fmt.Errorf("bad status %q", code)
+	}
+	step.status = status
+	return step, nil
+}
+
+// step returns the answer for the given attempt, counting from 1.
+func (r *sinkRule) step(attempt int) sinkStep {
+	for _, s := range r.steps {
+		if attempt <= s.times {
+			return s
+		}
+		attempt -= s.times
+	}
+	return r.steps[len(r.steps)-1]
+}
+
+func (r *sinkRule) String() string {
+	parts := make([]string, len(r.steps))
+	for i, s := range r.steps {
+		parts[i] = strconv.Itoa(s.status)
+		if s.times > 1 {
+			parts[i] += "x" + strconv.Itoa(s.times)
+		}
+		if s.latency > 0 {
+			parts[i] += " after " + s.latency.String()
+		}
+	}
+	return r.pattern + " → " + strings.Join(parts, ", then ")
+}
+
+// sinkScript picks the scripted answer for each request and counts the
+// attempts per event.
+type sinkScript struct {
+	rules []*sinkRule
+
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+// respond returns the answer for a delivery of eventID, which attempt this
+// is, and whether any rule matched eventType. Requests without an event ID
+// are counted per type.
+func (s *sinkScript) respond(eventType, eventID string) (sinkStep, int, bool) {
+	for _, r := range s.rules {
+		if !matchesEventTypes(eventType, []string{r.pattern}) {
+			continue
+		}
+		key := r.pattern + "\x00" + firstNonEmpty(eventID, eventType)
+		s.mu.Lock()
+		if s.attempts == nil {
+			s.attempts = map[string]int{}
+		}
+		s.attempts[key]++
+		attempt := s.attempts[key]
+		s.mu.Unlock()
+		return r.step(attempt), attempt, true
+	}
+	return sinkStep{}, 0, false
+}
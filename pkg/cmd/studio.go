@@ -0,0 +1,294 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The Studio is a static web app. Release builds embed it (go build -tags
+// studio_embed, see studio_embed.go); other builds, including go install,
+// serve a copy that 'sapliy studio install' downloads into the user config
+// directory. Either way the assets are the version pinned below.
+//
+// Both are variables so a release build can pin another Studio with -X.
+var (
+	studioVersion = "1.0.0"
+	// studioDigest is the studioTreeDigest of the pinned assets. It
+	// covers the files rather than the archive, so a mirror may repack
+	// them.
+	studioDigest = "sha256:9ff3c63f4d6ed8e1f6b51cd72ea261f0fe487db248cb8563386a826b4fdac4de"
+)
+
+// defaultStudioURL is where studio install downloads from, with the version
+// in place of %s. The studio.url config key points it at a mirror.
+const defaultStudioURL = "https://github.com/sapliy/sapliy-cli/releases/download/studio-v%s/sapliy-studio-%s.tar.gz"
+
+// maxStudioArchiveBytes bounds what studio install extracts.
+const maxStudioArchiveBytes = 256 << 20
+
+var errStudioNotInstalled = errors.New("the Studio is not installed; run 'sapliy studio install'")
+
+// studioDir is where the pinned Studio version is installed.
+func studioDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sapliy", "studio", studioVersion), nil
+}
+
+// studioAssets returns the Studio's files: the embedded ones in a release
+// build, otherwise the installed ones.
+func studioAssets() (fs.FS, error) {
+	if fsys := embeddedStudioAssets(); fsys != nil {
+		return fsys, nil
+	}
+	dir, err := studioDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		return nil, errStudioNotInstalled
+	}
+	return os.DirFS(dir), nil
+}
+
+// studioTreeDigest hashes every regular file in fsys with its path, in
+// path order, so the same files give the same digest however they were
+// packed.
+func studioTreeDigest(fsys fs.FS) (string, error) {
+	tree := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		fmt.Fprintf(tree, "%s\x00%x\n", p, h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(tree.Sum(nil)), nil
+}
+
+// downloadStudio saves the Studio archive to a temporary file, which the
+// caller removes.
+func downloadStudio(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	// Only the pool, proxy and CAs of API requests: the download is not
+	// an API call.
+	client := &http.Client{Timeout: 10 * time.Minute, Transport: apiTransport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", rawURL, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "sapliy-studio-*.tar.gz")
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxStudioArchiveBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxStudioArchiveBytes {
+		err = fmt.Errorf("archive is larger than %d MB", maxStudioArchiveBytes>>20)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// extractStudio unpacks a .tar.gz of the Studio into dir. Entries that
+// would land outside dir are refused.
+func extractStudio(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "" || name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q is outside the Studio directory", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if total += hdr.Size; total > maxStudioArchiveBytes {
+				return fmt.Errorf("archive is larger than %d MB unpacked", maxStudioArchiveBytes>>20)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			// Links and devices have no place in a static site.
+			return fmt.Errorf("archive entry %q is not a file or directory", hdr.Name)
+		}
+	}
+}
+
+// installStudio unpacks archive next to dir and moves it into place once it
+// is verified, so a failed install leaves nothing half-written.
+func installStudio(archive, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".install-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractStudio(archive, tmp); err != nil {
+		return err
+	}
+	digest, err := studioTreeDigest(os.DirFS(tmp))
+	if err != nil {
+		return err
+	}
+	if digest != studioDigest {
+		return fmt.Errorf("checksum mismatch: the archive is not Studio %s (expected %s, got %s)", studioVersion, studioDigest, digest)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "index.html")); err != nil {
+		return errors.New("the archive has no index.html at its top level")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+var studioCmd = &cobra.Command{
+	Use:   "studio",
+	Short: "Manage the Sapliy Automation Studio assets",
+	Long: `The Studio that 'sapliy run' and 'sapliy dev' serve is a static web app.
+Release builds include it; other builds, such as go install, download it once
+with 'sapliy studio install'.`,
+}
+
+var studioInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Download the Studio assets this CLI version uses",
+	Long: `Download the Studio version this CLI is pinned to into the user config
+directory, and check it against the pinned checksum before using it.
+
+--file installs from an archive downloaded beforehand, e.g. on a machine
+without internet access; it is checked the same way. The studio.url config
+key points downloads at a mirror (%s stands for the version).`,
+	Example: `  sapliy studio install
+  sapliy studio install --file sapliy-studio-1.0.0.tar.gz`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if embeddedStudioAssets() != nil {
+			fmt.Println("✅ This build includes the Studio; there is nothing to install.")
+			return
+		}
+		dir, err := studioDir()
+		if err != nil {
+			fmt.Printf("❌ Failed to find the config directory: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := studioAssets(); err == nil && !force {
+			fmt.Printf("✅ Studio %s is already installed in %s (use --force to reinstall)\n", studioVersion, dir)
+			return
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+		archive := file
+		if archive == "" {
+			rawURL := strings.ReplaceAll(firstNonEmpty(viper.GetString("studio.url"), defaultStudioURL), "%s", studioVersion)
+			fmt.Printf("⬇️  Downloading Studio %s from %s\n", studioVersion, rawURL)
+			archive, err = downloadStudio(ctx, rawURL)
+			if err != nil {
+				fmt.Printf("❌ Failed to download the Studio: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		err = installStudio(archive, dir)
+		if file == "" {
+			os.Remove(archive)
+		}
+		if err != nil {
+			fmt.Printf("❌ Failed to install the Studio: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Installed Studio %s in %s\n", studioVersion, dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(studioCmd)
+	studioCmd.AddCommand(studioInstallCmd)
+	studioInstallCmd.Flags().String("file", "", "Install from this .tar.gz instead of downloading it")
+	studioInstallCmd.Flags().BoolP("force", "f", false, "Reinstall even if already installed")
+}
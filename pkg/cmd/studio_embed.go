@@ -0,0 +1,19 @@
+//go:build studio_embed && !headless
+
+package cmd
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Release builds embed the Studio, so it works without 'studio install'.
+// The ui directory must hold the pinned Studio version's files.
+//
+//go:embed ui/*
+var studioFiles embed.FS
+
+func embeddedStudioAssets() fs.FS {
+	fsys, _ := fs.Sub(studioFiles, "ui")
+	return fsys
+}
@@ -0,0 +1,9 @@
+//go:build !studio_embed && !headless
+
+package cmd
+
+import "io/fs"
+
+// embeddedStudioAssets returns nil: this build serves the Studio 'sapliy
+// studio install' downloaded.
+func embeddedStudioAssets() fs.FS { return nil }
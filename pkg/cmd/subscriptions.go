@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// subscriptionStatuses are the statuses subscriptions list --status accepts.
+var subscriptionStatuses = []string{"trialing", "active", "past_due", "paused", "canceled", "unpaid"}
+
+// subscription bills a customer for a price on a recurring interval.
+type subscription struct {
+	ID                string     `json:"id"`
+	Customer          string     `json:"customer"`
+	Price             string     `json:"price"`
+	Plan              string     `json:"plan,omitempty"`
+	Quantity          int        `json:"quantity"`
+	Status            string     `json:"status"`
+	TrialEnd          *time.Time `json:"trialEnd,omitempty"`
+	CurrentPeriodEnd  *time.Time `json:"currentPeriodEnd,omitempty"`
+	CancelAtPeriodEnd bool       `json:"cancelAtPeriodEnd,omitempty"`
+	ResumesAt         *time.Time `json:"resumesAt,omitempty"`
+	CreatedAt         *time.Time `json:"createdAt,omitempty"`
+}
+
+func subscriptionPath(subscriptionID, suffix string) string {
+	return "/v1/subscriptions/" + url.PathEscape(subscriptionID) + suffix
+}
+
+func printSubscription(s *subscription) {
+	fmt.Printf("🔁 Subscription %s — %s\n", s.ID, s.Status)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Customer:      %s\n", s.Customer)
+	price := s.Price
+	if s.Plan != "" {
+		price += " (" + s.Plan + ")"
+	}
+	fmt.Printf("Price:         %s × %d\n", price, s.Quantity)
+	if s.TrialEnd != nil {
+		fmt.Printf("Trial ends:    %s\n", s.TrialEnd.Local().Format(time.RFC1123))
+	}
+	if s.CurrentPeriodEnd != nil {
+		label := "Renews:"
+		if s.CancelAtPeriodEnd {
+			label = "Ends:"
+		}
+		fmt.Printf("%-14s %s\n", label, s.CurrentPeriodEnd.Local().Format(time.RFC1123))
+	}
+	if s.ResumesAt != nil {
+		fmt.Printf("Resumes:       %s\n", s.ResumesAt.Local().Format(time.RFC1123))
+	}
+}
+
+var subscriptionsCmd = &cobra.Command{
+	Use:   "subscriptions",
+	Short: "Manage recurring-billing subscriptions",
+}
+
+var subscriptionsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Subscribe a customer to a price",
+	Long: `Subscribe a customer to a recurring price. --price takes a price ID; --plan
+takes a plan ID and uses its default price. With --trial-days (or
+--trial-end) the first invoice is only raised when the trial ends.`,
+	Example: `  sapliy subscriptions create --customer cus_123 --price price_pro_monthly
+  sapliy subscriptions create --customer cus_123 --plan plan_pro --trial-days 14
+  sapliy subscriptions create --customer cus_123 --price price_seat --quantity 5 --trial-end 2026-12-01`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		flags := cmd.Flags()
+		customerID, _ := flags.GetString("customer")
+		price, _ := flags.GetString("price")
+		plan, _ := flags.GetString("plan")
+		quantity, _ := flags.GetInt("quantity")
+		trialDays, _ := flags.GetInt("trial-days")
+		trialEnd, _ := flags.GetString("trial-end")
+		if (price == "") == (plan == "") {
+			fmt.Println("Error: pass exactly one of --price or --plan.")
+			os.Exit(1)
+		}
+		if quantity < 1 {
+			fmt.Println("Error: --quantity must be at least 1.")
+			os.Exit(1)
+		}
+		if trialDays < 0 {
+			fmt.Println("Error: --trial-days cannot be negative.")
+			os.Exit(1)
+		}
+		if trialDays > 0 && trialEnd != "" {
+			fmt.Println("Error: pass --trial-days or --trial-end, not both.")
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{"customer": customerID, "quantity": quantity}
+		if price != "" {
+			req["price"] = price
+		} else {
+			req["plan"] = plan
+		}
+		if trialDays > 0 {
+			req["trialPeriodDays"] = trialDays
+		}
+		if trialEnd != "" {
+			t, err := time.ParseInLocation("2006-01-02", trialEnd, time.Local)
+			if err != nil {
+				t, err = time.Parse(time.RFC3339, trialEnd)
+			}
+			if err != nil || !t.After(time.Now()) {
+				fmt.Printf("Error: invalid --trial-end %q (use a future date like 2026-12-01 or an RFC 3339 time)\n", trialEnd)
+				os.Exit(1)
+			}
+			req["trialEnd"] = t.UTC().Format(time.RFC3339)
+		}
+
+		var s subscription
+		if err := apiRequest(context.Background(), http.MethodPost, "/v1/subscriptions", req, &s); err != nil {
+			fmt.Printf("❌ Failed to create subscription: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(s, func() {
+			printSubscription(&s)
+			fmt.Println("\n✅ Subscription created!")
+		})
+	},
+}
+
+var subscriptionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subscriptions",
+	Example: `  sapliy subscriptions list --customer cus_123
+  sapliy subscriptions list --status trialing --all -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		customerID, _ := cmd.Flags().GetString("customer")
+		price, _ := cmd.Flags().GetString("price")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "" && !slices.Contains(subscriptionStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(subscriptionStatuses, ", "))
+			os.Exit(1)
+		}
+
+		q := url.Values{"limit": {strconv.Itoa(limit)}}
+		for key, v := range map[string]string{"status": status, "customer": customerID, "price": price} {
+			if v != "" {
+				q.Set(key, v)
+			}
+		}
+		subs, cursor, err := listPages[subscription](context.Background(), "/v1/subscriptions", q, cursor, all)
+		if err != nil {
+			fmt.Printf("Error listing subscriptions: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(subs, func() {
+			if len(subs) == 0 {
+				fmt.Println("No subscriptions found.")
+				return
+			}
+
+			fmt.Printf("%-24s %-20s %-24s %4s %-10s %s\n", "ID", "CUSTOMER", "PRICE", "QTY", "STATUS", "PERIOD END")
+			fmt.Println(strings.Repeat("─", 100))
+			for _, s := range subs {
+				periodEnd := ""
+				if s.CurrentPeriodEnd != nil {
+					periodEnd = s.CurrentPeriodEnd.Local().Format("2006-01-02")
+				}
+				if s.CancelAtPeriodEnd {
+					periodEnd += " (ends)"
+				}
+				fmt.Printf("%-24s %-20s %-24s %4d %-10s %s\n", s.ID, s.Customer, truncate(s.Price, 24), s.Quantity, s.Status, periodEnd)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore subscriptions available: --cursor %s (or --all)\n", cursor)
+		}
+	},
+}
+
+var subscriptionsCancelCmd = &cobra.Command{
+	Use:   "cancel [subscription_id]",
+	Short: "Cancel a subscription",
+	Long: `Cancel a subscription immediately, or with --at-period-end let it run until
+the end of the period that has already been paid for.`,
+	Example: `  sapliy subscriptions cancel sub_123
+  sapliy subscriptions cancel sub_123 --at-period-end --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		atPeriodEnd, _ := cmd.Flags().GetBool("at-period-end")
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			when := "now"
+			if atPeriodEnd {
+				when = "at the end of the current period"
+			}
+			if !confirm(fmt.Sprintf("Cancel subscription %s %s? [y/N]: ", args[0], when)) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		req := map[string]interface{}{"atPeriodEnd": atPeriodEnd}
+		var s subscription
+		if err := apiRequest(context.Background(), http.MethodPost, subscriptionPath(args[0], "/cancel"), req, &s); err != nil {
+			fmt.Printf("❌ Failed to cancel subscription: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(s, func() {
+			if s.CancelAtPeriodEnd && s.CurrentPeriodEnd != nil {
+				fmt.Printf("🛑 Subscription %s ends on %s\n", s.ID, s.CurrentPeriodEnd.Local().Format("Jan 02, 2006"))
+			} else {
+				fmt.Printf("🛑 Subscription %s canceled\n", s.ID)
+			}
+		})
+	},
+}
+
+var subscriptionsPauseCmd = &cobra.Command{
+	Use:   "pause [subscription_id]",
+	Short: "Pause billing on a subscription",
+	Long: `Pause a subscription: no invoices are raised while it is paused. It stays
+paused until 'resume', or until --resumes-at if given.`,
+	Example: `  sapliy subscriptions pause sub_123
+  sapliy subscriptions pause sub_123 --resumes-at 2026-11-01`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		req := map[string]interface{}{}
+		if resumesAt, _ := cmd.Flags().GetString("resumes-at"); resumesAt != "" {
+			t, err := time.ParseInLocation("2006-01-02", resumesAt, time.Local)
+			if err != nil || !t.After(time.Now()) {
+				fmt.Printf("Error: invalid --resumes-at %q (use a future date like 2026-11-01)\n", resumesAt)
+				os.Exit(1)
+			}
+			req["resumesAt"] = t.UTC().Format(time.RFC3339)
+		}
+
+		var s subscription
+		if err := apiRequest(context.Background(), http.MethodPost, subscriptionPath(args[0], "/pause"), req, &s); err != nil {
+			fmt.Printf("❌ Failed to pause subscription: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(s, func() {
+			fmt.Printf("⏸️  Subscription %s paused\n", s.ID)
+			if s.ResumesAt != nil {
+				fmt.Printf("   Resumes on %s\n", s.ResumesAt.Local().Format("Jan 02, 2006"))
+			}
+		})
+	},
+}
+
+var subscriptionsResumeCmd = &cobra.Command{
+	Use:   "resume [subscription_id]",
+	Short: "Resume a paused subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: Not authenticated. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		var s subscription
+		if err := apiRequest(context.Background(), http.MethodPost, subscriptionPath(args[0], "/resume"), nil, &s); err != nil {
+			fmt.Printf("❌ Failed to resume subscription: %v\n", err)
+			os.Exit(1)
+		}
+		printOutput(s, func() {
+			fmt.Printf("▶️  Subscription %s resumed (%s)\n", s.ID, s.Status)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(subscriptionsCmd)
+	subscriptionsCmd.AddCommand(subscriptionsCreateCmd)
+	subscriptionsCmd.AddCommand(subscriptionsListCmd)
+	subscriptionsCmd.AddCommand(subscriptionsCancelCmd)
+	subscriptionsCmd.AddCommand(subscriptionsPauseCmd)
+	subscriptionsCmd.AddCommand(subscriptionsResumeCmd)
+
+	subscriptionsCreateCmd.Flags().String("customer", "", "Customer to subscribe (cus_...)")
+	subscriptionsCreateCmd.Flags().String("price", "", "Recurring price ID")
+	subscriptionsCreateCmd.Flags().String("plan", "", "Plan ID (uses the plan's default price)")
+	subscriptionsCreateCmd.Flags().Int("quantity", 1, "Number of units, e.g. seats")
+	subscriptionsCreateCmd.Flags().Int("trial-days", 0, "Free trial length in days")
+	subscriptionsCreateCmd.Flags().String("trial-end", "", "End the free trial on this date instead")
+	subscriptionsCreateCmd.MarkFlagRequired("customer")
+
+	subscriptionsListCmd.Flags().IntP("limit", "l", 20, "Number of subscriptions per page")
+	subscriptionsListCmd.Flags().StringP("status", "s", "", "Filter by status ("+strings.Join(subscriptionStatuses, ", ")+")")
+	subscriptionsListCmd.Flags().String("customer", "", "Filter by customer ID")
+	subscriptionsListCmd.Flags().String("price", "", "Filter by price ID")
+	subscriptionsListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	subscriptionsListCmd.Flags().Bool("all", false, "Fetch every page")
+
+	subscriptionsCancelCmd.Flags().Bool("at-period-end", false, "Keep it active until the end of the current period")
+	subscriptionsCancelCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+
+	subscriptionsPauseCmd.Flags().String("resumes-at", "", "Resume automatically on this date")
+}
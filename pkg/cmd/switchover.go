@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// zoneHealth is the API's view of whether a zone can take live traffic.
+type zoneHealth struct {
+	Healthy bool `json:"healthy"`
+	Checks  []struct {
+		Name    string `json:"name"`
+		OK      bool   `json:"ok"`
+		Message string `json:"message,omitempty"`
+	} `json:"checks"`
+}
+
+// failedChecks summarizes the checks that did not pass.
+func (h *zoneHealth) failedChecks() string {
+	var failed []string
+	for _, c := range h.Checks {
+		if !c.OK {
+			failed = append(failed, c.Name+": "+c.Message)
+		}
+	}
+	if len(failed) == 0 {
+		return "zone reported unhealthy"
+	}
+	return strings.Join(failed, "; ")
+}
+
+// zoneSwitchover moves the account's triggers and webhook endpoints from one
+// zone to another in a single transaction, so no event is routed to both or
+// neither.
+type zoneSwitchover struct {
+	ID        string `json:"id,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Triggers  int    `json:"triggers"`
+	Endpoints int    `json:"endpoints"`
+	Status    string `json:"status,omitempty"`
+	// RolledBack is set when verification failed and traffic was moved
+	// back to From.
+	RolledBack bool   `json:"rolledBack,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func checkZoneHealth(ctx context.Context, zone string) (*zoneHealth, error) {
+	var h zoneHealth
+	if err := apiRequest(ctx, http.MethodGet, zonePath(zone, "/health"), nil, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// verifyZone polls a zone's health until it has been healthy for the whole
+// window, failing on the first unhealthy report.
+func verifyZone(ctx context.Context, zone string, window, interval time.Duration, log func(string, ...interface{})) error {
+	deadline := time.Now().Add(window)
+	for {
+		h, err := checkZoneHealth(ctx, zone)
+		if err != nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+		if !h.Healthy {
+			return fmt.Errorf("%s", h.failedChecks())
+		}
+		if time.Now().After(deadline) {
+			return nil
+		}
+		log("   ✓ healthy, watching for %s more\n", time.Until(deadline).Round(time.Second))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+var zonesSwitchoverCmd = &cobra.Command{
+	Use:   "switchover",
+	Short: "Move triggers and endpoints from one zone to another (blue/green)",
+	Long: `Re-point the triggers and webhook endpoints that route to --from so they
+route to --to instead. The move is a single API transaction.
+
+With --verify, --to must be healthy before the switch and stay healthy for
+--verify-window afterwards. If it does not, the switchover is rolled back
+automatically and the command exits with status 1.`,
+	Example: `  sapliy zones switchover --from zone_blue --to zone_green --verify
+  sapliy zones switchover --from zone_green --to zone_blue --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		verify, _ := cmd.Flags().GetBool("verify")
+		window, _ := cmd.Flags().GetDuration("verify-window")
+		force, _ := cmd.Flags().GetBool("force")
+		if from == to {
+			fmt.Println("Error: --from and --to must be different zones.")
+			os.Exit(1)
+		}
+
+		// Progress goes to stderr so -o json output stays parseable.
+		log := func(format string, a ...interface{}) { fmt.Fprintf(os.Stderr, format, a...) }
+		ctx := context.Background()
+
+		if verify {
+			log("🩺 Checking %s before switching...\n", to)
+			h, err := checkZoneHealth(ctx, to)
+			if err != nil {
+				fmt.Printf("❌ Health check failed: %v\n", err)
+				os.Exit(1)
+			}
+			if !h.Healthy {
+				fmt.Printf("❌ %s is not healthy, nothing was changed: %s\n", to, h.failedChecks())
+				os.Exit(1)
+			}
+		}
+
+		if !force {
+			if !confirm(fmt.Sprintf("Route all triggers and endpoints from %s to %s? [y/N]: ", from, to)) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		var so zoneSwitchover
+		if err := apiRequest(ctx, http.MethodPost, "/v1/zones/switchovers", &zoneSwitchover{From: from, To: to}, &so); err != nil {
+			fmt.Printf("❌ Switchover failed, nothing was changed: %v\n", err)
+			os.Exit(1)
+		}
+		log("🔀 Moved %d trigger(s) and %d endpoint(s) from %s to %s\n", so.Triggers, so.Endpoints, from, to)
+
+		if verify {
+			log("🩺 Verifying %s for %s...\n", to, window)
+			if err := verifyZone(ctx, to, window, 10*time.Second, log); err != nil {
+				log("❌ Verification failed: %v\n", err)
+				log("↩️  Rolling back to %s...\n", from)
+				if rbErr := apiRequest(ctx, http.MethodPost, "/v1/zones/switchovers/"+url.PathEscape(so.ID)+"/rollback", nil, nil); rbErr != nil {
+					fmt.Printf("❌ Rollback failed: %v\n", rbErr)
+					fmt.Printf("   Traffic is still on %s. Roll back by hand with 'sapliy zones switchover --from %s --to %s --force'.\n", to, to, from)
+					os.Exit(1)
+				}
+				so.RolledBack, so.Reason = true, err.Error()
+				printOutput(so, func() {
+					fmt.Printf("↩️  Rolled back: traffic is on %s again.\n", from)
+				})
+				os.Exit(1)
+			}
+		}
+
+		printOutput(so, func() {
+			fmt.Printf("✅ Switched over to %s\n", to)
+			fmt.Printf("   Switch back with 'sapliy zones switchover --from %s --to %s'\n", to, from)
+		})
+	},
+}
+
+func init() {
+	zonesCmd.AddCommand(zonesSwitchoverCmd)
+
+	zonesSwitchoverCmd.Flags().String("from", "", "Zone currently receiving traffic (blue)")
+	zonesSwitchoverCmd.Flags().String("to", "", "Zone to move traffic to (green)")
+	zonesSwitchoverCmd.Flags().Bool("verify", false, "Check --to is healthy before and after switching, and roll back if not")
+	zonesSwitchoverCmd.Flags().Duration("verify-window", time.Minute, "How long --to must stay healthy after switching")
+	zonesSwitchoverCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	zonesSwitchoverCmd.MarkFlagRequired("from")
+	zonesSwitchoverCmd.MarkFlagRequired("to")
+}
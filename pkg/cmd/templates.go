@@ -81,7 +81,7 @@ var templatesApplyCmd = &cobra.Command{
 			return
 		}
 
-		client := fintech.NewClient(apiKey, fintech.WithBaseURL(viper.GetString("api_url")))
+		client := newClient()
 		orgID := viper.GetString("org_id")
 
 		// Step 1: Create the zone
@@ -128,7 +128,7 @@ var templatesApplyCmd = &cobra.Command{
 		fmt.Printf("Configured %d flow(s) and %d webhook endpoint(s)\n", flows, webhooks)
 		fmt.Println()
 		fmt.Println("Next steps:")
-		fmt.Printf("  1. Switch to zone: sapliy zones switch %s\n", zone.ID)
+		fmt.Printf("  1. Switch to zone: sapliy zones use %s\n", zone.ID)
 		fmt.Println("  2. List flows: sapliy flows list")
 		fmt.Println("  3. Start debugging: sapliy debug listen")
 	},
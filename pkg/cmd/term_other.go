@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package cmd
+
+import "errors"
+
+// makeRaw is not supported here; prompts fall back to reading whole lines,
+// which on Windows still get the console's own line editing.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func terminalWidth(fd int) int {
+	return 0
+}
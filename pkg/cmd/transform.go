@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dop251/goja"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// payloadTransform rewrites an event payload before it is forwarded. A nil
+// result means the event should be dropped.
+type payloadTransform interface {
+	Apply(payload []byte) ([]byte, error)
+}
+
+// loadTransform compiles a user script exposing a transform(event) function.
+// The language is picked from the file extension: .star (Starlark) or .js.
+func loadTransform(path string) (payloadTransform, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".star":
+		return newStarlarkTransform(path, src)
+	case ".js":
+		return newJSTransform(path, src)
+	default:
+		return nil, fmt.Errorf("unsupported transform %s: use a .star (Starlark) or .js script", path)
+	}
+}
+
+type starlarkTransform struct {
+	fn starlark.Callable
+}
+
+func newStarlarkTransform(path string, src []byte) (*starlarkTransform, error) {
+	thread := &starlark.Thread{Name: "transform", Print: starlarkPrint}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, ok := globals["transform"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s must define a transform(event) function", path)
+	}
+	return &starlarkTransform{fn: fn}, nil
+}
+
+func (t *starlarkTransform) Apply(payload []byte) ([]byte, error) {
+	var event interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{Name: "transform", Print: starlarkPrint}
+	result, err := starlark.Call(thread, t.fn, starlark.Tuple{toStarlark(event)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result == starlark.None {
+		return nil, nil
+	}
+
+	out, err := fromStarlark(result)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+func starlarkPrint(_ *starlark.Thread, msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+// toStarlark converts a decoded JSON value into the equivalent Starlark value.
+func toStarlark(v interface{}) starlark.Value {
+	switch val := v.(type) {
+	case nil:
+		return starlark.None
+	case bool:
+		return starlark.Bool(val)
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return starlark.MakeInt64(int64(val))
+		}
+		return starlark.Float(val)
+	case string:
+		return starlark.String(val)
+	case []interface{}:
+		elems := make([]starlark.Value, len(val))
+		for i, e := range val {
+			elems[i] = toStarlark(e)
+		}
+		return starlark.NewList(elems)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := starlark.NewDict(len(val))
+		for _, k := range keys {
+			d.SetKey(starlark.String(k), toStarlark(val[k]))
+		}
+		return d
+	default:
+		return starlark.String(fmt.Sprint(val))
+	}
+}
+
+// fromStarlark converts a Starlark value back into something encoding/json
+// can marshal.
+func fromStarlark(v starlark.Value) (interface{}, error) {
+	switch val := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(val), nil
+	case starlark.Int:
+		if i, ok := val.Int64(); ok {
+			return i, nil
+		}
+		return val.String(), nil
+	case starlark.Float:
+		return float64(val), nil
+	case starlark.String:
+		return string(val), nil
+	case *starlark.List:
+		out := make([]interface{}, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			e, err := fromStarlark(val.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case starlark.Tuple:
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			c, err := fromStarlark(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	case *starlark.Dict:
+		out := make(map[string]interface{}, val.Len())
+		for _, item := range val.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+			e, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			out[string(key)] = e
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to JSON", v.Type())
+	}
+}
+
+type jsTransform struct {
+	vm *goja.Runtime
+	fn goja.Callable
+}
+
+func newJSTransform(path string, src []byte) (*jsTransform, error) {
+	vm := goja.New()
+	console := vm.NewObject()
+	console.Set("log", func(call goja.FunctionCall) goja.Value {
+		args := make([]interface{}, len(call.Arguments))
+		for i, a := range call.Arguments {
+			args[i] = a.Export()
+		}
+		fmt.Fprintln(os.Stderr, args...)
+		return goja.Undefined()
+	})
+	vm.Set("console", console)
+
+	if _, err := vm.RunScript(path, string(src)); err != nil {
+		return nil, err
+	}
+
+	fn, ok := goja.AssertFunction(vm.Get("transform"))
+	if !ok {
+		return nil, fmt.Errorf("%s must define a transform(event) function", path)
+	}
+	return &jsTransform{vm: vm, fn: fn}, nil
+}
+
+func (t *jsTransform) Apply(payload []byte) ([]byte, error) {
+	var event interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	result, err := t.fn(goja.Undefined(), t.vm.ToValue(event))
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(result) || goja.IsNull(result) {
+		return nil, nil
+	}
+	return json.Marshal(result.Export())
+}
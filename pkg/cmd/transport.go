@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// transportProfile sizes the connection pool shared by every API request.
+// Go's default keeps only two idle connections per host, so anything that
+// sends requests concurrently to the API closes and redials connections
+// constantly; the profiles keep enough of them open for the CLI's workers.
+type transportProfile struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps open connections to the API; 0 means no limit.
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+	KeepAlive       time.Duration
+}
+
+// transportProfiles are the presets --transport-profile accepts. bulk is
+// for jobs that send thousands of requests, such as replay-failed or
+// foreach-account with high --concurrency.
+var transportProfiles = map[string]transportProfile{
+	"default": {
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+	},
+	"bulk": {
+		MaxIdleConns:        512,
+		MaxIdleConnsPerHost: 128,
+		MaxConnsPerHost:     128,
+		IdleConnTimeout:     5 * time.Minute,
+		KeepAlive:           15 * time.Second,
+	},
+}
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     *http.Transport
+)
+
+func transportProfileNames() []string {
+	names := make([]string, 0, len(transportProfiles))
+	for name := range transportProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveTransportProfile returns the selected profile with any
+// transport.* overrides from the config file applied.
+func resolveTransportProfile() (string, transportProfile, error) {
+	name := viper.GetString("transport.profile")
+	if name == "" {
+		name = "default"
+	}
+	p, ok := transportProfiles[name]
+	if !ok {
+		return name, p, fmt.Errorf("invalid --transport-profile %q (use %s)", name, strings.Join(transportProfileNames(), ", "))
+	}
+
+	if viper.IsSet("transport.max_idle_conns") {
+		p.MaxIdleConns = viper.GetInt("transport.max_idle_conns")
+	}
+	if viper.IsSet("transport.max_idle_conns_per_host") {
+		p.MaxIdleConnsPerHost = viper.GetInt("transport.max_idle_conns_per_host")
+	}
+	if viper.IsSet("transport.max_conns_per_host") {
+		p.MaxConnsPerHost = viper.GetInt("transport.max_conns_per_host")
+	}
+	for key, d := range map[string]*time.Duration{
+		"transport.idle_timeout": &p.IdleConnTimeout,
+		"transport.keep_alive":   &p.KeepAlive,
+	} {
+		if !viper.IsSet(key) {
+			continue
+		}
+		v, err := time.ParseDuration(viper.GetString(key))
+		if err != nil || v < 0 {
+			return name, p, fmt.Errorf("invalid %s %q (use a duration like 90s)", key, viper.GetString(key))
+		}
+		*d = v
+	}
+	return name, p, nil
+}
+
+// validateTransportProfile reports a bad --transport-profile or transport.*
+// setting before any request is made.
+func validateTransportProfile() error {
+	_, _, err := resolveTransportProfile()
+	return err
+}
+
+// apiTransport returns the pooled, HTTP/2-capable transport shared by all
+// API clients, so connections are reused across commands' requests instead
+// of being dialled per client.
+func apiTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		name, p, _ := resolveTransportProfile()
+		if viper.GetBool("verbose") && name != "default" {
+			fmt.Fprintf(os.Stderr, "Using transport profile %s (%d idle connections per host)\n", name, p.MaxIdleConnsPerHost)
+		}
+
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: p.KeepAlive,
+		}
+		tlsConfig, _ := apiTLSConfig()
+		sharedTransport = &http.Transport{
+			Proxy:                 apiProxy,
+			TLSClientConfig:       tlsConfig,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          p.MaxIdleConns,
+			MaxIdleConnsPerHost:   p.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       p.MaxConnsPerHost,
+			IdleConnTimeout:       p.IdleConnTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	})
+	return sharedTransport
+}
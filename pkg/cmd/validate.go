@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// The JSON Schemas for zone and flow files. Editors can use the same files,
+// which 'sapliy validate --print-schema' writes out.
+//
+//go:embed schemas/*.schema.json
+var schemaFiles embed.FS
+
+// validationError is a problem found in a file, at the line and column of
+// the value it is about (or of the object missing a field).
+type validationError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e validationError) String() string {
+	msg := e.Message
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, msg)
+}
+
+// schemaError is a value that does not match a schema. Path is written as
+// steps[0].config.event.
+type schemaError struct {
+	Path    string
+	Message string
+}
+
+// jsonSchema checks values against a schema. It knows the keywords the
+// embedded schemas use: type, const, enum, required, properties, items,
+// minItems, minLength, minimum, pattern, allOf, anyOf, if/then/else and
+// $ref to "#/$defs/...".
+type jsonSchema struct {
+	root     map[string]interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+func loadSchema(kind string) (*jsonSchema, error) {
+	raw, err := schemaFiles.ReadFile("schemas/" + kind + ".schema.json")
+	if err != nil {
+		return nil, err
+	}
+	s := &jsonSchema{patterns: map[string]*regexp.Regexp{}}
+	if err := json.Unmarshal(raw, &s.root); err != nil {
+		return nil, fmt.Errorf("%s schema: %w", kind, err)
+	}
+	return s, nil
+}
+
+// jsonType names the JSON type of a value decoded into interface{}.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func schemaStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func typeMatches(want []string, got string) bool {
+	for _, t := range want {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns where value does not match schema.
+func (s *jsonSchema) check(schema map[string]interface{}, value interface{}, path string) []schemaError {
+	if ref, ok := schema["$ref"].(string); ok {
+		defs, _ := s.root["$defs"].(map[string]interface{})
+		schema, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+	}
+	fail := func(format string, args ...interface{}) []schemaError {
+		return []schemaError{{Path: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if want := schemaStrings(schema["type"]); want != nil && !typeMatches(want, jsonType(value)) {
+		return fail("must be %s, not %s", strings.Join(want, " or "), jsonType(value))
+	}
+	if c, ok := schema["const"]; ok && c != value {
+		return fail("must be %s", diffValue(c))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		names := make([]string, len(enum))
+		for i, e := range enum {
+			found = found || e == value
+			names[i] = fmt.Sprint(e)
+		}
+		if !found {
+			return fail("%s is not one of: %s", diffValue(value), strings.Join(names, ", "))
+		}
+	}
+
+	var errs []schemaError
+	switch v := value.(type) {
+	case string:
+		if n, ok := schema["minLength"].(float64); ok && utf8.RuneCountInString(v) < int(n) {
+			if n == 1 {
+				return fail("must not be empty")
+			}
+			return fail("must be at least %d characters", int(n))
+		}
+		if p, ok := schema["pattern"].(string); ok && !s.pattern(p).MatchString(v) {
+			msg := fmt.Sprintf("%q is not valid", v)
+			if desc, ok := schema["description"].(string); ok {
+				msg += "; expected " + desc
+			}
+			return fail("%s", msg)
+		}
+	case float64:
+		if n, ok := schema["minimum"].(float64); ok && v < n {
+			return fail("must be at least %v", n)
+		}
+	case []interface{}:
+		if n, ok := schema["minItems"].(float64); ok && len(v) < int(n) {
+			return fail("must have at least %d item(s)", int(n))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errs = append(errs, s.check(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		for _, field := range schemaStrings(schema["required"]) {
+			if _, ok := v[field]; !ok {
+				errs = append(errs, schemaError{Path: path, Message: fmt.Sprintf("missing %q", field)})
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		for _, field := range slices.Sorted(maps.Keys(props)) {
+			if fieldValue, ok := v[field]; ok {
+				errs = append(errs, s.check(props[field].(map[string]interface{}), fieldValue, joinPath(path, field))...)
+			}
+		}
+	}
+
+	if cond, ok := schema["if"].(map[string]interface{}); ok {
+		branch := "then"
+		if len(s.check(cond, value, path)) > 0 {
+			branch = "else"
+		}
+		if sub, ok := schema[branch].(map[string]interface{}); ok {
+			errs = append(errs, s.check(sub, value, path)...)
+		}
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			errs = append(errs, s.check(sub.(map[string]interface{}), value, path)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var alternatives []string
+		for _, sub := range anyOf {
+			subErrs := s.check(sub.(map[string]interface{}), value, path)
+			if len(subErrs) == 0 {
+				alternatives = nil
+				break
+			}
+			alternatives = append(alternatives, subErrs[0].Message)
+		}
+		if alternatives != nil {
+			errs = append(errs, schemaError{Path: path, Message: joinAlternatives(alternatives)})
+		}
+	}
+	return errs
+}
+
+// joinAlternatives words failed anyOf branches as one message, so
+// `missing "event"` and `missing "eventType"` read as
+// `missing "event" or "eventType"`.
+func joinAlternatives(messages []string) string {
+	for _, m := range messages {
+		if !strings.HasPrefix(m, "missing ") {
+			return strings.Join(messages, ", or ")
+		}
+	}
+	fields := make([]string, len(messages))
+	for i, m := range messages {
+		fields[i] = strings.TrimPrefix(m, "missing ")
+	}
+	return "missing " + strings.Join(fields, " or ")
+}
+
+func (s *jsonSchema) pattern(p string) *regexp.Regexp {
+	re, ok := s.patterns[p]
+	if !ok {
+		re = regexp.MustCompile(p)
+		s.patterns[p] = re
+	}
+	return re
+}
+
+// filePosition is a 1-based line and column in a file.
+type filePosition struct {
+	line, column int
+}
+
+// jsonPositions maps the path of every value in a JSON file, written as
+// check writes paths, to where it starts.
+func jsonPositions(raw []byte) (map[string]filePosition, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	positions := map[string]filePosition{}
+	var walk func(path string) error
+	walk = func(path string) error {
+		start := dec.InputOffset()
+		for start < int64(len(raw)) && strings.IndexByte(" \t\r\n,:", raw[start]) >= 0 {
+			start++
+		}
+		positions[path] = offsetPosition(raw, start)
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := walk(joinPath(path, key.(string))); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = dec.Token()
+		return err
+	}
+	return positions, walk("")
+}
+
+// yamlPositions is jsonPositions for a YAML file.
+func yamlPositions(raw []byte) (map[string]filePosition, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+	positions := map[string]filePosition{}
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		positions[path] = filePosition{n.Line, n.Column}
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, child := range n.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				walk(n.Content[i+1], joinPath(path, n.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			for i, child := range n.Content {
+				walk(child, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(&root, "")
+	return positions, nil
+}
+
+// offsetPosition turns a byte offset in raw into a line and column.
+func offsetPosition(raw []byte, offset int64) filePosition {
+	before := raw[:min(max(offset, 0), int64(len(raw)))]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return filePosition{bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[lineStart:]) + 1}
+}
+
+var yamlErrorLine = regexp.MustCompile(`yaml: line (\d+):`)
+
+// errorPosition finds where in raw a parse error from decodeResource is.
+func errorPosition(raw []byte, err error) filePosition {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return offsetPosition(raw, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return offsetPosition(raw, typeErr.Offset)
+	}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return filePosition{line, 1}
+	}
+	return filePosition{1, 1}
+}
+
+// validateFile checks a zone or flow file, JSON or YAML, against its schema.
+func validateFile(file string, schemas map[string]*jsonSchema) ([]validationError, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	at := func(pos filePosition, path, message string) validationError {
+		return validationError{File: file, Line: pos.line, Column: pos.column, Path: path, Message: message}
+	}
+	start := filePosition{1, 1}
+
+	var doc interface{}
+	if err := decodeResource(file, raw, &doc); err != nil {
+		return []validationError{at(errorPosition(raw, err), "", err.Error())}, nil
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return []validationError{at(start, "", "must be an object, not "+jsonType(doc))}, nil
+	}
+	kind := resourceKind(file, obj)
+	if kind == "" {
+		return []validationError{at(start, "", "cannot tell whether this is a zone or a flow; name it *.zone.json or *.flow.json (or .yaml)")}, nil
+	}
+
+	positions, err := jsonPositions(raw)
+	if isYAMLFile(file) {
+		positions, err = yamlPositions(raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var errs []validationError
+	schema := schemas[kind]
+	for _, e := range schema.check(schema.root, doc, "") {
+		pos, ok := positions[e.Path]
+		if !ok {
+			pos = start
+		}
+		errs = append(errs, at(pos, e.Path, e.Message))
+	}
+	slices.SortStableFunc(errs, func(a, b validationError) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return errs, nil
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check zone and flow files against their JSON Schemas",
+	Long: `Check zone and flow files, JSON or YAML, against the JSON Schemas built
+into the CLI: required fields, known step types, and the config each step
+type needs (the event of a trigger, the url of an http step, ...). Each
+problem is printed as file:line:column, so editors and CI logs link straight
+to it, and validate exits 1 if any file has one.
+
+Validation runs offline and checks a file's shape only; 'sapliy flows lint'
+checks what the steps do. --print-schema writes a schema out, for editors
+that validate JSON or YAML as you type.`,
+	Example: `  sapliy validate -f checkout.flow.json
+  sapliy validate -f infra/
+  sapliy validate -f infra/ -o json
+  sapliy validate --print-schema flow > flow.schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if kind, _ := cmd.Flags().GetString("print-schema"); kind != "" {
+			raw, err := schemaFiles.ReadFile("schemas/" + kind + ".schema.json")
+			if err != nil {
+				fmt.Printf("Error: unknown schema %q; use zone or flow\n", kind)
+				os.Exit(1)
+			}
+			os.Stdout.Write(raw)
+			return
+		}
+		paths, _ := cmd.Flags().GetStringArray("file")
+		if len(paths) == 0 {
+			fmt.Println("Error: pass the files or directories to validate with -f.")
+			os.Exit(1)
+		}
+		files, err := deployFiles(paths)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		schemas := map[string]*jsonSchema{}
+		for _, kind := range []string{"zone", "flow"} {
+			if schemas[kind], err = loadSchema(kind); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		errs := []validationError{}
+		invalid := 0
+		for _, file := range files {
+			fileErrs, err := validateFile(file, schemas)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if len(fileErrs) > 0 {
+				invalid++
+			}
+			errs = append(errs, fileErrs...)
+		}
+
+		printOutput(errs, func() {
+			for _, e := range errs {
+				fmt.Println(e)
+			}
+			if len(errs) == 0 {
+				fmt.Printf("✅ %d file(s) valid\n", len(files))
+				return
+			}
+			fmt.Printf("❌ %d error(s) in %d of %d file(s)\n", len(errs), invalid, len(files))
+		})
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringArrayP("file", "f", nil, "Zone or flow file, or a directory of them (repeatable)")
+	validateCmd.Flags().String("print-schema", "", "Print the JSON Schema for zone or flow files and exit")
+}
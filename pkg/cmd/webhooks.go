@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/sapliy/fintech-sdk-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,9 +24,26 @@ var webhooksCmd = &cobra.Command{
 List past webhook deliveries and replay failed or missed webhooks.`,
 }
 
+// webhookEventStatuses are the delivery statuses --status accepts.
+var webhookEventStatuses = []string{"pending", "succeeded", "failed"}
+
+func fetchWebhookEventPage[T any](ctx context.Context, q url.Values) (*apiPage[T], error) {
+	var page apiPage[T]
+	if err := apiGetTrimmed(ctx, "/v1/webhooks/events?"+q.Encode(), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
 var webhooksListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List recent webhook events",
+	Long: `List webhook events, newest first, filtered by delivery status and event
+type on the server. One page of --limit events is shown; pass the printed
+cursor to --cursor for the next page, or use --all to fetch every page.`,
+	Example: `  sapliy webhooks list --status failed --type payment.failed,refund.failed
+  sapliy webhooks list --limit 50 --cursor c_8f2a...
+  sapliy webhooks list --status failed --all -o json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey := viper.GetString("api_key")
 		if apiKey == "" {
@@ -30,54 +51,83 @@ var webhooksListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		zone := viper.GetString("current_zone")
-		if zoneID != "" {
-			zone = zoneID
-		}
-
-		if zone == "" {
-			fmt.Println("Error: Zone ID is required. Use --zone or set in config.")
-			return
-		}
-
-		fmt.Printf("📋 Fetching webhook events (zone: %s)...\n", zone)
-		fmt.Println(strings.Repeat("─", 80))
-
-		client := fintech.NewClient(apiKey, fintech.WithBaseURL(viper.GetString("api_url")))
+		zone := requireZone(cmd)
 
 		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		types, _ := cmd.Flags().GetStringSlice("type")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		if status != "" && !slices.Contains(webhookEventStatuses, status) {
+			fmt.Printf("Error: invalid --status %q (use %s)\n", status, strings.Join(webhookEventStatuses, ", "))
+			os.Exit(1)
+		}
 
-		// In a real implementation, we'd need a GetPastEvents method in the SDK
-		// Let's assume we use the do method directly if the SDK doesn't have it yet
-		// But for now, I'll use a placeholder that describes the real API call
-		// actually, I'll add GetPastEvents to the SDK as well
+		if !structuredOutput() {
+			fmt.Printf("📋 Fetching webhook events (zone: %s)...\n", zone)
+			fmt.Println(strings.Repeat("─", 80))
+		}
 
-		events, err := client.GetPastEvents(context.Background(), zone, limit, 0)
-		if err != nil {
-			fmt.Printf("Error: Failed to fetch events: %v\n", err)
-			return
+		q := url.Values{"zone": {zone}, "limit": {strconv.Itoa(limit)}}
+		if status != "" {
+			q.Set("status", status)
+		}
+		for _, t := range types {
+			q.Add("type", t)
 		}
 
-		if len(events) == 0 {
-			fmt.Println("No webhook events found.")
-			return
+		views := []webhookEventView{}
+		for {
+			if cursor != "" {
+				q.Set("cursor", cursor)
+			}
+			page, err := fetchWebhookEventPage[webhookEventView](context.Background(), q)
+			if err != nil {
+				fmt.Printf("Error: Failed to fetch events: %v\n", err)
+				os.Exit(1)
+			}
+			views = append(views, page.Data...)
+			cursor = page.NextCursor
+			if !all || cursor == "" {
+				break
+			}
 		}
 
-		// Header
-		fmt.Printf("%-24s %-25s %-15s %-15s\n", "EVENT ID", "TYPE", "CREATED AT", "DATA")
-		fmt.Println(strings.Repeat("─", 80))
+		printOutput(views, func() {
+			if len(views) == 0 {
+				fmt.Println("No webhook events found.")
+				return
+			}
 
-		for _, evt := range events {
-			timestamp := evt.CreatedAt.Format("Jan 02 15:04")
-			data, _ := json.Marshal(evt.Data)
-			dataStr := truncate(string(data), 30)
+			// Header
+			fmt.Printf("%-24s %-25s %-10s %-15s %s\n", "EVENT ID", "TYPE", "STATUS", "CREATED AT", "DATA")
+			fmt.Println(strings.Repeat("─", 100))
 
-			fmt.Printf("%-24s %-25s %-15s %s\n",
-				evt.ID, evt.Type, timestamp, dataStr)
+			for _, evt := range views {
+				timestamp := evt.CreatedAt.Format("Jan 02 15:04")
+				data, _ := json.Marshal(evt.Data)
+				dataStr := truncate(string(data), 30)
+
+				fmt.Printf("%-24s %-25s %-10s %-15s %s\n",
+					evt.ID, evt.Type, evt.Status, timestamp, dataStr)
+			}
+		})
+		if cursor != "" {
+			fmt.Fprintf(os.Stderr, "\nMore events available: --cursor %s (or --all)\n", cursor)
 		}
 	},
 }
 
+// webhookEventView is a webhook event as printed by list commands.
+type webhookEventView struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Zone      string                 `json:"zone"`
+	Status    string                 `json:"status,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Data      map[string]interface{} `json:"data"`
+}
+
 var webhooksReplayCmd = &cobra.Command{
 	Use:   "replay [event_id]",
 	Short: "Replay a webhook event",
@@ -89,33 +139,65 @@ var webhooksReplayCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		zone := viper.GetString("current_zone")
-		if zoneID != "" {
-			zone = zoneID
-		}
-
-		if zone == "" {
-			fmt.Println("Error: Zone ID is required. Use --zone or set in config.")
-			return
-		}
+		zone := requireZone(cmd)
 
 		eventID := args[0]
 		force, _ := cmd.Flags().GetBool("force")
+		override, _ := cmd.Flags().GetString("override")
+		edit, _ := cmd.Flags().GetBool("edit")
+
+		// With --override or --edit the event is replayed with a different
+		// payload, which the SDK's ReplayEvent cannot send.
+		var payload map[string]interface{}
+		if override != "" {
+			raw, err := readArgValue(override)
+			if err == nil {
+				err = json.Unmarshal(raw, &payload)
+			}
+			if err != nil {
+				fmt.Printf("Error: invalid --override: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if edit {
+			if payload == nil {
+				var evt webhookEventView
+				if err := apiRequest(context.Background(), http.MethodGet, "/v1/webhooks/events/"+url.PathEscape(eventID), nil, &evt); err != nil {
+					fmt.Printf("Error fetching event: %v\n", err)
+					os.Exit(1)
+				}
+				payload = evt.Data
+			}
+			b, _ := json.MarshalIndent(payload, "", "  ")
+			edited, err := editJSON("replay", append(b, '\n'), nil)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if edited == nil {
+				fmt.Println("Cancelled.")
+				return
+			}
+			payload = edited
+		}
 
 		fmt.Printf("🔄 Replaying webhook event: %s in zone: %s\n", eventID, zone)
 
 		if !force {
-			fmt.Print("Are you sure you want to replay this webhook? [y/N]: ")
-			var response string
-			fmt.Scanln(&response)
-			if strings.ToLower(response) != "y" {
+			if !confirm("Are you sure you want to replay this webhook? [y/N]: ") {
 				fmt.Println("Cancelled.")
 				return
 			}
 		}
 
-		client := fintech.NewClient(apiKey, fintech.WithBaseURL(viper.GetString("api_url")))
-		err := client.ReplayEvent(context.Background(), eventID, zone)
+		var err error
+		if payload != nil {
+			req := map[string]interface{}{"zoneId": zone, "data": payload}
+			err = apiRequest(context.Background(), http.MethodPost, "/v1/webhooks/events/"+url.PathEscape(eventID)+"/replay", req, nil)
+		} else {
+			client := newClient()
+			err = client.ReplayEvent(context.Background(), eventID, zone)
+		}
 		if err != nil {
 			fmt.Printf("❌ Failed to replay event: %v\n", err)
 			return
@@ -125,9 +207,110 @@ var webhooksReplayCmd = &cobra.Command{
 	},
 }
 
+// failedDelivery is a webhook event whose delivery ran out of attempts.
+type failedDelivery struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Endpoint  string    `json:"endpoint,omitempty"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// replayResult is the outcome of replaying one failed delivery.
+type replayResult struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// parseSince turns a --since value, either a duration such as 90m, 24h or
+// 7d, or an RFC 3339 timestamp, into the time it refers to.
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q", s)
+		}
+		return time.Now().AddDate(0, 0, -n), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 1h, 24h, 7d or an RFC 3339 time)", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// fetchFailedDeliveries pages through the failed deliveries of a zone since
+// the given time.
+func fetchFailedDeliveries(ctx context.Context, zone string, since time.Time) ([]failedDelivery, error) {
+	q := url.Values{
+		"zone":   {zone},
+		"status": {"failed"},
+		"since":  {since.UTC().Format(time.RFC3339)},
+		"limit":  {"100"},
+	}
+	var all []failedDelivery
+	for {
+		page, err := fetchWebhookEventPage[failedDelivery](ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.NextCursor == "" {
+			return all, nil
+		}
+		q.Set("cursor", page.NextCursor)
+	}
+}
+
+// progressBar draws "[█████░░░░░] 12/40" on stderr, redrawing in place. In
+// accessible mode it prints a "Progress: 12 of 40" line every tenth instead.
+type progressBar struct {
+	mu    sync.Mutex
+	total int
+	done  int
+	show  bool
+}
+
+func newProgressBar(total int) *progressBar {
+	return &progressBar{total: total, show: !structuredOutput() && (stderrIsTerminal() || accessibleMode())}
+}
+
+func (p *progressBar) step() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if !p.show {
+		return
+	}
+	if accessibleMode() {
+		if p.done == p.total || p.done*10/p.total != (p.done-1)*10/p.total {
+			fmt.Fprintf(os.Stderr, "Progress: %d of %d\n", p.done, p.total)
+		}
+		return
+	}
+	const width = 30
+	filled := width * p.done / p.total
+	fmt.Fprintf(os.Stderr, "\r[%s%s] %d/%d", strings.Repeat("█", filled), strings.Repeat("░", width-filled), p.done, p.total)
+	if p.done == p.total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
 var webhooksReplayFailedCmd = &cobra.Command{
 	Use:   "replay-failed",
 	Short: "Replay all failed webhook events",
+	Long: `Find the webhook deliveries that failed since --since and replay them,
+--concurrency at a time. A progress bar is shown while replaying, followed
+by a summary with the error for each event that could not be replayed. The
+exit status is 1 if any replay failed.`,
+	Example: `  sapliy webhooks replay-failed --since 6h --dry-run
+  sapliy webhooks replay-failed --since 7d --concurrency 10`,
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey := viper.GetString("api_key")
 		if apiKey == "" {
@@ -135,44 +318,106 @@ var webhooksReplayFailedCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		zone := viper.GetString("current_zone")
-		since, _ := cmd.Flags().GetString("since")
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		zone := requireZone(cmd)
 
-		fmt.Printf("🔍 Finding failed webhooks (zone: %s, since: %s)...\n", zone, since)
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		since, err := parseSince(sinceFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		// Demo data
-		failedEvents := []string{"we_def456", "we_xyz999"}
+		ctx := context.Background()
+		if !structuredOutput() {
+			fmt.Printf("🔍 Finding failed webhooks (zone: %s, since: %s)...\n", zone, since.Local().Format(time.RFC1123))
+		}
+		failed, err := fetchFailedDeliveries(ctx, zone, since)
+		if err != nil {
+			fmt.Printf("❌ Failed to list failed webhooks: %v\n", err)
+			os.Exit(1)
+		}
 
-		if len(failedEvents) == 0 {
-			fmt.Println("✅ No failed webhooks found.")
+		if len(failed) == 0 || dryRun {
+			printOutput(failed, func() {
+				if len(failed) == 0 {
+					fmt.Println("✅ No failed webhooks found.")
+					return
+				}
+				fmt.Printf("Found %d failed webhook(s)\n", len(failed))
+				fmt.Println("\n🏃 Dry run - would replay:")
+				for _, d := range failed {
+					fmt.Printf("   - %s  %-25s %s\n", d.ID, d.Type, truncate(d.LastError, 40))
+				}
+			})
 			return
 		}
 
-		fmt.Printf("Found %d failed webhook(s)\n", len(failedEvents))
+		if !structuredOutput() {
+			fmt.Printf("Found %d failed webhook(s), replaying %d at a time...\n", len(failed), concurrency)
+		}
 
-		if dryRun {
-			fmt.Println("\n🏃 Dry run - would replay:")
-			for _, evt := range failedEvents {
-				fmt.Printf("   - %s\n", evt)
-			}
-			return
+		client := newClient()
+		results := make([]replayResult, len(failed))
+		progress := newProgressBar(len(failed))
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					d := failed[i]
+					results[i] = replayResult{ID: d.ID, Type: d.Type, OK: true}
+					if err := client.ReplayEvent(ctx, d.ID, zone); err != nil {
+						results[i].OK, results[i].Error = false, err.Error()
+					}
+					progress.step()
+				}
+			}()
 		}
+		for i := range failed {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
 
-		fmt.Println("\nReplaying...")
-		for _, evt := range failedEvents {
-			fmt.Printf("   ✅ %s → Replayed\n", evt)
+		succeeded := 0
+		for _, r := range results {
+			if r.OK {
+				succeeded++
+			}
 		}
 
-		fmt.Println(strings.Repeat("─", 40))
-		fmt.Printf("Completed: %d succeeded\n", len(failedEvents))
+		printOutput(results, func() {
+			for _, r := range results {
+				if !r.OK {
+					fmt.Printf("   ❌ %s (%s): %s\n", r.ID, r.Type, r.Error)
+				}
+			}
+			fmt.Println(strings.Repeat("─", 40))
+			fmt.Printf("Completed: %d succeeded, %d failed\n", succeeded, len(results)-succeeded)
+		})
+		if succeeded < len(results) {
+			os.Exit(1)
+		}
 	},
 }
 
 var webhooksInspectCmd = &cobra.Command{
 	Use:   "inspect [event_id]",
 	Short: "Inspect a webhook event in detail",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show a webhook event's delivery details and its payload. The payload is
+colored on a terminal; --path prints one part of it (data.items[0]),
+--collapse-depth folds what is nested deeper onto one line, and --sort-keys
+sorts its keys.`,
+	Example: `  sapliy webhooks inspect evt_123
+  sapliy webhooks inspect evt_123 --path customer --collapse-depth 1`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKey := viper.GetString("api_key")
 		if apiKey == "" {
@@ -182,9 +427,6 @@ var webhooksInspectCmd = &cobra.Command{
 
 		eventID := args[0]
 
-		fmt.Printf("📦 Webhook Event: %s\n", eventID)
-		fmt.Println(strings.Repeat("─", 60))
-
 		// Demo data
 		event := map[string]interface{}{
 			"id":           eventID,
@@ -202,20 +444,144 @@ var webhooksInspectCmd = &cobra.Command{
 			},
 		}
 
-		fmt.Printf("Type:        %s\n", event["type"])
-		fmt.Printf("Status:      %s\n", event["status"])
-		fmt.Printf("Endpoint:    %s\n", event["endpoint"])
-		fmt.Printf("Created:     %s\n", event["createdAt"])
-		fmt.Printf("Delivered:   %s\n", formatTimestamp(event["deliveredAt"].(string)))
-		fmt.Printf("Attempts:    %v\n", event["attempts"])
-		fmt.Printf("Response:    %v\n", event["responseCode"])
+		showAttempts, _ := cmd.Flags().GetBool("attempts")
+		bodyBytes, _ := cmd.Flags().GetInt("body-bytes")
+		view := jsonViewFromFlags(cmd)
+
+		var attempts []deliveryAttempt
+		var attemptsErr error
+		if showAttempts {
+			attempts, attemptsErr = fetchDeliveryAttempts(eventID, bodyBytes)
+			if attemptsErr == nil {
+				event["deliveryAttempts"] = attempts
+			}
+		}
+
+		printOutput(event, func() {
+			fmt.Printf("📦 Webhook Event: %s\n", eventID)
+			fmt.Println(strings.Repeat("─", 60))
+
+			fmt.Printf("Type:        %s\n", event["type"])
+			fmt.Printf("Status:      %s\n", event["status"])
+			fmt.Printf("Endpoint:    %s\n", event["endpoint"])
+			fmt.Printf("Created:     %s\n", event["createdAt"])
+			fmt.Printf("Delivered:   %s\n", formatTimestamp(event["deliveredAt"].(string)))
+			fmt.Printf("Attempts:    %v\n", event["attempts"])
+			fmt.Printf("Response:    %v\n", event["responseCode"])
+
+			fmt.Println("\nPayload:")
+			payload, err := view.render(event["payload"], "")
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(payload)
+			printFileRefs(context.Background(), event["payload"])
+
+			if showAttempts {
+				printDeliveryAttempts(attempts, attemptsErr)
+			}
+		})
 
-		fmt.Println("\nPayload:")
-		prettyJSON, _ := json.MarshalIndent(event["payload"], "", "  ")
-		fmt.Println(string(prettyJSON))
+		if attemptsErr != nil && structuredOutput() {
+			fmt.Fprintf(os.Stderr, "Error: failed to fetch attempts: %v\n", attemptsErr)
+			os.Exit(1)
+		}
 	},
 }
 
+// deliveryAttempt is one try at delivering an event to an endpoint.
+type deliveryAttempt struct {
+	Attempt      int       `json:"attempt"`
+	Endpoint     string    `json:"endpoint"`
+	StartedAt    time.Time `json:"startedAt"`
+	DurationMs   int64     `json:"durationMs"`
+	StatusCode   int       `json:"statusCode"`
+	Error        string    `json:"error"`
+	ResponseBody *string   `json:"responseBody"`
+	Truncated    bool      `json:"responseTruncated"`
+
+	// Omitted is how many bytes of the body were cut to fit --body-bytes.
+	Omitted int `json:"responseBytesOmitted,omitempty"`
+}
+
+// fetchDeliveryAttempts loads the attempts for an event with their captured
+// response bodies redacted and cut to bodyBytes (0 for no limit).
+func fetchDeliveryAttempts(eventID string, bodyBytes int) ([]deliveryAttempt, error) {
+	var attempts []deliveryAttempt
+	err := apiRequest(context.Background(), http.MethodGet, "/v1/webhooks/events/"+url.PathEscape(eventID)+"/attempts", nil, &attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range attempts {
+		a := &attempts[i]
+		if a.ResponseBody == nil {
+			continue
+		}
+		body := redactBody(*a.ResponseBody)
+		if bodyBytes > 0 && len(body) > bodyBytes {
+			a.Omitted = len(body) - bodyBytes
+			body = body[:bodyBytes]
+		}
+		a.ResponseBody = &body
+	}
+	return attempts, nil
+}
+
+func printDeliveryAttempts(attempts []deliveryAttempt, err error) {
+	fmt.Println("\nDelivery attempts:")
+	fmt.Println(strings.Repeat("─", 60))
+	if err != nil {
+		fmt.Printf("❌ Failed to fetch attempts: %v\n", err)
+		return
+	}
+	if len(attempts) == 0 {
+		fmt.Println("No delivery attempts yet.")
+		return
+	}
+
+	for _, a := range attempts {
+		status := fmt.Sprintf("%d", a.StatusCode)
+		icon := "✅"
+		if a.StatusCode < 200 || a.StatusCode >= 300 {
+			icon = "❌"
+		}
+		if a.Error != "" {
+			status = a.Error
+			icon = "❌"
+		}
+		fmt.Printf("%s #%d  %s  %s  (%dms)\n", icon, a.Attempt, a.StartedAt.Local().Format("Jan 02 15:04:05"), status, a.DurationMs)
+		if a.Endpoint != "" {
+			fmt.Printf("   Endpoint: %s\n", a.Endpoint)
+		}
+
+		if a.ResponseBody == nil {
+			fmt.Println("   Response body not captured (enable with 'sapliy webhooks endpoints capture-response enable <endpoint_id>')")
+			continue
+		}
+
+		body := *a.ResponseBody
+		if body == "" {
+			fmt.Println("   Response body: (empty)")
+			continue
+		}
+		extra := ""
+		if a.Omitted > 0 {
+			extra = fmt.Sprintf(" … %d more bytes", a.Omitted)
+		} else if a.Truncated {
+			extra = " … truncated when captured"
+		}
+		fmt.Println("   Response body:")
+		for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+			fmt.Printf("   │ %s\n", line)
+		}
+		if extra != "" {
+			fmt.Printf("   │%s\n", extra)
+		}
+	}
+}
+
 func formatTimestamp(ts string) string {
 	if ts == "" {
 		return "—"
@@ -241,12 +607,22 @@ func init() {
 	webhooksCmd.AddCommand(webhooksReplayFailedCmd)
 	webhooksCmd.AddCommand(webhooksInspectCmd)
 
-	webhooksListCmd.Flags().IntP("limit", "l", 20, "Number of events to fetch")
+	webhooksListCmd.Flags().IntP("limit", "l", 20, "Number of events per page")
 	webhooksListCmd.Flags().StringP("status", "s", "", "Filter by status (pending, succeeded, failed)")
+	webhooksListCmd.Flags().StringSliceP("type", "t", nil, "Filter by event type (comma-separated)")
+	webhooksListCmd.Flags().String("cursor", "", "Start from this page cursor (printed after each page)")
+	webhooksListCmd.Flags().Bool("all", false, "Fetch every page")
 	webhooksCmd.PersistentFlags().StringVarP(&zoneID, "zone", "z", "", "Zone ID to scope the events")
 
 	webhooksReplayCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	webhooksReplayCmd.Flags().String("override", "", "Replay with this payload instead (JSON, @file or @- for stdin)")
+	webhooksReplayCmd.Flags().Bool("edit", false, "Edit the payload in $EDITOR before replaying (starts from --override or the original)")
+
+	webhooksInspectCmd.Flags().Bool("attempts", false, "Show delivery attempts with captured response bodies")
+	webhooksInspectCmd.Flags().Int("body-bytes", 500, "Truncate captured response bodies to this many bytes (0 for no limit)")
+	addJSONViewFlags(webhooksInspectCmd)
 
 	webhooksReplayFailedCmd.Flags().String("since", "24h", "Time range for failed webhooks (e.g., 1h, 24h, 7d)")
+	webhooksReplayFailedCmd.Flags().IntP("concurrency", "c", 4, "Number of events to replay at once")
 	webhooksReplayFailedCmd.Flags().Bool("dry-run", false, "Show what would be replayed without doing it")
 }
@@ -0,0 +1,560 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+// sinkMaxBody is the largest request body the webhook sink stores.
+const sinkMaxBody = 10 << 20
+
+// sinkRequest is a request received by the webhook sink.
+type sinkRequest struct {
+	ID         int64               `json:"id"`
+	ReceivedAt time.Time           `json:"receivedAt"`
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	EventID    string              `json:"eventId,omitempty"`
+	EventType  string              `json:"eventType,omitempty"`
+	Signature  string              `json:"signature"`
+	Reason     string              `json:"reason,omitempty"`
+	Status     int                 `json:"status"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+}
+
+// Signature states of a received request.
+const (
+	sinkSigValid     = "valid"
+	sinkSigInvalid   = "invalid"
+	sinkSigMissing   = "missing"
+	sinkSigUnchecked = "unchecked"
+)
+
+// sinkStore is the local index of requests received by the webhook sink.
+type sinkStore struct {
+	db *sql.DB
+}
+
+func defaultSinkDB() (string, error) {
+	dir, err := sapliyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sink.db"), nil
+}
+
+func openSinkStore(path string) (*sinkStore, error) {
+	if path == "" {
+		p, err := defaultSinkDB()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS requests (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		received_at TEXT NOT NULL,
+		method      TEXT NOT NULL,
+		path        TEXT NOT NULL,
+		event_id    TEXT NOT NULL,
+		event_type  TEXT NOT NULL,
+		signature   TEXT NOT NULL,
+		reason      TEXT NOT NULL,
+		status      INTEGER NOT NULL,
+		headers     TEXT NOT NULL,
+		body        TEXT NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize sink store %s: %w", path, err)
+	}
+	return &sinkStore{db: db}, nil
+}
+
+func (s *sinkStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sinkStore) add(r *sinkRequest) error {
+	headers, _ := json.Marshal(r.Headers)
+	res, err := s.db.Exec(`INSERT INTO requests (received_at, method, path, event_id, event_type, signature, reason, status, headers, body)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ReceivedAt.UTC().Format(time.RFC3339Nano), r.Method, r.Path, r.EventID, r.EventType,
+		r.Signature, r.Reason, r.Status, string(headers), r.Body)
+	if err != nil {
+		return err
+	}
+	r.ID, err = res.LastInsertId()
+	return err
+}
+
+// sinkFilter selects requests for list.
+type sinkFilter struct {
+	eventType string
+	signature string
+	since     time.Time
+	limit     int
+}
+
+func (s *sinkStore) list(f sinkFilter) ([]sinkRequest, error) {
+	query := `SELECT id, received_at, method, path, event_id, event_type, signature, reason, status, headers, body FROM requests WHERE 1=1`
+	var args []interface{}
+	if f.eventType != "" {
+		query += ` AND event_type LIKE ?`
+		args = append(args, "%"+f.eventType+"%")
+	}
+	if f.signature != "" {
+		query += ` AND signature = ?`
+		args = append(args, f.signature)
+	}
+	if !f.since.IsZero() {
+		query += ` AND received_at >= ?`
+		args = append(args, f.since.UTC().Format(time.RFC3339Nano))
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, f.limit)
+	return s.query(query, args...)
+}
+
+func (s *sinkStore) get(id int64) (*sinkRequest, error) {
+	reqs, err := s.query(`SELECT id, received_at, method, path, event_id, event_type, signature, reason, status, headers, body FROM requests WHERE id = ?`, id)
+	if err != nil || len(reqs) == 0 {
+		return nil, err
+	}
+	return &reqs[0], nil
+}
+
+func (s *sinkStore) query(query string, args ...interface{}) ([]sinkRequest, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var reqs []sinkRequest
+	for rows.Next() {
+		var r sinkRequest
+		var received, headers string
+		if err := rows.Scan(&r.ID, &received, &r.Method, &r.Path, &r.EventID, &r.EventType, &r.Signature, &r.Reason, &r.Status, &headers, &r.Body); err != nil {
+			return nil, err
+		}
+		r.ReceivedAt, _ = time.Parse(time.RFC3339Nano, received)
+		json.Unmarshal([]byte(headers), &r.Headers)
+		reqs = append(reqs, r)
+	}
+	return reqs, rows.Err()
+}
+
+func (s *sinkStore) clear() (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM requests`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// webhookSink is the catch-all receiver behind 'webhooks sink'.
+type webhookSink struct {
+	store   *sinkStore
+	secret  string
+	status  int
+	latency time.Duration
+	// rejectInvalid answers 400 to requests whose signature is missing or
+	// wrong, like a handler that verifies signatures would.
+	rejectInvalid bool
+	// script overrides status and latency for the event types given to
+	// --respond.
+	script *sinkScript
+}
+
+func (s *webhookSink) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, sinkMaxBody+1))
+	if err != nil {
+		http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > sinkMaxBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	r := &sinkRequest{
+		ReceivedAt: time.Now(),
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		EventID:    req.Header.Get("Sapliy-Event-Id"),
+		EventType:  req.Header.Get("Sapliy-Event-Type"),
+		Headers:    redactHeaders(req.Header),
+		Body:       string(body),
+		Status:     s.status,
+	}
+	if r.EventID == "" || r.EventType == "" {
+		var event struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(body, &event) == nil {
+			r.EventID = firstNonEmpty(r.EventID, event.ID)
+			r.EventType = firstNonEmpty(r.EventType, event.Type)
+		}
+	}
+
+	header := req.Header.Get(signatureHeader)
+	switch {
+	case s.secret == "":
+		r.Signature = sinkSigUnchecked
+	case header == "":
+		r.Signature, r.Reason = sinkSigMissing, "no "+signatureHeader+" header"
+	default:
+		check := verifySignature(s.secret, header, body, signatureTolerance, time.Now())
+		r.Signature = sinkSigValid
+		if !check.Valid {
+			r.Signature, r.Reason = sinkSigInvalid, check.Reason
+		}
+	}
+	latency, attempt := s.latency, 0
+	if s.script != nil {
+		if step, n, ok := s.script.respond(r.EventType, r.EventID); ok {
+			r.Status, latency, attempt = step.status, step.latency, n
+		}
+	}
+	if s.rejectInvalid && (r.Signature == sinkSigInvalid || r.Signature == sinkSigMissing) {
+		r.Status = http.StatusBadRequest
+	}
+
+	if err := s.store.add(r); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  could not store request: %v\n", err)
+	}
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(r.Status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"received": true, "id": r.ID, "signature": r.Signature})
+
+	icon := "✅"
+	if r.Signature == sinkSigInvalid || r.Signature == sinkSigMissing {
+		icon = "⚠️ "
+	}
+	label := r.EventType
+	if label == "" {
+		label = r.Method + " " + r.Path
+	}
+	line := fmt.Sprintf("[%s] %s #%-4d %d %-30s %s  sig:%s", r.ReceivedAt.Format("15:04:05"), icon, r.ID, r.Status, label, r.EventID, r.Signature)
+	if r.Reason != "" {
+		line += " (" + r.Reason + ")"
+	}
+	if attempt > 0 {
+		line += fmt.Sprintf("  attempt %d", attempt)
+	}
+	fmt.Println(line)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// redactHeaders copies h with credentials masked, since the index is kept on
+// disk and shared in bug reports.
+func redactHeaders(h http.Header) map[string][]string {
+	out := make(map[string][]string, len(h))
+	for k, v := range h {
+		if isSensitiveKey(k) || strings.EqualFold(k, "Cookie") {
+			out[k] = []string{redacted}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// openSinkStoreFlag opens the store named by the command's --db flag.
+func openSinkStoreFlag(cmd *cobra.Command) *sinkStore {
+	path, _ := cmd.Flags().GetString("db")
+	store, err := openSinkStore(path)
+	if err != nil {
+		fmt.Printf("Error opening sink store: %v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+var webhooksSinkCmd = &cobra.Command{
+	Use:   "sink",
+	Short: "Run a local catch-all webhook receiver",
+	Long: `Run a local HTTP server that accepts every request as a webhook delivery,
+for when there is no handler yet. Each request is checked against --secret
+(or SAPLIY_WEBHOOK_SECRET), stored in a local index, and answered with
+--status after --latency, so timeouts and error handling can be tested too.
+
+--respond scripts the answer per event type, to watch the platform's retries
+and backoff deterministically: TYPE=STATUS[xN][ after DURATION], with steps
+separated by commas. Attempts are counted per event and the last step
+repeats, so "payment.*=500x2,200" fails each payment event twice and then
+accepts it. The first matching --respond wins; other events get --status.
+
+Browse what was received with 'sapliy webhooks sink list' and
+'sapliy webhooks sink show <n>'.`,
+	Example: `  sapliy webhooks sink --port 4100 --secret whsec_local
+  sapliy webhooks listen --forward-to http://localhost:4100/webhook --secret whsec_local
+
+  # Simulate a slow, failing handler
+  sapliy webhooks sink --status 503 --latency 8s
+
+  # Answer payment.failed slowly; fail other payment events twice, then succeed
+  sapliy webhooks sink --respond 'payment.failed=503 after 2s' --respond 'payment.*=500x2,200'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		secret, _ := cmd.Flags().GetString("secret")
+		status, _ := cmd.Flags().GetInt("status")
+		latency, _ := cmd.Flags().GetDuration("latency")
+		rejectInvalid, _ := cmd.Flags().GetBool("reject-invalid")
+		respond, _ := cmd.Flags().GetStringArray("respond")
+		if status < 100 || status > 599 {
+			fmt.Printf("Error: invalid --status %d\n", status)
+			os.Exit(1)
+		}
+		if secret == "" {
+			secret = os.Getenv("SAPLIY_WEBHOOK_SECRET")
+		}
+		if rejectInvalid && secret == "" {
+			fmt.Println("Error: --reject-invalid needs --secret (or SAPLIY_WEBHOOK_SECRET).")
+			os.Exit(1)
+		}
+		var script *sinkScript
+		if len(respond) > 0 {
+			script = &sinkScript{}
+			for _, r := range respond {
+				rule, err := parseSinkRule(r)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				script.rules = append(script.rules, rule)
+			}
+		}
+
+		store := openSinkStoreFlag(cmd)
+		defer store.Close()
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		server := &http.Server{
+			Handler:           &webhookSink{store: store, secret: secret, status: status, latency: latency, rejectInvalid: rejectInvalid, script: script},
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		fmt.Printf("📥 Webhook sink listening on http://%s (any path)\n", addr)
+		if secret == "" {
+			fmt.Println("   Signatures are not checked; pass --secret to verify them.")
+		}
+		fmt.Printf("   Answering %d", status)
+		if latency > 0 {
+			fmt.Printf(" after %s", latency)
+		}
+		fmt.Println(" (Ctrl+C to stop)")
+		if script != nil {
+			for _, r := range script.rules {
+				fmt.Printf("   Scripted: %s\n", r)
+			}
+		}
+		fmt.Println(strings.Repeat("─", 60))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			server.Shutdown(shutdown)
+		}()
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\n👋 Sink stopped")
+	},
+}
+
+var webhooksSinkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List requests the sink received",
+	Example: `  sapliy webhooks sink list
+  sapliy webhooks sink list --type payment --signature invalid --since 1h`,
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+		eventType, _ := cmd.Flags().GetString("type")
+		signature, _ := cmd.Flags().GetString("signature")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		switch signature {
+		case "", sinkSigValid, sinkSigInvalid, sinkSigMissing, sinkSigUnchecked:
+		default:
+			fmt.Printf("Error: invalid --signature %q (use valid, invalid, missing or unchecked)\n", signature)
+			os.Exit(1)
+		}
+		f := sinkFilter{eventType: eventType, signature: signature, limit: limit}
+		if sinceFlag != "" {
+			since, err := parseSince(sinceFlag)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			f.since = since
+		}
+
+		store := openSinkStoreFlag(cmd)
+		defer store.Close()
+		reqs, err := store.list(f)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(reqs, func() {
+			if len(reqs) == 0 {
+				fmt.Println("No requests received.")
+				return
+			}
+			fmt.Printf("%-6s %-19s %-30s %-28s %-9s %s\n", "#", "RECEIVED", "TYPE", "EVENT ID", "SIGNATURE", "STATUS")
+			fmt.Println(strings.Repeat("─", 104))
+			for _, r := range reqs {
+				label := r.EventType
+				if label == "" {
+					label = r.Method + " " + r.Path
+				}
+				fmt.Printf("%-6d %-19s %-30s %-28s %-9s %d\n", r.ID, r.ReceivedAt.Local().Format("2006-01-02 15:04:05"),
+					truncate(label, 30), truncate(r.EventID, 28), r.Signature, r.Status)
+			}
+		})
+	},
+}
+
+var webhooksSinkShowCmd = &cobra.Command{
+	Use:   "show [n]",
+	Short: "Show a request the sink received",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+		if err != nil {
+			fmt.Printf("Error: %q is not a request number (see 'sapliy webhooks sink list')\n", args[0])
+			os.Exit(1)
+		}
+		store := openSinkStoreFlag(cmd)
+		defer store.Close()
+		r, err := store.get(id)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if r == nil {
+			fmt.Printf("No request #%d.\n", id)
+			os.Exit(1)
+		}
+
+		printOutput(r, func() {
+			fmt.Printf("📨 Request #%d — %s %s\n", r.ID, r.Method, r.Path)
+			fmt.Println(strings.Repeat("─", 60))
+			fmt.Printf("Received:  %s\n", r.ReceivedAt.Local().Format(time.RFC1123))
+			if r.EventType != "" {
+				fmt.Printf("Event:     %s %s\n", r.EventType, r.EventID)
+			}
+			fmt.Printf("Signature: %s", r.Signature)
+			if r.Reason != "" {
+				fmt.Printf(" (%s)", r.Reason)
+			}
+			fmt.Println()
+			fmt.Printf("Answered:  %d\n", r.Status)
+			fmt.Println("\nHeaders:")
+			for k, v := range r.Headers {
+				fmt.Printf("  %s: %s\n", k, strings.Join(v, ", "))
+			}
+			fmt.Println("\nBody:")
+			var pretty interface{}
+			if json.Unmarshal([]byte(r.Body), &pretty) == nil {
+				b, _ := json.MarshalIndent(pretty, "", "  ")
+				fmt.Println(string(b))
+			} else {
+				fmt.Println(r.Body)
+			}
+		})
+	},
+}
+
+var webhooksSinkClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete every request the sink stored",
+	Run: func(cmd *cobra.Command, args []string) {
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm("Delete every stored sink request? [y/N]: ") {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+		store := openSinkStoreFlag(cmd)
+		defer store.Close()
+		n, err := store.clear()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🧹 Deleted %d request(s)\n", n)
+	},
+}
+
+func init() {
+	webhooksCmd.AddCommand(webhooksSinkCmd)
+	webhooksSinkCmd.AddCommand(webhooksSinkListCmd)
+	webhooksSinkCmd.AddCommand(webhooksSinkShowCmd)
+	webhooksSinkCmd.AddCommand(webhooksSinkClearCmd)
+
+	webhooksSinkCmd.PersistentFlags().String("db", "", "Request index to use (default ~/.sapliy/sink.db)")
+	webhooksSinkCmd.Flags().Int("port", 4100, "Port to listen on")
+	webhooksSinkCmd.Flags().String("host", "127.0.0.1", "Address to listen on")
+	webhooksSinkCmd.Flags().String("secret", "", "Endpoint secret to verify signatures with (default $SAPLIY_WEBHOOK_SECRET)")
+	webhooksSinkCmd.Flags().Int("status", http.StatusOK, "Status code to answer with")
+	webhooksSinkCmd.Flags().Duration("latency", 0, "Wait this long before answering")
+	webhooksSinkCmd.Flags().StringArray("respond", nil, "Script answers per event type, e.g. 'payment.failed=503 after 2s' or 'payment.*=500x2,200' (repeatable)")
+	webhooksSinkCmd.Flags().Bool("reject-invalid", false, "Answer 400 to requests with a missing or invalid signature")
+
+	webhooksSinkListCmd.Flags().IntP("limit", "l", 20, "Number of requests to show")
+	webhooksSinkListCmd.Flags().String("type", "", "Only event types containing this")
+	webhooksSinkListCmd.Flags().String("signature", "", "Only requests whose signature is valid, invalid, missing or unchecked")
+	webhooksSinkListCmd.Flags().String("since", "", "Only requests received since this age or time (e.g. 1h)")
+
+	webhooksSinkClearCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// whyCheck is one stage an event passes through on its way to flows and
+// endpoints. Detail explains a failure, or what happened when OK.
+type whyCheck struct {
+	Stage  string `json:"stage"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// whyReport traces one event. Reason is the first failed check, empty when
+// the event reached everything it was routed to.
+type whyReport struct {
+	Event  webhookEventView `json:"event"`
+	Reason string           `json:"reason,omitempty"`
+	Checks []whyCheck       `json:"checks"`
+}
+
+func (r *whyReport) check(stage string, ok bool, format string, a ...interface{}) {
+	c := whyCheck{Stage: stage, OK: ok, Detail: fmt.Sprintf(format, a...)}
+	r.Checks = append(r.Checks, c)
+	if !ok && r.Reason == "" {
+		r.Reason = c.Detail
+	}
+}
+
+// targetReasons summarizes why targets did not match, e.g.
+// "fl_1: filtered out; we_2: endpoint disabled".
+func targetReasons(targets []routingTarget) string {
+	var reasons []string
+	for _, t := range targets {
+		if !t.Matched {
+			reasons = append(reasons, t.ID+": "+t.Reason)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// traceEvent re-runs routing for a past event and compares it with the
+// delivery attempts that were made.
+func traceEvent(ctx context.Context, eventID string) (*whyReport, error) {
+	var r whyReport
+	if err := apiRequest(ctx, http.MethodGet, "/v1/webhooks/events/"+url.PathEscape(eventID), nil, &r.Event); err != nil {
+		return nil, fmt.Errorf("fetch event: %w", err)
+	}
+	evt := r.Event
+
+	var zone struct {
+		Paused bool `json:"paused"`
+	}
+	if err := apiRequest(ctx, http.MethodGet, zonePath(evt.Zone, ""), nil, &zone); err != nil {
+		return nil, fmt.Errorf("fetch zone: %w", err)
+	}
+	if zone.Paused {
+		r.check("zone", false, "zone %s is paused, so its events are not processed", evt.Zone)
+	} else {
+		r.check("zone", true, "zone %s is active", evt.Zone)
+	}
+
+	req := map[string]interface{}{
+		"event":  map[string]interface{}{"id": evt.ID, "type": evt.Type, "zoneId": evt.Zone, "data": evt.Data},
+		"zoneId": evt.Zone,
+	}
+	var ex routingExplanation
+	if err := apiRequest(ctx, http.MethodPost, "/v1/routing/explain", req, &ex); err != nil {
+		return nil, fmt.Errorf("explain routing: %w", err)
+	}
+	if len(ex.Destinations) == 0 {
+		var reasons []string
+		for _, rule := range ex.Rules {
+			reasons = append(reasons, rule.ID+": "+rule.Reason)
+		}
+		detail := "the event was routed to no zone"
+		if len(reasons) > 0 {
+			detail += " (" + strings.Join(reasons, "; ") + ")"
+		}
+		r.check("routing", false, "%s", detail)
+		return &r, nil
+	}
+	var zones []string
+	var flows, endpoints []routingTarget
+	for _, d := range ex.Destinations {
+		zones = append(zones, d.Zone)
+		flows = append(flows, d.Flows...)
+		endpoints = append(endpoints, d.Endpoints...)
+	}
+	r.check("routing", true, "routed to %s", strings.Join(zones, ", "))
+
+	for _, stage := range []struct {
+		name    string
+		targets []routingTarget
+	}{{"flows", flows}, {"endpoints", endpoints}} {
+		matched := 0
+		for _, t := range stage.targets {
+			if t.Matched {
+				matched++
+			}
+		}
+		switch {
+		case len(stage.targets) == 0:
+			r.check(stage.name, false, "no %s in %s", stage.name, strings.Join(zones, ", "))
+		case matched == 0:
+			r.check(stage.name, false, "no %s matched (%s)", stage.name, targetReasons(stage.targets))
+		default:
+			r.check(stage.name, true, "%d of %d %s matched", matched, len(stage.targets), stage.name)
+		}
+	}
+
+	attempts, err := fetchDeliveryAttempts(eventID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("fetch delivery attempts: %w", err)
+	}
+	// Only the latest attempt per endpoint says whether it got the event.
+	last := map[string]deliveryAttempt{}
+	var order []string
+	for _, a := range attempts {
+		if _, ok := last[a.Endpoint]; !ok {
+			order = append(order, a.Endpoint)
+		}
+		if a.Attempt >= last[a.Endpoint].Attempt {
+			last[a.Endpoint] = a
+		}
+	}
+	for _, endpoint := range order {
+		a := last[endpoint]
+		if a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300 {
+			r.check("delivery", true, "%s returned %d on attempt %d", endpoint, a.StatusCode, a.Attempt)
+			continue
+		}
+		result := a.Error
+		if result == "" {
+			result = fmt.Sprintf("returned %d", a.StatusCode)
+		}
+		state := "no retries left"
+		if evt.Status == "pending" {
+			state = "will be retried"
+		}
+		r.check("delivery", false, "%s %s on attempt %d, %s", endpoint, result, a.Attempt, state)
+	}
+	if len(attempts) == 0 && evt.Status == "pending" {
+		r.check("delivery", false, "no delivery attempted yet; the event is still pending")
+	}
+	return &r, nil
+}
+
+var whyCmd = &cobra.Command{
+	Use:   "why [event_id]",
+	Short: "Explain why an event was not delivered or did not trigger a flow",
+	Long: `Trace an event through its zone, routing rules, flow and endpoint filters
+and delivery attempts, and print the first reason it stopped: a paused zone,
+no matching rule, a filtered-out flow, a disabled endpoint, a schema mismatch,
+or an endpoint that kept failing.`,
+	Example: `  sapliy why evt_abc123
+  sapliy why evt_abc123 -o json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		r, err := traceEvent(context.Background(), args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to trace event: %v\n", err)
+			os.Exit(1)
+		}
+
+		printOutput(r, func() {
+			fmt.Printf("🔎 %s (%s, zone %s, %s)\n", r.Event.ID, r.Event.Type, r.Event.Zone, r.Event.CreatedAt.Local().Format("Jan 02 15:04:05"))
+			fmt.Println(strings.Repeat("─", 60))
+			for _, c := range r.Checks {
+				mark := "✓"
+				if !c.OK {
+					mark = "✗"
+				}
+				fmt.Printf("  %s %-10s %s\n", mark, c.Stage, c.Detail)
+			}
+			fmt.Println()
+			if r.Reason == "" {
+				fmt.Println("✅ The event reached every flow and endpoint it was routed to.")
+			} else {
+				fmt.Printf("❌ %s\n", r.Reason)
+			}
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+}
@@ -0,0 +1,446 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Subprotocols the CLI offers for the event stream, most compact first.
+// With msgpack or protobuf the server sends events as binary frames; text
+// frames are always JSON.
+const (
+	frameProtocolMsgpack  = "sapliy.events.v1.msgpack"
+	frameProtocolProtobuf = "sapliy.events.v1.protobuf"
+	frameProtocolJSON     = "sapliy.events.v1.json"
+)
+
+// dialEventStream connects to an event stream, negotiating
+// permessage-deflate and binary frames, through the same proxy and trusted
+// CAs as API requests. Canceling ctx abandons the dial.
+func dialEventStream(ctx context.Context, wsURL string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	tlsConfig, err := apiTLSConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if u, err := url.Parse(wsURL); err == nil {
+		if err := checkResidency(u.Hostname()); err != nil {
+			return nil, nil, err
+		}
+	}
+	dialer := &websocket.Dialer{
+		Proxy:             streamProxy,
+		TLSClientConfig:   tlsConfig,
+		HandshakeTimeout:  45 * time.Second,
+		EnableCompression: true,
+		Subprotocols:      []string{frameProtocolMsgpack, frameProtocolProtobuf, frameProtocolJSON},
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		host := wsURL
+		if u, perr := url.Parse(wsURL); perr == nil {
+			host = u.Hostname()
+		}
+		// Let the next reconnect go to another API host.
+		if resp == nil && hostUnreachable(err, false) {
+			if hosts := apiHosts(); len(hosts) > 1 {
+				for _, h := range hosts {
+					if u, perr := url.Parse(h); perr == nil && u.Hostname() == host {
+						apiHealth.markDown(h)
+					}
+				}
+			}
+		}
+		return nil, resp, explainTLSError(host, err)
+	}
+	return conn, resp, nil
+}
+
+// retryableDialError reports whether a failed dial is worth retrying: the
+// network or the server failed, as opposed to the handshake being refused
+// (bad API key, unknown zone) or a TLS or residency problem.
+func retryableDialError(resp *http.Response, err error) bool {
+	if resp != nil {
+		return resp.StatusCode >= 500
+	}
+	return hostUnreachable(err, false)
+}
+
+// streamBackoff spaces out reconnects to the event stream: exponential from
+// 1s up to 30s, with jitter so many clients dropped at once do not reconnect
+// in lockstep.
+type streamBackoff struct {
+	attempt int
+}
+
+func (b *streamBackoff) next() time.Duration {
+	d := time.Second << min(b.attempt, 5)
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	b.attempt++
+	// Wait between half and all of d.
+	return d/2 + rand.N(d/2+1)
+}
+
+// reset starts over from the shortest wait, once a connection is working.
+func (b *streamBackoff) reset() { b.attempt = 0 }
+
+// streamFeatures describes what was negotiated, e.g. "deflate, msgpack".
+func streamFeatures(conn *websocket.Conn, resp *http.Response) string {
+	var features []string
+	if resp != nil && strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate") {
+		features = append(features, "deflate")
+	}
+	switch conn.Subprotocol() {
+	case frameProtocolMsgpack:
+		features = append(features, "msgpack")
+	case frameProtocolProtobuf:
+		features = append(features, "protobuf")
+	default:
+		features = append(features, "json")
+	}
+	return strings.Join(features, ", ")
+}
+
+// readEventFrame reads the next event from the stream as JSON, whatever
+// encoding it was sent in, so filters, sinks and forwarders only ever see
+// JSON. Binary frames that do not decode are reported and skipped.
+func readEventFrame(conn *websocket.Conn) ([]byte, error) {
+	for {
+		kind, message, err := conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if kind != websocket.BinaryMessage {
+			return message, nil
+		}
+
+		var event interface{}
+		switch conn.Subprotocol() {
+		case frameProtocolMsgpack:
+			event, err = decodeMsgpack(message)
+		case frameProtocolProtobuf:
+			event, err = protoStruct(message)
+		default:
+			err = fmt.Errorf("binary frame on a JSON stream")
+		}
+		var out []byte
+		if err == nil {
+			out, err = json.Marshal(event)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping undecodable %d-byte frame: %v\n", len(message), err)
+			continue
+		}
+		return out, nil
+	}
+}
+
+// msgpackReader decodes MessagePack into the same values encoding/json
+// produces: maps with string keys, []interface{}, float64, string, bool.
+type msgpackReader struct {
+	b []byte
+}
+
+func decodeMsgpack(b []byte) (interface{}, error) {
+	r := &msgpackReader{b: b}
+	v, err := r.value()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.b) > 0 {
+		return nil, fmt.Errorf("msgpack: %d trailing bytes", len(r.b))
+	}
+	return v, nil
+}
+
+func (r *msgpackReader) take(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b) {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out, nil
+}
+
+// uint reads an n-byte big-endian unsigned integer.
+func (r *msgpackReader) uint(n int) (uint64, error) {
+	b, err := r.take(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (r *msgpackReader) value() (interface{}, error) {
+	head, err := r.take(1)
+	if err != nil {
+		return nil, err
+	}
+	c := head[0]
+	switch {
+	case c <= 0x7f:
+		return float64(c), nil
+	case c >= 0xe0:
+		return float64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return r.mapOf(int(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return r.arrayOf(int(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return r.str(int(c & 0x1f))
+	}
+
+	// sizes maps the length-prefixed formats to the width of their length.
+	sizes := map[byte]int{
+		0xc4: 1, 0xc5: 2, 0xc6: 4, // bin
+		0xc7: 1, 0xc8: 2, 0xc9: 4, // ext
+		0xd9: 1, 0xda: 2, 0xdb: 4, // str
+		0xdc: 2, 0xdd: 4, // array
+		0xde: 2, 0xdf: 4, // map
+	}
+	if width, ok := sizes[c]; ok {
+		n64, err := r.uint(width)
+		if err != nil {
+			return nil, err
+		}
+		n := int(n64)
+		switch {
+		case c <= 0xc6:
+			b, err := r.take(n)
+			return base64.StdEncoding.EncodeToString(b), err
+		case c <= 0xc9:
+			return r.ext(n)
+		case c <= 0xdb:
+			return r.str(n)
+		case c <= 0xdd:
+			return r.arrayOf(n)
+		default:
+			return r.mapOf(n)
+		}
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := r.uint(1 << (c - 0xcc))
+		return float64(v), err
+	case 0xd0:
+		v, err := r.uint(1)
+		return float64(int8(v)), err
+	case 0xd1:
+		v, err := r.uint(2)
+		return float64(int16(v)), err
+	case 0xd2:
+		v, err := r.uint(4)
+		return float64(int32(v)), err
+	case 0xd3:
+		v, err := r.uint(8)
+		return float64(int64(v)), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return r.ext(1 << (c - 0xd4))
+	}
+	return nil, fmt.Errorf("msgpack: unknown type byte 0x%02x", c)
+}
+
+func (r *msgpackReader) str(n int) (interface{}, error) {
+	b, err := r.take(n)
+	return string(b), err
+}
+
+func (r *msgpackReader) arrayOf(n int) (interface{}, error) {
+	if n > len(r.b) {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (r *msgpackReader) mapOf(n int) (interface{}, error) {
+	out := make(map[string]interface{}, min(n, len(r.b)))
+	for i := 0; i < n; i++ {
+		k, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// ext decodes an extension value of n data bytes. The timestamp extension
+// (-1) becomes an RFC 3339 string; others become base64 of their data.
+func (r *msgpackReader) ext(n int) (interface{}, error) {
+	typ, err := r.take(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.take(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ[0]) != -1 {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	var t time.Time
+	switch n {
+	case 4:
+		t = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		t = time.Unix(int64(v&0x3ffffffff), int64(v>>34))
+	case 12:
+		t = time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data)))
+	default:
+		return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+// protoFields calls fn for each field of a protobuf message. For
+// length-delimited fields data is the payload; for varint and fixed-width
+// fields num holds the value.
+func protoFields(b []byte, fn func(field int, wire int, num uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("protobuf: bad tag")
+		}
+		b = b[n:]
+		field, wire := int(tag>>3), int(tag&7)
+		var num uint64
+		var data []byte
+		switch wire {
+		case 0:
+			num, n = binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("protobuf: bad varint")
+			}
+			b = b[n:]
+		case 1:
+			if len(b) < 8 {
+				return fmt.Errorf("protobuf: unexpected end of data")
+			}
+			num, b = binary.LittleEndian.Uint64(b), b[8:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return fmt.Errorf("protobuf: bad length")
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		case 5:
+			if len(b) < 4 {
+				return fmt.Errorf("protobuf: unexpected end of data")
+			}
+			num, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", wire)
+		}
+		if err := fn(field, wire, num, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoStruct decodes a google.protobuf.Struct, which protobuf streams use
+// to carry the event's JSON-like data without a schema:
+// Struct { map<string, Value> fields = 1; }.
+func protoStruct(b []byte) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	err := protoFields(b, func(field, wire int, _ uint64, entry []byte) error {
+		if field != 1 || wire != 2 {
+			return nil
+		}
+		var key string
+		var value interface{}
+		err := protoFields(entry, func(field, wire int, _ uint64, data []byte) error {
+			var err error
+			switch {
+			case field == 1 && wire == 2:
+				key = string(data)
+			case field == 2 && wire == 2:
+				value, err = protoValue(data)
+			}
+			return err
+		})
+		out[key] = value
+		return err
+	})
+	return out, err
+}
+
+// protoValue decodes a google.protobuf.Value.
+func protoValue(b []byte) (interface{}, error) {
+	var v interface{}
+	err := protoFields(b, func(field, wire int, num uint64, data []byte) error {
+		var err error
+		switch field {
+		case 1:
+			v = nil
+		case 2:
+			v = math.Float64frombits(num)
+		case 3:
+			v = string(data)
+		case 4:
+			v = num != 0
+		case 5:
+			v, err = protoStruct(data)
+		case 6:
+			list := []interface{}{}
+			err = protoFields(data, func(field, wire int, _ uint64, item []byte) error {
+				if field != 1 || wire != 2 {
+					return nil
+				}
+				iv, err := protoValue(item)
+				list = append(list, iv)
+				return err
+			})
+			v = list
+		}
+		return err
+	})
+	return v, err
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
 	fintech "github.com/sapliy/fintech-sdk-go"
@@ -26,20 +27,39 @@ var listZonesCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		client := fintech.NewClient(apiKey)
+		client := newClient()
 		zones, err := client.Zones.List(context.Background(), orgID)
 		if err != nil {
 			fmt.Printf("Error listing zones: %v\n", err)
 			return
 		}
 
-		fmt.Printf("%-20s %-20s %-10s\n", "ID", "NAME", "MODE")
+		views := make([]zoneView, 0, len(zones))
 		for _, z := range zones {
-			fmt.Printf("%-20s %-20s %-10s\n", z.ID, z.Name, z.Mode)
+			views = append(views, zoneView{ID: z.ID, Name: z.Name, Mode: z.Mode})
 		}
+
+		current := viper.GetString("current_zone")
+		printOutput(views, func() {
+			fmt.Printf("  %-20s %-20s %-10s\n", "ID", "NAME", "MODE")
+			for _, z := range views {
+				marker := " "
+				if z.ID == current {
+					marker = "*"
+				}
+				fmt.Printf("%s %-20s %-20s %-10s\n", marker, z.ID, z.Name, z.Mode)
+			}
+		})
 	},
 }
 
+// zoneView is a zone as printed by list commands.
+type zoneView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Mode string `json:"mode"`
+}
+
 var createZoneCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new zone",
@@ -54,7 +74,7 @@ var createZoneCmd = &cobra.Command{
 		name, _ := cmd.Flags().GetString("name")
 		mode, _ := cmd.Flags().GetString("mode")
 
-		client := fintech.NewClient(apiKey)
+		client := newClient()
 		z, err := client.Zones.Create(context.Background(), &fintech.CreateZoneRequest{
 			OrgID: orgID,
 			Name:  name,
@@ -66,24 +86,129 @@ var createZoneCmd = &cobra.Command{
 		}
 
 		fmt.Printf("Zone created successfully! ID: %s, Mode: %s\n", z.ID, z.Mode)
+		if use, _ := cmd.Flags().GetBool("use"); use {
+			if err := useZone(z.ID); err != nil {
+				fmt.Printf("Error saving config: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Switched to zone: %s\n", z.ID)
+		}
 	},
 }
 
-var switchZoneCmd = &cobra.Command{
-	Use:   "switch [id]",
-	Short: "Switch current zone",
-	Args:  cobra.ExactArgs(1),
+// resolveZone is the zone a zone-scoped command works in: its --zone flag,
+// or else the current zone set with 'sapliy zones use'. It is empty when
+// neither is set.
+func resolveZone(cmd *cobra.Command) string {
+	if f := cmd.Flags().Lookup("zone"); f != nil && f.Value.Type() == "string" && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	return viper.GetString("current_zone")
+}
+
+// requireZone is resolveZone for commands that cannot run without a zone.
+// It exits when no zone is selected.
+func requireZone(cmd *cobra.Command) string {
+	zone := resolveZone(cmd)
+	if zone == "" {
+		fmt.Println("Error: no zone selected. Pass --zone or run 'sapliy zones use <zone_id>'.")
+		os.Exit(1)
+	}
+	return zone
+}
+
+// useZone makes zone the current zone and saves the config.
+func useZone(zone string) error {
+	viper.Set("current_zone", zone)
+	return saveConfig("current_zone")
+}
+
+var useZoneCmd = &cobra.Command{
+	Use:     "use [zone_id]",
+	Aliases: []string{"switch"},
+	Short:   "Set the zone commands use when --zone is not given",
+	Long: `Set the current zone, saved as current_zone in the config file. Commands
+that work in a zone use it unless --zone is given. When logged in, the zone
+is looked up first so a typo is not saved.`,
+	Example: `  sapliy zones use zone_abc123`,
+	Args:    cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		viper.Set("current_zone", args[0])
-		err := viper.WriteConfig()
-		if err != nil {
-			err = viper.SafeWriteConfig()
+		apiKey := viper.GetString("api_key")
+		orgID := viper.GetString("org_id")
+		name := ""
+		if apiKey != "" && orgID != "" {
+			client := newClient()
+			zones, err := client.Zones.List(context.Background(), orgID)
+			if err == nil {
+				found := false
+				for _, z := range zones {
+					if z.ID == args[0] {
+						found, name = true, z.Name
+						break
+					}
+				}
+				if !found {
+					fmt.Printf("Error: zone %s not found in %s. See 'sapliy zones list'.\n", args[0], orgID)
+					os.Exit(1)
+				}
+			}
 		}
-		if err != nil {
+
+		if err := useZone(args[0]); err != nil {
 			fmt.Printf("Error saving config: %v\n", err)
-			return
+			os.Exit(1)
+		}
+		if name != "" {
+			fmt.Printf("Switched to zone: %s (%s)\n", args[0], name)
+		} else {
+			fmt.Printf("Switched to zone: %s\n", args[0])
+		}
+	},
+}
+
+var currentZoneCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the current zone",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		zone := viper.GetString("current_zone")
+		if zone == "" {
+			fmt.Println("No current zone. Use 'sapliy zones use <zone_id>'.")
+			os.Exit(1)
+		}
+		fmt.Println(zone)
+	},
+}
+
+var deleteZoneCmd = &cobra.Command{
+	Use:   "delete [zone_id]",
+	Short: "Delete a zone and everything in it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("api_key") == "" {
+			fmt.Println("Error: API key not set. Use 'sapliy auth login'.")
+			os.Exit(1)
+		}
+
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			if !confirm(fmt.Sprintf("Delete zone %s with all its flows, triggers and events? This cannot be undone. [y/N]: ", args[0])) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if err := apiRequest(context.Background(), http.MethodDelete, zonePath(args[0], ""), nil, nil); err != nil {
+			fmt.Printf("❌ Failed to delete zone: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  Deleted zone %s\n", args[0])
+		if viper.GetString("current_zone") == args[0] {
+			if err := useZone(""); err != nil {
+				fmt.Printf("Error saving config: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("It was the current zone; pick another with 'sapliy zones use <zone_id>'.")
 		}
-		fmt.Printf("Switched to zone: %s\n", args[0])
 	},
 }
 
@@ -91,9 +216,13 @@ func init() {
 	rootCmd.AddCommand(zonesCmd)
 	zonesCmd.AddCommand(listZonesCmd)
 	zonesCmd.AddCommand(createZoneCmd)
-	zonesCmd.AddCommand(switchZoneCmd)
+	zonesCmd.AddCommand(useZoneCmd)
+	zonesCmd.AddCommand(currentZoneCmd)
+	zonesCmd.AddCommand(deleteZoneCmd)
 
 	createZoneCmd.Flags().StringP("name", "n", "", "Name of the zone")
 	createZoneCmd.Flags().StringP("mode", "m", "test", "Mode (test/live)")
+	createZoneCmd.Flags().Bool("use", false, "Make the new zone the current zone")
 	createZoneCmd.MarkFlagRequired("name")
+	deleteZoneCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 }
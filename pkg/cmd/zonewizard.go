@@ -0,0 +1,207 @@
+//go:build !headless
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// zoneFile is a zone file as 'generate zone' writes it, with fields in the
+// order they are written.
+type zoneFile struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Mode        string        `json:"mode,omitempty"`
+	Version     string        `json:"version"`
+	Triggers    []zoneTrigger `json:"triggers"`
+	Actions     []zoneAction  `json:"actions"`
+}
+
+type zoneTrigger struct {
+	Event     string `json:"event"`
+	Condition string `json:"condition,omitempty"`
+}
+
+type zoneAction struct {
+	ID     string            `json:"id"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+}
+
+// zoneActionFields are the action types the zone wizard offers and the
+// config it asks for each; the first field is required.
+var zoneActionFields = []struct {
+	kind   string
+	fields []string
+}{
+	{"webhook", []string{"url", "secret"}},
+	{"http", []string{"url", "method"}},
+	{"email", []string{"to", "template"}},
+	{"sms", []string{"to", "message"}},
+	{"notify", []string{"channel", "message"}},
+}
+
+// zoneWizard asks for the parts of a zone on the terminal. Answers are
+// checked against the zone schema as they are given, so a wizard-made file
+// always passes 'sapliy validate'.
+type zoneWizard struct {
+	lines  *lineReader
+	schema *jsonSchema
+}
+
+// ask reads an answer, returning def for an empty one.
+func (w *zoneWizard) ask(prompt, def string) (string, error) {
+	if def != "" {
+		prompt += " [" + def + "]"
+	}
+	line, err := w.lines.readLine(prompt + ": ")
+	if err != nil {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// askValid asks until the answer is one of choices (or anything, for nil).
+func (w *zoneWizard) askValid(prompt, def string, choices []string) (string, error) {
+	for {
+		answer, err := w.ask(prompt, def)
+		if err != nil || choices == nil || answer == "" {
+			return answer, err
+		}
+		for _, c := range choices {
+			if strings.EqualFold(answer, c) {
+				return c, nil
+			}
+		}
+		fmt.Printf("   Use one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// events asks for the trigger event types until every one is valid.
+func (w *zoneWizard) events() ([]string, error) {
+	for {
+		answer, err := w.ask("Trigger event types, separated by spaces (Tab completes)", "payment.succeeded")
+		if err != nil {
+			return nil, err
+		}
+		var events, bad []string
+		for _, e := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if problems := w.schema.check(map[string]interface{}{"$ref": "#/$defs/eventType"}, e, e); len(problems) > 0 {
+				bad = append(bad, e)
+			}
+			events = append(events, e)
+		}
+		if len(bad) == 0 {
+			return events, nil
+		}
+		fmt.Printf("   Not an event type: %s (use e.g. payment.succeeded or payment.*)\n", strings.Join(bad, ", "))
+	}
+}
+
+// run asks for everything but the name and returns the zone.
+func (w *zoneWizard) run(name string) (*zoneFile, error) {
+	zone := &zoneFile{ID: "zone_" + name, Name: name, Version: "1.0.0", Triggers: []zoneTrigger{}, Actions: []zoneAction{}}
+	var err error
+	if zone.Description, err = w.ask("Description", "Automation zone for "+name); err != nil {
+		return nil, err
+	}
+	if zone.Mode, err = w.askValid("Environment (test/live)", "test", []string{"test", "live"}); err != nil {
+		return nil, err
+	}
+
+	w.lines.complete = func(string) []string { return fixtureEvents }
+	events, err := w.events()
+	w.lines.complete = nil
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		cond, err := w.ask(fmt.Sprintf("  Condition for %s (optional, e.g. event.data.amount > 1000)", e), "")
+		if err != nil {
+			return nil, err
+		}
+		zone.Triggers = append(zone.Triggers, zoneTrigger{Event: e, Condition: cond})
+	}
+
+	kinds := make([]string, len(zoneActionFields))
+	for i, a := range zoneActionFields {
+		kinds[i] = a.kind
+	}
+	for {
+		kind, err := w.askValid(fmt.Sprintf("Add an action (%s; Enter when done)", strings.Join(kinds, ", ")), "", kinds)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" {
+			break
+		}
+		action := zoneAction{ID: fmt.Sprintf("%s_%d", kind, len(zone.Actions)+1), Type: kind, Config: map[string]string{}}
+		for _, a := range zoneActionFields {
+			if a.kind != kind {
+				continue
+			}
+			for i, field := range a.fields {
+				for {
+					value, err := w.ask("  "+field, "")
+					if err != nil {
+						return nil, err
+					}
+					if value != "" {
+						action.Config[field] = value
+					}
+					if value != "" || i > 0 {
+						break
+					}
+					fmt.Printf("   %s is required for a %s action\n", field, kind)
+				}
+			}
+		}
+		zone.Actions = append(zone.Actions, action)
+	}
+	return zone, nil
+}
+
+// zoneFromWizard runs the zone wizard and returns the zone as JSON, or ""
+// if the user did not confirm it.
+func zoneFromWizard(name string) (string, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return "", fmt.Errorf("--interactive needs an interactive terminal")
+	}
+	schema, err := loadSchema("zone")
+	if err != nil {
+		return "", err
+	}
+	w := &zoneWizard{lines: &lineReader{}, schema: schema}
+	fmt.Printf("🧭 New zone %s (Enter keeps the [default])\n", name)
+	zone, err := w.run(name)
+	if err != nil {
+		return "", err
+	}
+
+	// Conditions use > and <, which are not escaped in a hand-written file.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(zone); err != nil {
+		return "", err
+	}
+	content := strings.TrimSuffix(buf.String(), "\n")
+	fmt.Printf("\n%s\n\n", content)
+	answer, err := w.ask("Write this zone? [Y/n]", "")
+	if err != nil {
+		return "", err
+	}
+	if a := strings.ToLower(answer); a != "" && a != "y" {
+		return "", nil
+	}
+	return content, nil
+}